@@ -11,10 +11,27 @@ import (
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/whoAngeel/wms-lite/internal/alert"
 	"github.com/whoAngeel/wms-lite/internal/auth"
+	"github.com/whoAngeel/wms-lite/internal/category"
+	"github.com/whoAngeel/wms-lite/internal/cyclecount"
+	"github.com/whoAngeel/wms-lite/internal/location"
+	"github.com/whoAngeel/wms-lite/internal/lot"
 	"github.com/whoAngeel/wms-lite/internal/movement"
+	"github.com/whoAngeel/wms-lite/internal/picking"
 	"github.com/whoAngeel/wms-lite/internal/platform"
 	"github.com/whoAngeel/wms-lite/internal/product"
+	"github.com/whoAngeel/wms-lite/internal/purchasing"
+	"github.com/whoAngeel/wms-lite/internal/reasoncode"
+	"github.com/whoAngeel/wms-lite/internal/reconciliation"
+	"github.com/whoAngeel/wms-lite/internal/report"
+	"github.com/whoAngeel/wms-lite/internal/reservation"
+	"github.com/whoAngeel/wms-lite/internal/rma"
+	"github.com/whoAngeel/wms-lite/internal/sales"
+	"github.com/whoAngeel/wms-lite/internal/serial"
+	"github.com/whoAngeel/wms-lite/internal/shipping"
+	"github.com/whoAngeel/wms-lite/internal/uom"
+	"github.com/whoAngeel/wms-lite/internal/warehouse"
 )
 
 func main() {
@@ -58,14 +75,92 @@ func main() {
 
 	logger.Info().Msg("Database connected successfully")
 
-	productRepo := product.NewRepository(db, logger)
-	productService := *product.NewService(productRepo, logger, cache)
-	productHandler := product.NewHandler(&productService, logger)
+	alertRepo := alert.NewRepository(db, logger)
+	alertService := alert.NewService(alertRepo, logger)
+	alertHandler := alert.NewHandler(alertService, logger)
 
 	movementRepo := movement.NewRepository(db)
-	movementService := *movement.NewService(movementRepo, db, cache, &logger)
+	movementService := *movement.NewService(movementRepo, db, cache, alertService, movement.CostingMethod(cfg.Costing.Method), &logger)
 	movementHandler := movement.NewHandler(&movementService, logger)
 
+	productRepo := product.NewRepository(db, logger)
+	productService := *product.NewService(productRepo, db, &movementService, logger, cache, alertService)
+	productHandler := product.NewHandler(&productService, logger)
+
+	warehouseRepo := warehouse.NewRepository(db, logger)
+	warehouseService := warehouse.NewService(warehouseRepo, logger)
+	warehouseHandler := warehouse.NewHandler(warehouseService, logger)
+
+	locationRepo := location.NewRepository(db, logger)
+	locationService := location.NewService(locationRepo, logger)
+	locationHandler := location.NewHandler(locationService, logger)
+
+	reasonCodeRepo := reasoncode.NewRepository(db, logger)
+	reasonCodeService := reasoncode.NewService(reasonCodeRepo, logger)
+	reasonCodeHandler := reasoncode.NewHandler(reasonCodeService, logger)
+
+	lotRepo := lot.NewRepository(db, logger)
+	lotService := lot.NewService(lotRepo, logger)
+	lotHandler := lot.NewHandler(lotService, logger)
+
+	serialRepo := serial.NewRepository(db, logger)
+	serialService := serial.NewService(serialRepo, logger)
+	serialHandler := serial.NewHandler(serialService, logger)
+
+	uomRepo := uom.NewRepository(db, logger)
+	uomService := uom.NewService(uomRepo, logger)
+	uomHandler := uom.NewHandler(uomService, logger)
+
+	categoryRepo := category.NewRepository(db, logger)
+	categoryService := category.NewService(categoryRepo, logger)
+	categoryHandler := category.NewHandler(categoryService, logger)
+
+	cycleCountRepo := cyclecount.NewRepository(db, logger)
+	cycleCountService := cyclecount.NewService(cycleCountRepo, db, &movementService, logger)
+	cycleCountHandler := cyclecount.NewHandler(cycleCountService, logger)
+
+	purchasingRepo := purchasing.NewRepository(db, logger)
+	purchasingService := purchasing.NewService(purchasingRepo, db, &movementService, logger)
+	purchasingHandler := purchasing.NewHandler(purchasingService, logger)
+
+	reservationRepo := reservation.NewRepository(db, logger)
+	reservationService := reservation.NewService(reservationRepo, db, cache, logger)
+	reservationHandler := reservation.NewHandler(reservationService, logger)
+
+	// libera las reservas vencidas en segundo plano hasta el shutdown
+	sweeperCtx, stopSweeper := context.WithCancel(context.Background())
+	defer stopSweeper()
+	go reservationService.RunSweeper(sweeperCtx, cfg.Reservation.SweepInterval)
+
+	salesRepo := sales.NewRepository(db, logger)
+	salesService := sales.NewService(salesRepo, db, &movementService, reservationService, logger)
+	salesHandler := sales.NewHandler(salesService, logger)
+
+	pickingRepo := picking.NewRepository(db, logger)
+	pickingService := picking.NewService(pickingRepo, db, &movementService, salesService, logger)
+	pickingHandler := picking.NewHandler(pickingService, logger)
+
+	shippingRepo := shipping.NewRepository(db, logger)
+	shippingService := shipping.NewService(shippingRepo, db, &movementService, salesService, logger)
+	shippingHandler := shipping.NewHandler(shippingService, logger)
+
+	rmaRepo := rma.NewRepository(db, logger)
+	rmaService := rma.NewService(rmaRepo, db, &movementService, logger)
+	rmaHandler := rma.NewHandler(rmaService, logger)
+
+	reportRepo := report.NewRepository(db, logger)
+	reportService := report.NewService(reportRepo, cfg.Costing.Method, logger)
+	reportHandler := report.NewHandler(reportService, logger)
+
+	// cierra cada dia con checkpoints de stock para las consultas de stock a una fecha
+	checkpointCtx, stopCheckpointer := context.WithCancel(context.Background())
+	defer stopCheckpointer()
+	go reportService.RunCheckpointer(checkpointCtx, cfg.Report.CheckpointInterval)
+
+	reconciliationRepo := reconciliation.NewRepository(db, logger)
+	reconciliationService := reconciliation.NewService(reconciliationRepo, db, &movementService, cache, alertService, logger)
+	reconciliationHandler := reconciliation.NewHandler(reconciliationService, logger)
+
 	authRepo := auth.NewRepository(db, logger)
 	authService := auth.NewService(authRepo, db, logger, cfg.Auth.JWTSecret)
 	authHandler := auth.NewHandler(authService, logger)
@@ -85,10 +180,10 @@ func main() {
 			"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS",
 		},
 		AllowHeaders: []string{
-			"Origin", "Content-Type", "Accept", "Authorization",
+			"Origin", "Content-Type", "Accept", "Authorization", "If-Match", platform.IdempotencyHeader,
 		},
 		ExposeHeaders: []string{
-			"Content-Length",
+			"Content-Length", "ETag", platform.IdempotencyReplayHeader,
 		},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
@@ -108,7 +203,7 @@ func main() {
 	router.Use(platform.LoggerMiddleware())
 	router.Use(gin.Recovery())
 
-	setupRoutes(router, productHandler, movementHandler, authHandler, authMiddleware)
+	setupRoutes(router, productHandler, movementHandler, warehouseHandler, locationHandler, reasonCodeHandler, cycleCountHandler, lotHandler, serialHandler, uomHandler, categoryHandler, alertHandler, purchasingHandler, salesHandler, reservationHandler, pickingHandler, shippingHandler, rmaHandler, reportHandler, reconciliationHandler, authHandler, authMiddleware, platform.IdempotencyMiddleware(cache))
 
 	srv := &http.Server{
 		Addr:           ":" + cfg.Server.Port,
@@ -132,6 +227,8 @@ func main() {
 	<-quit // block until signal received
 
 	logger.Info().Msg("Shutting down server...")
+	stopSweeper()
+	stopCheckpointer()
 	// context con timeout para shutdown
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -143,12 +240,31 @@ func main() {
 	logger.Info().Msg("Server stopped successfully")
 }
 
+// idempotency va en cada ruta que modifica, despues de RequireRole: un request rechazado por rol no ocupa la clave
 func setupRoutes(
 	router *gin.Engine,
 	productHandler *product.Handler,
 	movementHandler *movement.Handler,
+	warehouseHandler *warehouse.Handler,
+	locationHandler *location.Handler,
+	reasonCodeHandler *reasoncode.Handler,
+	cycleCountHandler *cyclecount.Handler,
+	lotHandler *lot.Handler,
+	serialHandler *serial.Handler,
+	uomHandler *uom.Handler,
+	categoryHandler *category.Handler,
+	alertHandler *alert.Handler,
+	purchasingHandler *purchasing.Handler,
+	salesHandler *sales.Handler,
+	reservationHandler *reservation.Handler,
+	pickingHandler *picking.Handler,
+	shippingHandler *shipping.Handler,
+	rmaHandler *rma.Handler,
+	reportHandler *report.Handler,
+	reconciliationHandler *reconciliation.Handler,
 	authHandler *auth.Handler,
 	authMiddleware *auth.Middleware,
+	idempotency gin.HandlerFunc,
 ) {
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
@@ -179,25 +295,211 @@ func setupRoutes(
 		products := v1.Group("/products")
 		products.Use(authMiddleware.RequireAuth())
 		{
-			products.POST("", authMiddleware.RequireRole("admin", "user"), productHandler.Create)
+			products.POST("", authMiddleware.RequireRole("admin", "user"), idempotency, productHandler.Create)
 			products.GET("", productHandler.GetAll)
 			products.GET("/search", productHandler.Search)
 			products.GET("/deleted", authMiddleware.RequireRole("admin"), productHandler.GetAllDeleted)
 			products.GET("/:id", productHandler.GetByID)
 			products.GET("/sku/:sku", productHandler.GetBySKU)
-			products.PUT("/:id", authMiddleware.RequireRole("admin", "user"), productHandler.Update)
-			products.DELETE("/:id", authMiddleware.RequireRole("admin"), productHandler.SoftDelete)
-			products.PATCH("/:id", authMiddleware.RequireRole("admin"), productHandler.Restore)
+			products.GET("/barcode/:code", productHandler.GetByBarcode)
+			products.PUT("/:id", authMiddleware.RequireRole("admin", "user"), idempotency, productHandler.Update)
+			products.DELETE("/:id", authMiddleware.RequireRole("admin"), idempotency, productHandler.SoftDelete)
+			products.PATCH("/:id", authMiddleware.RequireRole("admin"), idempotency, productHandler.Restore)
+			products.POST("/:id/barcodes", authMiddleware.RequireRole("admin", "user"), idempotency, productHandler.AddBarcode)
+			products.DELETE("/:id/barcodes/:code", authMiddleware.RequireRole("admin"), idempotency, productHandler.RemoveBarcode)
+			products.GET("/:id/uoms", uomHandler.ListProductUoMs)
+			products.GET("/:id/stock", reportHandler.ProductStock)
+			products.PUT("/:id/uoms/:code", authMiddleware.RequireRole("admin"), idempotency, uomHandler.SetProductUoM)
+			products.DELETE("/:id/uoms/:code", authMiddleware.RequireRole("admin"), idempotency, uomHandler.DeleteProductUoM)
 
 		}
 
 		movements := v1.Group("/movements")
 		movements.Use(authMiddleware.RequireAuth())
 		{
-			movements.POST("", authMiddleware.RequireRole("admin", "user"), movementHandler.Create)
+			movements.POST("", authMiddleware.RequireRole("admin", "user"), idempotency, movementHandler.Create)
+			movements.POST("/batch", authMiddleware.RequireRole("admin", "user"), idempotency, movementHandler.CreateBatch)
+			movements.POST("/adjustments", authMiddleware.RequireRole("admin"), idempotency, movementHandler.CreateAdjustment)
+			movements.POST("/status-changes", authMiddleware.RequireRole("admin", "user"), idempotency, movementHandler.CreateStatusChange)
+			movements.POST("/:id/reverse", authMiddleware.RequireRole("admin"), idempotency, movementHandler.Reverse)
 			movements.GET("", movementHandler.List)
 			movements.GET("/:id", movementHandler.GetByID)
 			movements.GET("/product/:id", movementHandler.ListByProductID)
 		}
+
+		warehouses := v1.Group("/warehouses")
+		warehouses.Use(authMiddleware.RequireAuth())
+		{
+			warehouses.POST("", authMiddleware.RequireRole("admin"), idempotency, warehouseHandler.Create)
+			warehouses.GET("", warehouseHandler.List)
+			warehouses.GET("/:id", warehouseHandler.GetByID)
+			warehouses.GET("/:id/stock", warehouseHandler.ListStock)
+			warehouses.PUT("/:id", authMiddleware.RequireRole("admin"), idempotency, warehouseHandler.Update)
+			warehouses.DELETE("/:id", authMiddleware.RequireRole("admin"), idempotency, warehouseHandler.Delete)
+		}
+
+		locations := v1.Group("/locations")
+		locations.Use(authMiddleware.RequireAuth())
+		{
+			locations.POST("", authMiddleware.RequireRole("admin"), idempotency, locationHandler.Create)
+			locations.GET("", locationHandler.List)
+			locations.GET("/sku/:sku", locationHandler.ListBySKU)
+			locations.GET("/:id", locationHandler.GetByID)
+			locations.GET("/:id/contents", locationHandler.ListContents)
+			locations.PUT("/:id", authMiddleware.RequireRole("admin"), idempotency, locationHandler.Update)
+			locations.DELETE("/:id", authMiddleware.RequireRole("admin"), idempotency, locationHandler.Delete)
+		}
+
+		reasonCodes := v1.Group("/reason-codes")
+		reasonCodes.Use(authMiddleware.RequireAuth())
+		{
+			reasonCodes.POST("", authMiddleware.RequireRole("admin"), idempotency, reasonCodeHandler.Create)
+			reasonCodes.GET("", reasonCodeHandler.List)
+			reasonCodes.GET("/:code", reasonCodeHandler.GetByCode)
+			reasonCodes.PUT("/:code", authMiddleware.RequireRole("admin"), idempotency, reasonCodeHandler.Update)
+			reasonCodes.DELETE("/:code", authMiddleware.RequireRole("admin"), idempotency, reasonCodeHandler.Delete)
+		}
+
+		cycleCounts := v1.Group("/cycle-counts")
+		cycleCounts.Use(authMiddleware.RequireAuth())
+		{
+			cycleCounts.POST("", authMiddleware.RequireRole("admin", "user"), idempotency, cycleCountHandler.Create)
+			cycleCounts.GET("", cycleCountHandler.List)
+			cycleCounts.GET("/:id", cycleCountHandler.GetByID)
+			cycleCounts.POST("/:id/counts", authMiddleware.RequireRole("admin", "user"), idempotency, cycleCountHandler.SubmitCounts)
+			cycleCounts.POST("/:id/review", authMiddleware.RequireRole("admin", "user"), idempotency, cycleCountHandler.SubmitForReview)
+			cycleCounts.POST("/:id/reopen", authMiddleware.RequireRole("admin"), idempotency, cycleCountHandler.Reopen)
+			cycleCounts.POST("/:id/approve", authMiddleware.RequireRole("admin"), idempotency, cycleCountHandler.Approve)
+		}
+
+		lots := v1.Group("/lots")
+		lots.Use(authMiddleware.RequireAuth())
+		{
+			lots.GET("", lotHandler.List)
+			lots.GET("/:id", lotHandler.GetByID)
+		}
+
+		serials := v1.Group("/serials")
+		serials.Use(authMiddleware.RequireAuth())
+		{
+			serials.GET("/:serial", serialHandler.GetByNumber)
+		}
+
+		uoms := v1.Group("/uoms")
+		uoms.Use(authMiddleware.RequireAuth())
+		{
+			uoms.POST("", authMiddleware.RequireRole("admin"), idempotency, uomHandler.Create)
+			uoms.GET("", uomHandler.List)
+			uoms.GET("/:code", uomHandler.GetByCode)
+			uoms.PUT("/:code", authMiddleware.RequireRole("admin"), idempotency, uomHandler.Update)
+			uoms.DELETE("/:code", authMiddleware.RequireRole("admin"), idempotency, uomHandler.Delete)
+		}
+
+		categories := v1.Group("/categories")
+		categories.Use(authMiddleware.RequireAuth())
+		{
+			categories.POST("", authMiddleware.RequireRole("admin"), idempotency, categoryHandler.Create)
+			categories.GET("", categoryHandler.List)
+			categories.GET("/:id", categoryHandler.GetByID)
+			categories.PUT("/:id", authMiddleware.RequireRole("admin"), idempotency, categoryHandler.Update)
+			categories.DELETE("/:id", authMiddleware.RequireRole("admin"), idempotency, categoryHandler.Delete)
+		}
+
+		alerts := v1.Group("/alerts")
+		alerts.Use(authMiddleware.RequireAuth())
+		{
+			alerts.GET("/low-stock", alertHandler.ListLowStock)
+			alerts.GET("/reorder-suggestions", alertHandler.ReorderSuggestions)
+		}
+
+		suppliers := v1.Group("/suppliers")
+		suppliers.Use(authMiddleware.RequireAuth())
+		{
+			suppliers.POST("", authMiddleware.RequireRole("admin"), idempotency, purchasingHandler.CreateSupplier)
+			suppliers.GET("", purchasingHandler.ListSuppliers)
+			suppliers.GET("/:id", purchasingHandler.GetSupplier)
+			suppliers.PUT("/:id", authMiddleware.RequireRole("admin"), idempotency, purchasingHandler.UpdateSupplier)
+			suppliers.DELETE("/:id", authMiddleware.RequireRole("admin"), idempotency, purchasingHandler.DeleteSupplier)
+		}
+
+		purchaseOrders := v1.Group("/purchase-orders")
+		purchaseOrders.Use(authMiddleware.RequireAuth())
+		{
+			purchaseOrders.POST("", authMiddleware.RequireRole("admin"), idempotency, purchasingHandler.CreateOrder)
+			purchaseOrders.GET("", purchasingHandler.ListOrders)
+			purchaseOrders.GET("/:id", purchasingHandler.GetOrder)
+			purchaseOrders.PUT("/:id", authMiddleware.RequireRole("admin"), idempotency, purchasingHandler.UpdateOrder)
+			purchaseOrders.POST("/:id/send", authMiddleware.RequireRole("admin"), idempotency, purchasingHandler.SendOrder)
+			purchaseOrders.POST("/:id/receive", authMiddleware.RequireRole("admin", "user"), idempotency, purchasingHandler.ReceiveOrder)
+			purchaseOrders.POST("/:id/close", authMiddleware.RequireRole("admin"), idempotency, purchasingHandler.CloseOrder)
+		}
+
+		salesOrders := v1.Group("/sales-orders")
+		salesOrders.Use(authMiddleware.RequireAuth())
+		{
+			salesOrders.POST("", authMiddleware.RequireRole("admin", "user"), idempotency, salesHandler.Create)
+			salesOrders.GET("", salesHandler.List)
+			salesOrders.GET("/:id", salesHandler.GetByID)
+			salesOrders.POST("/:id/allocate", authMiddleware.RequireRole("admin", "user"), idempotency, salesHandler.Allocate)
+			salesOrders.POST("/:id/pick", authMiddleware.RequireRole("admin", "user"), idempotency, salesHandler.Pick)
+			salesOrders.POST("/:id/pack", authMiddleware.RequireRole("admin", "user"), idempotency, salesHandler.Pack)
+			salesOrders.POST("/:id/ship", authMiddleware.RequireRole("admin", "user"), idempotency, salesHandler.Ship)
+			salesOrders.POST("/:id/cancel", authMiddleware.RequireRole("admin"), idempotency, salesHandler.Cancel)
+		}
+
+		reservations := v1.Group("/reservations")
+		reservations.Use(authMiddleware.RequireAuth())
+		{
+			reservations.POST("", authMiddleware.RequireRole("admin", "user"), idempotency, reservationHandler.Create)
+			reservations.GET("", reservationHandler.List)
+			reservations.GET("/:id", reservationHandler.GetByID)
+			reservations.DELETE("/:id", authMiddleware.RequireRole("admin", "user"), idempotency, reservationHandler.Release)
+		}
+
+		waves := v1.Group("/waves")
+		waves.Use(authMiddleware.RequireAuth())
+		{
+			waves.POST("", authMiddleware.RequireRole("admin", "user"), idempotency, pickingHandler.Create)
+			waves.GET("", pickingHandler.List)
+			waves.GET("/:id", pickingHandler.GetByID)
+			waves.POST("/:id/tasks/:taskId/confirm", authMiddleware.RequireRole("admin", "user"), idempotency, pickingHandler.ConfirmPick)
+			waves.POST("/:id/cancel", authMiddleware.RequireRole("admin"), idempotency, pickingHandler.Cancel)
+		}
+
+		shipments := v1.Group("/shipments")
+		shipments.Use(authMiddleware.RequireAuth())
+		{
+			shipments.POST("", authMiddleware.RequireRole("admin", "user"), idempotency, shippingHandler.Create)
+			shipments.GET("", shippingHandler.List)
+			shipments.GET("/:id", shippingHandler.GetByID)
+			shipments.POST("/:id/packages", authMiddleware.RequireRole("admin", "user"), idempotency, shippingHandler.AddPackage)
+			shipments.DELETE("/:id/packages/:packageId", authMiddleware.RequireRole("admin", "user"), idempotency, shippingHandler.RemovePackage)
+			shipments.POST("/:id/confirm", authMiddleware.RequireRole("admin", "user"), idempotency, shippingHandler.Confirm)
+			shipments.POST("/:id/cancel", authMiddleware.RequireRole("admin"), idempotency, shippingHandler.Cancel)
+		}
+
+		rmas := v1.Group("/rmas")
+		rmas.Use(authMiddleware.RequireAuth())
+		{
+			rmas.POST("", authMiddleware.RequireRole("admin", "user"), idempotency, rmaHandler.Create)
+			rmas.GET("", rmaHandler.List)
+			rmas.GET("/:id", rmaHandler.GetByID)
+			rmas.POST("/:id/lines/:lineId/inspect", authMiddleware.RequireRole("admin", "user"), idempotency, rmaHandler.InspectLine)
+			rmas.POST("/:id/cancel", authMiddleware.RequireRole("admin"), idempotency, rmaHandler.Cancel)
+		}
+
+		reports := v1.Group("/reports")
+		reports.Use(authMiddleware.RequireAuth())
+		{
+			reports.GET("/valuation", reportHandler.Valuation)
+			reports.GET("/stock-snapshot", reportHandler.StockSnapshot)
+		}
+
+		reconciliations := v1.Group("/reconciliation")
+		reconciliations.Use(authMiddleware.RequireAuth())
+		{
+			reconciliations.GET("", authMiddleware.RequireRole("admin"), reconciliationHandler.Check)
+			reconciliations.POST("/repair", authMiddleware.RequireRole("admin"), idempotency, reconciliationHandler.Repair)
+		}
 	}
 }
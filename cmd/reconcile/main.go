@@ -0,0 +1,78 @@
+// reconcile compara el stock de los productos contra el ledger de movimientos
+// y opcionalmente repara las diferencias con ajustes RECONCILIATION
+//
+// uso: go run ./cmd/reconcile [-product ID] [-repair] [-user EMAIL]
+// imprime el reporte en JSON y sale con codigo 1 si hay diferencias sin reparar
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"time"
+
+	"github.com/whoAngeel/wms-lite/internal/alert"
+	"github.com/whoAngeel/wms-lite/internal/movement"
+	"github.com/whoAngeel/wms-lite/internal/platform"
+	"github.com/whoAngeel/wms-lite/internal/reconciliation"
+)
+
+func main() {
+	productID := flag.Int("product", 0, "conciliar solo este producto (por defecto todos)")
+	repair := flag.Bool("repair", false, "registrar ajustes RECONCILIATION por las diferencias encontradas")
+	user := flag.String("user", "reconcile", "usuario que registra los ajustes")
+	flag.Parse()
+
+	cfg, err := platform.LoadConfig()
+	if err != nil {
+		panic("Error loading config: " + err.Error())
+	}
+
+	logger := platform.InitLogger(cfg.Server.Env)
+
+	cache, err := platform.NewCache(cfg.Cache)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Error connecting to cache")
+	}
+	defer cache.Close()
+
+	db, err := platform.NewDatabase(cfg.Database)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Error connecting to database")
+	}
+	defer db.Close()
+
+	alertService := alert.NewService(alert.NewRepository(db, logger), logger)
+	movementService := movement.NewService(movement.NewRepository(db), db, cache, alertService, movement.CostingMethod(cfg.Costing.Method), &logger)
+	reconciliationRepo := reconciliation.NewRepository(db, logger)
+	reconciliationService := reconciliation.NewService(reconciliationRepo, db, movementService, cache, alertService, logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	var filter *int
+	if *productID > 0 {
+		filter = productID
+	}
+
+	var report *reconciliation.ReconciliationReport
+	if *repair {
+		report, err = reconciliationService.Repair(ctx, reconciliation.RepairRequest{ProductID: filter, CreatedBy: *user})
+	} else {
+		report, err = reconciliationService.Check(ctx, reconciliation.CheckFilters{ProductID: filter})
+	}
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Stock reconciliation failed")
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		logger.Fatal().Err(err).Msg("Error writing reconciliation report")
+	}
+
+	if !*repair && report.Discrepancies > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,70 @@
+package alert
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+type Handler struct {
+	service *Service
+	logger  zerolog.Logger
+}
+
+func NewHandler(service *Service, logger zerolog.Logger) *Handler {
+	moduleLogger := logger.With().Str("module", "alert").Logger()
+	return &Handler{
+		service: service,
+		logger:  moduleLogger,
+	}
+}
+
+// ListLowStock maneja GET /alerts/low-stock
+func (h *Handler) ListLowStock(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	var filters ListFilters
+	if err := c.ShouldBindQuery(&filters); err != nil {
+		h.logger.Warn().Err(err).Msg("Invalid query parameters for low stock alerts")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+
+	response, err := h.service.List(ctx, filters)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error().Err(err).Msg("Error listing low stock alerts")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error listing low stock alerts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ReorderSuggestions maneja GET /alerts/reorder-suggestions
+func (h *Handler) ReorderSuggestions(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	response, err := h.service.ReorderSuggestions(ctx, page, pageSize)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Error listing reorder suggestions")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error listing reorder suggestions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,58 @@
+package alert
+
+import "time"
+
+// estados de una alerta de stock bajo
+const (
+	StatusOpen     = "OPEN"
+	StatusResolved = "RESOLVED"
+)
+
+// LowStockAlert se abre cuando el stock del producto baja al umbral de reabasto
+// y se resuelve cuando el stock vuelve a quedar por encima
+type LowStockAlert struct {
+	ID             int        `json:"id" db:"id"`
+	ProductID      int        `json:"product_id" db:"product_id"`
+	SKU            string     `json:"sku" db:"sku"`
+	ProductName    string     `json:"product_name" db:"product_name"`
+	Status         string     `json:"status" db:"status"`
+	Threshold      int        `json:"threshold" db:"threshold"` // reorder_point (o min_level) al abrir la alerta
+	StockAtOpen    int        `json:"stock_at_open" db:"stock_at_open"`
+	StockAtResolve *int       `json:"stock_at_resolve,omitempty" db:"stock_at_resolve"`
+	CurrentStock   int        `json:"current_stock" db:"current_stock"`
+	OpenedAt       time.Time  `json:"opened_at" db:"opened_at"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty" db:"resolved_at"`
+}
+
+// ListFilters son los filtros opcionales de GET /alerts/low-stock
+type ListFilters struct {
+	Status    string `form:"status"` // OPEN (por defecto), RESOLVED o ALL
+	ProductID *int   `form:"product_id"`
+	Page      int    `form:"page"`
+	PageSize  int    `form:"page_size"`
+}
+
+// ReorderSuggestion es un producto en o debajo de su umbral con la cantidad sugerida a pedir
+type ReorderSuggestion struct {
+	ProductID    int    `json:"product_id" db:"product_id"`
+	SKU          string `json:"sku" db:"sku"`
+	ProductName  string `json:"product_name" db:"product_name"`
+	OnHand       int    `json:"on_hand" db:"on_hand"`
+	ReorderPoint *int   `json:"reorder_point,omitempty" db:"reorder_point"`
+	MinLevel     *int   `json:"min_level,omitempty" db:"min_level"`
+	MaxLevel     *int   `json:"max_level,omitempty" db:"max_level"`
+	// max_level - on_hand, nulo si el producto no tiene max_level
+	SuggestedQuantity *int `json:"suggested_quantity" db:"suggested_quantity"`
+}
+
+type PaginatedResponse struct {
+	Data       interface{} `json:"data"`
+	Pagination Pagination  `json:"pagination"`
+}
+
+type Pagination struct {
+	Page       int `json:"page"`
+	PageSize   int `json:"page_size"`
+	Total      int `json:"total"`
+	TotalPages int `json:"total_pages"`
+}
@@ -0,0 +1,143 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+)
+
+// umbral de alerta del producto: el punto de reorden o, si no tiene, el minimo
+const thresholdExpr = `COALESCE(p.reorder_point, p.min_level)`
+
+type Repository struct {
+	db     *sqlx.DB
+	logger zerolog.Logger
+}
+
+func NewRepository(db *sqlx.DB, logger zerolog.Logger) *Repository {
+	moduleLogger := logger.With().Str("module", "alert").Logger()
+	return &Repository{db: db, logger: moduleLogger}
+}
+
+// resuelve la alerta abierta si el stock ya quedo por encima del umbral
+// (o el producto ya no tiene umbral o fue eliminado)
+func (r *Repository) ResolveIfRecovered(ctx context.Context, productID int) (bool, error) {
+	query := `
+		UPDATE low_stock_alerts a
+		SET status = 'RESOLVED', resolved_at = CURRENT_TIMESTAMP, stock_at_resolve = p.stock_quantity
+		FROM products p
+		WHERE a.product_id = p.id AND a.product_id = $1 AND a.status = 'OPEN'
+			AND (` + thresholdExpr + ` IS NULL OR p.stock_quantity > ` + thresholdExpr + ` OR p.deleted_at IS NOT NULL)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, productID)
+	if err != nil {
+		return false, fmt.Errorf("error resolving low stock alert: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error getting rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// abre una alerta si el stock esta en o debajo del umbral y no hay otra abierta
+// el indice unico parcial (una alerta OPEN por producto) evita duplicados entre requests concurrentes
+func (r *Repository) OpenIfLow(ctx context.Context, productID int) (bool, error) {
+	query := `
+		INSERT INTO low_stock_alerts (product_id, threshold, stock_at_open)
+		SELECT p.id, ` + thresholdExpr + `, p.stock_quantity
+		FROM products p
+		WHERE p.id = $1 AND p.deleted_at IS NULL
+			AND ` + thresholdExpr + ` IS NOT NULL AND p.stock_quantity <= ` + thresholdExpr + `
+		ON CONFLICT (product_id) WHERE status = 'OPEN' DO NOTHING
+	`
+
+	result, err := r.db.ExecContext(ctx, query, productID)
+	if err != nil {
+		return false, fmt.Errorf("error opening low stock alert: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error getting rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+func (r *Repository) List(ctx context.Context, filters ListFilters) ([]LowStockAlert, int, error) {
+	var conditions []string
+	var args []interface{}
+	argPosition := 1
+
+	if filters.Status != "ALL" {
+		conditions = append(conditions, fmt.Sprintf("a.status = $%d", argPosition))
+		args = append(args, filters.Status)
+		argPosition++
+	}
+
+	if filters.ProductID != nil {
+		conditions = append(conditions, fmt.Sprintf("a.product_id = $%d", argPosition))
+		args = append(args, *filters.ProductID)
+		argPosition++
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	err := r.db.GetContext(ctx, &total, "SELECT COUNT(*) FROM low_stock_alerts a"+where, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error counting low stock alerts: %w", err)
+	}
+
+	offset := (filters.Page - 1) * filters.PageSize
+	query := `
+		SELECT a.id, a.product_id, p.sku, p.name AS product_name, a.status, a.threshold,
+			a.stock_at_open, a.stock_at_resolve, p.stock_quantity AS current_stock, a.opened_at, a.resolved_at
+		FROM low_stock_alerts a
+		JOIN products p ON p.id = a.product_id` + where +
+		fmt.Sprintf(" ORDER BY a.opened_at DESC, a.id DESC LIMIT $%d OFFSET $%d", argPosition, argPosition+1)
+	args = append(args, filters.PageSize, offset)
+
+	var alerts []LowStockAlert
+	err = r.db.SelectContext(ctx, &alerts, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error listing low stock alerts: %w", err)
+	}
+
+	return alerts, total, nil
+}
+
+// productos en o debajo de su umbral, primero los que estan mas por debajo
+func (r *Repository) ListReorderSuggestions(ctx context.Context, page, pageSize int) ([]ReorderSuggestion, int, error) {
+	where := `
+		FROM products p
+		WHERE p.deleted_at IS NULL AND ` + thresholdExpr + ` IS NOT NULL AND p.stock_quantity <= ` + thresholdExpr
+
+	var total int
+	if err := r.db.GetContext(ctx, &total, "SELECT COUNT(*) "+where); err != nil {
+		return nil, 0, fmt.Errorf("error counting reorder suggestions: %w", err)
+	}
+
+	query := `
+		SELECT p.id AS product_id, p.sku, p.name AS product_name, p.stock_quantity AS on_hand,
+			p.reorder_point, p.min_level, p.max_level,
+			GREATEST(p.max_level - p.stock_quantity, 0) AS suggested_quantity
+		` + where + `
+		ORDER BY p.stock_quantity - ` + thresholdExpr + `, p.sku
+		LIMIT $1 OFFSET $2
+	`
+
+	var suggestions []ReorderSuggestion
+	if err := r.db.SelectContext(ctx, &suggestions, query, pageSize, (page-1)*pageSize); err != nil {
+		return nil, 0, fmt.Errorf("error listing reorder suggestions: %w", err)
+	}
+	return suggestions, total, nil
+}
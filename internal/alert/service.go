@@ -0,0 +1,111 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+type Service struct {
+	repo   *Repository
+	logger zerolog.Logger
+}
+
+func NewService(repo *Repository, logger zerolog.Logger) *Service {
+	serviceLogger := logger.With().Str("service", "alert").Logger()
+	return &Service{repo: repo, logger: serviceLogger}
+}
+
+// CheckProduct abre o resuelve la alerta de stock bajo segun el stock actual del producto
+// se llama despues del COMMIT (movimientos, cambios de umbral): un fallo no revierte nada, solo se registra
+func (s *Service) CheckProduct(ctx context.Context, productID int) {
+	resolved, err := s.repo.ResolveIfRecovered(ctx, productID)
+	if err != nil {
+		s.logger.Warn().Err(err).Int("product_id", productID).Msg("Failed to resolve low stock alert")
+		return
+	}
+	if resolved {
+		s.logger.Info().Int("product_id", productID).Msg("Low stock alert resolved")
+	}
+
+	opened, err := s.repo.OpenIfLow(ctx, productID)
+	if err != nil {
+		s.logger.Warn().Err(err).Int("product_id", productID).Msg("Failed to open low stock alert")
+		return
+	}
+	if opened {
+		s.logger.Info().Int("product_id", productID).Msg("Low stock alert opened")
+	}
+}
+
+func (s *Service) List(ctx context.Context, filters ListFilters) (*PaginatedResponse, error) {
+	filters.Page, filters.PageSize = normalizePagination(filters.Page, filters.PageSize)
+
+	filters.Status = strings.ToUpper(strings.TrimSpace(filters.Status))
+	if filters.Status == "" {
+		filters.Status = StatusOpen
+	}
+	if filters.Status != StatusOpen && filters.Status != StatusResolved && filters.Status != "ALL" {
+		return nil, fmt.Errorf("invalid status: must be OPEN, RESOLVED or ALL")
+	}
+
+	alerts, total, err := s.repo.List(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	if alerts == nil {
+		alerts = []LowStockAlert{}
+	}
+
+	return &PaginatedResponse{
+		Data: alerts,
+		Pagination: Pagination{
+			Page:       filters.Page,
+			PageSize:   filters.PageSize,
+			Total:      total,
+			TotalPages: (total + filters.PageSize - 1) / filters.PageSize,
+		},
+	}, nil
+}
+
+// ReorderSuggestions lista lo que hay que pedir: max_level menos el stock actual
+func (s *Service) ReorderSuggestions(ctx context.Context, page, pageSize int) (*PaginatedResponse, error) {
+	page, pageSize = normalizePagination(page, pageSize)
+
+	suggestions, total, err := s.repo.ListReorderSuggestions(ctx, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	if suggestions == nil {
+		suggestions = []ReorderSuggestion{}
+	}
+
+	return &PaginatedResponse{
+		Data: suggestions,
+		Pagination: Pagination{
+			Page:       page,
+			PageSize:   pageSize,
+			Total:      total,
+			TotalPages: (total + pageSize - 1) / pageSize,
+		},
+	}, nil
+}
+
+func normalizePagination(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	if pageSize > 100 {
+		pageSize = 100
+	}
+	return page, pageSize
+}
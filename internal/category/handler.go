@@ -0,0 +1,184 @@
+package category
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+type Handler struct {
+	service *Service
+	logger  zerolog.Logger
+}
+
+func NewHandler(service *Service, logger zerolog.Logger) *Handler {
+	moduleLogger := logger.With().Str("module", "category").Logger()
+	return &Handler{
+		service: service,
+		logger:  moduleLogger,
+	}
+}
+
+// Create maneja POST /categories
+func (h *Handler) Create(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	var req CreateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn().Err(err).Msg("Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	category, err := h.service.Create(ctx, req)
+	if err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error().Err(err).Str("code", req.Code).Msg("Error creating category")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creating category"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, category)
+}
+
+// List maneja GET /categories
+func (h *Handler) List(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	var filters ListFilters
+	if err := c.ShouldBindQuery(&filters); err != nil {
+		h.logger.Warn().Err(err).Msg("Invalid query parameters for categories")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+
+	response, err := h.service.List(ctx, filters)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Error listing categories")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error listing categories"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetByID maneja GET /categories/:id
+func (h *Handler) GetByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID, must be a number"})
+		return
+	}
+
+	category, err := h.service.GetByID(c.Request.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error().Err(err).Int("category_id", id).Msg("Error getting category")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+// Update maneja PUT /categories/:id
+func (h *Handler) Update(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID, must be a number"})
+		return
+	}
+
+	var req UpdateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	category, err := h.service.Update(ctx, id, req)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if strings.Contains(err.Error(), "cannot deactivate") {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error().Err(err).Int("category_id", id).Msg("Error updating category")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error updating category"})
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+// Delete maneja DELETE /categories/:id (desactiva la categoria)
+func (h *Handler) Delete(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID, must be a number"})
+		return
+	}
+
+	if err := h.service.Deactivate(ctx, id); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if strings.Contains(err.Error(), "cannot deactivate") {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error().Err(err).Int("category_id", id).Msg("Failed to deactivate category")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to deactivate category"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Category deactivated successfully"})
+}
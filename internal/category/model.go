@@ -0,0 +1,46 @@
+package category
+
+import "time"
+
+type Category struct {
+	ID        int       `json:"id" db:"id"`
+	ParentID  *int      `json:"parent_id,omitempty" db:"parent_id"`
+	Code      string    `json:"code" db:"code"`
+	Path      string    `json:"path" db:"path"`
+	Name      string    `json:"name" db:"name"`
+	IsActive  bool      `json:"is_active" db:"is_active"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type CreateCategoryRequest struct {
+	ParentID *int   `json:"parent_id" binding:"omitempty,min=1"` // sin padre es una categoria raiz
+	Code     string `json:"code" binding:"required,max=30"`
+	Name     string `json:"name" binding:"required,max=100"`
+}
+
+type UpdateCategoryRequest struct {
+	Name     *string `json:"name" binding:"omitempty,max=100"`
+	IsActive *bool   `json:"is_active"`
+}
+
+// ListFilters son los filtros opcionales de GET /categories
+type ListFilters struct {
+	ParentID        *int   `form:"parent_id"`
+	Path            string `form:"path"` // la categoria con ese path y todas sus descendientes
+	IncludeInactive bool   `form:"include_inactive"`
+	Page            int    `form:"page"`
+	PageSize        int    `form:"page_size"`
+}
+
+type PaginatedResponse struct {
+	Data       interface{} `json:"data"`
+	Pagination Pagination  `json:"pagination"`
+}
+
+type Pagination struct {
+	Page       int `json:"page"`
+	PageSize   int `json:"page_size"`
+	Total      int `json:"total"`
+	TotalPages int `json:"total_pages"`
+}
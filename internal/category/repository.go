@@ -0,0 +1,140 @@
+package category
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+)
+
+const categoryColumns = `id, parent_id, code, path, name, is_active, created_at, updated_at`
+
+type Repository struct {
+	db     *sqlx.DB
+	logger zerolog.Logger
+}
+
+func NewRepository(db *sqlx.DB, logger zerolog.Logger) *Repository {
+	moduleLogger := logger.With().Str("module", "category").Logger()
+	return &Repository{db: db, logger: moduleLogger}
+}
+
+// inserta una nueva categoria, el path ya viene calculado por el service
+func (r *Repository) Create(ctx context.Context, req CreateCategoryRequest, path string) (*Category, error) {
+	query := `
+		INSERT INTO categories (parent_id, code, path, name)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + categoryColumns
+
+	var category Category
+	err := r.db.GetContext(ctx, &category, query, req.ParentID, req.Code, path, req.Name)
+	if err != nil {
+		return nil, fmt.Errorf("error creating category: %w", err)
+	}
+	return &category, nil
+}
+
+func (r *Repository) GetByID(ctx context.Context, id int) (*Category, error) {
+	query := `SELECT ` + categoryColumns + ` FROM categories WHERE id = $1`
+
+	var category Category
+	err := r.db.GetContext(ctx, &category, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("category with id [%d] not found", id)
+		}
+		return nil, fmt.Errorf("error getting category: %w", err)
+	}
+	return &category, nil
+}
+
+func (r *Repository) List(ctx context.Context, filters ListFilters) ([]Category, int, error) {
+	var conditions []string
+	var args []interface{}
+	argPosition := 1
+
+	if filters.ParentID != nil {
+		conditions = append(conditions, fmt.Sprintf("parent_id = $%d", argPosition))
+		args = append(args, *filters.ParentID)
+		argPosition++
+	}
+
+	if filters.Path != "" {
+		conditions = append(conditions, fmt.Sprintf("(path = $%d OR left(path, length($%d) + 1) = $%d || '/')", argPosition, argPosition, argPosition))
+		args = append(args, filters.Path)
+		argPosition++
+	}
+
+	if !filters.IncludeInactive {
+		conditions = append(conditions, "is_active")
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	err := r.db.GetContext(ctx, &total, "SELECT COUNT(*) FROM categories"+where, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error counting categories: %w", err)
+	}
+
+	offset := (filters.Page - 1) * filters.PageSize
+	query := "SELECT " + categoryColumns + " FROM categories" + where +
+		fmt.Sprintf(" ORDER BY path LIMIT $%d OFFSET $%d", argPosition, argPosition+1)
+	args = append(args, filters.PageSize, offset)
+
+	var categories []Category
+	err = r.db.SelectContext(ctx, &categories, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error listing categories: %w", err)
+	}
+
+	return categories, total, nil
+}
+
+// actualiza solo los campos enviados
+func (r *Repository) Update(ctx context.Context, id int, req UpdateCategoryRequest) (*Category, error) {
+	query := `
+		UPDATE categories
+		SET name = COALESCE($1, name),
+			is_active = COALESCE($2, is_active)
+		WHERE id = $3
+		RETURNING ` + categoryColumns
+
+	var category Category
+	err := r.db.GetContext(ctx, &category, query, req.Name, req.IsActive, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("category with id [%d] not found", id)
+		}
+		return nil, fmt.Errorf("error updating category: %w", err)
+	}
+	return &category, nil
+}
+
+// cuenta las subcategorias activas de una categoria
+func (r *Repository) CountActiveChildren(ctx context.Context, id int) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM categories WHERE parent_id = $1 AND is_active`
+
+	if err := r.db.GetContext(ctx, &count, query, id); err != nil {
+		return 0, fmt.Errorf("error counting child categories: %w", err)
+	}
+	return count, nil
+}
+
+// cuenta los productos (no eliminados) asignados directamente a la categoria
+func (r *Repository) CountProducts(ctx context.Context, id int) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM products WHERE category_id = $1 AND deleted_at IS NULL`
+
+	if err := r.db.GetContext(ctx, &count, query, id); err != nil {
+		return 0, fmt.Errorf("error counting category products: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,156 @@
+package category
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+type Service struct {
+	repo   *Repository
+	logger zerolog.Logger
+}
+
+func NewService(repo *Repository, logger zerolog.Logger) *Service {
+	serviceLogger := logger.With().Str("service", "category").Logger()
+	return &Service{repo: repo, logger: serviceLogger}
+}
+
+// Crea una categoria, raiz o dentro de otra
+// el path se arma con el path del padre + el codigo (ej. ELECTRONICS/LAPTOPS)
+func (s *Service) Create(ctx context.Context, req CreateCategoryRequest) (*Category, error) {
+	req.Code = strings.TrimSpace(strings.ToUpper(req.Code))
+	req.Name = strings.TrimSpace(req.Name)
+
+	if req.Code == "" || strings.Contains(req.Code, "/") {
+		return nil, fmt.Errorf("invalid code: must not be empty or contain '/'")
+	}
+	if req.Name == "" {
+		return nil, fmt.Errorf("invalid name: must not be empty")
+	}
+
+	path := req.Code
+	if req.ParentID != nil {
+		parent, err := s.repo.GetByID(ctx, *req.ParentID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid parent_id: %w", err)
+		}
+		if !parent.IsActive {
+			return nil, fmt.Errorf("invalid parent_id: parent category is inactive")
+		}
+
+		path = parent.Path + "/" + req.Code
+	}
+
+	category, err := s.repo.Create(ctx, req, path)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			return nil, fmt.Errorf("category [%s] already exists", path)
+		}
+		return nil, err
+	}
+
+	s.logger.Info().Int("category_id", category.ID).Str("path", category.Path).Msg("Category created")
+	return category, nil
+}
+
+func (s *Service) GetByID(ctx context.Context, id int) (*Category, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("invalid ID: must be greater than 0")
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *Service) List(ctx context.Context, filters ListFilters) (*PaginatedResponse, error) {
+	filters.Page, filters.PageSize = normalizePagination(filters.Page, filters.PageSize)
+	filters.Path = strings.Trim(strings.TrimSpace(strings.ToUpper(filters.Path)), "/")
+
+	categories, total, err := s.repo.List(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	if categories == nil {
+		categories = []Category{}
+	}
+
+	return &PaginatedResponse{
+		Data: categories,
+		Pagination: Pagination{
+			Page:       filters.Page,
+			PageSize:   filters.PageSize,
+			Total:      total,
+			TotalPages: (total + filters.PageSize - 1) / filters.PageSize,
+		},
+	}, nil
+}
+
+func (s *Service) Update(ctx context.Context, id int, req UpdateCategoryRequest) (*Category, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("invalid ID: must be greater than 0")
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, fmt.Errorf("invalid name: must not be empty")
+		}
+		req.Name = &name
+	}
+
+	if req.IsActive != nil && !*req.IsActive {
+		if err := s.ensureUnused(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.repo.Update(ctx, id, req)
+}
+
+// Deactivate desactiva una categoria sin uso (no se borra para conservar el historial)
+func (s *Service) Deactivate(ctx context.Context, id int) error {
+	inactive := false
+	_, err := s.Update(ctx, id, UpdateCategoryRequest{IsActive: &inactive})
+	return err
+}
+
+// una categoria solo se desactiva si no tiene subcategorias activas ni productos
+func (s *Service) ensureUnused(ctx context.Context, id int) error {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return err
+	}
+
+	children, err := s.repo.CountActiveChildren(ctx, id)
+	if err != nil {
+		return err
+	}
+	if children > 0 {
+		return fmt.Errorf("cannot deactivate category with %d active subcategories", children)
+	}
+
+	products, err := s.repo.CountProducts(ctx, id)
+	if err != nil {
+		return err
+	}
+	if products > 0 {
+		return fmt.Errorf("cannot deactivate category with %d products assigned", products)
+	}
+	return nil
+}
+
+func normalizePagination(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	if pageSize > 100 {
+		pageSize = 100
+	}
+	return page, pageSize
+}
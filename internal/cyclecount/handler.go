@@ -0,0 +1,231 @@
+package cyclecount
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+type Handler struct {
+	service *Service
+	logger  zerolog.Logger
+}
+
+func NewHandler(service *Service, logger zerolog.Logger) *Handler {
+	moduleLogger := logger.With().Str("module", "cyclecount").Logger()
+	return &Handler{
+		service: service,
+		logger:  moduleLogger,
+	}
+}
+
+// Create maneja POST /cycle-counts
+func (h *Handler) Create(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	var req CreateSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn().Err(err).Msg("Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if email, exists := c.Get("email"); exists {
+		req.CreatedBy = email.(string)
+	}
+
+	session, err := h.service.Create(ctx, req)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error().Err(err).Msg("Error creating count session")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creating count session"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, session)
+}
+
+// List maneja GET /cycle-counts
+func (h *Handler) List(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	var filters ListFilters
+	if err := c.ShouldBindQuery(&filters); err != nil {
+		h.logger.Warn().Err(err).Msg("Invalid query parameters for count sessions")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+
+	response, err := h.service.List(ctx, filters)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error().Err(err).Msg("Error listing count sessions")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error listing count sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetByID maneja GET /cycle-counts/:id (incluye lineas y diferencias)
+func (h *Handler) GetByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID, must be a number"})
+		return
+	}
+
+	session, err := h.service.GetByID(c.Request.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error().Err(err).Int("session_id", id).Msg("Error getting count session")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+// SubmitCounts maneja POST /cycle-counts/:id/counts
+func (h *Handler) SubmitCounts(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID, must be a number"})
+		return
+	}
+
+	var req SubmitCountsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	countedBy := ""
+	if email, exists := c.Get("email"); exists {
+		countedBy = email.(string)
+	}
+
+	if err := h.service.SubmitCounts(ctx, id, req, countedBy); err != nil {
+		h.respondError(c, err, id, "Error submitting counts")
+		return
+	}
+
+	h.respondSession(c, id)
+}
+
+// SubmitForReview maneja POST /cycle-counts/:id/review
+func (h *Handler) SubmitForReview(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID, must be a number"})
+		return
+	}
+
+	if err := h.service.SubmitForReview(c.Request.Context(), id); err != nil {
+		h.respondError(c, err, id, "Error submitting count session for review")
+		return
+	}
+
+	h.respondSession(c, id)
+}
+
+// Reopen maneja POST /cycle-counts/:id/reopen (reconteo)
+func (h *Handler) Reopen(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID, must be a number"})
+		return
+	}
+
+	if err := h.service.Reopen(c.Request.Context(), id); err != nil {
+		h.respondError(c, err, id, "Error reopening count session")
+		return
+	}
+
+	h.respondSession(c, id)
+}
+
+// Approve maneja POST /cycle-counts/:id/approve
+// registra las diferencias como ajustes de inventario
+func (h *Handler) Approve(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID, must be a number"})
+		return
+	}
+
+	approvedBy := ""
+	if email, exists := c.Get("email"); exists {
+		approvedBy = email.(string)
+	}
+
+	if err := h.service.Approve(ctx, id, approvedBy); err != nil {
+		h.respondError(c, err, id, "Error approving count session")
+		return
+	}
+
+	h.respondSession(c, id)
+}
+
+// respondError traduce los errores de las acciones sobre una sesion
+// un ajuste que ya no cabe en el stock actual es un conflicto, no un error de datos
+func (h *Handler) respondError(c *gin.Context, err error, id int, message string) {
+	switch {
+	case strings.Contains(err.Error(), "cannot") || strings.Contains(err.Error(), "insufficient stock"):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "count session with id"):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "not found"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error().Err(err).Int("session_id", id).Msg(message)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+// responde con la sesion actualizada
+func (h *Handler) respondSession(c *gin.Context, id int) {
+	session, err := h.service.GetByID(c.Request.Context(), id)
+	if err != nil {
+		h.logger.Error().Err(err).Int("session_id", id).Msg("Error getting count session")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
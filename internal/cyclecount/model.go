@@ -0,0 +1,121 @@
+package cyclecount
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Status es el estado de una sesion de conteo
+// OPEN -> COUNTING -> REVIEW -> POSTED (REVIEW puede volver a COUNTING para reconteo)
+type Status string
+
+const (
+	StatusOpen     Status = "OPEN"
+	StatusCounting Status = "COUNTING"
+	StatusReview   Status = "REVIEW"
+	StatusPosted   Status = "POSTED"
+)
+
+// isValid verifica si el estado es valido
+func (st Status) IsValid() bool {
+	return st == StatusOpen || st == StatusCounting || st == StatusReview || st == StatusPosted
+}
+
+// razon con la que se registran los ajustes al aprobar un conteo
+const countCorrectionReasonCode = "COUNT_CORRECTION"
+
+type Session struct {
+	ID          int        `json:"id" db:"id"`
+	WarehouseID int        `json:"warehouse_id" db:"warehouse_id"`
+	Status      Status     `json:"status" db:"status"`
+	Notes       string     `json:"notes,omitempty" db:"notes"`
+	CreatedBy   string     `json:"created_by,omitempty" db:"created_by"`
+	ApprovedBy  *string    `json:"approved_by,omitempty" db:"approved_by"`
+	PostedAt    *time.Time `json:"posted_at,omitempty" db:"posted_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	Lines       []Line     `json:"lines,omitempty" db:"-"`
+}
+
+// Line es un producto a contar en un bin (o fuera de bins si location_id es nulo)
+type Line struct {
+	ID               int            `json:"id" db:"id"`
+	SessionID        int            `json:"session_id" db:"session_id"`
+	ProductID        int            `json:"product_id" db:"product_id"`
+	SKU              string         `json:"sku" db:"sku"`
+	ProductName      string         `json:"product_name" db:"product_name"`
+	IsLotTracked     bool           `json:"is_lot_tracked" db:"is_lot_tracked"`
+	IsSerialized     bool           `json:"is_serialized" db:"is_serialized"`
+	LocationID       *int           `json:"location_id,omitempty" db:"location_id"`
+	LocationPath     *string        `json:"location_path,omitempty" db:"location_path"`
+	ExpectedQuantity int            `json:"expected_quantity" db:"expected_quantity"` // foto del stock al crear la sesion
+	CountedQuantity  *int           `json:"counted_quantity" db:"counted_quantity"`
+	Variance         *int           `json:"variance" db:"-"`                      // contado - esperado
+	LotNumber        *string        `json:"lot_number,omitempty" db:"lot_number"` // lote de las unidades encontradas
+	Serials          pq.StringArray `json:"serials,omitempty" db:"serials"`       // series de la diferencia
+	CountedBy        *string        `json:"counted_by,omitempty" db:"counted_by"`
+	CountedAt        *time.Time     `json:"counted_at,omitempty" db:"counted_at"`
+	MovementID       *int           `json:"movement_id,omitempty" db:"movement_id"` // ajuste generado al aprobar
+}
+
+// calcula la diferencia si la linea ya fue contada
+func (l *Line) computeVariance() {
+	if l.CountedQuantity == nil {
+		l.Variance = nil
+		return
+	}
+	variance := *l.CountedQuantity - l.ExpectedQuantity
+	l.Variance = &variance
+}
+
+// snapshotLine es el stock esperado de un producto en un bin al abrir la sesion
+type snapshotLine struct {
+	ProductID  int  `db:"product_id"`
+	LocationID *int `db:"location_id"`
+	Quantity   int  `db:"quantity"`
+}
+
+// CreateSessionRequest define que se va a contar: productos, ubicaciones o ambos
+// una ubicacion incluye todos los bins que estan debajo de ella
+type CreateSessionRequest struct {
+	WarehouseID int    `json:"warehouse_id" binding:"omitempty,min=1"` // opcional, si se omite usa el almacen por defecto
+	ProductIDs  []int  `json:"product_ids" binding:"omitempty,dive,min=1"`
+	LocationIDs []int  `json:"location_ids" binding:"omitempty,dive,min=1"`
+	Notes       string `json:"notes" binding:"max=255"`
+	CreatedBy   string `json:"-"`
+}
+
+type SubmitCountsRequest struct {
+	Counts []CountEntry `json:"counts" binding:"required,min=1,dive"`
+}
+
+// CountEntry es la captura de una linea
+// en productos con lotes lot_number indica a que lote entran las unidades encontradas
+// en productos serializados serials lista las series de la diferencia (faltantes o encontradas)
+type CountEntry struct {
+	LineID          int      `json:"line_id" binding:"required,min=1"`
+	CountedQuantity *int     `json:"counted_quantity" binding:"required,min=0"`
+	LotNumber       string   `json:"lot_number" binding:"max=50"`
+	Serials         []string `json:"serials" binding:"omitempty,dive,required,max=100"`
+}
+
+// ListFilters son los filtros opcionales de GET /cycle-counts
+type ListFilters struct {
+	WarehouseID *int   `form:"warehouse_id"`
+	Status      string `form:"status"`
+	Page        int    `form:"page"`
+	PageSize    int    `form:"page_size"`
+}
+
+type PaginatedResponse struct {
+	Data       interface{} `json:"data"`
+	Pagination Pagination  `json:"pagination"`
+}
+
+type Pagination struct {
+	Page       int `json:"page"`
+	PageSize   int `json:"page_size"`
+	Total      int `json:"total"`
+	TotalPages int `json:"total_pages"`
+}
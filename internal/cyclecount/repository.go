@@ -0,0 +1,297 @@
+package cyclecount
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog"
+)
+
+const sessionColumns = `
+	id, warehouse_id, status, COALESCE(notes, '') AS notes, COALESCE(created_by, '') AS created_by,
+	approved_by, posted_at, created_at, updated_at
+`
+
+type Repository struct {
+	db     *sqlx.DB
+	logger zerolog.Logger
+}
+
+func NewRepository(db *sqlx.DB, logger zerolog.Logger) *Repository {
+	moduleLogger := logger.With().Str("module", "cyclecount").Logger()
+	return &Repository{db: db, logger: moduleLogger}
+}
+
+// inserta la sesion y sus lineas con el stock esperado
+func (r *Repository) CreateSession(ctx context.Context, tx *sqlx.Tx, req CreateSessionRequest, lines []snapshotLine) (int, error) {
+	var id int
+	query := `
+		INSERT INTO count_sessions (warehouse_id, notes, created_by)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, ''))
+		RETURNING id
+	`
+
+	if err := tx.GetContext(ctx, &id, query, req.WarehouseID, req.Notes, req.CreatedBy); err != nil {
+		return 0, fmt.Errorf("error creating count session: %w", err)
+	}
+
+	lineQuery := `
+		INSERT INTO count_lines (session_id, product_id, location_id, expected_quantity)
+		VALUES ($1, $2, $3, $4)
+	`
+	for _, line := range lines {
+		if _, err := tx.ExecContext(ctx, lineQuery, id, line.ProductID, line.LocationID, line.Quantity); err != nil {
+			return 0, fmt.Errorf("error creating count line: %w", err)
+		}
+	}
+
+	return id, nil
+}
+
+func (r *Repository) GetByID(ctx context.Context, id int) (*Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM count_sessions WHERE id = $1`
+
+	var session Session
+	err := r.db.GetContext(ctx, &session, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("count session with id [%d] not found", id)
+		}
+		return nil, fmt.Errorf("error getting count session: %w", err)
+	}
+	return &session, nil
+}
+
+// obtiene la sesion con LOCK PESIMISTA para serializar capturas y cambios de estado
+func (r *Repository) GetForUpdate(ctx context.Context, tx *sqlx.Tx, id int) (*Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM count_sessions WHERE id = $1 FOR UPDATE`
+
+	var session Session
+	err := tx.GetContext(ctx, &session, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("count session with id [%d] not found", id)
+		}
+		return nil, fmt.Errorf("error getting count session: %w", err)
+	}
+	return &session, nil
+}
+
+func (r *Repository) List(ctx context.Context, filters ListFilters) ([]Session, int, error) {
+	var conditions []string
+	var args []interface{}
+	argPosition := 1
+
+	if filters.WarehouseID != nil {
+		conditions = append(conditions, fmt.Sprintf("warehouse_id = $%d", argPosition))
+		args = append(args, *filters.WarehouseID)
+		argPosition++
+	}
+
+	if filters.Status != "" {
+		conditions = append(conditions, fmt.Sprintf("status = $%d", argPosition))
+		args = append(args, filters.Status)
+		argPosition++
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	err := r.db.GetContext(ctx, &total, "SELECT COUNT(*) FROM count_sessions"+where, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error counting count sessions: %w", err)
+	}
+
+	offset := (filters.Page - 1) * filters.PageSize
+	query := "SELECT " + sessionColumns + " FROM count_sessions" + where +
+		fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d", argPosition, argPosition+1)
+	args = append(args, filters.PageSize, offset)
+
+	var sessions []Session
+	err = r.db.SelectContext(ctx, &sessions, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error listing count sessions: %w", err)
+	}
+
+	return sessions, total, nil
+}
+
+// lista las lineas de una sesion (acepta la conexion o una transaccion)
+func (r *Repository) ListLines(ctx context.Context, q sqlx.QueryerContext, sessionID int) ([]Line, error) {
+	query := `
+		SELECT cl.id, cl.session_id, cl.product_id, p.sku, p.name AS product_name,
+			p.is_lot_tracked, p.is_serialized,
+			cl.location_id, l.path AS location_path, cl.expected_quantity, cl.counted_quantity,
+			cl.lot_number, cl.serials, cl.counted_by, cl.counted_at, cl.movement_id
+		FROM count_lines cl
+		JOIN products p ON p.id = cl.product_id
+		LEFT JOIN locations l ON l.id = cl.location_id
+		WHERE cl.session_id = $1
+		ORDER BY p.sku, l.path NULLS FIRST
+	`
+
+	var lines []Line
+	if err := sqlx.SelectContext(ctx, q, &lines, query, sessionID); err != nil {
+		return nil, fmt.Errorf("error listing count lines: %w", err)
+	}
+	return lines, nil
+}
+
+// registra la cantidad contada de una linea con el lote y las series de la diferencia
+func (r *Repository) UpdateLineCount(ctx context.Context, tx *sqlx.Tx, lineID int, entry CountEntry, countedBy string) error {
+	query := `
+		UPDATE count_lines
+		SET counted_quantity = $1, lot_number = NULLIF($2, ''), serials = COALESCE($3::text[], '{}'),
+			counted_by = NULLIF($4, ''), counted_at = CURRENT_TIMESTAMP
+		WHERE id = $5
+	`
+
+	_, err := tx.ExecContext(ctx, query, *entry.CountedQuantity, entry.LotNumber, pq.Array(entry.Serials), countedBy, lineID)
+	if err != nil {
+		return fmt.Errorf("error updating count line: %w", err)
+	}
+	return nil
+}
+
+// cuenta las lineas que aun no tienen cantidad contada
+func (r *Repository) CountPendingLines(ctx context.Context, tx *sqlx.Tx, sessionID int) (int, error) {
+	var pending int
+	query := `SELECT COUNT(*) FROM count_lines WHERE session_id = $1 AND counted_quantity IS NULL`
+
+	if err := tx.GetContext(ctx, &pending, query, sessionID); err != nil {
+		return 0, fmt.Errorf("error counting pending lines: %w", err)
+	}
+	return pending, nil
+}
+
+func (r *Repository) UpdateStatus(ctx context.Context, tx *sqlx.Tx, sessionID int, status Status) error {
+	query := `UPDATE count_sessions SET status = $1 WHERE id = $2`
+
+	if _, err := tx.ExecContext(ctx, query, status, sessionID); err != nil {
+		return fmt.Errorf("error updating count session status: %w", err)
+	}
+	return nil
+}
+
+// marca la sesion como registrada por el admin que la aprobo
+func (r *Repository) MarkPosted(ctx context.Context, tx *sqlx.Tx, sessionID int, approvedBy string) error {
+	query := `
+		UPDATE count_sessions
+		SET status = $1, approved_by = NULLIF($2, ''), posted_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+	`
+
+	if _, err := tx.ExecContext(ctx, query, StatusPosted, approvedBy, sessionID); err != nil {
+		return fmt.Errorf("error posting count session: %w", err)
+	}
+	return nil
+}
+
+// guarda el ajuste que se genero para la linea
+func (r *Repository) SetLineMovement(ctx context.Context, tx *sqlx.Tx, lineID, movementID int) error {
+	query := `UPDATE count_lines SET movement_id = $1 WHERE id = $2`
+
+	if _, err := tx.ExecContext(ctx, query, movementID, lineID); err != nil {
+		return fmt.Errorf("error linking count line to movement: %w", err)
+	}
+	return nil
+}
+
+// foto del stock de los productos en el almacen: una fila por bin con stock
+// y una fila (location_id nulo) con el stock que no esta en ningun bin
+func (r *Repository) SnapshotProducts(ctx context.Context, tx *sqlx.Tx, warehouseID int, productIDs []int) ([]snapshotLine, error) {
+	query := `
+		SELECT ls.product_id, ls.location_id, ls.quantity
+		FROM location_stock ls
+		JOIN locations l ON l.id = ls.location_id
+		WHERE l.warehouse_id = $1 AND ls.product_id = ANY($2) AND ls.quantity > 0
+		UNION ALL
+		SELECT p.id AS product_id, NULL AS location_id,
+			COALESCE(sl.quantity, 0) - COALESCE((
+				SELECT SUM(ls.quantity)
+				FROM location_stock ls
+				JOIN locations l ON l.id = ls.location_id
+				WHERE l.warehouse_id = $1 AND ls.product_id = p.id
+			), 0) AS quantity
+		FROM products p
+		LEFT JOIN stock_levels sl ON sl.product_id = p.id AND sl.warehouse_id = $1
+		WHERE p.id = ANY($2)
+	`
+
+	var lines []snapshotLine
+	if err := tx.SelectContext(ctx, &lines, query, warehouseID, pq.Array(productIDs)); err != nil {
+		return nil, fmt.Errorf("error taking stock snapshot: %w", err)
+	}
+	return lines, nil
+}
+
+// foto del stock de todos los bins que estan debajo de las ubicaciones indicadas
+func (r *Repository) SnapshotLocations(ctx context.Context, tx *sqlx.Tx, warehouseID int, locationIDs []int) ([]snapshotLine, error) {
+	query := `
+		SELECT DISTINCT ls.product_id, ls.location_id, ls.quantity
+		FROM location_stock ls
+		JOIN locations l ON l.id = ls.location_id
+		JOIN locations root ON root.id = ANY($2) AND root.warehouse_id = l.warehouse_id
+		WHERE l.warehouse_id = $1
+			AND (l.id = root.id OR left(l.path, length(root.path) + 1) = root.path || '/')
+			AND ls.quantity > 0
+	`
+
+	var lines []snapshotLine
+	if err := tx.SelectContext(ctx, &lines, query, warehouseID, pq.Array(locationIDs)); err != nil {
+		return nil, fmt.Errorf("error taking stock snapshot: %w", err)
+	}
+	return lines, nil
+}
+
+// cuenta cuantos de los productos existen (y no estan eliminados)
+func (r *Repository) CountProducts(ctx context.Context, productIDs []int) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM products WHERE id = ANY($1) AND deleted_at IS NULL`
+
+	if err := r.db.GetContext(ctx, &count, query, pq.Array(productIDs)); err != nil {
+		return 0, fmt.Errorf("error checking products: %w", err)
+	}
+	return count, nil
+}
+
+// cuenta cuantas de las ubicaciones pertenecen al almacen
+func (r *Repository) CountLocations(ctx context.Context, warehouseID int, locationIDs []int) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM locations WHERE id = ANY($1) AND warehouse_id = $2`
+
+	if err := r.db.GetContext(ctx, &count, query, pq.Array(locationIDs), warehouseID); err != nil {
+		return 0, fmt.Errorf("error checking locations: %w", err)
+	}
+	return count, nil
+}
+
+func (r *Repository) GetDefaultWarehouseID(ctx context.Context) (int, error) {
+	var id int
+	query := `SELECT id FROM warehouses WHERE is_default AND deleted_at IS NULL`
+
+	if err := r.db.GetContext(ctx, &id, query); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("default warehouse not found")
+		}
+		return 0, fmt.Errorf("error getting default warehouse: %w", err)
+	}
+	return id, nil
+}
+
+func (r *Repository) WarehouseExists(ctx context.Context, warehouseID int) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM warehouses WHERE id = $1 AND deleted_at IS NULL)`
+
+	if err := r.db.GetContext(ctx, &exists, query, warehouseID); err != nil {
+		return false, fmt.Errorf("error checking warehouse existence: %w", err)
+	}
+	return exists, nil
+}
@@ -0,0 +1,485 @@
+package cyclecount
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+	"github.com/whoAngeel/wms-lite/internal/movement"
+)
+
+type Service struct {
+	repo      *Repository
+	db        *sqlx.DB
+	movements *movement.Service
+	logger    zerolog.Logger
+}
+
+func NewService(repo *Repository, db *sqlx.DB, movements *movement.Service, logger zerolog.Logger) *Service {
+	serviceLogger := logger.With().Str("service", "cyclecount").Logger()
+	return &Service{repo: repo, db: db, movements: movements, logger: serviceLogger}
+}
+
+// Crea una sesion de conteo y toma la foto del stock esperado
+// la foto se lee en una transaccion REPEATABLE READ para que bins y stock sin ubicar sean consistentes
+func (s *Service) Create(ctx context.Context, req CreateSessionRequest) (session *Session, err error) {
+	req.ProductIDs = uniqueIDs(req.ProductIDs)
+	req.LocationIDs = uniqueIDs(req.LocationIDs)
+
+	if len(req.ProductIDs) == 0 && len(req.LocationIDs) == 0 {
+		return nil, fmt.Errorf("invalid session: product_ids or location_ids is required")
+	}
+
+	if req.WarehouseID == 0 {
+		req.WarehouseID, err = s.repo.GetDefaultWarehouseID(ctx)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		exists, err := s.repo.WarehouseExists(ctx, req.WarehouseID)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, fmt.Errorf("warehouse with id [%d] not found", req.WarehouseID)
+		}
+	}
+
+	if len(req.ProductIDs) > 0 {
+		count, err := s.repo.CountProducts(ctx, req.ProductIDs)
+		if err != nil {
+			return nil, err
+		}
+		if count != len(req.ProductIDs) {
+			return nil, fmt.Errorf("invalid product_ids: some products were not found")
+		}
+	}
+
+	if len(req.LocationIDs) > 0 {
+		count, err := s.repo.CountLocations(ctx, req.WarehouseID, req.LocationIDs)
+		if err != nil {
+			return nil, err
+		}
+		if count != len(req.LocationIDs) {
+			return nil, fmt.Errorf("invalid location_ids: some locations were not found in warehouse %d", req.WarehouseID)
+		}
+	}
+
+	tx, err := s.db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead})
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	lines, err := s.snapshot(ctx, tx, req)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("invalid session: there is no stock to count in the selected locations")
+	}
+
+	id, err := s.repo.CreateSession(ctx, tx, req, lines)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	s.logger.Info().Int("session_id", id).Int("warehouse_id", req.WarehouseID).Int("lines", len(lines)).Msg("Count session created")
+	return s.GetByID(ctx, id)
+}
+
+// une la foto por productos y por ubicaciones sin repetir (producto, bin)
+// el stock sin ubicar solo se cuenta si hay o si el producto no esta en ningun bin
+func (s *Service) snapshot(ctx context.Context, tx *sqlx.Tx, req CreateSessionRequest) ([]snapshotLine, error) {
+	type lineKey struct {
+		productID  int
+		locationID int
+	}
+
+	seen := make(map[lineKey]bool)
+	var lines []snapshotLine
+
+	add := func(line snapshotLine) {
+		key := lineKey{productID: line.ProductID}
+		if line.LocationID != nil {
+			key.locationID = *line.LocationID
+		}
+		if !seen[key] {
+			seen[key] = true
+			lines = append(lines, line)
+		}
+	}
+
+	if len(req.ProductIDs) > 0 {
+		productLines, err := s.repo.SnapshotProducts(ctx, tx, req.WarehouseID, req.ProductIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		located := make(map[int]bool)
+		for _, line := range productLines {
+			if line.LocationID != nil {
+				located[line.ProductID] = true
+				add(line)
+			}
+		}
+		for _, line := range productLines {
+			if line.LocationID == nil && (line.Quantity > 0 || !located[line.ProductID]) {
+				add(line)
+			}
+		}
+	}
+
+	if len(req.LocationIDs) > 0 {
+		locationLines, err := s.repo.SnapshotLocations(ctx, tx, req.WarehouseID, req.LocationIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range locationLines {
+			add(line)
+		}
+	}
+
+	return lines, nil
+}
+
+// obtiene la sesion con sus lineas y la diferencia de cada una
+func (s *Service) GetByID(ctx context.Context, id int) (*Session, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("invalid ID: must be greater than 0")
+	}
+
+	session, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := s.repo.ListLines(ctx, s.db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range lines {
+		lines[i].computeVariance()
+	}
+	session.Lines = lines
+	return session, nil
+}
+
+func (s *Service) List(ctx context.Context, filters ListFilters) (*PaginatedResponse, error) {
+	filters.Page, filters.PageSize = normalizePagination(filters.Page, filters.PageSize)
+
+	if filters.Status != "" {
+		status := Status(strings.ToUpper(filters.Status))
+		if !status.IsValid() {
+			return nil, fmt.Errorf("invalid status: must be OPEN, COUNTING, REVIEW or POSTED")
+		}
+		filters.Status = string(status)
+	}
+
+	sessions, total, err := s.repo.List(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PaginatedResponse{
+		Data: sessions,
+		Pagination: Pagination{
+			Page:       filters.Page,
+			PageSize:   filters.PageSize,
+			Total:      total,
+			TotalPages: (total + filters.PageSize - 1) / filters.PageSize,
+		},
+	}, nil
+}
+
+// registra cantidades contadas, la primera captura pasa la sesion a COUNTING
+// una linea se puede volver a capturar mientras la sesion no este en revision
+func (s *Service) SubmitCounts(ctx context.Context, id int, req SubmitCountsRequest, countedBy string) (err error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	session, err := s.repo.GetForUpdate(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	if session.Status != StatusOpen && session.Status != StatusCounting {
+		return fmt.Errorf("cannot submit counts: session is %s", session.Status)
+	}
+
+	lines, err := s.repo.ListLines(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	byID := make(map[int]*Line, len(lines))
+	for i := range lines {
+		byID[lines[i].ID] = &lines[i]
+	}
+
+	for _, entry := range req.Counts {
+		line, found := byID[entry.LineID]
+		if !found {
+			return fmt.Errorf("count line [%d] not found in session %d", entry.LineID, id)
+		}
+
+		if err = validateCountEntry(line, entry); err != nil {
+			return err
+		}
+
+		if err = s.repo.UpdateLineCount(ctx, tx, line.ID, entry, countedBy); err != nil {
+			return err
+		}
+	}
+
+	if session.Status == StatusOpen {
+		if err = s.repo.UpdateStatus(ctx, tx, id, StatusCounting); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+	return nil
+}
+
+// cierra la captura y deja la sesion lista para que un admin revise las diferencias
+func (s *Service) SubmitForReview(ctx context.Context, id int) error {
+	return s.transition(ctx, id, StatusCounting, StatusReview, func(tx *sqlx.Tx) error {
+		pending, err := s.repo.CountPendingLines(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if pending > 0 {
+			return fmt.Errorf("cannot submit for review: %d lines pending count", pending)
+		}
+		return nil
+	})
+}
+
+// regresa una sesion en revision a captura para reconteo
+func (s *Service) Reopen(ctx context.Context, id int) error {
+	return s.transition(ctx, id, StatusReview, StatusCounting, nil)
+}
+
+// cambia el estado de la sesion si esta en el estado esperado
+func (s *Service) transition(ctx context.Context, id int, from, to Status, check func(tx *sqlx.Tx) error) (err error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	session, err := s.repo.GetForUpdate(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	if session.Status != from {
+		return fmt.Errorf("cannot move session to %s: session is %s", to, session.Status)
+	}
+
+	if check != nil {
+		if err = check(tx); err != nil {
+			return err
+		}
+	}
+
+	if err = s.repo.UpdateStatus(ctx, tx, id, to); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	s.logger.Info().Int("session_id", id).Str("status", string(to)).Msg("Count session status changed")
+	return nil
+}
+
+// Approve registra las diferencias como movimientos ADJUST en una sola transaccion
+// la diferencia es contra la foto, asi los movimientos ocurridos durante el conteo no se pierden
+// las lineas se aplican en orden de product_id para respetar el orden de locks de stock_levels
+func (s *Service) Approve(ctx context.Context, id int, approvedBy string) (err error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	session, err := s.repo.GetForUpdate(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	if session.Status != StatusReview {
+		return fmt.Errorf("cannot approve session: session is %s", session.Status)
+	}
+
+	lines, err := s.repo.ListLines(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].ProductID < lines[j].ProductID
+	})
+
+	var posted []*movement.Movement
+	for i := range lines {
+		line := &lines[i]
+		line.computeVariance()
+		if line.Variance == nil || *line.Variance == 0 {
+			continue
+		}
+
+		adjustment, err := s.movements.CreateMovementTx(ctx, tx, adjustmentRequest(session, line, approvedBy))
+		if err != nil {
+			return fmt.Errorf("error posting line %d (%s): %w", line.ID, line.SKU, err)
+		}
+
+		if err = s.repo.SetLineMovement(ctx, tx, line.ID, adjustment.ID); err != nil {
+			return err
+		}
+		posted = append(posted, adjustment)
+	}
+
+	if err = s.repo.MarkPosted(ctx, tx, id, approvedBy); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	s.movements.AfterCommit(ctx, posted...)
+
+	s.logger.Info().Int("session_id", id).Int("adjustments", len(posted)).Msg("Count session posted")
+	return nil
+}
+
+// arma el ADJUST de una linea con diferencia: entra al bin si sobra y sale del bin si falta
+// las unidades encontradas entran al lote capturado, las faltantes salen del lote capturado o por FEFO
+func adjustmentRequest(session *Session, line *Line, approvedBy string) movement.CreateMovementRequest {
+	req := movement.CreateMovementRequest{
+		ProductID:    line.ProductID,
+		WarehouseID:  session.WarehouseID,
+		MovementType: movement.MovementTypeAdjust,
+		Quantity:     *line.Variance,
+		ReasonCode:   countCorrectionReasonCode,
+		Reason:       fmt.Sprintf("cycle count #%d", session.ID),
+		Serials:      line.Serials,
+		CreatedBy:    approvedBy,
+	}
+	if line.LotNumber != nil {
+		req.LotNumber = *line.LotNumber
+	}
+
+	if *line.Variance > 0 {
+		req.ToLocationID = line.LocationID
+	} else {
+		req.FromLocationID = line.LocationID
+	}
+	return req
+}
+
+// valida el lote y las series de una captura contra el producto de la linea
+// asi la diferencia se puede registrar al aprobar sin volver a pedir datos
+func validateCountEntry(line *Line, entry CountEntry) error {
+	variance := *entry.CountedQuantity - line.ExpectedQuantity
+
+	if !line.IsLotTracked && entry.LotNumber != "" {
+		return fmt.Errorf("invalid lot_number: product [%s] is not lot-tracked", line.SKU)
+	}
+	if line.IsLotTracked && variance > 0 && entry.LotNumber == "" {
+		return fmt.Errorf("invalid lot_number: product [%s] is lot-tracked, lot_number is required for the %d units found in line %d", line.SKU, variance, line.ID)
+	}
+
+	if !line.IsSerialized {
+		if len(entry.Serials) > 0 {
+			return fmt.Errorf("invalid serials: product [%s] is not serialized", line.SKU)
+		}
+		return nil
+	}
+
+	units := variance
+	if units < 0 {
+		units = -units
+	}
+	if len(entry.Serials) != units {
+		return fmt.Errorf("invalid serials: product [%s] is serialized, line %d has a difference of %d, expected %d serial numbers (missing or found units), got %d", line.SKU, line.ID, variance, units, len(entry.Serials))
+	}
+
+	seen := make(map[string]bool, len(entry.Serials))
+	for _, serial := range entry.Serials {
+		if serial == "" {
+			return fmt.Errorf("invalid serials: serial numbers must not be empty")
+		}
+		if seen[serial] {
+			return fmt.Errorf("invalid serials: [%s] is repeated", serial)
+		}
+		seen[serial] = true
+	}
+	return nil
+}
+
+// quita ids repetidos conservando el orden
+func uniqueIDs(ids []int) []int {
+	seen := make(map[int]bool, len(ids))
+	unique := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+func normalizePagination(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	if pageSize > 100 {
+		pageSize = 100
+	}
+	return page, pageSize
+}
@@ -0,0 +1,119 @@
+package cyclecount
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/whoAngeel/wms-lite/internal/movement"
+)
+
+func intPtr(value int) *int { return &value }
+
+func stringPtr(value string) *string { return &value }
+
+func TestAdjustmentRequest(t *testing.T) {
+	session := &Session{ID: 7, WarehouseID: 2}
+
+	tests := []struct {
+		name  string
+		line  Line
+		check func(t *testing.T, req movement.CreateMovementRequest)
+	}{
+		{
+			name: "producto con lotes: lo encontrado entra al lote capturado",
+			line: Line{
+				ID: 1, ProductID: 10, SKU: "LOT-1", IsLotTracked: true, LocationID: intPtr(5),
+				ExpectedQuantity: 8, CountedQuantity: intPtr(11), LotNumber: stringPtr("L-2026-01"),
+			},
+			check: func(t *testing.T, req movement.CreateMovementRequest) {
+				if req.Quantity != 3 || req.LotNumber != "L-2026-01" {
+					t.Errorf("got quantity %d lot %q, want 3 into L-2026-01", req.Quantity, req.LotNumber)
+				}
+				if req.ToLocationID == nil || *req.ToLocationID != 5 || req.FromLocationID != nil {
+					t.Errorf("got from %v to %v, want to 5", req.FromLocationID, req.ToLocationID)
+				}
+			},
+		},
+		{
+			name: "producto con lotes: el faltante sin lote sale por FEFO",
+			line: Line{
+				ID: 2, ProductID: 10, SKU: "LOT-1", IsLotTracked: true, LocationID: intPtr(5),
+				ExpectedQuantity: 8, CountedQuantity: intPtr(6),
+			},
+			check: func(t *testing.T, req movement.CreateMovementRequest) {
+				if req.Quantity != -2 || req.LotNumber != "" {
+					t.Errorf("got quantity %d lot %q, want -2 without lot", req.Quantity, req.LotNumber)
+				}
+				if req.FromLocationID == nil || *req.FromLocationID != 5 || req.ToLocationID != nil {
+					t.Errorf("got from %v to %v, want from 5", req.FromLocationID, req.ToLocationID)
+				}
+			},
+		},
+		{
+			name: "producto serializado: salen las series faltantes",
+			line: Line{
+				ID: 3, ProductID: 11, SKU: "SER-1", IsSerialized: true,
+				ExpectedQuantity: 3, CountedQuantity: intPtr(1), Serials: []string{"SN-1", "SN-2"},
+			},
+			check: func(t *testing.T, req movement.CreateMovementRequest) {
+				if req.Quantity != -2 || len(req.Serials) != 2 || req.Serials[0] != "SN-1" {
+					t.Errorf("got quantity %d serials %v, want -2 with SN-1 and SN-2", req.Quantity, req.Serials)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.line.computeVariance()
+			req := adjustmentRequest(session, &tt.line, "admin@example.com")
+
+			if req.MovementType != movement.MovementTypeAdjust || req.ReasonCode != countCorrectionReasonCode {
+				t.Errorf("got %s %s, want ADJUST %s", req.MovementType, req.ReasonCode, countCorrectionReasonCode)
+			}
+			if req.ProductID != tt.line.ProductID || req.WarehouseID != 2 || req.CreatedBy != "admin@example.com" {
+				t.Errorf("got product %d warehouse %d created_by %q", req.ProductID, req.WarehouseID, req.CreatedBy)
+			}
+			tt.check(t, req)
+		})
+	}
+}
+
+func TestValidateCountEntry(t *testing.T) {
+	plain := Line{ID: 1, SKU: "PLAIN-1", ExpectedQuantity: 5}
+	lotTracked := Line{ID: 2, SKU: "LOT-1", IsLotTracked: true, ExpectedQuantity: 5}
+	serialized := Line{ID: 3, SKU: "SER-1", IsSerialized: true, ExpectedQuantity: 2}
+
+	tests := []struct {
+		name    string
+		line    Line
+		entry   CountEntry
+		wantErr string
+	}{
+		{name: "producto sin seguimiento", line: plain, entry: CountEntry{CountedQuantity: intPtr(7)}},
+		{name: "lote en producto sin lotes", line: plain, entry: CountEntry{CountedQuantity: intPtr(7), LotNumber: "L-1"}, wantErr: "is not lot-tracked"},
+		{name: "series en producto no serializado", line: plain, entry: CountEntry{CountedQuantity: intPtr(4), Serials: []string{"SN-1"}}, wantErr: "is not serialized"},
+		{name: "sobrante con lote", line: lotTracked, entry: CountEntry{CountedQuantity: intPtr(7), LotNumber: "L-1"}},
+		{name: "sobrante sin lote", line: lotTracked, entry: CountEntry{CountedQuantity: intPtr(7)}, wantErr: "lot_number is required for the 2 units found"},
+		{name: "faltante sin lote sale por FEFO", line: lotTracked, entry: CountEntry{CountedQuantity: intPtr(3)}},
+		{name: "serializado sin diferencia", line: serialized, entry: CountEntry{CountedQuantity: intPtr(2)}},
+		{name: "serializado con faltante", line: serialized, entry: CountEntry{CountedQuantity: intPtr(1), Serials: []string{"SN-1"}}},
+		{name: "serializado sin las series de la diferencia", line: serialized, entry: CountEntry{CountedQuantity: intPtr(4), Serials: []string{"SN-9"}}, wantErr: "expected 2 serial numbers"},
+		{name: "serie repetida", line: serialized, entry: CountEntry{CountedQuantity: intPtr(4), Serials: []string{"SN-9", "SN-9"}}, wantErr: "[SN-9] is repeated"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCountEntry(&tt.line, tt.entry)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
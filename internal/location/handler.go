@@ -0,0 +1,230 @@
+package location
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+type Handler struct {
+	service *Service
+	logger  zerolog.Logger
+}
+
+func NewHandler(service *Service, logger zerolog.Logger) *Handler {
+	moduleLogger := logger.With().Str("module", "location").Logger()
+	return &Handler{
+		service: service,
+		logger:  moduleLogger,
+	}
+}
+
+// Create maneja POST /locations
+func (h *Handler) Create(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	var req CreateLocationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn().Err(err).Msg("Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	location, err := h.service.Create(ctx, req)
+	if err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error().Err(err).Str("code", req.Code).Msg("Error creating location")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creating location"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, location)
+}
+
+// List maneja GET /locations
+func (h *Handler) List(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	var filters ListFilters
+	if err := c.ShouldBindQuery(&filters); err != nil {
+		h.logger.Warn().Err(err).Msg("Invalid query parameters for locations")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+
+	response, err := h.service.List(ctx, filters)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error().Err(err).Msg("Error listing locations")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error listing locations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetByID maneja GET /locations/:id
+func (h *Handler) GetByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID, must be a number"})
+		return
+	}
+
+	location, err := h.service.GetByID(c.Request.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error().Err(err).Int("location_id", id).Msg("Error getting location")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, location)
+}
+
+// Update maneja PUT /locations/:id
+func (h *Handler) Update(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID, must be a number"})
+		return
+	}
+
+	var req UpdateLocationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	location, err := h.service.Update(ctx, id, req)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if strings.Contains(err.Error(), "cannot deactivate") {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error().Err(err).Int("location_id", id).Msg("Error updating location")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error updating location"})
+		return
+	}
+
+	c.JSON(http.StatusOK, location)
+}
+
+// Delete maneja DELETE /locations/:id (desactiva la ubicacion)
+func (h *Handler) Delete(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID, must be a number"})
+		return
+	}
+
+	if err := h.service.Deactivate(ctx, id); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if strings.Contains(err.Error(), "cannot deactivate") {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error().Err(err).Int("location_id", id).Msg("Failed to deactivate location")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to deactivate location"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Location deactivated successfully"})
+}
+
+// ListContents maneja GET /locations/:id/contents
+// lista lo que contiene un bin (o todos los bins debajo de una zona/pasillo/rack)
+func (h *Handler) ListContents(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID, must be a number"})
+		return
+	}
+
+	contents, err := h.service.ListContents(ctx, id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error().Err(err).Int("location_id", id).Msg("Error listing location contents")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error listing location contents"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": contents})
+}
+
+// ListBySKU maneja GET /locations/sku/:sku
+// lista las ubicaciones donde esta guardado un SKU
+func (h *Handler) ListBySKU(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	sku := c.Param("sku")
+	response, err := h.service.ListBySKU(ctx, sku)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error().Err(err).Str("sku", sku).Msg("Error listing SKU locations")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error listing SKU locations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
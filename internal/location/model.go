@@ -0,0 +1,107 @@
+package location
+
+import "time"
+
+// Representa el nivel de la ubicacion dentro del almacen
+type LocationType string
+
+const (
+	LocationTypeZone  LocationType = "ZONE"
+	LocationTypeAisle LocationType = "AISLE"
+	LocationTypeRack  LocationType = "RACK"
+	LocationTypeBin   LocationType = "BIN"
+)
+
+// isValid verifica si el tipo de ubicacion es valido
+func (lt LocationType) IsValid() bool {
+	return lt == LocationTypeZone || lt == LocationTypeAisle || lt == LocationTypeRack || lt == LocationTypeBin
+}
+
+// ParentType retorna el tipo que debe tener el padre (zone -> aisle -> rack -> bin)
+// una zona no tiene padre
+func (lt LocationType) ParentType() LocationType {
+	switch lt {
+	case LocationTypeAisle:
+		return LocationTypeZone
+	case LocationTypeRack:
+		return LocationTypeAisle
+	case LocationTypeBin:
+		return LocationTypeRack
+	}
+	return ""
+}
+
+type Location struct {
+	ID           int          `json:"id" db:"id"`
+	WarehouseID  int          `json:"warehouse_id" db:"warehouse_id"`
+	ParentID     *int         `json:"parent_id,omitempty" db:"parent_id"`
+	LocationType LocationType `json:"location_type" db:"location_type"`
+	Code         string       `json:"code" db:"code"`
+	Path         string       `json:"path" db:"path"`
+	Name         string       `json:"name" db:"name"`
+	IsActive     bool         `json:"is_active" db:"is_active"`
+	CreatedAt    time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at" db:"updated_at"`
+}
+
+type CreateLocationRequest struct {
+	WarehouseID  int          `json:"warehouse_id" binding:"required,min=1"`
+	ParentID     *int         `json:"parent_id" binding:"omitempty,min=1"`
+	LocationType LocationType `json:"location_type" binding:"required"`
+	Code         string       `json:"code" binding:"required,max=30"`
+	Name         string       `json:"name" binding:"max=255"`
+}
+
+type UpdateLocationRequest struct {
+	Name     *string `json:"name" binding:"omitempty,max=255"`
+	IsActive *bool   `json:"is_active"`
+}
+
+// ListFilters son los filtros opcionales de GET /locations
+type ListFilters struct {
+	WarehouseID  *int   `form:"warehouse_id"`
+	ParentID     *int   `form:"parent_id"`
+	LocationType string `form:"location_type"`
+	Page         int    `form:"page"`
+	PageSize     int    `form:"page_size"`
+}
+
+// LocationContent es lo que hay de un producto dentro de una ubicacion
+type LocationContent struct {
+	LocationID   int       `json:"location_id" db:"location_id"`
+	LocationPath string    `json:"location_path" db:"location_path"`
+	ProductID    int       `json:"product_id" db:"product_id"`
+	SKU          string    `json:"sku" db:"sku"`
+	ProductName  string    `json:"product_name" db:"product_name"`
+	Quantity     int       `json:"quantity" db:"quantity"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// ProductLocation es una ubicacion donde esta almacenado un SKU
+type ProductLocation struct {
+	WarehouseID   int    `json:"warehouse_id" db:"warehouse_id"`
+	WarehouseCode string `json:"warehouse_code" db:"warehouse_code"`
+	LocationID    int    `json:"location_id" db:"location_id"`
+	LocationPath  string `json:"location_path" db:"location_path"`
+	Quantity      int    `json:"quantity" db:"quantity"`
+}
+
+type SKULocationsResponse struct {
+	ProductID int               `json:"product_id"`
+	SKU       string            `json:"sku"`
+	Locations []ProductLocation `json:"locations"`
+	// stock del almacen que aun no tiene ubicacion asignada
+	Unlocated int `json:"unlocated_quantity"`
+}
+
+type PaginatedResponse struct {
+	Data       interface{} `json:"data"`
+	Pagination Pagination  `json:"pagination"`
+}
+
+type Pagination struct {
+	Page       int `json:"page"`
+	PageSize   int `json:"page_size"`
+	Total      int `json:"total"`
+	TotalPages int `json:"total_pages"`
+}
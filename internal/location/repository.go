@@ -0,0 +1,230 @@
+package location
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+)
+
+const locationColumns = `
+	id, warehouse_id, parent_id, location_type, code, path, COALESCE(name, '') AS name,
+	is_active, created_at, updated_at
+`
+
+type Repository struct {
+	db     *sqlx.DB
+	logger zerolog.Logger
+}
+
+func NewRepository(db *sqlx.DB, logger zerolog.Logger) *Repository {
+	moduleLogger := logger.With().Str("module", "location").Logger()
+	return &Repository{db: db, logger: moduleLogger}
+}
+
+// inserta una nueva ubicacion, el path ya viene calculado por el service
+func (r *Repository) Create(ctx context.Context, req CreateLocationRequest, path string) (*Location, error) {
+	query := `
+		INSERT INTO locations (warehouse_id, parent_id, location_type, code, path, name)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + locationColumns
+
+	var location Location
+	err := r.db.GetContext(ctx, &location, query,
+		req.WarehouseID, req.ParentID, req.LocationType, req.Code, path, req.Name,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error creating location: %w", err)
+	}
+	return &location, nil
+}
+
+func (r *Repository) GetByID(ctx context.Context, id int) (*Location, error) {
+	query := `SELECT ` + locationColumns + ` FROM locations WHERE id = $1`
+
+	var location Location
+	err := r.db.GetContext(ctx, &location, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("location with id [%d] not found", id)
+		}
+		return nil, fmt.Errorf("error getting location: %w", err)
+	}
+	return &location, nil
+}
+
+func (r *Repository) List(ctx context.Context, filters ListFilters) ([]Location, int, error) {
+	var conditions []string
+	var args []interface{}
+	argPosition := 1
+
+	if filters.WarehouseID != nil {
+		conditions = append(conditions, fmt.Sprintf("warehouse_id = $%d", argPosition))
+		args = append(args, *filters.WarehouseID)
+		argPosition++
+	}
+
+	if filters.ParentID != nil {
+		conditions = append(conditions, fmt.Sprintf("parent_id = $%d", argPosition))
+		args = append(args, *filters.ParentID)
+		argPosition++
+	}
+
+	if filters.LocationType != "" {
+		conditions = append(conditions, fmt.Sprintf("location_type = $%d", argPosition))
+		args = append(args, filters.LocationType)
+		argPosition++
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	err := r.db.GetContext(ctx, &total, "SELECT COUNT(*) FROM locations"+where, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error counting locations: %w", err)
+	}
+
+	offset := (filters.Page - 1) * filters.PageSize
+	query := "SELECT " + locationColumns + " FROM locations" + where +
+		fmt.Sprintf(" ORDER BY warehouse_id, path LIMIT $%d OFFSET $%d", argPosition, argPosition+1)
+	args = append(args, filters.PageSize, offset)
+
+	var locations []Location
+	err = r.db.SelectContext(ctx, &locations, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error listing locations: %w", err)
+	}
+
+	return locations, total, nil
+}
+
+// actualiza solo los campos enviados
+func (r *Repository) Update(ctx context.Context, id int, req UpdateLocationRequest) (*Location, error) {
+	query := `
+		UPDATE locations
+		SET name = COALESCE($1, name),
+			is_active = COALESCE($2, is_active)
+		WHERE id = $3
+		RETURNING ` + locationColumns
+
+	var location Location
+	err := r.db.GetContext(ctx, &location, query, req.Name, req.IsActive, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("location with id [%d] not found", id)
+		}
+		return nil, fmt.Errorf("error updating location: %w", err)
+	}
+	return &location, nil
+}
+
+// cuenta los hijos activos de una ubicacion
+func (r *Repository) CountActiveChildren(ctx context.Context, id int) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM locations WHERE parent_id = $1 AND is_active`
+
+	if err := r.db.GetContext(ctx, &count, query, id); err != nil {
+		return 0, fmt.Errorf("error counting child locations: %w", err)
+	}
+	return count, nil
+}
+
+// suma el stock guardado en la ubicacion
+func (r *Repository) TotalStock(ctx context.Context, id int) (int, error) {
+	var total int
+	query := `SELECT COALESCE(SUM(quantity), 0) FROM location_stock WHERE location_id = $1`
+
+	if err := r.db.GetContext(ctx, &total, query, id); err != nil {
+		return 0, fmt.Errorf("error getting location stock: %w", err)
+	}
+	return total, nil
+}
+
+func (r *Repository) WarehouseExists(ctx context.Context, warehouseID int) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM warehouses WHERE id = $1 AND deleted_at IS NULL)`
+
+	if err := r.db.GetContext(ctx, &exists, query, warehouseID); err != nil {
+		return false, fmt.Errorf("error checking warehouse existence: %w", err)
+	}
+	return exists, nil
+}
+
+// lista lo que contiene una ubicacion y todas las ubicaciones debajo de ella
+// (para un bin es solo su contenido, para una zona es todo lo que hay en sus bins)
+func (r *Repository) ListContents(ctx context.Context, location *Location) ([]LocationContent, error) {
+	query := `
+		SELECT l.id AS location_id, l.path AS location_path, p.id AS product_id, p.sku,
+			p.name AS product_name, ls.quantity, ls.updated_at
+		FROM location_stock ls
+		JOIN locations l ON l.id = ls.location_id
+		JOIN products p ON p.id = ls.product_id
+		WHERE l.warehouse_id = $1
+			AND (l.id = $2 OR left(l.path, length($3) + 1) = $3 || '/')
+			AND ls.quantity > 0
+		ORDER BY l.path, p.sku
+	`
+
+	var contents []LocationContent
+	err := r.db.SelectContext(ctx, &contents, query, location.WarehouseID, location.ID, location.Path)
+	if err != nil {
+		return nil, fmt.Errorf("error listing location contents: %w", err)
+	}
+	return contents, nil
+}
+
+// obtiene id del producto a partir del sku
+func (r *Repository) GetProductIDBySKU(ctx context.Context, sku string) (int, error) {
+	var id int
+	query := `SELECT id FROM products WHERE sku = $1 AND deleted_at IS NULL`
+
+	err := r.db.GetContext(ctx, &id, query, sku)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("product with sku [%s] not found", sku)
+		}
+		return 0, fmt.Errorf("error getting product: %w", err)
+	}
+	return id, nil
+}
+
+// lista las ubicaciones donde esta guardado un producto
+func (r *Repository) ListProductLocations(ctx context.Context, productID int) ([]ProductLocation, error) {
+	query := `
+		SELECT w.id AS warehouse_id, w.code AS warehouse_code, l.id AS location_id,
+			l.path AS location_path, ls.quantity
+		FROM location_stock ls
+		JOIN locations l ON l.id = ls.location_id
+		JOIN warehouses w ON w.id = l.warehouse_id
+		WHERE ls.product_id = $1 AND ls.quantity > 0
+		ORDER BY w.code, l.path
+	`
+
+	var locations []ProductLocation
+	err := r.db.SelectContext(ctx, &locations, query, productID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing product locations: %w", err)
+	}
+	return locations, nil
+}
+
+// stock total del producto que no esta asignado a ninguna ubicacion
+func (r *Repository) UnlocatedStock(ctx context.Context, productID int) (int, error) {
+	var unlocated int
+	query := `
+		SELECT
+			(SELECT COALESCE(SUM(quantity), 0) FROM stock_levels WHERE product_id = $1) -
+			(SELECT COALESCE(SUM(quantity), 0) FROM location_stock WHERE product_id = $1)
+	`
+
+	if err := r.db.GetContext(ctx, &unlocated, query, productID); err != nil {
+		return 0, fmt.Errorf("error getting unlocated stock: %w", err)
+	}
+	return unlocated, nil
+}
@@ -0,0 +1,237 @@
+package location
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+type Service struct {
+	repo   *Repository
+	logger zerolog.Logger
+}
+
+func NewService(repo *Repository, logger zerolog.Logger) *Service {
+	serviceLogger := logger.With().Str("service", "location").Logger()
+	return &Service{repo: repo, logger: serviceLogger}
+}
+
+// Crea una ubicacion respetando la jerarquia zone -> aisle -> rack -> bin
+// el path se arma con el path del padre + el codigo (ej. A/01/03/B)
+func (s *Service) Create(ctx context.Context, req CreateLocationRequest) (*Location, error) {
+	req.Code = strings.TrimSpace(strings.ToUpper(req.Code))
+	req.LocationType = LocationType(strings.ToUpper(string(req.LocationType)))
+
+	if !req.LocationType.IsValid() {
+		return nil, fmt.Errorf("invalid location_type: must be ZONE, AISLE, RACK or BIN")
+	}
+
+	if req.Code == "" || strings.Contains(req.Code, "/") {
+		return nil, fmt.Errorf("invalid code: must not be empty or contain '/'")
+	}
+
+	exists, err := s.repo.WarehouseExists(ctx, req.WarehouseID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("warehouse with id [%d] not found", req.WarehouseID)
+	}
+
+	path := req.Code
+	parentType := req.LocationType.ParentType()
+
+	if parentType == "" {
+		if req.ParentID != nil {
+			return nil, fmt.Errorf("invalid parent_id: a ZONE cannot have a parent")
+		}
+	} else {
+		if req.ParentID == nil {
+			return nil, fmt.Errorf("invalid parent_id: a %s must be inside a %s", req.LocationType, parentType)
+		}
+
+		parent, err := s.repo.GetByID(ctx, *req.ParentID)
+		if err != nil {
+			return nil, err
+		}
+
+		if parent.WarehouseID != req.WarehouseID {
+			return nil, fmt.Errorf("invalid parent_id: parent belongs to another warehouse")
+		}
+		if parent.LocationType != parentType {
+			return nil, fmt.Errorf("invalid parent_id: a %s must be inside a %s", req.LocationType, parentType)
+		}
+		if !parent.IsActive {
+			return nil, fmt.Errorf("invalid parent_id: parent location is inactive")
+		}
+
+		path = parent.Path + "/" + req.Code
+	}
+
+	location, err := s.repo.Create(ctx, req, path)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			return nil, fmt.Errorf("location [%s] already exists in warehouse", path)
+		}
+		return nil, err
+	}
+
+	s.logger.Info().Int("location_id", location.ID).Str("path", location.Path).Msg("Location created")
+	return location, nil
+}
+
+func (s *Service) GetByID(ctx context.Context, id int) (*Location, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("invalid ID: must be greater than 0")
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *Service) List(ctx context.Context, filters ListFilters) (*PaginatedResponse, error) {
+	filters.Page, filters.PageSize = normalizePagination(filters.Page, filters.PageSize)
+
+	if filters.LocationType != "" {
+		lt := LocationType(strings.ToUpper(filters.LocationType))
+		if !lt.IsValid() {
+			return nil, fmt.Errorf("invalid location_type: must be ZONE, AISLE, RACK or BIN")
+		}
+		filters.LocationType = string(lt)
+	}
+
+	locations, total, err := s.repo.List(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PaginatedResponse{
+		Data: locations,
+		Pagination: Pagination{
+			Page:       filters.Page,
+			PageSize:   filters.PageSize,
+			Total:      total,
+			TotalPages: (total + filters.PageSize - 1) / filters.PageSize,
+		},
+	}, nil
+}
+
+func (s *Service) Update(ctx context.Context, id int, req UpdateLocationRequest) (*Location, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("invalid ID: must be greater than 0")
+	}
+
+	if req.IsActive != nil && !*req.IsActive {
+		if err := s.ensureEmpty(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.repo.Update(ctx, id, req)
+}
+
+// Deactivate desactiva una ubicacion vacia (no se borra porque los movimientos la referencian)
+func (s *Service) Deactivate(ctx context.Context, id int) error {
+	if id <= 0 {
+		return fmt.Errorf("invalid ID: must be greater than 0")
+	}
+
+	if err := s.ensureEmpty(ctx, id); err != nil {
+		return err
+	}
+
+	inactive := false
+	_, err := s.repo.Update(ctx, id, UpdateLocationRequest{IsActive: &inactive})
+	return err
+}
+
+// lista el contenido de una ubicacion (incluye las ubicaciones hijas)
+func (s *Service) ListContents(ctx context.Context, id int) ([]LocationContent, error) {
+	location, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	contents, err := s.repo.ListContents(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+
+	if contents == nil {
+		contents = []LocationContent{}
+	}
+	return contents, nil
+}
+
+// lista donde esta guardado un SKU
+func (s *Service) ListBySKU(ctx context.Context, sku string) (*SKULocationsResponse, error) {
+	sku = strings.TrimSpace(sku)
+	if sku == "" {
+		return nil, fmt.Errorf("invalid SKU: must not be empty")
+	}
+
+	productID, err := s.repo.GetProductIDBySKU(ctx, sku)
+	if err != nil {
+		return nil, err
+	}
+
+	locations, err := s.repo.ListProductLocations(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	unlocated, err := s.repo.UnlocatedStock(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if locations == nil {
+		locations = []ProductLocation{}
+	}
+
+	return &SKULocationsResponse{
+		ProductID: productID,
+		SKU:       sku,
+		Locations: locations,
+		Unlocated: unlocated,
+	}, nil
+}
+
+// una ubicacion solo se desactiva si no tiene stock ni hijos activos
+func (s *Service) ensureEmpty(ctx context.Context, id int) error {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return err
+	}
+
+	children, err := s.repo.CountActiveChildren(ctx, id)
+	if err != nil {
+		return err
+	}
+	if children > 0 {
+		return fmt.Errorf("cannot deactivate location with %d active child locations", children)
+	}
+
+	stock, err := s.repo.TotalStock(ctx, id)
+	if err != nil {
+		return err
+	}
+	if stock > 0 {
+		return fmt.Errorf("cannot deactivate location with stock: %d units remaining", stock)
+	}
+	return nil
+}
+
+func normalizePagination(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	if pageSize > 100 {
+		pageSize = 100
+	}
+	return page, pageSize
+}
@@ -0,0 +1,48 @@
+package lot
+
+import "time"
+
+type Lot struct {
+	ID             int        `json:"id" db:"id"`
+	ProductID      int        `json:"product_id" db:"product_id"`
+	SKU            string     `json:"sku" db:"sku"`
+	LotNumber      string     `json:"lot_number" db:"lot_number"`
+	ManufacturedAt *time.Time `json:"manufactured_at,omitempty" db:"manufactured_at"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	Expired        bool       `json:"expired" db:"expired"`
+	Quantity       int        `json:"quantity" db:"quantity"` // stock total (o del almacen filtrado)
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+
+	// desglose del stock por almacen (solo en GetByID)
+	Stock []LotStock `json:"stock,omitempty" db:"-"`
+}
+
+// LotStock es el stock del lote en un almacen
+type LotStock struct {
+	WarehouseID   int       `json:"warehouse_id" db:"warehouse_id"`
+	WarehouseCode string    `json:"warehouse_code" db:"warehouse_code"`
+	Quantity      int       `json:"quantity" db:"quantity"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// ListFilters son los filtros opcionales de GET /lots
+type ListFilters struct {
+	ProductID      *int   `form:"product_id"`
+	WarehouseID    *int   `form:"warehouse_id"`
+	ExpiringBefore string `form:"expiring_before"` // YYYY-MM-DD
+	IncludeEmpty   bool   `form:"include_empty"`   // por defecto solo lotes con stock
+	Page           int    `form:"page"`
+	PageSize       int    `form:"page_size"`
+}
+
+type PaginatedResponse struct {
+	Data       interface{} `json:"data"`
+	Pagination Pagination  `json:"pagination"`
+}
+
+type Pagination struct {
+	Page       int `json:"page"`
+	PageSize   int `json:"page_size"`
+	Total      int `json:"total"`
+	TotalPages int `json:"total_pages"`
+}
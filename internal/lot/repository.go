@@ -0,0 +1,124 @@
+package lot
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+)
+
+type Repository struct {
+	db     *sqlx.DB
+	logger zerolog.Logger
+}
+
+func NewRepository(db *sqlx.DB, logger zerolog.Logger) *Repository {
+	moduleLogger := logger.With().Str("module", "lot").Logger()
+	return &Repository{db: db, logger: moduleLogger}
+}
+
+func (r *Repository) GetByID(ctx context.Context, id int) (*Lot, error) {
+	query := `
+		SELECT l.id, l.product_id, p.sku, l.lot_number, l.manufactured_at, l.expires_at,
+			COALESCE(l.expires_at < CURRENT_DATE, FALSE) AS expired,
+			COALESCE((SELECT SUM(quantity) FROM lot_stock WHERE lot_id = l.id), 0) AS quantity,
+			l.created_at
+		FROM lots l
+		JOIN products p ON p.id = l.product_id
+		WHERE l.id = $1
+	`
+
+	var lot Lot
+	err := r.db.GetContext(ctx, &lot, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("lot with id [%d] not found", id)
+		}
+		return nil, fmt.Errorf("error getting lot: %w", err)
+	}
+	return &lot, nil
+}
+
+// lista los lotes en orden FEFO
+// con warehouse_id la cantidad es la del almacen, sin filtro es el total
+func (r *Repository) List(ctx context.Context, filters ListFilters) ([]Lot, int, error) {
+	var conditions []string
+	var args []interface{}
+	argPosition := 1
+
+	stockJoin := "LEFT JOIN lot_stock ls ON ls.lot_id = l.id"
+	if filters.WarehouseID != nil {
+		stockJoin = fmt.Sprintf("LEFT JOIN lot_stock ls ON ls.lot_id = l.id AND ls.warehouse_id = $%d", argPosition)
+		args = append(args, *filters.WarehouseID)
+		argPosition++
+	}
+
+	if filters.ProductID != nil {
+		conditions = append(conditions, fmt.Sprintf("l.product_id = $%d", argPosition))
+		args = append(args, *filters.ProductID)
+		argPosition++
+	}
+
+	if filters.ExpiringBefore != "" {
+		conditions = append(conditions, fmt.Sprintf("l.expires_at < $%d", argPosition))
+		args = append(args, filters.ExpiringBefore)
+		argPosition++
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	having := ""
+	if !filters.IncludeEmpty {
+		having = " HAVING COALESCE(SUM(ls.quantity), 0) > 0"
+	}
+
+	baseQuery := `
+		SELECT l.id, l.product_id, p.sku, l.lot_number, l.manufactured_at, l.expires_at,
+			COALESCE(l.expires_at < CURRENT_DATE, FALSE) AS expired,
+			COALESCE(SUM(ls.quantity), 0) AS quantity, l.created_at
+		FROM lots l
+		JOIN products p ON p.id = l.product_id
+		` + stockJoin + where + `
+		GROUP BY l.id, p.sku` + having
+
+	var total int
+	err := r.db.GetContext(ctx, &total, "SELECT COUNT(*) FROM ("+baseQuery+") t", args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error counting lots: %w", err)
+	}
+
+	offset := (filters.Page - 1) * filters.PageSize
+	query := baseQuery + fmt.Sprintf(" ORDER BY l.expires_at NULLS LAST, l.id LIMIT $%d OFFSET $%d", argPosition, argPosition+1)
+	args = append(args, filters.PageSize, offset)
+
+	var lots []Lot
+	err = r.db.SelectContext(ctx, &lots, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error listing lots: %w", err)
+	}
+
+	return lots, total, nil
+}
+
+// desglose del stock del lote por almacen
+func (r *Repository) ListStock(ctx context.Context, lotID int) ([]LotStock, error) {
+	query := `
+		SELECT w.id AS warehouse_id, w.code AS warehouse_code, ls.quantity, ls.updated_at
+		FROM lot_stock ls
+		JOIN warehouses w ON w.id = ls.warehouse_id
+		WHERE ls.lot_id = $1 AND ls.quantity > 0
+		ORDER BY w.code
+	`
+
+	var stock []LotStock
+	if err := r.db.SelectContext(ctx, &stock, query, lotID); err != nil {
+		return nil, fmt.Errorf("error getting lot stock: %w", err)
+	}
+	return stock, nil
+}
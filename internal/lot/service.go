@@ -0,0 +1,79 @@
+package lot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+type Service struct {
+	repo   *Repository
+	logger zerolog.Logger
+}
+
+func NewService(repo *Repository, logger zerolog.Logger) *Service {
+	serviceLogger := logger.With().Str("service", "lot").Logger()
+	return &Service{repo: repo, logger: serviceLogger}
+}
+
+// obtiene el lote con su stock por almacen
+func (s *Service) GetByID(ctx context.Context, id int) (*Lot, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("invalid ID: must be greater than 0")
+	}
+
+	lot, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	stock, err := s.repo.ListStock(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	lot.Stock = stock
+
+	return lot, nil
+}
+
+func (s *Service) List(ctx context.Context, filters ListFilters) (*PaginatedResponse, error) {
+	filters.Page, filters.PageSize = normalizePagination(filters.Page, filters.PageSize)
+
+	if filters.ExpiringBefore != "" {
+		if _, err := time.Parse("2006-01-02", filters.ExpiringBefore); err != nil {
+			return nil, fmt.Errorf("invalid expiring_before: must be YYYY-MM-DD")
+		}
+	}
+
+	lots, total, err := s.repo.List(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PaginatedResponse{
+		Data: lots,
+		Pagination: Pagination{
+			Page:       filters.Page,
+			PageSize:   filters.PageSize,
+			Total:      total,
+			TotalPages: (total + filters.PageSize - 1) / filters.PageSize,
+		},
+	}, nil
+}
+
+func normalizePagination(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	if pageSize > 100 {
+		pageSize = 100
+	}
+	return page, pageSize
+}
@@ -1,10 +1,14 @@
 package movement
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog"
@@ -27,7 +31,7 @@ func NewHandler(service *Service, logger zerolog.Logger) *Handler {
 
 // Create maneja POST /movements
 // @Summary Registrar un nuevo movimiento de inventario
-// @Description Crea un movimiento de tipo IN (entrada) o OUT (salida) y actualiza el stock del producto
+// @Description Crea un movimiento de tipo IN (entrada), OUT (salida) o TRANSFER (entre ubicaciones/almacenes) y actualiza el stock del producto
 // @Tags movements
 // @Accept json
 // @Produce json
@@ -48,37 +52,262 @@ func (h *Handler) Create(c *gin.Context) {
 
 	// Normalizar movement_type a mayúsculas
 	req.MovementType = MovementType(strings.ToUpper(string(req.MovementType)))
+	req.ReasonCode = strings.ToUpper(strings.TrimSpace(req.ReasonCode))
+	req.FromStatus = normalizeStatus(req.FromStatus)
+	req.ToStatus = normalizeStatus(req.ToStatus)
 
 	// Obtener usuario del context (setado por middleware)
 	if email, exists := c.Get("email"); exists {
 		req.CreatedBy = email.(string)
 	}
 
+	// los ajustes tienen su propio endpoint restringido a admins
+	if req.MovementType == MovementTypeAdjust {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid movement_type: use POST /movements/adjustments for ADJUST"})
+		return
+	}
+
+	if req.MovementType == MovementTypeStatusChange {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid movement_type: use POST /movements/status-changes for STATUS_CHANGE"})
+		return
+	}
+
+	// sacar stock no disponible (cuarentena, dañado...) es una decision de admin
+	if req.OverrideStatus {
+		if role, _ := c.Get("role"); role != "admin" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "only admins can override the stock status"})
+			return
+		}
+	}
+
 	// Crear movimiento
 	response, err := h.service.CreateMovement(c.Request.Context(), req)
 	if err != nil {
-		// Determinar el código de estado según el error
-		statusCode := http.StatusInternalServerError
-		errorMessage := err.Error()
-
-		// Si es un error de validación o negocio, retornar 400
-		if strings.Contains(errorMessage, "invalid") ||
-			strings.Contains(errorMessage, "must be") ||
-			strings.Contains(errorMessage, "insufficient stock") ||
-			strings.Contains(errorMessage, "not found") {
-			h.logger.Warn().Err(err).Int("product_id", req.ProductID).Str("movement_type", string(req.MovementType)).Msg("Business validation failed")
-			statusCode = http.StatusBadRequest
-		} else {
-			h.logger.Error().Err(err).Int("product_id", req.ProductID).Str("movement_type", string(req.MovementType)).Msg("Error creating movement")
+		h.respondCreateError(c, err, req)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// CreateBatch maneja POST /movements/batch
+// @Summary Registrar varios movimientos de forma atomica
+// @Description Aplica todas las lineas (IN, OUT o TRANSFER) en una sola transaccion con un batch_id comun; si una falla no se registra ninguna
+// @Tags movements
+// @Accept json
+// @Produce json
+// @Param batch body CreateBatchRequest true "Lineas del lote"
+// @Success 201 {object} BatchResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /movements/batch [post]
+func (h *Handler) CreateBatch(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	var batch CreateBatchRequest
+	if err := c.ShouldBindJSON(&batch); err != nil {
+		h.logger.Warn().Err(err).Msg("Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	role, _ := c.Get("role")
+	for i := range batch.Lines {
+		line := &batch.Lines[i]
+		line.MovementType = MovementType(strings.ToUpper(string(line.MovementType)))
+		line.ReasonCode = strings.ToUpper(strings.TrimSpace(line.ReasonCode))
+		line.FromStatus = normalizeStatus(line.FromStatus)
+		line.ToStatus = normalizeStatus(line.ToStatus)
+
+		// mismas reglas que POST /movements: ajustes y cambios de estado van por sus endpoints
+		if line.MovementType == MovementTypeAdjust || line.MovementType == MovementTypeStatusChange {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("line %d: invalid movement_type: batches only accept IN, OUT and TRANSFER", i+1)})
+			return
+		}
+		if line.OverrideStatus && role != "admin" {
+			c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("line %d: only admins can override the stock status", i+1)})
+			return
 		}
+	}
 
-		c.JSON(statusCode, gin.H{"error": errorMessage})
+	if email, exists := c.Get("email"); exists {
+		batch.CreatedBy = email.(string)
+	}
+
+	response, err := h.service.CreateBatch(ctx, batch)
+	if err != nil {
+		// el error de una linea se responde como el de POST /movements, con la linea en el mensaje
+		var lineErr *BatchLineError
+		var req CreateMovementRequest
+		if errors.As(err, &lineErr) {
+			req = batch.Lines[lineErr.Line-1]
+		}
+		h.respondCreateError(c, err, req)
 		return
 	}
 
 	c.JSON(http.StatusCreated, response)
 }
 
+// CreateAdjustment maneja POST /movements/adjustments
+// @Summary Registrar un ajuste de inventario
+// @Description Crea un movimiento ADJUST (cantidad positiva o negativa) con un codigo de razon del catalogo. Solo admins
+// @Tags movements
+// @Accept json
+// @Produce json
+// @Param adjustment body CreateAdjustmentRequest true "Datos del ajuste"
+// @Success 201 {object} MovementResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /movements/adjustments [post]
+func (h *Handler) CreateAdjustment(c *gin.Context) {
+	var adjustment CreateAdjustmentRequest
+
+	if err := c.ShouldBindJSON(&adjustment); err != nil {
+		h.logger.Warn().Err(err).Msg("Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	adjustment.ReasonCode = strings.ToUpper(strings.TrimSpace(adjustment.ReasonCode))
+	adjustment.Status = normalizeStatus(adjustment.Status)
+
+	if email, exists := c.Get("email"); exists {
+		adjustment.CreatedBy = email.(string)
+	}
+
+	req := adjustment.ToMovementRequest()
+	response, err := h.service.CreateMovement(c.Request.Context(), req)
+	if err != nil {
+		h.respondCreateError(c, err, req)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// CreateStatusChange maneja POST /movements/status-changes
+// @Summary Cambiar el estado del inventario
+// @Description Crea un movimiento STATUS_CHANGE que pasa stock entre estados del almacen (AVAILABLE, QUARANTINE, DAMAGED, ON_HOLD)
+// @Tags movements
+// @Accept json
+// @Produce json
+// @Param statusChange body CreateStatusChangeRequest true "Datos del cambio de estado"
+// @Success 201 {object} MovementResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /movements/status-changes [post]
+func (h *Handler) CreateStatusChange(c *gin.Context) {
+	var statusChange CreateStatusChangeRequest
+
+	if err := c.ShouldBindJSON(&statusChange); err != nil {
+		h.logger.Warn().Err(err).Msg("Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	statusChange.ReasonCode = strings.ToUpper(strings.TrimSpace(statusChange.ReasonCode))
+	statusChange.FromStatus = normalizeStatus(statusChange.FromStatus)
+	statusChange.ToStatus = normalizeStatus(statusChange.ToStatus)
+
+	if email, exists := c.Get("email"); exists {
+		statusChange.CreatedBy = email.(string)
+	}
+
+	req := statusChange.ToMovementRequest()
+	response, err := h.service.CreateMovement(c.Request.Context(), req)
+	if err != nil {
+		h.respondCreateError(c, err, req)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// Reverse maneja POST /movements/:id/reverse
+// @Summary Revertir un movimiento
+// @Description Registra el movimiento contrario ligado al original (reversal_of_id). Solo admins; un movimiento se revierte una sola vez
+// @Tags movements
+// @Accept json
+// @Produce json
+// @Param id path int true "Movement ID"
+// @Param reversal body ReverseMovementRequest false "Motivo de la reversion"
+// @Success 201 {object} MovementResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /movements/{id}/reverse [post]
+func (h *Handler) Reverse(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid movement ID"})
+		return
+	}
+
+	// el body es opcional
+	var req ReverseMovementRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.logger.Warn().Err(err).Msg("Invalid request body")
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if email, exists := c.Get("email"); exists {
+		req.CreatedBy = email.(string)
+	}
+
+	response, err := h.service.Reverse(c.Request.Context(), id, req)
+	if err != nil {
+		// una reversion que no procede o que dejaria stock negativo es un conflicto con el estado actual
+		switch {
+		case strings.Contains(err.Error(), "cannot") || strings.Contains(err.Error(), "insufficient stock"):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case strings.Contains(err.Error(), "movement with ID"):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "must be") ||
+			strings.Contains(err.Error(), "not found"):
+			h.logger.Warn().Err(err).Int("movement_id", id).Msg("Business validation failed")
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			h.logger.Error().Err(err).Int("movement_id", id).Msg("Error reversing movement")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error reversing movement"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// normaliza el estado del inventario a mayusculas
+func normalizeStatus(status StockStatus) StockStatus {
+	return StockStatus(strings.ToUpper(strings.TrimSpace(string(status))))
+}
+
+// respondCreateError traduce el error de CreateMovement al codigo HTTP
+// los errores de validacion o negocio son 400, el resto 500
+func (h *Handler) respondCreateError(c *gin.Context, err error, req CreateMovementRequest) {
+	statusCode := http.StatusInternalServerError
+	errorMessage := err.Error()
+
+	if strings.Contains(errorMessage, "invalid") ||
+		strings.Contains(errorMessage, "must be") ||
+		strings.Contains(errorMessage, "insufficient stock") ||
+		strings.Contains(errorMessage, "not found") {
+		h.logger.Warn().Err(err).Int("product_id", req.ProductID).Str("movement_type", string(req.MovementType)).Msg("Business validation failed")
+		statusCode = http.StatusBadRequest
+	} else {
+		h.logger.Error().Err(err).Int("product_id", req.ProductID).Str("movement_type", string(req.MovementType)).Msg("Error creating movement")
+	}
+
+	c.JSON(statusCode, gin.H{"error": errorMessage})
+}
+
 // GetByID maneja GET /movements/:id
 // @Summary Obtener un movimiento por ID
 // @Description Retorna los detalles de un movimiento específico
@@ -159,13 +388,15 @@ func (h *Handler) ListByProductID(c *gin.Context) {
 
 // List maneja GET /movements
 // @Summary Listar movimientos con filtros opcionales
-// @Description Retorna movimientos filtrados por product_id y/o movement_type con paginación
+// @Description Retorna movimientos filtrados por product_id, warehouse_id y/o movement_type con paginación
 // @Tags movements
 // @Produce json
 // @Param page query int false "Número de página" default(1)
 // @Param page_size query int false "Tamaño de página" default(10)
 // @Param product_id query int false "Filtrar por ID de producto"
-// @Param movement_type query string false "Filtrar por tipo (IN o OUT)"
+// @Param warehouse_id query int false "Filtrar por ID de almacen"
+// @Param movement_type query string false "Filtrar por tipo (IN, OUT, TRANSFER, ADJUST o STATUS_CHANGE)"
+// @Param batch_id query int false "Filtrar por lote de movimientos"
 // @Success 200 {object} ListMovementsResponse
 // @Failure 400 {object} ErrorResponse
 // @Router /movements [get]
@@ -186,6 +417,16 @@ func (h *Handler) List(c *gin.Context) {
 		productID = &id // Asignar el puntero
 	}
 
+	var warehouseID *int
+	if warehouseIDStr := c.Query("warehouse_id"); warehouseIDStr != "" {
+		id, err := strconv.Atoi(warehouseIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid warehouse_id parameter"})
+			return
+		}
+		warehouseID = &id
+	}
+
 	var movementType *MovementType
 	if movementTypeStr := c.Query("movement_type"); movementTypeStr != "" {
 		// Normalizar a mayúsculas
@@ -193,15 +434,25 @@ func (h *Handler) List(c *gin.Context) {
 
 		// Validar que sea un valor válido
 		if !mt.IsValid() {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid movement_type: must be IN or OUT"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid movement_type: must be IN, OUT, TRANSFER, ADJUST or STATUS_CHANGE"})
 			return
 		}
 
 		movementType = &mt // Asignar el puntero
 	}
 
+	var batchID *int
+	if batchIDStr := c.Query("batch_id"); batchIDStr != "" {
+		id, err := strconv.Atoi(batchIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid batch_id parameter"})
+			return
+		}
+		batchID = &id
+	}
+
 	// Obtener movimientos con filtros opcionales
-	response, err := h.service.List(c.Request.Context(), productID, movementType, page, pageSize)
+	response, err := h.service.List(c.Request.Context(), productID, warehouseID, movementType, batchID, page, pageSize)
 	if err != nil {
 		h.logger.Error().Err(err).Msg("Error listing movements")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -1,46 +1,420 @@
 package movement
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
-// Representa el tipo de movimiento (IN OUT)
+// Representa el tipo de movimiento (IN OUT TRANSFER ADJUST STATUS_CHANGE)
 type MovementType string
 
 const (
-	MovementTypeIn  MovementType = "IN"
-	MovementTypeOut MovementType = "OUT"
+	MovementTypeIn           MovementType = "IN"
+	MovementTypeOut          MovementType = "OUT"
+	MovementTypeTransfer     MovementType = "TRANSFER"
+	MovementTypeAdjust       MovementType = "ADJUST"
+	MovementTypeStatusChange MovementType = "STATUS_CHANGE"
 )
 
 // isValid verifica si el movimiento es valido
 func (mt MovementType) IsValid() bool {
-	return mt == MovementTypeIn || mt == MovementTypeOut
+	return mt == MovementTypeIn || mt == MovementTypeOut || mt == MovementTypeTransfer || mt == MovementTypeAdjust ||
+		mt == MovementTypeStatusChange
+}
+
+// Representa el estado del inventario dentro del almacen
+// solo el stock AVAILABLE se puede reservar o vender, el resto queda retenido
+type StockStatus string
+
+const (
+	StockStatusAvailable  StockStatus = "AVAILABLE"
+	StockStatusQuarantine StockStatus = "QUARANTINE"
+	StockStatusDamaged    StockStatus = "DAMAGED"
+	StockStatusOnHold     StockStatus = "ON_HOLD"
+)
+
+func (st StockStatus) IsValid() bool {
+	return st == StockStatusAvailable || st == StockStatusQuarantine || st == StockStatusDamaged || st == StockStatusOnHold
+}
+
+// Representa el metodo de costeo de las salidas
+// FIFO consume las capas de la mas antigua a la mas reciente
+// AVERAGE valora todo el stock del almacen al costo promedio movil
+type CostingMethod string
+
+const (
+	CostingFIFO    CostingMethod = "FIFO"
+	CostingAverage CostingMethod = "AVERAGE"
+)
+
+// orDefault trata el estado vacio como AVAILABLE
+func (st StockStatus) orDefault() StockStatus {
+	if st == "" {
+		return StockStatusAvailable
+	}
+	return st
 }
 
 type Movement struct {
-	ID           int          `db:"id" json:"id"`
-	ProductID    int          `db:"product_id" json:"product_id"`
-	MovementType MovementType `db:"movement_type" json:"movement_type"`
-	Quantity     int          `db:"quantity" json:"quantity"`
-	Reason       string       `db:"reason" json:"reason"`
-	CreatedAt    time.Time    `db:"created_at" json:"created_at"`
-	CreatedBy    string       `db:"created_by" json:"created_by"`
+	ID             int          `db:"id" json:"id"`
+	ProductID      int          `db:"product_id" json:"product_id"`
+	WarehouseID    int          `db:"warehouse_id" json:"warehouse_id"`
+	ToWarehouseID  *int         `db:"to_warehouse_id" json:"to_warehouse_id,omitempty"` // destino (TRANSFER)
+	MovementType   MovementType `db:"movement_type" json:"movement_type"`
+	Quantity       int          `db:"quantity" json:"quantity"`         // en la unidad base, con signo en ADJUST
+	UoM            string       `db:"uom" json:"uom"`                   // unidad en la que se capturo
+	UoMQuantity    int          `db:"uom_quantity" json:"uom_quantity"` // cantidad capturada en uom
+	Reason         string       `db:"reason" json:"reason"`
+	ReasonCode     *string      `db:"reason_code" json:"reason_code,omitempty"`
+	FromLocationID *int         `db:"from_location_id" json:"from_location_id,omitempty"` // bin origen (OUT)
+	ToLocationID   *int         `db:"to_location_id" json:"to_location_id,omitempty"`     // bin destino (IN)
+	ReservationID  *int         `db:"reservation_id" json:"reservation_id,omitempty"`     // reserva consumida (OUT)
+	FromStatus     *StockStatus `db:"from_status" json:"from_status,omitempty"`           // estado de origen (nulo = AVAILABLE)
+	ToStatus       *StockStatus `db:"to_status" json:"to_status,omitempty"`               // estado de destino (nulo = AVAILABLE)
+	UnitCost       *float64     `db:"unit_cost" json:"unit_cost,omitempty"`               // costo por unidad base (en salidas, el promedio de lo consumido)
+	TotalCost      *float64     `db:"total_cost" json:"total_cost,omitempty"`             // valor que entro o salio (costo de lo vendido en OUT)
+	ReversalOfID   *int         `db:"reversal_of_id" json:"reversal_of_id,omitempty"`     // movimiento que este revierte
+	ReversedByID   *int         `db:"reversed_by_id" json:"reversed_by_id,omitempty"`     // movimiento que revirtio a este
+	BatchID        *int         `db:"batch_id" json:"batch_id,omitempty"`                 // lote de movimientos con el que se registro
+	CreatedAt      time.Time    `db:"created_at" json:"created_at"`
+	CreatedBy      string       `db:"created_by" json:"created_by"`
+
+	Lots    []MovementLot `db:"-" json:"lots,omitempty"`    // desglose por lote (productos con lotes)
+	Serials []string      `db:"-" json:"serials,omitempty"` // series de las unidades (productos serializados)
+
+	sku string // para invalidar la cache del producto despues del commit
 }
 
 type CreateMovementRequest struct {
-	ProductID    int          `json:"product_id" binding:"required,min=1"`
-	MovementType MovementType `json:"movement_type" binding:"required"`
-	Quantity     int          `json:"quantity" binding:"required,min=1"`
-	Reason       string       `json:"reason" binding:"max=255"`
-	CreatedBy    string       `json:"created_by" binding:"max=100"`
+	ProductID      int          `json:"product_id" binding:"required,min=1"`
+	WarehouseID    int          `json:"warehouse_id" binding:"omitempty,min=1"`    // opcional, si se omite usa el almacen por defecto
+	ToWarehouseID  int          `json:"to_warehouse_id" binding:"omitempty,min=1"` // TRANSFER: almacen destino, por defecto el mismo
+	MovementType   MovementType `json:"movement_type" binding:"required"`
+	Quantity       int          `json:"quantity" binding:"required,min=1"` // ADJUST (solo interno) admite negativos
+	UoM            string       `json:"uom" binding:"max=10"`              // opcional, por defecto la unidad base (EA)
+	Reason         string       `json:"reason" binding:"max=255"`
+	ReasonCode     string       `json:"reason_code" binding:"max=30"` // opcional, obligatorio en ADJUST
+	CreatedBy      string       `json:"created_by" binding:"max=100"`
+	FromLocationID *int         `json:"from_location_id" binding:"omitempty,min=1"` // opcional, bin de origen
+	ToLocationID   *int         `json:"to_location_id" binding:"omitempty,min=1"`   // opcional, bin de destino
+	// lotes: en entradas el lote es obligatorio (se crea si no existe)
+	// en salidas es opcional, sin lote se asigna FEFO (primero el que caduca antes)
+	LotNumber      string `json:"lot_number" binding:"max=50"`
+	ManufacturedAt string `json:"manufactured_at" binding:"omitempty,datetime=2006-01-02"` // solo entradas
+	ExpiresAt      string `json:"expires_at" binding:"omitempty,datetime=2006-01-02"`      // solo entradas
+	// productos serializados: una serie por unidad (len(serials) == quantity)
+	Serials []string `json:"serials" binding:"omitempty,dive,required,max=100"`
+	// OUT: reserva que se consume, sin reserva solo se puede sacar el stock no reservado
+	ReservationID *int `json:"reservation_id" binding:"omitempty,min=1"`
+	// estado del inventario, vacio es AVAILABLE: from_status en lo que sale (OUT, ADJUST negativo)
+	// y to_status en lo que entra (IN, ADJUST positivo), STATUS_CHANGE lleva los dos
+	FromStatus StockStatus `json:"from_status" binding:"max=20"`
+	ToStatus   StockStatus `json:"to_status" binding:"max=20"`
+	// OUT: permite sacar stock que no esta AVAILABLE (solo admins)
+	OverrideStatus bool `json:"override_status"`
+	// entradas: costo por unidad base, si se omite se usa el costo promedio actual del producto en el almacen
+	UnitCost *float64 `json:"unit_cost" binding:"omitempty,min=0"`
+
+	// reversiones (solo internas): el movimiento que se revierte y los lotes exactos que se devuelven
+	reversalOf *int
+	lots       []MovementLot
+	// lote de movimientos al que pertenece (POST /movements/batch)
+	batchID *int
+	// movimiento interno con un codigo de razon de sistema: esos codigos estan inactivos
+	// para que no se capturen por API, solo los usan los llamadores internos (reversiones, saldo inicial, conciliacion)
+	system bool
+	// ajuste de conciliacion: se registra el movimiento sin aplicarlo, el stock ya tenia esas unidades
+	ledgerOnly bool
 }
 
 type MovementResponse struct {
-	ID           int          `json:"id"`
-	ProductID    int          `json:"product_id"`
-	MovementType MovementType `json:"movement_type"`
-	Quantity     int          `json:"quantity"`
-	Reason       string       `json:"reason,omitempty"`
-	CreatedAt    time.Time    `json:"created_at"`
-	CreatedBy    string       `json:"created_by,omitempty"`
+	ID             int           `json:"id"`
+	ProductID      int           `json:"product_id"`
+	WarehouseID    int           `json:"warehouse_id"`
+	ToWarehouseID  *int          `json:"to_warehouse_id,omitempty"`
+	MovementType   MovementType  `json:"movement_type"`
+	Quantity       int           `json:"quantity"`
+	UoM            string        `json:"uom"`
+	UoMQuantity    int           `json:"uom_quantity"`
+	Reason         string        `json:"reason,omitempty"`
+	ReasonCode     *string       `json:"reason_code,omitempty"`
+	FromLocationID *int          `json:"from_location_id,omitempty"`
+	ToLocationID   *int          `json:"to_location_id,omitempty"`
+	ReservationID  *int          `json:"reservation_id,omitempty"`
+	FromStatus     *StockStatus  `json:"from_status,omitempty"`
+	ToStatus       *StockStatus  `json:"to_status,omitempty"`
+	UnitCost       *float64      `json:"unit_cost,omitempty"`
+	TotalCost      *float64      `json:"total_cost,omitempty"`
+	Reversed       bool          `json:"reversed"`
+	ReversalOfID   *int          `json:"reversal_of_id,omitempty"`
+	ReversedByID   *int          `json:"reversed_by_id,omitempty"`
+	BatchID        *int          `json:"batch_id,omitempty"`
+	CreatedAt      time.Time     `json:"created_at"`
+	CreatedBy      string        `json:"created_by,omitempty"`
+	Lots           []MovementLot `json:"lots,omitempty"`
+	Serials        []string      `json:"serials,omitempty"`
+}
+
+// CreateAdjustmentRequest es el body de POST /movements/adjustments
+// la cantidad lleva signo: positiva suma stock, negativa lo resta
+type CreateAdjustmentRequest struct {
+	ProductID   int    `json:"product_id" binding:"required,min=1"`
+	WarehouseID int    `json:"warehouse_id" binding:"omitempty,min=1"` // opcional, si se omite usa el almacen por defecto
+	Quantity    int    `json:"quantity" binding:"required"`
+	UoM         string `json:"uom" binding:"max=10"` // opcional, por defecto la unidad base (EA)
+	ReasonCode  string `json:"reason_code" binding:"required,max=30"`
+	Reason      string `json:"reason" binding:"max=255"`
+	LocationID  *int   `json:"location_id" binding:"omitempty,min=1"` // opcional, bin que se ajusta
+	CreatedBy   string `json:"created_by" binding:"max=100"`
+	// opcional, estado del inventario que se ajusta (por defecto AVAILABLE)
+	Status StockStatus `json:"status" binding:"max=20"`
+	// opcional, costo por unidad base de lo que se suma (por defecto el costo promedio actual)
+	UnitCost *float64 `json:"unit_cost" binding:"omitempty,min=0"`
+	// lotes: obligatorio si el ajuste suma, opcional (FEFO) si resta
+	LotNumber      string `json:"lot_number" binding:"max=50"`
+	ManufacturedAt string `json:"manufactured_at" binding:"omitempty,datetime=2006-01-02"`
+	ExpiresAt      string `json:"expires_at" binding:"omitempty,datetime=2006-01-02"`
+	// productos serializados: una serie por unidad ajustada
+	Serials []string `json:"serials" binding:"omitempty,dive,required,max=100"`
+}
+
+// convierte el ajuste en un movimiento ADJUST
+// el bin y el estado quedan como destino si el ajuste suma y como origen si resta
+func (r CreateAdjustmentRequest) ToMovementRequest() CreateMovementRequest {
+	req := CreateMovementRequest{
+		ProductID:      r.ProductID,
+		WarehouseID:    r.WarehouseID,
+		MovementType:   MovementTypeAdjust,
+		Quantity:       r.Quantity,
+		UoM:            r.UoM,
+		Reason:         r.Reason,
+		ReasonCode:     r.ReasonCode,
+		CreatedBy:      r.CreatedBy,
+		LotNumber:      r.LotNumber,
+		ManufacturedAt: r.ManufacturedAt,
+		ExpiresAt:      r.ExpiresAt,
+		Serials:        r.Serials,
+		UnitCost:       r.UnitCost,
+	}
+
+	if r.Quantity > 0 {
+		req.ToLocationID = r.LocationID
+		req.ToStatus = r.Status
+	} else {
+		req.FromLocationID = r.LocationID
+		req.FromStatus = r.Status
+	}
+	return req
+}
+
+// CreateStatusChangeRequest es el body de POST /movements/status-changes
+// mueve stock entre estados del almacen (ej. AVAILABLE -> QUARANTINE) sin cambiar el stock fisico
+type CreateStatusChangeRequest struct {
+	ProductID   int         `json:"product_id" binding:"required,min=1"`
+	WarehouseID int         `json:"warehouse_id" binding:"omitempty,min=1"` // opcional, si se omite usa el almacen por defecto
+	Quantity    int         `json:"quantity" binding:"required,min=1"`
+	UoM         string      `json:"uom" binding:"max=10"` // opcional, por defecto la unidad base (EA)
+	FromStatus  StockStatus `json:"from_status" binding:"required,max=20"`
+	ToStatus    StockStatus `json:"to_status" binding:"required,max=20"`
+	ReasonCode  string      `json:"reason_code" binding:"max=30"`
+	Reason      string      `json:"reason" binding:"max=255"`
+	CreatedBy   string      `json:"created_by" binding:"max=100"`
+}
+
+// convierte el cambio de estado en un movimiento STATUS_CHANGE
+func (r CreateStatusChangeRequest) ToMovementRequest() CreateMovementRequest {
+	return CreateMovementRequest{
+		ProductID:    r.ProductID,
+		WarehouseID:  r.WarehouseID,
+		MovementType: MovementTypeStatusChange,
+		Quantity:     r.Quantity,
+		UoM:          r.UoM,
+		FromStatus:   r.FromStatus,
+		ToStatus:     r.ToStatus,
+		Reason:       r.Reason,
+		ReasonCode:   r.ReasonCode,
+		CreatedBy:    r.CreatedBy,
+	}
+}
+
+// CreateBatchRequest es el body de POST /movements/batch
+// todas las lineas se aplican en una sola transaccion: se registran todas o ninguna
+type CreateBatchRequest struct {
+	Reference string                  `json:"reference" binding:"max=50"` // opcional, documento de origen (ej. remision del proveedor)
+	Lines     []CreateMovementRequest `json:"lines" binding:"required,min=1,max=200,dive"`
+	CreatedBy string                  `json:"-"`
+}
+
+// BatchResponse es el resultado de un lote: un movimiento por linea, en el orden del request
+type BatchResponse struct {
+	BatchID   int               `json:"batch_id"`
+	Reference string            `json:"reference,omitempty"`
+	CreatedBy string            `json:"created_by,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	Lines     []BatchLineResult `json:"lines"`
+}
+
+// BatchLineResult es el movimiento que genero una linea del lote
+type BatchLineResult struct {
+	Line     int              `json:"line"` // posicion de la linea en el request, desde 1
+	BatchID  int              `json:"batch_id"`
+	Movement MovementResponse `json:"movement"`
+}
+
+// BatchLineError es el error de una linea del lote: el mensaje lleva la linea (desde 1)
+type BatchLineError struct {
+	Line int
+	Err  error
+}
+
+func (e *BatchLineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *BatchLineError) Unwrap() error {
+	return e.Err
+}
+
+// ReverseMovementRequest es el body opcional de POST /movements/:id/reverse
+type ReverseMovementRequest struct {
+	Reason    string `json:"reason" binding:"max=255"` // por defecto "reversal of movement <id>"
+	CreatedBy string `json:"-"`
+}
+
+// reversalReasonCode es el codigo de razon (de sistema) de los movimientos de reversion
+const reversalReasonCode = "REVERSAL"
+
+// openingBalanceReasonCode es el codigo de razon (de sistema) de la entrada del stock inicial de un producto
+const openingBalanceReasonCode = "OPENING_BALANCE"
+
+// OpeningBalanceRequest arma la entrada del stock inicial de un producto recien creado
+// el llamador la registra con CreateMovementTx en la misma transaccion en que inserta el producto
+func OpeningBalanceRequest(productID, warehouseID, quantity int, unitCost *float64, createdBy string) CreateMovementRequest {
+	return CreateMovementRequest{
+		ProductID:    productID,
+		WarehouseID:  warehouseID,
+		MovementType: MovementTypeIn,
+		Quantity:     quantity,
+		Reason:       "Saldo inicial",
+		ReasonCode:   openingBalanceReasonCode,
+		CreatedBy:    createdBy,
+		UnitCost:     unitCost,
+		system:       true,
+	}
+}
+
+// reconciliationReasonCode es el codigo de razon (de sistema) de los ajustes de conciliacion
+const reconciliationReasonCode = "RECONCILIATION"
+
+// ReconciliationRequest arma el ajuste que completa el ledger de un almacen con la diferencia contra stock_levels
+// solo se registra: no mueve stock, lotes, series ni valor (stock_levels ya incluye esas unidades)
+// el llamador lo registra con CreateMovementTx con los stock_levels del producto ya bloqueados
+func ReconciliationRequest(productID, warehouseID, quantity, reconciliationID int, createdBy string) CreateMovementRequest {
+	return CreateMovementRequest{
+		ProductID:    productID,
+		WarehouseID:  warehouseID,
+		MovementType: MovementTypeAdjust,
+		Quantity:     quantity,
+		Reason:       fmt.Sprintf("stock reconciliation #%d", reconciliationID),
+		ReasonCode:   reconciliationReasonCode,
+		CreatedBy:    createdBy,
+		system:       true,
+		ledgerOnly:   true,
+	}
+}
+
+// CostLayer es una entrada de stock valorada que todavia no se consume por completo
+type CostLayer struct {
+	ID                int     `db:"id"`
+	MovementID        *int    `db:"movement_id"` // movimiento que la creo (nulo = saldo inicial)
+	Quantity          int     `db:"quantity"`
+	RemainingQuantity int     `db:"remaining_quantity"`
+	UnitCost          float64 `db:"unit_cost"`
+}
+
+// MovementLot es la cantidad de un lote que entro o salio en un movimiento
+type MovementLot struct {
+	MovementID int        `db:"movement_id" json:"-"`
+	LotID      int        `db:"lot_id" json:"lot_id"`
+	LotNumber  string     `db:"lot_number" json:"lot_number"`
+	ExpiresAt  *time.Time `db:"expires_at" json:"expires_at,omitempty"`
+	Quantity   int        `db:"quantity" json:"quantity"`
+}
+
+// ProductInfo son los datos del producto que se usan al mover stock
+type ProductInfo struct {
+	SKU          string `db:"sku"`
+	IsLotTracked bool   `db:"is_lot_tracked"`
+	IsSerialized bool   `db:"is_serialized"`
+}
+
+// baseUoM es la unidad en la que se guarda el stock (piezas)
+const baseUoM = "EA"
+
+// UoMInfo es la unidad de medida del movimiento y su conversion para el producto
+// Factor es nulo si el producto no tiene configurada la unidad
+type UoMInfo struct {
+	Code     string `db:"code"`
+	IsActive bool   `db:"is_active"`
+	Factor   *int   `db:"factor"`
+}
+
+// estados de una unidad serializada
+const (
+	serialInStock    = "IN_STOCK"
+	serialShipped    = "SHIPPED"
+	serialWrittenOff = "WRITTEN_OFF"
+)
+
+// SerialInfo es el estado actual de una unidad serializada
+type SerialInfo struct {
+	ID           int    `db:"id"`
+	ProductID    int    `db:"product_id"`
+	SerialNumber string `db:"serial_number"`
+	Status       string `db:"status"`
+	WarehouseID  *int   `db:"warehouse_id"`
+}
+
+// LotInfo son los datos del lote que se validan al mover stock
+type LotInfo struct {
+	ID             int        `db:"id"`
+	LotNumber      string     `db:"lot_number"`
+	ManufacturedAt *time.Time `db:"manufactured_at"`
+	ExpiresAt      *time.Time `db:"expires_at"`
+	Expired        bool       `db:"expired"`
+}
+
+// LotStockInfo es el stock de un lote en un almacen
+type LotStockInfo struct {
+	LotInfo
+	Quantity int `db:"quantity"`
+}
+
+// ReasonCodeInfo son los datos del codigo de razon que se validan al crear el movimiento
+type ReasonCodeInfo struct {
+	Code      string `db:"code"`
+	Direction string `db:"direction"`
+	IsActive  bool   `db:"is_active"`
+}
+
+// ReservationInfo es la reserva que consume una salida
+// Remaining es lo que queda por consumir y Expired si ya vencio aunque el barrido no la haya liberado
+type ReservationInfo struct {
+	ID          int    `db:"id"`
+	ProductID   int    `db:"product_id"`
+	WarehouseID int    `db:"warehouse_id"`
+	Status      string `db:"status"`
+	Remaining   int    `db:"remaining"`
+	Expired     bool   `db:"expired"`
+}
+
+// LocationInfo son los datos de la ubicacion que se validan al mover stock
+type LocationInfo struct {
+	ID           int    `db:"id"`
+	WarehouseID  int    `db:"warehouse_id"`
+	LocationType string `db:"location_type"`
+	Path         string `db:"path"`
+	IsActive     bool   `db:"is_active"`
 }
 
 type ListMovementResponse struct {
@@ -58,12 +432,30 @@ type PaginationMeta struct {
 // convierte un movimiento en un movimiento response
 func (m *Movement) ToResponse() MovementResponse {
 	return MovementResponse{
-		ID:           m.ID,
-		ProductID:    m.ProductID,
-		MovementType: m.MovementType,
-		Quantity:     m.Quantity,
-		Reason:       m.Reason,
-		CreatedAt:    m.CreatedAt,
-		CreatedBy:    m.CreatedBy,
+		ID:             m.ID,
+		ProductID:      m.ProductID,
+		WarehouseID:    m.WarehouseID,
+		ToWarehouseID:  m.ToWarehouseID,
+		MovementType:   m.MovementType,
+		Quantity:       m.Quantity,
+		UoM:            m.UoM,
+		UoMQuantity:    m.UoMQuantity,
+		Reason:         m.Reason,
+		ReasonCode:     m.ReasonCode,
+		FromLocationID: m.FromLocationID,
+		ToLocationID:   m.ToLocationID,
+		ReservationID:  m.ReservationID,
+		FromStatus:     m.FromStatus,
+		ToStatus:       m.ToStatus,
+		UnitCost:       m.UnitCost,
+		TotalCost:      m.TotalCost,
+		Reversed:       m.ReversedByID != nil,
+		ReversalOfID:   m.ReversalOfID,
+		ReversedByID:   m.ReversedByID,
+		BatchID:        m.BatchID,
+		CreatedAt:      m.CreatedAt,
+		CreatedBy:      m.CreatedBy,
+		Lots:           m.Lots,
+		Serials:        m.Serials,
 	}
 }
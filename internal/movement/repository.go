@@ -2,9 +2,13 @@ package movement
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type Repository struct {
@@ -19,16 +23,27 @@ func NewRepository(db *sqlx.DB) *Repository {
 
 func (r *Repository) Create(ctx context.Context, tx *sqlx.Tx, movement *Movement) error {
 	query := `
-		INSERT INTO movements (product_id, movement_type, quantity, reason, created_by)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO movements (
+			product_id, warehouse_id, movement_type, quantity, reason, created_by,
+			from_location_id, to_location_id, to_warehouse_id, reason_code, uom, uom_quantity, reservation_id,
+			from_status, to_status, unit_cost, total_cost, reversal_of_id, batch_id
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
 		RETURNING id, created_at
 	`
 
 	err := tx.QueryRowxContext(
-		ctx, query, movement.ProductID, movement.MovementType, movement.Quantity, movement.Reason, movement.CreatedBy,
+		ctx, query, movement.ProductID, movement.WarehouseID, movement.MovementType, movement.Quantity, movement.Reason, movement.CreatedBy,
+		movement.FromLocationID, movement.ToLocationID, movement.ToWarehouseID, movement.ReasonCode, movement.UoM, movement.UoMQuantity,
+		movement.ReservationID, movement.FromStatus, movement.ToStatus, movement.UnitCost, movement.TotalCost, movement.ReversalOfID,
+		movement.BatchID,
 	).Scan(&movement.ID, &movement.CreatedAt)
 
 	if err != nil {
+		// el indice unico de reversal_of_id impide revertir dos veces el mismo movimiento
+		if movement.ReversalOfID != nil && strings.Contains(err.Error(), "duplicate key") {
+			return fmt.Errorf("cannot reverse movement %d: it was already reversed", *movement.ReversalOfID)
+		}
 		return fmt.Errorf("error creating movement: %w", err)
 	}
 	return nil
@@ -37,7 +52,8 @@ func (r *Repository) Create(ctx context.Context, tx *sqlx.Tx, movement *Movement
 func (r *Repository) GetByID(ctx context.Context, id int) (*Movement, error) {
 	var movement Movement
 	query := `
-		SELECT id, product_id, movement_type, quantity, reason, created_at, created_by
+		SELECT id, product_id, warehouse_id, to_warehouse_id, movement_type, quantity, uom, uom_quantity, reason, reason_code, from_location_id, to_location_id, reservation_id, from_status, to_status, unit_cost, total_cost, created_at, created_by,
+			reversal_of_id, (SELECT rv.id FROM movements rv WHERE rv.reversal_of_id = movements.id) AS reversed_by_id, batch_id
 		FROM movements
 		WHERE id = $1
 	`
@@ -49,11 +65,75 @@ func (r *Repository) GetByID(ctx context.Context, id int) (*Movement, error) {
 	return &movement, nil
 }
 
+// registra el encabezado de un lote de movimientos
+func (r *Repository) CreateBatch(ctx context.Context, tx *sqlx.Tx, reference, createdBy string) (int, time.Time, error) {
+	var batch struct {
+		ID        int       `db:"id"`
+		CreatedAt time.Time `db:"created_at"`
+	}
+	query := `
+		INSERT INTO movement_batches (reference, created_by)
+		VALUES (NULLIF($1, ''), NULLIF($2, ''))
+		RETURNING id, created_at
+	`
+
+	if err := tx.GetContext(ctx, &batch, query, reference, createdBy); err != nil {
+		return 0, time.Time{}, fmt.Errorf("error creating movement batch: %w", err)
+	}
+	return batch.ID, batch.CreatedAt, nil
+}
+
+// obtiene el movimiento con LOCK PESIMISTA (serializa las reversiones del mismo movimiento)
+func (r *Repository) GetForUpdate(ctx context.Context, tx *sqlx.Tx, id int) (*Movement, error) {
+	var movement Movement
+	query := `
+		SELECT id, product_id, warehouse_id, to_warehouse_id, movement_type, quantity, uom, uom_quantity, reason, reason_code, from_location_id, to_location_id, reservation_id, from_status, to_status, unit_cost, total_cost, created_at, created_by,
+			reversal_of_id, (SELECT rv.id FROM movements rv WHERE rv.reversal_of_id = movements.id) AS reversed_by_id, batch_id
+		FROM movements
+		WHERE id = $1
+		FOR UPDATE
+	`
+
+	if err := tx.GetContext(ctx, &movement, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("movement with ID %d not found", id)
+		}
+		return nil, fmt.Errorf("error getting movement: %w", err)
+	}
+	return &movement, nil
+}
+
+// obtiene el documento que registro el movimiento (vacio si se registro directamente)
+// estos movimientos se corrigen desde su documento, no revirtiendolos
+func (r *Repository) GetMovementDocument(ctx context.Context, tx *sqlx.Tx, id int) (string, error) {
+	var document string
+	query := `
+		SELECT document FROM (
+			SELECT 'purchase order receipt' AS document FROM purchase_receipts WHERE movement_id = $1
+			UNION ALL SELECT 'sales order' FROM sales_order_lines WHERE movement_id = $1
+			UNION ALL SELECT 'pick task' FROM pick_tasks WHERE movement_id = $1
+			UNION ALL SELECT 'cycle count' FROM count_lines WHERE movement_id = $1
+			UNION ALL SELECT 'rma inspection' FROM rma_lines WHERE movement_id = $1
+			UNION ALL SELECT 'rma' FROM rmas WHERE movement_id = $1 AND status <> 'CANCELLED'
+		) documents
+		LIMIT 1
+	`
+
+	if err := tx.GetContext(ctx, &document, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", fmt.Errorf("error getting movement document: %w", err)
+	}
+	return document, nil
+}
+
 func (r *Repository) ListByProductID(ctx context.Context, productID, page, pageSize int) ([]Movement, int, error) {
 	var movements []Movement
 	offset := (page - 1) * pageSize
 	query := `
-		SELECT id, product_id, movement_type, quantity, reason, created_at, created_by
+		SELECT id, product_id, warehouse_id, to_warehouse_id, movement_type, quantity, uom, uom_quantity, reason, reason_code, from_location_id, to_location_id, reservation_id, from_status, to_status, unit_cost, total_cost, created_at, created_by,
+			reversal_of_id, (SELECT rv.id FROM movements rv WHERE rv.reversal_of_id = movements.id) AS reversed_by_id, batch_id
 		FROM movements
 		WHERE product_id = $1
 		ORDER BY created_at DESC
@@ -78,12 +158,13 @@ func (r *Repository) ListByProductID(ctx context.Context, productID, page, pageS
 }
 
 // obtiene los elementos con paginacion y filtros opcionales
-func (r *Repository) List(ctx context.Context, productID *int, movementType *MovementType, page, pageSize int) ([]Movement, int, error) {
+func (r *Repository) List(ctx context.Context, productID, warehouseID *int, movementType *MovementType, batchID *int, page, pageSize int) ([]Movement, int, error) {
 	var movements []Movement
 	offset := (page - 1) * pageSize
 
 	query := `
-		SELECT id, product_id, movement_type, quantity, reason, created_at, created_by
+		SELECT id, product_id, warehouse_id, to_warehouse_id, movement_type, quantity, uom, uom_quantity, reason, reason_code, from_location_id, to_location_id, reservation_id, from_status, to_status, unit_cost, total_cost, created_at, created_by,
+			reversal_of_id, (SELECT rv.id FROM movements rv WHERE rv.reversal_of_id = movements.id) AS reversed_by_id, batch_id
 		FROM movements
 		WHERE 1=1
 	`
@@ -101,6 +182,14 @@ func (r *Repository) List(ctx context.Context, productID *int, movementType *Mov
 		argPosition++
 	}
 
+	// agregar filtro de warehouse_id si existe (incluye transferencias que llegan al almacen)
+	if warehouseID != nil {
+		query += fmt.Sprintf(" AND (warehouse_id = $%d OR to_warehouse_id = $%d)", argPosition, argPosition)
+		countQuery += fmt.Sprintf(" AND (warehouse_id = $%d OR to_warehouse_id = $%d)", argPosition, argPosition)
+		args = append(args, *warehouseID)
+		argPosition++
+	}
+
 	// agregar filtro de movement_type si existe
 	if movementType != nil {
 		query += fmt.Sprintf(" AND movement_type = $%d", argPosition)
@@ -109,6 +198,14 @@ func (r *Repository) List(ctx context.Context, productID *int, movementType *Mov
 		argPosition++
 	}
 
+	// agregar filtro de batch_id si existe
+	if batchID != nil {
+		query += fmt.Sprintf(" AND batch_id = $%d", argPosition)
+		countQuery += fmt.Sprintf(" AND batch_id = $%d", argPosition)
+		args = append(args, *batchID)
+		argPosition++
+	}
+
 	// agegar order by limit y offset
 	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", argPosition, argPosition+1)
 	args = append(args, pageSize, offset)
@@ -129,39 +226,76 @@ func (r *Repository) List(ctx context.Context, productID *int, movementType *Mov
 	return movements, total, nil
 }
 
-// obtiene el stock actuan de un producto con LOCK PESIMISTA
+// obtiene el stock de un producto en un almacen con LOCK PESIMISTA
 // CONCEPTO CRITICO: SELECT ... FOR UPDATE
-// ESTO bloquea la fila hasta que la transaccion termine (COMMIT o ROLLBACK)
-// Previene race conditions cuando dos requests intentan modificar el mismo producto
-func (r *Repository) GetProductStockForUpdate(ctx context.Context, tx *sqlx.Tx, productID int) (int, string, error) {
+// ESTO bloquea la fila de stock_levels hasta que la transaccion termine (COMMIT o ROLLBACK)
+// Previene race conditions cuando dos requests intentan modificar el mismo producto en el mismo almacen
+// (movimientos en almacenes distintos ya no se bloquean entre si)
+func (r *Repository) GetStockLevelForUpdate(ctx context.Context, tx *sqlx.Tx, productID, warehouseID int) (int, error) {
+	// la fila puede no existir todavia (primer movimiento del producto en el almacen)
+	insertQuery := `
+		INSERT INTO stock_levels (product_id, warehouse_id, quantity)
+		VALUES ($1, $2, 0)
+		ON CONFLICT (product_id, warehouse_id) DO NOTHING
+	`
+	if _, err := tx.ExecContext(ctx, insertQuery, productID, warehouseID); err != nil {
+		return 0, fmt.Errorf("error initializing stock level: %w", err)
+	}
+
 	var stock int
-	var sku string
 	query := `
-		SELECT stock_quantity, sku
-		FROM products 
-		WHERE id = $1
+		SELECT quantity
+		FROM stock_levels
+		WHERE product_id = $1 AND warehouse_id = $2
 		FOR UPDATE
 	`
 
 	// DEBE ejecutarse dentro de una transaccion
-	err := tx.QueryRowxContext(ctx, query, productID).Scan(&stock, &sku)
+	err := tx.QueryRowxContext(ctx, query, productID, warehouseID).Scan(&stock)
+	if err != nil {
+		return 0, fmt.Errorf("error getting stock level with lock: %w", err)
+	}
+
+	return stock, nil
+}
+
+// Actualiza el stock de un producto en un almacen
+// DEBE ejecutarse dentro de una transaccion despues de GetStockLevelForUpdate()
+func (r *Repository) UpdateStockLevel(ctx context.Context, tx *sqlx.Tx, productID, warehouseID, newStock int) error {
+	query := `
+		UPDATE stock_levels
+		SET quantity = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE product_id = $2 AND warehouse_id = $3
+	`
+
+	result, err := tx.ExecContext(ctx, query, newStock, productID, warehouseID)
 	if err != nil {
-		return 0, "", fmt.Errorf("error getting product stock with lock: %w", err)
+		return fmt.Errorf("error updating stock level: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("stock level not found")
 	}
 
-	return stock, sku, nil
+	return nil
 }
 
-// Actualiza el stock de un producto
-// DEBE ejecutarse dentro de una transaccion junto con create()
-func (r *Repository) UpdateProductStock(ctx context.Context, tx *sqlx.Tx, productID, newStock int) error {
+// Suma delta al stock total del producto (products.stock_quantity)
+// se usa un incremento relativo porque la fila de products no se bloquea antes:
+// el lock que serializa los movimientos es el de stock_levels
+func (r *Repository) AddProductStock(ctx context.Context, tx *sqlx.Tx, productID, delta int) error {
 	query := `
-		UPDATE products 
-		SET stock_quantity = $1, updated_at = CURRENT_TIMESTAMP
+		UPDATE products
+		SET stock_quantity = stock_quantity + $1, updated_at = CURRENT_TIMESTAMP
 		WHERE id = $2
 	`
 
-	result, err := tx.ExecContext(ctx, query, newStock, productID)
+	result, err := tx.ExecContext(ctx, query, delta, productID)
 	if err != nil {
 		return fmt.Errorf("error updating product stock: %w", err)
 	}
@@ -178,6 +312,551 @@ func (r *Repository) UpdateProductStock(ctx context.Context, tx *sqlx.Tx, produc
 	return nil
 }
 
+// obtiene los datos de una ubicacion para validar el movimiento
+func (r *Repository) GetLocation(ctx context.Context, tx *sqlx.Tx, locationID int) (*LocationInfo, error) {
+	var location LocationInfo
+	query := `
+		SELECT id, warehouse_id, location_type, path, is_active
+		FROM locations
+		WHERE id = $1
+	`
+
+	err := tx.GetContext(ctx, &location, query, locationID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("location with ID [%d] not found", locationID)
+		}
+		return nil, fmt.Errorf("error getting location: %w", err)
+	}
+
+	return &location, nil
+}
+
+// obtiene el stock de un producto en una ubicacion con LOCK PESIMISTA
+// se llama despues de GetStockLevelForUpdate() para respetar el orden de locks
+func (r *Repository) GetLocationStockForUpdate(ctx context.Context, tx *sqlx.Tx, productID, locationID int) (int, error) {
+	insertQuery := `
+		INSERT INTO location_stock (product_id, location_id, quantity)
+		VALUES ($1, $2, 0)
+		ON CONFLICT (product_id, location_id) DO NOTHING
+	`
+	if _, err := tx.ExecContext(ctx, insertQuery, productID, locationID); err != nil {
+		return 0, fmt.Errorf("error initializing location stock: %w", err)
+	}
+
+	var stock int
+	query := `
+		SELECT quantity
+		FROM location_stock
+		WHERE product_id = $1 AND location_id = $2
+		FOR UPDATE
+	`
+
+	err := tx.QueryRowxContext(ctx, query, productID, locationID).Scan(&stock)
+	if err != nil {
+		return 0, fmt.Errorf("error getting location stock with lock: %w", err)
+	}
+
+	return stock, nil
+}
+
+func (r *Repository) UpdateLocationStock(ctx context.Context, tx *sqlx.Tx, productID, locationID, newStock int) error {
+	query := `
+		UPDATE location_stock
+		SET quantity = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE product_id = $2 AND location_id = $3
+	`
+
+	if _, err := tx.ExecContext(ctx, query, newStock, productID, locationID); err != nil {
+		return fmt.Errorf("error updating location stock: %w", err)
+	}
+	return nil
+}
+
+// suma el stock del producto que ya esta asignado a ubicaciones del almacen
+// (el resto del stock_level es stock sin ubicar)
+func (r *Repository) GetLocatedStock(ctx context.Context, tx *sqlx.Tx, productID, warehouseID int) (int, error) {
+	var located int
+	query := `
+		SELECT COALESCE(SUM(ls.quantity), 0)
+		FROM location_stock ls
+		JOIN locations l ON l.id = ls.location_id
+		WHERE ls.product_id = $1 AND l.warehouse_id = $2
+	`
+
+	if err := tx.GetContext(ctx, &located, query, productID, warehouseID); err != nil {
+		return 0, fmt.Errorf("error getting located stock: %w", err)
+	}
+	return located, nil
+}
+
+// suma lo reservado y no consumido del producto en el almacen (reservas activas y vigentes)
+// DEBE ejecutarse despues de GetStockLevelForUpdate, el lock de stock_levels serializa reservas y salidas
+func (r *Repository) GetReservedStock(ctx context.Context, tx *sqlx.Tx, productID, warehouseID int) (int, error) {
+	var reserved int
+	query := `
+		SELECT COALESCE(SUM(quantity - consumed_quantity), 0)
+		FROM reservations
+		WHERE product_id = $1 AND warehouse_id = $2 AND status = 'ACTIVE'
+			AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+	`
+
+	if err := tx.GetContext(ctx, &reserved, query, productID, warehouseID); err != nil {
+		return 0, fmt.Errorf("error getting reserved stock: %w", err)
+	}
+	return reserved, nil
+}
+
+// suma el stock retenido del producto en el almacen (todo lo que no esta AVAILABLE)
+// DEBE ejecutarse despues de GetStockLevelForUpdate, el lock de stock_levels serializa los cambios de estado
+func (r *Repository) GetHeldStock(ctx context.Context, tx *sqlx.Tx, productID, warehouseID int) (int, error) {
+	var held int
+	query := `
+		SELECT COALESCE(SUM(quantity), 0)
+		FROM stock_status_levels
+		WHERE product_id = $1 AND warehouse_id = $2
+	`
+
+	if err := tx.GetContext(ctx, &held, query, productID, warehouseID); err != nil {
+		return 0, fmt.Errorf("error getting held stock: %w", err)
+	}
+	return held, nil
+}
+
+// obtiene el stock de un producto en un estado retenido con LOCK PESIMISTA
+// se llama despues de GetStockLevelForUpdate() para respetar el orden de locks
+func (r *Repository) GetStatusStockForUpdate(ctx context.Context, tx *sqlx.Tx, productID, warehouseID int, status StockStatus) (int, error) {
+	insertQuery := `
+		INSERT INTO stock_status_levels (product_id, warehouse_id, status, quantity)
+		VALUES ($1, $2, $3, 0)
+		ON CONFLICT (product_id, warehouse_id, status) DO NOTHING
+	`
+	if _, err := tx.ExecContext(ctx, insertQuery, productID, warehouseID, status); err != nil {
+		return 0, fmt.Errorf("error initializing status stock: %w", err)
+	}
+
+	var stock int
+	query := `
+		SELECT quantity
+		FROM stock_status_levels
+		WHERE product_id = $1 AND warehouse_id = $2 AND status = $3
+		FOR UPDATE
+	`
+
+	if err := tx.QueryRowxContext(ctx, query, productID, warehouseID, status).Scan(&stock); err != nil {
+		return 0, fmt.Errorf("error getting status stock with lock: %w", err)
+	}
+	return stock, nil
+}
+
+func (r *Repository) UpdateStatusStock(ctx context.Context, tx *sqlx.Tx, productID, warehouseID int, status StockStatus, newStock int) error {
+	query := `
+		UPDATE stock_status_levels
+		SET quantity = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE product_id = $2 AND warehouse_id = $3 AND status = $4
+	`
+
+	if _, err := tx.ExecContext(ctx, query, newStock, productID, warehouseID, status); err != nil {
+		return fmt.Errorf("error updating status stock: %w", err)
+	}
+	return nil
+}
+
+// lista las capas de costo con stock del producto en el almacen, de la mas antigua a la mas reciente
+// bloquea las filas, se llama despues de GetStockLevelForUpdate() para respetar el orden de locks
+func (r *Repository) ListCostLayersForUpdate(ctx context.Context, tx *sqlx.Tx, productID, warehouseID int) ([]CostLayer, error) {
+	query := `
+		SELECT id, movement_id, quantity, remaining_quantity, unit_cost
+		FROM cost_layers
+		WHERE product_id = $1 AND warehouse_id = $2 AND remaining_quantity > 0
+		ORDER BY id
+		FOR UPDATE
+	`
+
+	var layers []CostLayer
+	if err := tx.SelectContext(ctx, &layers, query, productID, warehouseID); err != nil {
+		return nil, fmt.Errorf("error listing cost layers: %w", err)
+	}
+	return layers, nil
+}
+
+// actualiza lo que queda de una capa y su costo (el costo solo cambia con el promedio movil)
+// DEBE ejecutarse despues de ListCostLayersForUpdate()
+func (r *Repository) UpdateCostLayer(ctx context.Context, tx *sqlx.Tx, id, remaining int, unitCost float64) error {
+	query := `UPDATE cost_layers SET remaining_quantity = $1, unit_cost = $2 WHERE id = $3`
+
+	if _, err := tx.ExecContext(ctx, query, remaining, unitCost, id); err != nil {
+		return fmt.Errorf("error updating cost layer: %w", err)
+	}
+	return nil
+}
+
+// crea las capas de costo que genero un movimiento de entrada
+func (r *Repository) CreateCostLayers(ctx context.Context, tx *sqlx.Tx, movementID, productID, warehouseID int, layers []CostLayer) error {
+	query := `
+		INSERT INTO cost_layers (product_id, warehouse_id, movement_id, quantity, remaining_quantity, unit_cost)
+		VALUES ($1, $2, $3, $4, $4, $5)
+	`
+
+	for _, layer := range layers {
+		if _, err := tx.ExecContext(ctx, query, productID, warehouseID, movementID, layer.Quantity, layer.UnitCost); err != nil {
+			return fmt.Errorf("error creating cost layer: %w", err)
+		}
+	}
+	return nil
+}
+
+// obtiene el costo de la capa mas reciente del producto en cualquier almacen (nulo si nunca tuvo costo)
+func (r *Repository) GetLastUnitCost(ctx context.Context, tx *sqlx.Tx, productID int) (*float64, error) {
+	var unitCost float64
+	query := `SELECT unit_cost FROM cost_layers WHERE product_id = $1 ORDER BY id DESC LIMIT 1`
+
+	if err := tx.GetContext(ctx, &unitCost, query, productID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting last unit cost: %w", err)
+	}
+	return &unitCost, nil
+}
+
+// obtiene la reserva que consume una salida con LOCK PESIMISTA
+func (r *Repository) GetReservationForUpdate(ctx context.Context, tx *sqlx.Tx, id int) (*ReservationInfo, error) {
+	var reservation ReservationInfo
+	query := `
+		SELECT id, product_id, warehouse_id, status, quantity - consumed_quantity AS remaining,
+			COALESCE(expires_at <= CURRENT_TIMESTAMP, false) AS expired
+		FROM reservations
+		WHERE id = $1
+		FOR UPDATE
+	`
+
+	if err := tx.GetContext(ctx, &reservation, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("reservation with id [%d] not found", id)
+		}
+		return nil, fmt.Errorf("error getting reservation with lock: %w", err)
+	}
+	return &reservation, nil
+}
+
+// descuenta quantity de la reserva, al consumirse completa pasa a CONSUMED
+func (r *Repository) ConsumeReservation(ctx context.Context, tx *sqlx.Tx, id, quantity int) error {
+	query := `
+		UPDATE reservations
+		SET consumed_quantity = consumed_quantity + $1,
+			status = CASE WHEN consumed_quantity + $1 >= quantity THEN 'CONSUMED'::reservation_status ELSE status END,
+			released_at = CASE WHEN consumed_quantity + $1 >= quantity THEN CURRENT_TIMESTAMP ELSE released_at END
+		WHERE id = $2
+	`
+
+	if _, err := tx.ExecContext(ctx, query, quantity, id); err != nil {
+		return fmt.Errorf("error consuming reservation: %w", err)
+	}
+	return nil
+}
+
+// obtiene los datos del producto que definen como se mueve su stock
+// el sku se usa para invalidar cache
+func (r *Repository) GetProductInfo(ctx context.Context, tx *sqlx.Tx, productID int) (*ProductInfo, error) {
+	var product ProductInfo
+	query := `SELECT sku, is_lot_tracked, is_serialized FROM products WHERE id = $1`
+
+	if err := tx.GetContext(ctx, &product, query, productID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("product with ID [%d] not found", productID)
+		}
+		return nil, fmt.Errorf("error getting product: %w", err)
+	}
+	return &product, nil
+}
+
+// crea el lote si no existe y lo retorna
+// si el lote ya existe solo se completan las fechas que no tenia
+func (r *Repository) UpsertLot(ctx context.Context, tx *sqlx.Tx, productID int, lotNumber string, manufacturedAt, expiresAt *time.Time) (*LotInfo, error) {
+	var lot LotInfo
+	query := `
+		INSERT INTO lots (product_id, lot_number, manufactured_at, expires_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (product_id, lot_number) DO UPDATE
+		SET manufactured_at = COALESCE(lots.manufactured_at, EXCLUDED.manufactured_at),
+			expires_at = COALESCE(lots.expires_at, EXCLUDED.expires_at)
+		RETURNING id, lot_number, manufactured_at, expires_at,
+			COALESCE(expires_at < CURRENT_DATE, FALSE) AS expired
+	`
+
+	if err := tx.GetContext(ctx, &lot, query, productID, lotNumber, manufacturedAt, expiresAt); err != nil {
+		return nil, fmt.Errorf("error saving lot: %w", err)
+	}
+	return &lot, nil
+}
+
+// obtiene un lote del producto por su numero
+func (r *Repository) GetLotByNumber(ctx context.Context, tx *sqlx.Tx, productID int, lotNumber string) (*LotInfo, error) {
+	var lot LotInfo
+	query := `
+		SELECT id, lot_number, manufactured_at, expires_at,
+			COALESCE(expires_at < CURRENT_DATE, FALSE) AS expired
+		FROM lots
+		WHERE product_id = $1 AND lot_number = $2
+	`
+
+	if err := tx.GetContext(ctx, &lot, query, productID, lotNumber); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("lot [%s] not found for product %d", lotNumber, productID)
+		}
+		return nil, fmt.Errorf("error getting lot: %w", err)
+	}
+	return &lot, nil
+}
+
+// obtiene el stock de un lote en un almacen con LOCK PESIMISTA
+// se llama despues de GetStockLevelForUpdate() para respetar el orden de locks
+func (r *Repository) GetLotStockForUpdate(ctx context.Context, tx *sqlx.Tx, lotID, warehouseID int) (int, error) {
+	insertQuery := `
+		INSERT INTO lot_stock (lot_id, warehouse_id, quantity)
+		VALUES ($1, $2, 0)
+		ON CONFLICT (lot_id, warehouse_id) DO NOTHING
+	`
+	if _, err := tx.ExecContext(ctx, insertQuery, lotID, warehouseID); err != nil {
+		return 0, fmt.Errorf("error initializing lot stock: %w", err)
+	}
+
+	var stock int
+	query := `
+		SELECT quantity
+		FROM lot_stock
+		WHERE lot_id = $1 AND warehouse_id = $2
+		FOR UPDATE
+	`
+
+	if err := tx.QueryRowxContext(ctx, query, lotID, warehouseID).Scan(&stock); err != nil {
+		return 0, fmt.Errorf("error getting lot stock with lock: %w", err)
+	}
+	return stock, nil
+}
+
+// lista los lotes con stock del producto en el almacen en orden FEFO (primero el que caduca antes)
+// los lotes sin caducidad van al final, bloquea las filas de lot_stock
+func (r *Repository) ListLotStockForUpdate(ctx context.Context, tx *sqlx.Tx, productID, warehouseID int) ([]LotStockInfo, error) {
+	query := `
+		SELECT l.id, l.lot_number, l.manufactured_at, l.expires_at,
+			COALESCE(l.expires_at < CURRENT_DATE, FALSE) AS expired, ls.quantity
+		FROM lot_stock ls
+		JOIN lots l ON l.id = ls.lot_id
+		WHERE l.product_id = $1 AND ls.warehouse_id = $2 AND ls.quantity > 0
+		ORDER BY l.expires_at NULLS LAST, l.id
+		FOR UPDATE OF ls
+	`
+
+	var lots []LotStockInfo
+	if err := tx.SelectContext(ctx, &lots, query, productID, warehouseID); err != nil {
+		return nil, fmt.Errorf("error listing lot stock: %w", err)
+	}
+	return lots, nil
+}
+
+// Actualiza el stock de un lote en un almacen
+// DEBE ejecutarse dentro de una transaccion despues de GetLotStockForUpdate() o ListLotStockForUpdate()
+func (r *Repository) UpdateLotStock(ctx context.Context, tx *sqlx.Tx, lotID, warehouseID, newStock int) error {
+	query := `
+		UPDATE lot_stock
+		SET quantity = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE lot_id = $2 AND warehouse_id = $3
+	`
+
+	if _, err := tx.ExecContext(ctx, query, newStock, lotID, warehouseID); err != nil {
+		return fmt.Errorf("error updating lot stock: %w", err)
+	}
+	return nil
+}
+
+// guarda el desglose por lote del movimiento
+func (r *Repository) CreateMovementLots(ctx context.Context, tx *sqlx.Tx, movementID int, lots []MovementLot) error {
+	query := `
+		INSERT INTO movement_lots (movement_id, lot_id, quantity)
+		VALUES ($1, $2, $3)
+	`
+
+	for _, lot := range lots {
+		if _, err := tx.ExecContext(ctx, query, movementID, lot.LotID, lot.Quantity); err != nil {
+			return fmt.Errorf("error creating movement lot: %w", err)
+		}
+	}
+	return nil
+}
+
+// obtiene el desglose por lote de varios movimientos, agrupado por movimiento
+func (r *Repository) GetMovementLots(ctx context.Context, movementIDs []int) (map[int][]MovementLot, error) {
+	query := `
+		SELECT ml.movement_id, ml.lot_id, l.lot_number, l.expires_at, ml.quantity
+		FROM movement_lots ml
+		JOIN lots l ON l.id = ml.lot_id
+		WHERE ml.movement_id = ANY($1)
+		ORDER BY ml.movement_id, l.expires_at NULLS LAST, l.id
+	`
+
+	var rows []MovementLot
+	if err := r.db.SelectContext(ctx, &rows, query, pq.Array(movementIDs)); err != nil {
+		return nil, fmt.Errorf("error getting movement lots: %w", err)
+	}
+
+	lots := make(map[int][]MovementLot)
+	for _, row := range rows {
+		lots[row.MovementID] = append(lots[row.MovementID], row)
+	}
+	return lots, nil
+}
+
+// bloquea las series indicadas (LOCK PESIMISTA, en orden de id para evitar deadlocks)
+// las series que no existen simplemente no vienen en el resultado
+func (r *Repository) LockSerials(ctx context.Context, tx *sqlx.Tx, serialNumbers []string) ([]SerialInfo, error) {
+	query := `
+		SELECT id, product_id, serial_number, status, warehouse_id
+		FROM serials
+		WHERE serial_number = ANY($1)
+		ORDER BY id
+		FOR UPDATE
+	`
+
+	var serials []SerialInfo
+	if err := tx.SelectContext(ctx, &serials, query, pq.Array(serialNumbers)); err != nil {
+		return nil, fmt.Errorf("error getting serials with lock: %w", err)
+	}
+	return serials, nil
+}
+
+// registra una serie nueva en stock en el almacen
+func (r *Repository) CreateSerial(ctx context.Context, tx *sqlx.Tx, productID int, serialNumber string, warehouseID int) (int, error) {
+	var id int
+	query := `
+		INSERT INTO serials (product_id, serial_number, status, warehouse_id)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+
+	if err := tx.GetContext(ctx, &id, query, productID, serialNumber, serialInStock, warehouseID); err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			return 0, fmt.Errorf("invalid serials: [%s] is already registered", serialNumber)
+		}
+		return 0, fmt.Errorf("error creating serial: %w", err)
+	}
+	return id, nil
+}
+
+// cambia el estado y el almacen de una serie (warehouseID nulo si la unidad sale)
+func (r *Repository) UpdateSerial(ctx context.Context, tx *sqlx.Tx, serialID int, status string, warehouseID *int) error {
+	query := `UPDATE serials SET status = $1, warehouse_id = $2 WHERE id = $3`
+
+	if _, err := tx.ExecContext(ctx, query, status, warehouseID, serialID); err != nil {
+		return fmt.Errorf("error updating serial: %w", err)
+	}
+	return nil
+}
+
+// guarda las series del movimiento
+func (r *Repository) CreateMovementSerials(ctx context.Context, tx *sqlx.Tx, movementID int, serialIDs []int) error {
+	query := `
+		INSERT INTO movement_serials (movement_id, serial_id)
+		VALUES ($1, $2)
+	`
+
+	for _, serialID := range serialIDs {
+		if _, err := tx.ExecContext(ctx, query, movementID, serialID); err != nil {
+			return fmt.Errorf("error creating movement serial: %w", err)
+		}
+	}
+	return nil
+}
+
+// obtiene las series de varios movimientos, agrupadas por movimiento
+func (r *Repository) GetMovementSerials(ctx context.Context, movementIDs []int) (map[int][]string, error) {
+	query := `
+		SELECT ms.movement_id, s.serial_number
+		FROM movement_serials ms
+		JOIN serials s ON s.id = ms.serial_id
+		WHERE ms.movement_id = ANY($1)
+		ORDER BY ms.movement_id, s.serial_number
+	`
+
+	var rows []struct {
+		MovementID   int    `db:"movement_id"`
+		SerialNumber string `db:"serial_number"`
+	}
+	if err := r.db.SelectContext(ctx, &rows, query, pq.Array(movementIDs)); err != nil {
+		return nil, fmt.Errorf("error getting movement serials: %w", err)
+	}
+
+	serials := make(map[int][]string)
+	for _, row := range rows {
+		serials[row.MovementID] = append(serials[row.MovementID], row.SerialNumber)
+	}
+	return serials, nil
+}
+
+// obtiene la unidad de medida y su factor de conversion para el producto
+func (r *Repository) GetUoM(ctx context.Context, productID int, code string) (*UoMInfo, error) {
+	var uom UoMInfo
+	query := `
+		SELECT u.code, u.is_active, pu.factor
+		FROM units_of_measure u
+		LEFT JOIN product_uoms pu ON pu.uom_code = u.code AND pu.product_id = $1
+		WHERE u.code = $2
+	`
+
+	if err := r.db.GetContext(ctx, &uom, query, productID, code); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("uom [%s] not found", code)
+		}
+		return nil, fmt.Errorf("error getting unit of measure: %w", err)
+	}
+	return &uom, nil
+}
+
+// obtiene el almacen por defecto (se usa cuando el request no indica warehouse_id)
+func (r *Repository) GetDefaultWarehouseID(ctx context.Context) (int, error) {
+	var id int
+	query := `SELECT id FROM warehouses WHERE is_default AND deleted_at IS NULL`
+
+	err := r.db.GetContext(ctx, &id, query)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("default warehouse not found")
+		}
+		return 0, fmt.Errorf("error getting default warehouse: %w", err)
+	}
+
+	return id, nil
+}
+
+// obtiene un codigo de razon del catalogo para validar el movimiento
+func (r *Repository) GetReasonCode(ctx context.Context, code string) (*ReasonCodeInfo, error) {
+	var reasonCode ReasonCodeInfo
+	query := `SELECT code, direction, is_active FROM reason_codes WHERE code = $1`
+
+	err := r.db.GetContext(ctx, &reasonCode, query, code)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("reason_code [%s] not found", code)
+		}
+		return nil, fmt.Errorf("error getting reason code: %w", err)
+	}
+
+	return &reasonCode, nil
+}
+
+func (r *Repository) WarehouseExists(ctx context.Context, warehouseID int) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM warehouses WHERE id = $1 AND deleted_at IS NULL)`
+
+	err := r.db.GetContext(ctx, &exists, query, warehouseID)
+	if err != nil {
+		return false, fmt.Errorf("error checking warehouse existence: %w", err)
+	}
+
+	return exists, nil
+}
+
 func (r *Repository) ProductExists(ctx context.Context, productID int) (bool, error) {
 	var exists bool
 	query := `SELECT EXISTS(SELECT 1 FROM products WHERE id = $1)`
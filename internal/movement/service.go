@@ -4,49 +4,1066 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/rs/zerolog"
+	"github.com/whoAngeel/wms-lite/internal/alert"
 	"github.com/whoAngeel/wms-lite/internal/platform"
 )
 
 type Service struct {
-	repo   *Repository
-	db     *sqlx.DB
-	cache  *platform.Cache
-	logger zerolog.Logger
+	repo    *Repository
+	db      *sqlx.DB
+	cache   *platform.Cache
+	alerts  *alert.Service
+	costing CostingMethod
+	logger  zerolog.Logger
 }
 
-func NewService(repo *Repository, db *sqlx.DB, cache *platform.Cache, logger *zerolog.Logger) *Service {
+func NewService(repo *Repository, db *sqlx.DB, cache *platform.Cache, alerts *alert.Service, costing CostingMethod, logger *zerolog.Logger) *Service {
 	moduleLogger := logger.With().Str("module", "movement").Logger()
 	return &Service{
-		repo:   repo,
-		cache:  cache,
-		db:     db,
-		logger: moduleLogger,
+		repo:    repo,
+		cache:   cache,
+		alerts:  alerts,
+		costing: costing,
+		db:      db,
+		logger:  moduleLogger,
 	}
 }
 
 // Crea un nuevo movimiento de inventario
+// TRANSFER debita el origen y acredita el destino en la misma transaccion (un solo registro)
+// ADJUST suma o resta segun el signo de la cantidad y exige un codigo de razon
+// STATUS_CHANGE mueve stock entre estados del almacen (AVAILABLE, QUARANTINE...) sin cambiar el stock fisico
+// las entradas crean capas de costo y las salidas las consumen (FIFO o promedio movil) registrando su costo
 // flujo
 // 1. BEGIN transaction
-// 2. SELECT stock for UPDATE (bloquea la fila)
+// 2. SELECT stock del almacen for UPDATE (bloquea la fila de stock_levels)
 // 3. Validar stock suficiente (si es OUT)
 // 4. Calcular nuevo stock
-// 5. UPDATE stock en products
+// 5. UPDATE stock de la ubicacion (si aplica), stock_levels y total en products
 // 6. INSERT movement
 // 7. COMMIT (o ROLLBACK si hay error)
 func (s *Service) CreateMovement(ctx context.Context, req CreateMovementRequest) (resp *MovementResponse, err error) {
-	if err = s.validateCreateRequest(req); err != nil {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	// patron critico: DEFER para rollback automatico
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	movement, err := s.CreateMovementTx(ctx, tx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// commit todo salio bien commit
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	s.AfterCommit(ctx, movement)
+
+	// retornar el movimiento creado
+	response := movement.ToResponse()
+	return &response, nil
+}
+
+// CreateMovementTx valida y aplica un movimiento dentro de la transaccion del llamador
+// lo usan los modulos que registran varios movimientos de forma atomica (ej. conteos ciclicos)
+// el llamador hace COMMIT y despues llama AfterCommit con los movimientos creados
+func (s *Service) CreateMovementTx(ctx context.Context, tx *sqlx.Tx, req CreateMovementRequest) (*Movement, error) {
+	entered, err := s.prepareMovement(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.applyMovement(ctx, tx, req, entered)
+}
+
+// valida el movimiento sin tomar locks: deja req.Quantity en la unidad base
+// y resuelve el almacen (y el destino de una transferencia)
+func (s *Service) prepareMovement(ctx context.Context, req *CreateMovementRequest) (uomQuantity, error) {
+	if err := s.validateCreateRequest(*req); err != nil {
+		return uomQuantity{}, err
+	}
+
+	if err := s.validateReasonCode(ctx, *req); err != nil {
+		return uomQuantity{}, err
+	}
+
+	// los movimientos de sistema validan el producto dentro de la transaccion (applyMovement):
+	// el saldo inicial se registra antes de que se confirme el producto
+	if !req.system {
+		exists, err := s.repo.ProductExists(ctx, req.ProductID)
+		if err != nil {
+			return uomQuantity{}, fmt.Errorf("error checking product existence: %w", err)
+		}
+		if !exists {
+			return uomQuantity{}, fmt.Errorf("product with ID [%d] not found", req.ProductID)
+		}
+	}
+
+	// desde aqui req.Quantity queda en la unidad base
+	entered, err := s.convertToBaseUoM(ctx, req)
+	if err != nil {
+		return entered, err
+	}
+
+	warehouseID, err := s.resolveWarehouse(ctx, req.WarehouseID)
+	if err != nil {
+		return entered, err
+	}
+	req.WarehouseID = warehouseID
+
+	if req.MovementType == MovementTypeTransfer {
+		if err := s.validateTransfer(ctx, req); err != nil {
+			return entered, err
+		}
+	}
+	return entered, nil
+}
+
+// CreateBatch registra varias lineas de movimiento en una sola transaccion ligadas a un mismo lote (batch_id)
+// si una linea falla no se registra ninguna; el error indica la linea (desde 1)
+// flujo
+// 1. validar todas las lineas (sin locks)
+// 2. BEGIN transaction
+// 3. bloquear las filas de stock_levels de todas las lineas en orden ascendente de producto y almacen
+// 4. aplicar las lineas ordenadas por producto (estable: las del mismo producto conservan su orden)
+// 5. COMMIT y AfterCommit con todos los movimientos
+// el orden de locks evita deadlocks entre lotes, ordenes de venta y movimientos sueltos sobre los mismos productos
+func (s *Service) CreateBatch(ctx context.Context, req CreateBatchRequest) (resp *BatchResponse, err error) {
+	if len(req.Lines) == 0 {
+		return nil, fmt.Errorf("invalid lines: at least one line is required")
+	}
+
+	lines := make([]CreateMovementRequest, len(req.Lines))
+	entered := make([]uomQuantity, len(req.Lines))
+	for i, line := range req.Lines {
+		if req.CreatedBy != "" {
+			line.CreatedBy = req.CreatedBy
+		}
+		entered[i], err = s.prepareMovement(ctx, &line)
+		if err != nil {
+			return nil, &BatchLineError{Line: i + 1, Err: err}
+		}
+		lines[i] = line
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := s.lockStockLevels(ctx, tx, lines); err != nil {
+		return nil, err
+	}
+
+	batchID, createdAt, err := s.repo.CreateBatch(ctx, tx, req.Reference, req.CreatedBy)
+	if err != nil {
 		return nil, err
 	}
 
-	exists, err := s.repo.ProductExists(ctx, req.ProductID)
+	order := make([]int, len(lines))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return lines[order[a]].ProductID < lines[order[b]].ProductID
+	})
+
+	movements := make([]*Movement, len(lines))
+	for _, i := range order {
+		lines[i].batchID = &batchID
+		movements[i], err = s.applyMovement(ctx, tx, lines[i], entered[i])
+		if err != nil {
+			return nil, &BatchLineError{Line: i + 1, Err: err}
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	s.AfterCommit(ctx, movements...)
+
+	response := &BatchResponse{
+		BatchID:   batchID,
+		Reference: req.Reference,
+		CreatedBy: req.CreatedBy,
+		CreatedAt: createdAt,
+		Lines:     make([]BatchLineResult, len(movements)),
+	}
+	for i, movement := range movements {
+		response.Lines[i] = BatchLineResult{Line: i + 1, BatchID: batchID, Movement: movement.ToResponse()}
+	}
+	return response, nil
+}
+
+// bloquea el stock de todos los almacenes que tocan las lineas (origen y destino de las transferencias)
+// en orden ascendente de producto y almacen, antes de aplicar cualquier linea
+func (s *Service) lockStockLevels(ctx context.Context, tx *sqlx.Tx, lines []CreateMovementRequest) error {
+	type stockKey struct{ productID, warehouseID int }
+
+	seen := make(map[stockKey]bool)
+	var keys []stockKey
+	for _, line := range lines {
+		candidates := []stockKey{{line.ProductID, line.WarehouseID}}
+		if line.MovementType == MovementTypeTransfer {
+			candidates = append(candidates, stockKey{line.ProductID, line.ToWarehouseID})
+		}
+		for _, key := range candidates {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].productID != keys[j].productID {
+			return keys[i].productID < keys[j].productID
+		}
+		return keys[i].warehouseID < keys[j].warehouseID
+	})
+
+	for _, key := range keys {
+		if _, err := s.repo.GetStockLevelForUpdate(ctx, tx, key.productID, key.warehouseID); err != nil {
+			return fmt.Errorf("error getting product stock: %w", err)
+		}
+	}
+	return nil
+}
+
+// AfterCommit invalida la cache de los productos afectados por movimientos ya confirmados
+// y abre o resuelve su alerta de stock bajo con el stock ya confirmado
+// un fallo de cache o de alertas no revierte nada, solo se registra
+func (s *Service) AfterCommit(ctx context.Context, movements ...*Movement) {
+	checked := make(map[int]bool, len(movements))
+	for _, movement := range movements {
+		cacheKeys := []string{
+			fmt.Sprintf("product:%d", movement.ProductID),
+			fmt.Sprintf("product:sku:%s", movement.sku),
+		}
+
+		if err := s.cache.Del(ctx, cacheKeys...); err != nil {
+			s.logger.Warn().Err(err).Int("product_id", movement.ProductID).Msg("Failed to invalidate product cache")
+		} else {
+			s.logger.Debug().Int("product_id", movement.ProductID).Msg("Product cache invalidated successfully")
+		}
+
+		if !checked[movement.ProductID] {
+			checked[movement.ProductID] = true
+			s.alerts.CheckProduct(ctx, movement.ProductID)
+		}
+	}
+}
+
+// aplica el movimiento dentro de la transaccion (req.WarehouseID ya resuelto y req.Quantity en la unidad base)
+// el movimiento insertado guarda el sku del producto para invalidar cache
+func (s *Service) applyMovement(ctx context.Context, tx *sqlx.Tx, req CreateMovementRequest, entered uomQuantity) (*Movement, error) {
+	product, err := s.repo.GetProductInfo(ctx, tx, req.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	// un ajuste de conciliacion no mueve lotes ni series
+	if !req.ledgerOnly {
+		if err := validateLotRequest(req, product); err != nil {
+			return nil, err
+		}
+
+		if err := validateSerialRequest(req, product); err != nil {
+			return nil, err
+		}
+	}
+
+	movement := &Movement{
+		ProductID:      req.ProductID,
+		WarehouseID:    req.WarehouseID,
+		MovementType:   req.MovementType,
+		Quantity:       req.Quantity,
+		UoM:            entered.uom,
+		UoMQuantity:    entered.quantity,
+		Reason:         req.Reason,
+		FromLocationID: req.FromLocationID,
+		ToLocationID:   req.ToLocationID,
+		ReservationID:  req.ReservationID,
+		ReversalOfID:   req.reversalOf,
+		BatchID:        req.batchID,
+		CreatedBy:      req.CreatedBy,
+		sku:            product.SKU,
+	}
+	if req.ReasonCode != "" {
+		movement.ReasonCode = &req.ReasonCode
+	}
+	if req.FromStatus != "" {
+		movement.FromStatus = &req.FromStatus
+	}
+	if req.ToStatus != "" {
+		movement.ToStatus = &req.ToStatus
+	}
+
+	var detail movementDetail
+	switch {
+	case req.ledgerOnly:
+		// stock_levels ya tiene estas unidades, lo que faltaba era su movimiento
+	case req.MovementType == MovementTypeIn:
+		detail, err = s.applyStockChange(ctx, tx, req, product, req.Quantity)
+	case req.MovementType == MovementTypeOut:
+		detail, err = s.applyStockChange(ctx, tx, req, product, -req.Quantity)
+	case req.MovementType == MovementTypeTransfer:
+		detail, err = s.applyTransfer(ctx, tx, req, product)
+		movement.ToWarehouseID = &req.ToWarehouseID
+	case req.MovementType == MovementTypeAdjust:
+		detail, err = s.applyStockChange(ctx, tx, req, product, req.Quantity)
+	case req.MovementType == MovementTypeStatusChange:
+		detail, err = s.applyStatusChange(ctx, tx, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if detail.cost != nil {
+		total := roundCost(detail.cost.total)
+		unitCost := roundCost(total / float64(max(req.Quantity, -req.Quantity)))
+		movement.TotalCost, movement.UnitCost = &total, &unitCost
+	}
+
+	err = s.repo.Create(ctx, tx, movement)
+	if err != nil {
+		return nil, fmt.Errorf("error creating movement: %w", err)
+	}
+
+	if detail.cost != nil {
+		err := s.repo.CreateCostLayers(ctx, tx, movement.ID, movement.ProductID, detail.cost.warehouseID, detail.cost.layers)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.CreateMovementLots(ctx, tx, movement.ID, detail.lots); err != nil {
+		return nil, err
+	}
+	movement.Lots = detail.lots
+
+	serialIDs := make([]int, len(detail.serials))
+	for i, serial := range detail.serials {
+		serialIDs[i] = serial.ID
+		movement.Serials = append(movement.Serials, serial.SerialNumber)
+	}
+	if err := s.repo.CreateMovementSerials(ctx, tx, movement.ID, serialIDs); err != nil {
+		return nil, err
+	}
+
+	return movement, nil
+}
+
+// movementDetail es el desglose por lote y por serie que se guarda junto al movimiento
+// cost es nulo si el movimiento no cambia el valor del inventario de ningun almacen
+type movementDetail struct {
+	lots    []MovementLot
+	serials []SerialInfo
+	cost    *movementCost
+}
+
+// movementCost es el valor que entro o salio y las capas que el movimiento crea en warehouseID
+type movementCost struct {
+	total       float64
+	warehouseID int
+	layers      []CostLayer
+}
+
+// suma delta (positivo entra, negativo sale) al stock del producto en el almacen
+// bloquea stock_levels, ajusta el bin, los lotes y las series si aplica y actualiza el total en products
+func (s *Service) applyStockChange(ctx context.Context, tx *sqlx.Tx, req CreateMovementRequest, product *ProductInfo, delta int) (movementDetail, error) {
+	var detail movementDetail
+
+	// obtener stock del almacen con lock pesimista
+	currentStock, err := s.repo.GetStockLevelForUpdate(ctx, tx, req.ProductID, req.WarehouseID)
+	if err != nil {
+		return detail, fmt.Errorf("error getting product stock: %w", err)
+	}
+
+	// lo que entra queda en su estado, lo que sale se descuenta del estado indicado
+	if delta > 0 {
+		err = s.changeStatusStock(ctx, tx, req.ProductID, req.WarehouseID, req.ToStatus.orDefault(), delta)
+	} else {
+		err = s.takeStatusStock(ctx, tx, req, currentStock, -delta)
+	}
+	if err != nil {
+		return detail, err
+	}
+
+	if delta > 0 {
+		err = s.putStock(ctx, tx, req.ProductID, req.WarehouseID, req.ToLocationID, delta)
+	} else {
+		err = s.takeStock(ctx, tx, req.ProductID, req.WarehouseID, currentStock, req.FromLocationID, -delta)
+	}
+	if err != nil {
+		return detail, err
+	}
+
+	if product.IsLotTracked {
+		if delta > 0 {
+			detail.lots, err = s.receiveLot(ctx, tx, req, req.WarehouseID, delta)
+		} else {
+			// en salidas normales no se despachan lotes caducados, en ajustes si (mermas por caducidad)
+			detail.lots, err = s.issueLots(ctx, tx, req, req.WarehouseID, -delta, req.MovementType != MovementTypeOut)
+		}
+		if err != nil {
+			return detail, err
+		}
+	}
+
+	if product.IsSerialized {
+		if delta > 0 {
+			detail.serials, err = s.receiveSerials(ctx, tx, req, req.WarehouseID)
+		} else {
+			// una salida embarca la unidad, un ajuste negativo o la reversion de una entrada la da de baja
+			status := serialShipped
+			if req.MovementType == MovementTypeAdjust || req.reversalOf != nil {
+				status = serialWrittenOff
+			}
+			detail.serials, err = s.moveSerials(ctx, tx, req, req.WarehouseID, status, nil)
+		}
+		if err != nil {
+			return detail, err
+		}
+	}
+
+	if delta > 0 {
+		detail.cost, err = s.receiveCost(ctx, tx, req, delta)
+	} else {
+		var consumed []CostLayer
+		consumed, err = s.issueCost(ctx, tx, req.ProductID, req.WarehouseID, -delta, req.reversalOf)
+		detail.cost = &movementCost{total: layersValue(consumed)}
+	}
+	if err != nil {
+		return detail, err
+	}
+
+	// actualizar stock del almacen y el total del producto (dentro de la transaccion)
+	err = s.repo.UpdateStockLevel(ctx, tx, req.ProductID, req.WarehouseID, currentStock+delta)
+	if err != nil {
+		return detail, fmt.Errorf("error updating stock level: %w", err)
+	}
+
+	if err := s.repo.AddProductStock(ctx, tx, req.ProductID, delta); err != nil {
+		return detail, fmt.Errorf("error updating product stock: %w", err)
+	}
+
+	return detail, nil
+}
+
+// mueve stock entre ubicaciones o almacenes en la misma transaccion
+// las filas de stock_levels se bloquean en orden ascendente de warehouse_id
+// para que dos transferencias opuestas (A->B y B->A) no se bloqueen mutuamente (deadlock)
+// los bins, lotes y series quedan protegidos por el lock de stock_levels de su almacen
+func (s *Service) applyTransfer(ctx context.Context, tx *sqlx.Tx, req CreateMovementRequest, product *ProductInfo) (movementDetail, error) {
+	var detail movementDetail
+	source, destination := req.WarehouseID, req.ToWarehouseID
+
+	lockOrder := []int{source}
+	if destination != source {
+		lockOrder = append(lockOrder, destination)
+		sort.Ints(lockOrder)
+	}
+
+	stock := make(map[int]int, len(lockOrder))
+	for _, warehouseID := range lockOrder {
+		current, err := s.repo.GetStockLevelForUpdate(ctx, tx, req.ProductID, warehouseID)
+		if err != nil {
+			return detail, fmt.Errorf("error getting product stock: %w", err)
+		}
+		stock[warehouseID] = current
+	}
+
+	// entre almacenes solo viaja stock disponible: lo retenido y lo reservado se quedan en su almacen
+	if destination != source {
+		if err := s.checkTransferableStock(ctx, tx, req.ProductID, source, stock[source], req.Quantity); err != nil {
+			return detail, err
+		}
+	}
+
+	// debitar origen
+	if err := s.takeStock(ctx, tx, req.ProductID, source, stock[source], req.FromLocationID, req.Quantity); err != nil {
+		return detail, err
+	}
+
+	// acreditar destino
+	if err := s.putStock(ctx, tx, req.ProductID, destination, req.ToLocationID, req.Quantity); err != nil {
+		return detail, err
+	}
+
+	// lotes y series se llevan por almacen: dentro del mismo almacen no cambian
+	if destination == source {
+		return detail, nil
+	}
+
+	if product.IsLotTracked {
+		lots, err := s.issueLots(ctx, tx, req, source, req.Quantity, true)
+		if err != nil {
+			return detail, err
+		}
+		for _, lot := range lots {
+			if err := s.changeLotStock(ctx, tx, lot.LotID, destination, lot.Quantity); err != nil {
+				return detail, err
+			}
+		}
+		detail.lots = lots
+	}
+
+	if product.IsSerialized {
+		serials, err := s.moveSerials(ctx, tx, req, source, serialInStock, &destination)
+		if err != nil {
+			return detail, err
+		}
+		detail.serials = serials
+	}
+
+	// el valor viaja con el stock: lo consumido en el origen entra al destino al mismo costo
+	consumed, err := s.issueCost(ctx, tx, req.ProductID, source, req.Quantity, req.reversalOf)
+	if err != nil {
+		return detail, err
+	}
+	detail.cost = &movementCost{total: layersValue(consumed), warehouseID: destination, layers: consumed}
+
+	// entre almacenes cambia el stock de cada uno, el total del producto no cambia
+	if err := s.repo.UpdateStockLevel(ctx, tx, req.ProductID, source, stock[source]-req.Quantity); err != nil {
+		return detail, fmt.Errorf("error updating stock level: %w", err)
+	}
+	if err := s.repo.UpdateStockLevel(ctx, tx, req.ProductID, destination, stock[destination]+req.Quantity); err != nil {
+		return detail, fmt.Errorf("error updating stock level: %w", err)
+	}
+
+	return detail, nil
+}
+
+// mueve quantity de un estado a otro dentro del almacen
+// el stock fisico, los bins, lotes y series no cambian: el estado se lleva por producto y almacen
+// pasar stock disponible a un estado retenido solo toma stock no reservado
+func (s *Service) applyStatusChange(ctx context.Context, tx *sqlx.Tx, req CreateMovementRequest) (movementDetail, error) {
+	var detail movementDetail
+
+	currentStock, err := s.repo.GetStockLevelForUpdate(ctx, tx, req.ProductID, req.WarehouseID)
+	if err != nil {
+		return detail, fmt.Errorf("error getting product stock: %w", err)
+	}
+
+	if req.FromStatus == StockStatusAvailable {
+		held, err := s.repo.GetHeldStock(ctx, tx, req.ProductID, req.WarehouseID)
+		if err != nil {
+			return detail, err
+		}
+		reserved, err := s.repo.GetReservedStock(ctx, tx, req.ProductID, req.WarehouseID)
+		if err != nil {
+			return detail, err
+		}
+
+		if err := checkAvailableStock(currentStock, reserved, held, req.Quantity); err != nil {
+			return detail, err
+		}
+	} else if err := s.changeStatusStock(ctx, tx, req.ProductID, req.WarehouseID, req.FromStatus, -req.Quantity); err != nil {
+		return detail, err
+	}
+
+	if err := s.changeStatusStock(ctx, tx, req.ProductID, req.WarehouseID, req.ToStatus, req.Quantity); err != nil {
+		return detail, err
+	}
+	return detail, nil
+}
+
+// descuenta quantity del estado de origen del movimiento
+// de un estado retenido se descuenta de ese estado (una salida OUT ya paso la validacion del override)
+// del disponible nunca se toma lo retenido, y una salida ademas respeta lo reservado
+func (s *Service) takeStatusStock(ctx context.Context, tx *sqlx.Tx, req CreateMovementRequest, currentStock, quantity int) error {
+	status := req.FromStatus.orDefault()
+	if status != StockStatusAvailable {
+		return s.changeStatusStock(ctx, tx, req.ProductID, req.WarehouseID, status, -quantity)
+	}
+
+	held, err := s.repo.GetHeldStock(ctx, tx, req.ProductID, req.WarehouseID)
+	if err != nil {
+		return err
+	}
+
+	if req.MovementType == MovementTypeOut {
+		return s.checkReservedStock(ctx, tx, req, currentStock, held)
+	}
+
+	if available := currentStock - held; available < quantity {
+		return fmt.Errorf("insufficient stock: available=%d (on_hand=%d, held=%d), request=%d", max(available, 0), currentStock, held, quantity)
+	}
+	return nil
+}
+
+// valida que quantity salga del stock disponible del almacen, sin tocar lo retenido ni lo reservado
+// currentStock es el stock_level ya bloqueado
+func (s *Service) checkTransferableStock(ctx context.Context, tx *sqlx.Tx, productID, warehouseID, currentStock, quantity int) error {
+	held, err := s.repo.GetHeldStock(ctx, tx, productID, warehouseID)
+	if err != nil {
+		return err
+	}
+	reserved, err := s.repo.GetReservedStock(ctx, tx, productID, warehouseID)
+	if err != nil {
+		return err
+	}
+	return checkAvailableStock(currentStock, reserved, held, quantity)
+}
+
+// disponible = existencia - reservado - retenido
+func checkAvailableStock(onHand, reserved, held, quantity int) error {
+	if available := onHand - reserved - held; available < quantity {
+		return fmt.Errorf("insufficient stock: available=%d (on_hand=%d, reserved=%d, held=%d), request=%d",
+			max(available, 0), onHand, reserved, held, quantity)
+	}
+	return nil
+}
+
+// suma delta al stock de un estado retenido
+// AVAILABLE no se guarda: es lo que queda del stock_level despues de lo retenido
+func (s *Service) changeStatusStock(ctx context.Context, tx *sqlx.Tx, productID, warehouseID int, status StockStatus, delta int) error {
+	if status == StockStatusAvailable {
+		return nil
+	}
+
+	current, err := s.repo.GetStatusStockForUpdate(ctx, tx, productID, warehouseID, status)
+	if err != nil {
+		return err
+	}
+
+	if current+delta < 0 {
+		return fmt.Errorf("insufficient stock in status %s: available=%d, request=%d", status, current, -delta)
+	}
+
+	return s.repo.UpdateStatusStock(ctx, tx, productID, warehouseID, status, current+delta)
+}
+
+// valora una entrada: una capa nueva al costo indicado o, si se omite, al costo promedio actual
+func (s *Service) receiveCost(ctx context.Context, tx *sqlx.Tx, req CreateMovementRequest, quantity int) (*movementCost, error) {
+	var unitCost float64
+	if req.UnitCost != nil {
+		unitCost = roundCost(*req.UnitCost)
+	} else {
+		current, err := s.currentUnitCost(ctx, tx, req.ProductID, req.WarehouseID)
+		if err != nil {
+			return nil, err
+		}
+		unitCost = current
+	}
+
+	layer := CostLayer{Quantity: quantity, RemainingQuantity: quantity, UnitCost: unitCost}
+	return &movementCost{total: float64(quantity) * unitCost, warehouseID: req.WarehouseID, layers: []CostLayer{layer}}, nil
+}
+
+// costo promedio del stock del producto en el almacen
+// sin capas en el almacen usa el ultimo costo conocido del producto, y sin ninguno es cero
+func (s *Service) currentUnitCost(ctx context.Context, tx *sqlx.Tx, productID, warehouseID int) (float64, error) {
+	layers, err := s.repo.ListCostLayersForUpdate(ctx, tx, productID, warehouseID)
+	if err != nil {
+		return 0, err
+	}
+	if len(layers) > 0 {
+		return averageCost(layers), nil
+	}
+
+	last, err := s.repo.GetLastUnitCost(ctx, tx, productID)
+	if err != nil {
+		return 0, err
+	}
+	if last == nil {
+		return 0, nil
+	}
+	return *last, nil
+}
+
+// consume quantity de las capas de costo del producto en el almacen y devuelve lo consumido por capa
+// FIFO toma cada capa a su propio costo, AVERAGE revalua antes todas las capas al costo promedio
+// el stock que no tiene capa sale a costo cero
+// en una reversion (reversalOf) se consumen primero las capas que creo el movimiento revertido
+func (s *Service) issueCost(ctx context.Context, tx *sqlx.Tx, productID, warehouseID, quantity int, reversalOf *int) ([]CostLayer, error) {
+	layers, err := s.repo.ListCostLayersForUpdate(ctx, tx, productID, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	if reversalOf != nil {
+		createdBy := func(layer CostLayer) bool { return layer.MovementID != nil && *layer.MovementID == *reversalOf }
+		sort.SliceStable(layers, func(i, j int) bool { return createdBy(layers[i]) && !createdBy(layers[j]) })
+	}
+
+	average := averageCost(layers)
+
+	var consumed []CostLayer
+	remaining := quantity
+	for _, layer := range layers {
+		unitCost := layer.UnitCost
+		if s.costing == CostingAverage {
+			unitCost = average
+		}
+
+		take := min(remaining, layer.RemainingQuantity)
+		if take == 0 && unitCost == layer.UnitCost {
+			continue
+		}
+
+		if err := s.repo.UpdateCostLayer(ctx, tx, layer.ID, layer.RemainingQuantity-take, unitCost); err != nil {
+			return nil, err
+		}
+		if take > 0 {
+			consumed = append(consumed, CostLayer{Quantity: take, RemainingQuantity: take, UnitCost: unitCost})
+			remaining -= take
+		}
+	}
+
+	if remaining > 0 {
+		s.logger.Warn().Int("product_id", productID).Int("warehouse_id", warehouseID).Int("quantity", remaining).
+			Msg("Stock without cost layers issued at zero cost")
+		consumed = append(consumed, CostLayer{Quantity: remaining, RemainingQuantity: remaining})
+	}
+	return consumed, nil
+}
+
+// costo promedio ponderado de lo que queda en las capas
+func averageCost(layers []CostLayer) float64 {
+	quantity, value := 0, 0.0
+	for _, layer := range layers {
+		quantity += layer.RemainingQuantity
+		value += float64(layer.RemainingQuantity) * layer.UnitCost
+	}
+	if quantity == 0 {
+		return 0
+	}
+	return roundCost(value / float64(quantity))
+}
+
+// valor de las cantidades consumidas de cada capa
+func layersValue(layers []CostLayer) float64 {
+	value := 0.0
+	for _, layer := range layers {
+		value += float64(layer.Quantity) * layer.UnitCost
+	}
+	return value
+}
+
+// redondea un costo a 4 decimales (la precision de las columnas de costo)
+func roundCost(value float64) float64 {
+	return math.Round(value*10000) / 10000
+}
+
+// registra la entrada de quantity en el lote indicado (lo crea si no existe)
+// una reversion devuelve exactamente los lotes del movimiento revertido
+func (s *Service) receiveLot(ctx context.Context, tx *sqlx.Tx, req CreateMovementRequest, warehouseID, quantity int) ([]MovementLot, error) {
+	if len(req.lots) > 0 {
+		return s.changeLotsStock(ctx, tx, req.lots, warehouseID, 1)
+	}
+
+	manufacturedAt, err := parseDate(req.ManufacturedAt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid manufactured_at: must be YYYY-MM-DD")
+	}
+	expiresAt, err := parseDate(req.ExpiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expires_at: must be YYYY-MM-DD")
+	}
+
+	lot, err := s.repo.UpsertLot(ctx, tx, req.ProductID, req.LotNumber, manufacturedAt, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	// un lote existente conserva sus fechas
+	if expiresAt != nil && !sameDate(lot.ExpiresAt, expiresAt) {
+		return nil, fmt.Errorf("invalid expires_at: lot [%s] already exists with a different expiry date", lot.LotNumber)
+	}
+	if manufacturedAt != nil && !sameDate(lot.ManufacturedAt, manufacturedAt) {
+		return nil, fmt.Errorf("invalid manufactured_at: lot [%s] already exists with a different manufacture date", lot.LotNumber)
+	}
+
+	if err := s.changeLotStock(ctx, tx, lot.ID, warehouseID, quantity); err != nil {
+		return nil, err
+	}
+
+	return []MovementLot{{LotID: lot.ID, LotNumber: lot.LotNumber, ExpiresAt: lot.ExpiresAt, Quantity: quantity}}, nil
+}
+
+// saca quantity de los lotes del almacen: del lote indicado o en orden FEFO
+// allowExpired permite tomar lotes caducados (ajustes y transferencias)
+// una reversion saca exactamente los lotes del movimiento revertido, aunque esten caducados
+func (s *Service) issueLots(ctx context.Context, tx *sqlx.Tx, req CreateMovementRequest, warehouseID, quantity int, allowExpired bool) ([]MovementLot, error) {
+	if len(req.lots) > 0 {
+		return s.changeLotsStock(ctx, tx, req.lots, warehouseID, -1)
+	}
+
+	if req.LotNumber != "" {
+		lot, err := s.repo.GetLotByNumber(ctx, tx, req.ProductID, req.LotNumber)
+		if err != nil {
+			return nil, err
+		}
+		if lot.Expired && !allowExpired {
+			return nil, fmt.Errorf("invalid lot_number: lot [%s] expired on %s", lot.LotNumber, lot.ExpiresAt.Format("2006-01-02"))
+		}
+
+		if err := s.changeLotStock(ctx, tx, lot.ID, warehouseID, -quantity); err != nil {
+			return nil, err
+		}
+		return []MovementLot{{LotID: lot.ID, LotNumber: lot.LotNumber, ExpiresAt: lot.ExpiresAt, Quantity: quantity}}, nil
+	}
+
+	candidates, err := s.repo.ListLotStockForUpdate(ctx, tx, req.ProductID, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	var lots []MovementLot
+	remaining := quantity
+	for _, candidate := range candidates {
+		if remaining == 0 {
+			break
+		}
+		if candidate.Expired && !allowExpired {
+			continue
+		}
+
+		take := min(remaining, candidate.Quantity)
+		if err := s.repo.UpdateLotStock(ctx, tx, candidate.ID, warehouseID, candidate.Quantity-take); err != nil {
+			return nil, err
+		}
+
+		lots = append(lots, MovementLot{LotID: candidate.ID, LotNumber: candidate.LotNumber, ExpiresAt: candidate.ExpiresAt, Quantity: take})
+		remaining -= take
+	}
+
+	if remaining > 0 {
+		return nil, fmt.Errorf("insufficient stock: available in lots=%d, request=%d", quantity-remaining, quantity)
+	}
+	return lots, nil
+}
+
+// registra la entrada de las series en el almacen
+// una serie nueva se crea, una que ya salio (devolucion o reingreso) vuelve a stock
+func (s *Service) receiveSerials(ctx context.Context, tx *sqlx.Tx, req CreateMovementRequest, warehouseID int) ([]SerialInfo, error) {
+	existing, err := s.repo.LockSerials(ctx, tx, req.Serials)
+	if err != nil {
+		return nil, err
+	}
+
+	byNumber := make(map[string]SerialInfo, len(existing))
+	for _, serial := range existing {
+		byNumber[serial.SerialNumber] = serial
+	}
+
+	serials := make([]SerialInfo, 0, len(req.Serials))
+	for _, number := range req.Serials {
+		serial, found := byNumber[number]
+		if !found {
+			id, err := s.repo.CreateSerial(ctx, tx, req.ProductID, number, warehouseID)
+			if err != nil {
+				return nil, err
+			}
+			serials = append(serials, SerialInfo{ID: id, ProductID: req.ProductID, SerialNumber: number})
+			continue
+		}
+
+		if serial.ProductID != req.ProductID {
+			return nil, fmt.Errorf("invalid serials: [%s] belongs to another product", number)
+		}
+		if serial.Status == serialInStock {
+			return nil, fmt.Errorf("invalid serials: [%s] is already in stock", number)
+		}
+
+		if err := s.repo.UpdateSerial(ctx, tx, serial.ID, serialInStock, &warehouseID); err != nil {
+			return nil, err
+		}
+		serials = append(serials, serial)
+	}
+	return serials, nil
+}
+
+// saca las series del almacen: quedan con el nuevo estado y en el almacen destino (nulo si salen)
+// cada serie debe existir, ser del producto y estar en stock en el almacen de origen
+func (s *Service) moveSerials(ctx context.Context, tx *sqlx.Tx, req CreateMovementRequest, warehouseID int, status string, toWarehouseID *int) ([]SerialInfo, error) {
+	existing, err := s.repo.LockSerials(ctx, tx, req.Serials)
+	if err != nil {
+		return nil, err
+	}
+
+	byNumber := make(map[string]SerialInfo, len(existing))
+	for _, serial := range existing {
+		byNumber[serial.SerialNumber] = serial
+	}
+
+	serials := make([]SerialInfo, 0, len(req.Serials))
+	for _, number := range req.Serials {
+		serial, found := byNumber[number]
+		if !found {
+			return nil, fmt.Errorf("serial [%s] not found", number)
+		}
+
+		if serial.ProductID != req.ProductID {
+			return nil, fmt.Errorf("invalid serials: [%s] belongs to another product", number)
+		}
+		if serial.Status != serialInStock || serial.WarehouseID == nil || *serial.WarehouseID != warehouseID {
+			return nil, fmt.Errorf("invalid serials: [%s] is not in stock in warehouse %d", number, warehouseID)
+		}
+
+		if err := s.repo.UpdateSerial(ctx, tx, serial.ID, status, toWarehouseID); err != nil {
+			return nil, err
+		}
+		serials = append(serials, serial)
+	}
+	return serials, nil
+}
+
+// suma (sign 1) o resta (sign -1) la cantidad de cada lote al stock del almacen
+func (s *Service) changeLotsStock(ctx context.Context, tx *sqlx.Tx, lots []MovementLot, warehouseID, sign int) ([]MovementLot, error) {
+	for _, lot := range lots {
+		if err := s.changeLotStock(ctx, tx, lot.LotID, warehouseID, sign*lot.Quantity); err != nil {
+			return nil, err
+		}
+	}
+	return lots, nil
+}
+
+// suma delta al stock de un lote en un almacen
+func (s *Service) changeLotStock(ctx context.Context, tx *sqlx.Tx, lotID, warehouseID, delta int) error {
+	current, err := s.repo.GetLotStockForUpdate(ctx, tx, lotID, warehouseID)
+	if err != nil {
+		return err
+	}
+
+	if current+delta < 0 {
+		return fmt.Errorf("insufficient stock in lot: available=%d, request=%d", current, -delta)
+	}
+
+	return s.repo.UpdateLotStock(ctx, tx, lotID, warehouseID, current+delta)
+}
+
+// valida que la salida no tome stock reservado para otros ni retenido y consume su reserva si la indica
+// los ajustes no pasan por aqui: una merma fisica reduce el stock aunque este reservado
+func (s *Service) checkReservedStock(ctx context.Context, tx *sqlx.Tx, req CreateMovementRequest, currentStock, held int) error {
+	reserved, err := s.repo.GetReservedStock(ctx, tx, req.ProductID, req.WarehouseID)
+	if err != nil {
+		return err
+	}
+
+	// lo reservado por la propia reserva si esta disponible para esta salida
+	own := 0
+	if req.ReservationID != nil {
+		reservation, err := s.repo.GetReservationForUpdate(ctx, tx, *req.ReservationID)
+		if err != nil {
+			return err
+		}
+
+		if reservation.ProductID != req.ProductID || reservation.WarehouseID != req.WarehouseID {
+			return fmt.Errorf("invalid reservation_id: reservation %d is for another product or warehouse", reservation.ID)
+		}
+		if reservation.Status != "ACTIVE" || reservation.Expired {
+			return fmt.Errorf("invalid reservation_id: reservation %d is not active", reservation.ID)
+		}
+		if req.Quantity > reservation.Remaining {
+			return fmt.Errorf("invalid quantity: reservation %d has %d units left, request=%d", reservation.ID, reservation.Remaining, req.Quantity)
+		}
+
+		if err := s.repo.ConsumeReservation(ctx, tx, reservation.ID, req.Quantity); err != nil {
+			return err
+		}
+		own = reservation.Remaining
+	}
+
+	return checkAvailableStock(currentStock, reserved-own, held, req.Quantity)
+}
+
+// saca quantity del almacen: del bin indicado o del stock sin ubicar
+// currentStock es el stock_level ya bloqueado
+func (s *Service) takeStock(ctx context.Context, tx *sqlx.Tx, productID, warehouseID, currentStock int, fromLocationID *int, quantity int) error {
+	if currentStock < quantity {
+		return fmt.Errorf("insufficient stock: available=%d, request=%d", currentStock, quantity)
+	}
+
+	if fromLocationID != nil {
+		return s.changeLocationStock(ctx, tx, productID, warehouseID, *fromLocationID, -quantity)
+	}
+
+	// sin bin de origen solo se puede sacar stock que no esta asignado a ninguna ubicacion
+	located, err := s.repo.GetLocatedStock(ctx, tx, productID, warehouseID)
 	if err != nil {
-		return nil, fmt.Errorf("error checking product existence: %w", err)
+		return err
 	}
-	if !exists {
-		return nil, fmt.Errorf("product with ID [%d] not found", req.ProductID)
+	if unlocated := currentStock - located; unlocated < quantity {
+		return fmt.Errorf("insufficient stock: unlocated=%d, request=%d (from_location_id is required)", unlocated, quantity)
+	}
+	return nil
+}
+
+// guarda quantity en el bin indicado (sin bin queda como stock sin ubicar)
+func (s *Service) putStock(ctx context.Context, tx *sqlx.Tx, productID, warehouseID int, toLocationID *int, quantity int) error {
+	if toLocationID == nil {
+		return nil
+	}
+	return s.changeLocationStock(ctx, tx, productID, warehouseID, *toLocationID, quantity)
+}
+
+// suma delta al stock del producto en un bin
+// el bin debe estar activo y pertenecer al almacen del movimiento
+func (s *Service) changeLocationStock(ctx context.Context, tx *sqlx.Tx, productID, warehouseID, locationID, delta int) error {
+	location, err := s.repo.GetLocation(ctx, tx, locationID)
+	if err != nil {
+		return err
+	}
+
+	if location.WarehouseID != warehouseID {
+		return fmt.Errorf("invalid location: [%s] does not belong to warehouse %d", location.Path, warehouseID)
+	}
+	if location.LocationType != "BIN" {
+		return fmt.Errorf("invalid location: [%s] is not a BIN", location.Path)
+	}
+	if !location.IsActive {
+		return fmt.Errorf("invalid location: [%s] is inactive", location.Path)
+	}
+
+	current, err := s.repo.GetLocationStockForUpdate(ctx, tx, productID, locationID)
+	if err != nil {
+		return err
+	}
+
+	if current+delta < 0 {
+		return fmt.Errorf("insufficient stock in location [%s]: available=%d, request=%d", location.Path, current, -delta)
+	}
+
+	return s.repo.UpdateLocationStock(ctx, tx, productID, locationID, current+delta)
+}
+
+// Reverse anula un movimiento registrando el movimiento contrario, ligado al original por reversal_of_id
+// el contrario se aplica como cualquier movimiento (mismos locks y validaciones de stock, no deja stock negativo)
+// y devuelve el bin, el estado, los lotes, las series y el valor del original
+// IN <-> OUT, ADJUST con el signo contrario, TRANSFER y STATUS_CHANGE en sentido inverso
+// no se revierte dos veces, ni una reversion, ni un movimiento registrado por un documento
+// (recepcion, orden de venta, conteo, devolucion): esos se corrigen desde el documento
+// la reversion de un OUT no devuelve la reserva que haya consumido
+func (s *Service) Reverse(ctx context.Context, id int, req ReverseMovementRequest) (resp *MovementResponse, err error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("invalid movement ID: %d", id)
 	}
 
 	tx, err := s.db.BeginTxx(ctx, nil)
@@ -54,71 +1071,137 @@ func (s *Service) CreateMovement(ctx context.Context, req CreateMovementRequest)
 		return nil, fmt.Errorf("error starting transaction: %w", err)
 	}
 
-	// patron critico: DEFER para rollback automatico
 	defer func() {
 		if err != nil {
 			tx.Rollback()
 		}
 	}()
 
-	// obtener stock actual con lock pesimista
-	currentStock, sku, err := s.repo.GetProductStockForUpdate(ctx, tx, req.ProductID)
+	original, err := s.repo.GetForUpdate(ctx, tx, id)
 	if err != nil {
-		return nil, fmt.Errorf("error getting product stock: %w", err)
+		return nil, err
 	}
 
-	// calcular nuevo stock segun el tipo de movimiento
-	var newStock int
-	switch req.MovementType {
-	case MovementTypeIn:
-		newStock = currentStock + req.Quantity
-	case MovementTypeOut:
-		if currentStock < req.Quantity {
-			return nil, fmt.Errorf("insufficient stock: available=%d, request=%d", currentStock, req.Quantity)
-		}
-		newStock = currentStock - req.Quantity
+	if err := checkReversible(original); err != nil {
+		return nil, err
 	}
 
-	// actualizar stock del producto (dentro de la transaccion)
-	err = s.repo.UpdateProductStock(ctx, tx, req.ProductID, newStock)
+	document, err := s.repo.GetMovementDocument(ctx, tx, id)
 	if err != nil {
-		return nil, fmt.Errorf("error updating product stock: %w", err)
+		return nil, err
+	}
+	if document != "" {
+		return nil, fmt.Errorf("cannot reverse movement %d: it was posted by a %s, correct it from there", id, document)
 	}
 
-	movement := &Movement{
-		ProductID:    req.ProductID,
-		MovementType: req.MovementType,
-		Quantity:     req.Quantity,
-		Reason:       req.Reason,
-		CreatedBy:    req.CreatedBy,
+	lots, err := s.repo.GetMovementLots(ctx, []int{id})
+	if err != nil {
+		return nil, err
+	}
+	serials, err := s.repo.GetMovementSerials(ctx, []int{id})
+	if err != nil {
+		return nil, err
 	}
 
-	err = s.repo.Create(ctx, tx, movement)
+	reversal, entered := reversalRequest(original, lots[id], serials[id], req)
+	if err := s.validateCreateRequest(reversal); err != nil {
+		return nil, err
+	}
+	if err := s.validateReasonCode(ctx, reversal); err != nil {
+		return nil, err
+	}
+
+	movement, err := s.applyMovement(ctx, tx, reversal, entered)
 	if err != nil {
-		return nil, fmt.Errorf("error creating movement: %w", err)
+		return nil, err
 	}
 
-	// commit todo salio bien commit
 	err = tx.Commit()
 	if err != nil {
 		return nil, fmt.Errorf("error committing transaction: %w", err)
 	}
 
-	// invalidar cache de producto afectado
-	cacheKeys := []string{
-		fmt.Sprintf("product:%d", req.ProductID),
-		fmt.Sprintf("product:sku:%s", sku),
+	s.AfterCommit(ctx, movement)
+
+	response := movement.ToResponse()
+	return &response, nil
+}
+
+// rechaza revertir una reversion, un movimiento ya revertido o un ajuste de conciliacion
+func checkReversible(original *Movement) error {
+	if original.ReversalOfID != nil {
+		return fmt.Errorf("cannot reverse movement %d: it is the reversal of movement %d", original.ID, *original.ReversalOfID)
 	}
+	if original.ReversedByID != nil {
+		return fmt.Errorf("cannot reverse movement %d: it was already reversed by movement %d", original.ID, *original.ReversedByID)
+	}
+	if original.ReasonCode != nil && *original.ReasonCode == reconciliationReasonCode {
+		return fmt.Errorf("cannot reverse movement %d: reconciliation adjustments only record the ledger, run a new reconciliation instead", original.ID)
+	}
+	return nil
+}
 
-	if err := s.cache.Del(ctx, cacheKeys...); err != nil {
-		s.logger.Warn().Err(err).Int("product_id", req.ProductID).Msg("Failed to invalidate product cache")
-	} else {
-		s.logger.Debug().Int("product_id", req.ProductID).Msg("Product cache invalidated successfully")
+// arma el movimiento contrario del original, en la unidad base y en la unidad en que se capturo el original
+func reversalRequest(original *Movement, lots []MovementLot, serials []string, reverse ReverseMovementRequest) (CreateMovementRequest, uomQuantity) {
+	reason := reverse.Reason
+	if reason == "" {
+		reason = fmt.Sprintf("reversal of movement %d", original.ID)
 	}
 
-	// retornar el movimiento creado
-	response := movement.ToResponse()
-	return &response, nil
+	req := CreateMovementRequest{
+		ProductID:    original.ProductID,
+		WarehouseID:  original.WarehouseID,
+		MovementType: original.MovementType,
+		Quantity:     original.Quantity,
+		Reason:       reason,
+		ReasonCode:   reversalReasonCode,
+		CreatedBy:    reverse.CreatedBy,
+		Serials:      serials,
+		reversalOf:   &original.ID,
+		lots:         lots,
+		system:       true,
+	}
+	if len(lots) > 0 {
+		req.LotNumber = lots[0].LotNumber
+	}
+	entered := uomQuantity{uom: original.UoM, quantity: original.UoMQuantity}
+
+	statusOf := func(status *StockStatus) StockStatus {
+		if status == nil {
+			return ""
+		}
+		return *status
+	}
+
+	switch original.MovementType {
+	case MovementTypeIn:
+		// sacar lo que entro a un estado retenido es sacar stock no disponible
+		req.MovementType = MovementTypeOut
+		req.FromLocationID = original.ToLocationID
+		req.FromStatus = statusOf(original.ToStatus)
+		req.OverrideStatus = req.FromStatus.orDefault() != StockStatusAvailable
+	case MovementTypeOut:
+		req.MovementType = MovementTypeIn
+		req.ToLocationID = original.FromLocationID
+		req.ToStatus = statusOf(original.FromStatus)
+		req.UnitCost = original.UnitCost
+	case MovementTypeAdjust:
+		req.Quantity, entered.quantity = -original.Quantity, -original.UoMQuantity
+		req.FromLocationID, req.ToLocationID = original.ToLocationID, original.FromLocationID
+		req.FromStatus, req.ToStatus = statusOf(original.ToStatus), statusOf(original.FromStatus)
+		if req.Quantity > 0 {
+			req.UnitCost = original.UnitCost
+		}
+	case MovementTypeTransfer:
+		req.ToWarehouseID = original.WarehouseID
+		if original.ToWarehouseID != nil {
+			req.WarehouseID = *original.ToWarehouseID
+		}
+		req.FromLocationID, req.ToLocationID = original.ToLocationID, original.FromLocationID
+	case MovementTypeStatusChange:
+		req.FromStatus, req.ToStatus = statusOf(original.ToStatus), statusOf(original.FromStatus)
+	}
+	return req, entered
 }
 
 func (s *Service) GetByID(ctx context.Context, id int) (*MovementResponse, error) {
@@ -134,7 +1217,13 @@ func (s *Service) GetByID(ctx context.Context, id int) (*MovementResponse, error
 		return nil, fmt.Errorf("error getting movement: %w", err)
 
 	}
-	response := movement.ToResponse()
+
+	movements := []Movement{*movement}
+	if err := s.attachDetail(ctx, movements); err != nil {
+		return nil, err
+	}
+
+	response := movements[0].ToResponse()
 	return &response, nil
 }
 
@@ -158,6 +1247,10 @@ func (s *Service) ListByProductID(ctx context.Context, productID, page, pageSize
 		return nil, fmt.Errorf("error listing movements: %w", err)
 	}
 
+	if err := s.attachDetail(ctx, movements); err != nil {
+		return nil, err
+	}
+
 	responses := make([]MovementResponse, len(movements))
 	for i, m := range movements {
 		responses[i] = m.ToResponse()
@@ -180,16 +1273,24 @@ func (s *Service) ListByProductID(ctx context.Context, productID, page, pageSize
 	}, nil
 }
 
-func (s *Service) List(ctx context.Context, productID *int, movementType *MovementType, page, pageSize int) (*ListMovementResponse, error) {
+func (s *Service) List(ctx context.Context, productID, warehouseID *int, movementType *MovementType, batchID *int, page, pageSize int) (*ListMovementResponse, error) {
 	// validaciones de filtros
 	if productID != nil && *productID <= 0 {
 		return nil, fmt.Errorf("invalid product ID: %d", *productID)
 	}
 
+	if warehouseID != nil && *warehouseID <= 0 {
+		return nil, fmt.Errorf("invalid warehouse ID: %d", *warehouseID)
+	}
+
 	if movementType != nil && !movementType.IsValid() {
 		return nil, fmt.Errorf("invalid movement type: %s", *movementType)
 	}
 
+	if batchID != nil && *batchID <= 0 {
+		return nil, fmt.Errorf("invalid batch ID: %d", *batchID)
+	}
+
 	page, pageSize = s.normalizePagination(page, pageSize)
 
 	// si se especifica productID, validar que existe
@@ -203,11 +1304,15 @@ func (s *Service) List(ctx context.Context, productID *int, movementType *Moveme
 		}
 	}
 
-	movements, total, err := s.repo.List(ctx, productID, movementType, page, pageSize)
+	movements, total, err := s.repo.List(ctx, productID, warehouseID, movementType, batchID, page, pageSize)
 	if err != nil {
 		return nil, fmt.Errorf("error listing movements: %w", err)
 	}
 
+	if err := s.attachDetail(ctx, movements); err != nil {
+		return nil, err
+	}
+
 	responses := make([]MovementResponse, len(movements))
 	for i, m := range movements {
 		responses[i] = m.ToResponse()
@@ -231,19 +1336,165 @@ func (s *Service) List(ctx context.Context, productID *int, movementType *Moveme
 
 }
 
+// agrega el desglose por lote y las series a los movimientos de una pagina (una consulta de cada uno)
+func (s *Service) attachDetail(ctx context.Context, movements []Movement) error {
+	if len(movements) == 0 {
+		return nil
+	}
+
+	ids := make([]int, len(movements))
+	for i, m := range movements {
+		ids[i] = m.ID
+	}
+
+	lots, err := s.repo.GetMovementLots(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	serials, err := s.repo.GetMovementSerials(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	for i := range movements {
+		movements[i].Lots = lots[movements[i].ID]
+		movements[i].Serials = serials[movements[i].ID]
+	}
+	return nil
+}
+
+// uomQuantity es la cantidad tal como se capturo, antes de convertirla a la unidad base
+type uomQuantity struct {
+	uom      string
+	quantity int
+}
+
+// convierte req.Quantity de la unidad capturada a la unidad base (piezas)
+// la unidad debe existir, estar activa y tener factor configurado para el producto
+func (s *Service) convertToBaseUoM(ctx context.Context, req *CreateMovementRequest) (uomQuantity, error) {
+	code := strings.ToUpper(strings.TrimSpace(req.UoM))
+	if code == "" {
+		code = baseUoM
+	}
+	entered := uomQuantity{uom: code, quantity: req.Quantity}
+
+	if code == baseUoM {
+		return entered, nil
+	}
+
+	uom, err := s.repo.GetUoM(ctx, req.ProductID, code)
+	if err != nil {
+		return entered, err
+	}
+	if !uom.IsActive {
+		return entered, fmt.Errorf("invalid uom: [%s] is inactive", code)
+	}
+	if uom.Factor == nil {
+		return entered, fmt.Errorf("invalid uom: product %d has no conversion for [%s]", req.ProductID, code)
+	}
+
+	base := int64(req.Quantity) * int64(*uom.Factor)
+	if base > math.MaxInt32 || base < math.MinInt32 {
+		return entered, fmt.Errorf("invalid quantity: %d %s exceeds the maximum quantity per movement", req.Quantity, code)
+	}
+
+	req.Quantity = int(base)
+	return entered, nil
+}
+
+// resuelve el almacen del movimiento: el indicado o el almacen por defecto
+func (s *Service) resolveWarehouse(ctx context.Context, warehouseID int) (int, error) {
+	if warehouseID == 0 {
+		id, err := s.repo.GetDefaultWarehouseID(ctx)
+		if err != nil {
+			return 0, err
+		}
+		return id, nil
+	}
+
+	exists, err := s.repo.WarehouseExists(ctx, warehouseID)
+	if err != nil {
+		return 0, err
+	}
+	if !exists {
+		return 0, fmt.Errorf("warehouse with ID [%d] not found", warehouseID)
+	}
+	return warehouseID, nil
+}
+
+// resuelve el almacen destino y verifica que la transferencia mueva algo
+func (s *Service) validateTransfer(ctx context.Context, req *CreateMovementRequest) error {
+	if req.ToWarehouseID == 0 {
+		req.ToWarehouseID = req.WarehouseID
+	} else if req.ToWarehouseID != req.WarehouseID {
+		exists, err := s.repo.WarehouseExists(ctx, req.ToWarehouseID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("warehouse with ID [%d] not found", req.ToWarehouseID)
+		}
+	}
+
+	if req.ToWarehouseID == req.WarehouseID {
+		if req.FromLocationID == nil && req.ToLocationID == nil {
+			return fmt.Errorf("invalid transfer: source and destination must be different")
+		}
+		if req.FromLocationID != nil && req.ToLocationID != nil && *req.FromLocationID == *req.ToLocationID {
+			return fmt.Errorf("invalid transfer: source and destination must be different")
+		}
+	}
+
+	return nil
+}
+
 func (s *Service) validateCreateRequest(req CreateMovementRequest) error {
 	if req.ProductID <= 0 {
 		return fmt.Errorf("product_id must be greater than 0")
 	}
 
 	if !req.MovementType.IsValid() {
-		return fmt.Errorf("invalid movement_type: must be 'IN' or 'OUT'")
+		return fmt.Errorf("invalid movement_type: must be 'IN', 'OUT', 'TRANSFER', 'ADJUST' or 'STATUS_CHANGE'")
+	}
+
+	if req.WarehouseID < 0 {
+		return fmt.Errorf("warehouse_id must be greater than 0")
 	}
 
-	if req.Quantity <= 0 {
+	if req.MovementType == MovementTypeAdjust {
+		if req.Quantity == 0 {
+			return fmt.Errorf("quantity must be different from 0")
+		}
+		if req.ReasonCode == "" {
+			return fmt.Errorf("invalid reason_code: ADJUST movements require a reason code")
+		}
+		if req.Quantity > 0 && req.FromLocationID != nil {
+			return fmt.Errorf("invalid from_location_id: positive adjustments only accept to_location_id")
+		}
+		if req.Quantity < 0 && req.ToLocationID != nil {
+			return fmt.Errorf("invalid to_location_id: negative adjustments only accept from_location_id")
+		}
+	} else if req.Quantity <= 0 {
 		return fmt.Errorf("quantity must be greater than 0")
 	}
 
+	if req.MovementType == MovementTypeIn && req.FromLocationID != nil {
+		return fmt.Errorf("invalid from_location_id: IN movements only accept to_location_id")
+	}
+
+	if req.MovementType == MovementTypeOut && req.ToLocationID != nil {
+		return fmt.Errorf("invalid to_location_id: OUT movements only accept from_location_id")
+	}
+
+	if req.MovementType != MovementTypeOut && req.ReservationID != nil {
+		return fmt.Errorf("invalid reservation_id: only OUT movements consume reservations")
+	}
+
+	if req.MovementType != MovementTypeTransfer && req.ToWarehouseID != 0 {
+		return fmt.Errorf("invalid to_warehouse_id: only TRANSFER movements have a destination warehouse")
+	}
+
 	if len(req.Reason) > 255 {
 		return fmt.Errorf("reason must be at most 255 characters")
 	}
@@ -251,6 +1502,200 @@ func (s *Service) validateCreateRequest(req CreateMovementRequest) error {
 	if len(req.CreatedBy) > 100 {
 		return fmt.Errorf("created_by must be at most 100 characters")
 	}
+
+	if len(req.LotNumber) > 50 {
+		return fmt.Errorf("lot_number must be at most 50 characters")
+	}
+
+	manufacturedAt, err := parseDate(req.ManufacturedAt)
+	if err != nil {
+		return fmt.Errorf("invalid manufactured_at: must be YYYY-MM-DD")
+	}
+	expiresAt, err := parseDate(req.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("invalid expires_at: must be YYYY-MM-DD")
+	}
+	if manufacturedAt != nil && expiresAt != nil && expiresAt.Before(*manufacturedAt) {
+		return fmt.Errorf("invalid expires_at: must be after manufactured_at")
+	}
+
+	if req.UnitCost != nil {
+		if *req.UnitCost < 0 {
+			return fmt.Errorf("invalid unit_cost: must be greater than or equal to 0")
+		}
+		if req.MovementType != MovementTypeIn && !(req.MovementType == MovementTypeAdjust && req.Quantity > 0) {
+			return fmt.Errorf("invalid unit_cost: only IN and positive ADJUST movements accept a unit cost")
+		}
+	}
+	return validateStatusRequest(req)
+}
+
+// valida los estados del inventario segun el tipo de movimiento
+// from_status solo aplica a lo que sale y to_status a lo que entra
+// una salida OUT de stock no disponible requiere override_status (el handler lo limita a admins)
+func validateStatusRequest(req CreateMovementRequest) error {
+	if req.FromStatus != "" && !req.FromStatus.IsValid() {
+		return fmt.Errorf("invalid from_status: must be AVAILABLE, QUARANTINE, DAMAGED or ON_HOLD")
+	}
+	if req.ToStatus != "" && !req.ToStatus.IsValid() {
+		return fmt.Errorf("invalid to_status: must be AVAILABLE, QUARANTINE, DAMAGED or ON_HOLD")
+	}
+
+	if req.MovementType == MovementTypeStatusChange {
+		if req.FromStatus == "" || req.ToStatus == "" {
+			return fmt.Errorf("invalid status change: from_status and to_status are required")
+		}
+		if req.FromStatus == req.ToStatus {
+			return fmt.Errorf("invalid status change: from_status and to_status must be different")
+		}
+		if req.FromLocationID != nil || req.ToLocationID != nil {
+			return fmt.Errorf("invalid location: statuses are tracked per warehouse, STATUS_CHANGE does not move stock between locations")
+		}
+		if req.LotNumber != "" || len(req.Serials) > 0 {
+			return fmt.Errorf("invalid lot_number: statuses are tracked per warehouse, STATUS_CHANGE does not move lots or serials")
+		}
+		return nil
+	}
+
+	outbound := req.MovementType == MovementTypeOut || (req.MovementType == MovementTypeAdjust && req.Quantity < 0)
+	inbound := req.MovementType == MovementTypeIn || (req.MovementType == MovementTypeAdjust && req.Quantity > 0)
+
+	if req.FromStatus != "" && !outbound {
+		return fmt.Errorf("invalid from_status: only OUT, negative ADJUST and STATUS_CHANGE movements take stock from a status")
+	}
+	if req.ToStatus != "" && !inbound {
+		return fmt.Errorf("invalid to_status: only IN, positive ADJUST and STATUS_CHANGE movements put stock into a status")
+	}
+
+	if req.OverrideStatus && req.MovementType != MovementTypeOut {
+		return fmt.Errorf("invalid override_status: only OUT movements can override the stock status")
+	}
+
+	if req.MovementType == MovementTypeOut && req.FromStatus.orDefault() != StockStatusAvailable {
+		if !req.OverrideStatus {
+			return fmt.Errorf("invalid from_status: %s stock is not available, shipping it requires override_status", req.FromStatus)
+		}
+		if req.ReservationID != nil {
+			return fmt.Errorf("invalid reservation_id: reservations only hold AVAILABLE stock")
+		}
+	}
+	return nil
+}
+
+// valida el lote contra el producto: solo los productos con lotes aceptan lote
+// y en las entradas el lote es obligatorio
+func validateLotRequest(req CreateMovementRequest, product *ProductInfo) error {
+	inbound := req.MovementType == MovementTypeIn || (req.MovementType == MovementTypeAdjust && req.Quantity > 0)
+	hasDates := req.ManufacturedAt != "" || req.ExpiresAt != ""
+
+	if !product.IsLotTracked {
+		if req.LotNumber != "" || hasDates {
+			return fmt.Errorf("invalid lot_number: product [%s] is not lot-tracked", product.SKU)
+		}
+		return nil
+	}
+
+	if inbound && req.LotNumber == "" {
+		return fmt.Errorf("invalid lot_number: product [%s] is lot-tracked, lot_number is required", product.SKU)
+	}
+
+	if !inbound && hasDates {
+		return fmt.Errorf("invalid expires_at: lot dates are only accepted on inbound movements")
+	}
+
+	if req.MovementType == MovementTypeTransfer && req.LotNumber != "" && req.ToWarehouseID == req.WarehouseID {
+		return fmt.Errorf("invalid lot_number: lots are tracked per warehouse, a transfer inside the warehouse does not move lots")
+	}
+	return nil
+}
+
+// valida las series contra el producto: solo los serializados aceptan series
+// y cada unidad que entra, sale o cambia de almacen debe traer su serie
+func validateSerialRequest(req CreateMovementRequest, product *ProductInfo) error {
+	if !product.IsSerialized {
+		if len(req.Serials) > 0 {
+			return fmt.Errorf("invalid serials: product [%s] is not serialized", product.SKU)
+		}
+		return nil
+	}
+
+	// una transferencia dentro del almacen o un cambio de estado no cambian el estado de las series
+	if req.MovementType == MovementTypeStatusChange || (req.MovementType == MovementTypeTransfer && req.ToWarehouseID == req.WarehouseID) {
+		if len(req.Serials) > 0 {
+			return fmt.Errorf("invalid serials: serials are tracked per warehouse, a transfer inside the warehouse does not move serials")
+		}
+		return nil
+	}
+
+	units := req.Quantity
+	if units < 0 {
+		units = -units
+	}
+	if len(req.Serials) != units {
+		return fmt.Errorf("invalid serials: product [%s] is serialized, expected %d serial numbers, got %d", product.SKU, units, len(req.Serials))
+	}
+
+	seen := make(map[string]bool, len(req.Serials))
+	for _, serial := range req.Serials {
+		if serial == "" {
+			return fmt.Errorf("invalid serials: serial numbers must not be empty")
+		}
+		if seen[serial] {
+			return fmt.Errorf("invalid serials: [%s] is repeated", serial)
+		}
+		seen[serial] = true
+	}
+	return nil
+}
+
+// convierte una fecha YYYY-MM-DD opcional
+func parseDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, err
+	}
+	return &date, nil
+}
+
+// compara dos fechas sin importar la hora ni la zona
+func sameDate(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Format("2006-01-02") == b.Format("2006-01-02")
+}
+
+// verifica que el codigo de razon exista, este activo y permita la direccion del ajuste
+// en los demas tipos el codigo es opcional y solo se valida que exista
+// los movimientos internos (req.system) pueden usar los codigos de sistema, que estan inactivos
+func (s *Service) validateReasonCode(ctx context.Context, req CreateMovementRequest) error {
+	if req.ReasonCode == "" {
+		return nil
+	}
+
+	reasonCode, err := s.repo.GetReasonCode(ctx, req.ReasonCode)
+	if err != nil {
+		return err
+	}
+
+	if !reasonCode.IsActive && !req.system {
+		return fmt.Errorf("invalid reason_code: [%s] is inactive", reasonCode.Code)
+	}
+
+	if req.MovementType != MovementTypeAdjust {
+		return nil
+	}
+
+	if req.Quantity > 0 && reasonCode.Direction == "DECREASE" {
+		return fmt.Errorf("invalid reason_code: [%s] only allows negative adjustments", reasonCode.Code)
+	}
+	if req.Quantity < 0 && reasonCode.Direction == "INCREASE" {
+		return fmt.Errorf("invalid reason_code: [%s] only allows positive adjustments", reasonCode.Code)
+	}
 	return nil
 }
 
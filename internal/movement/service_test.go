@@ -0,0 +1,256 @@
+package movement
+
+import (
+	"strings"
+	"testing"
+)
+
+func intPtr(value int) *int { return &value }
+
+func floatPtr(value float64) *float64 { return &value }
+
+func statusPtr(status StockStatus) *StockStatus { return &status }
+
+func TestReversalRequest(t *testing.T) {
+	tests := []struct {
+		name     string
+		original Movement
+		check    func(t *testing.T, req CreateMovementRequest, entered uomQuantity)
+	}{
+		{
+			name: "IN se revierte con un OUT del mismo bin",
+			original: Movement{
+				ID: 10, ProductID: 1, WarehouseID: 2, MovementType: MovementTypeIn, Quantity: 12, UoM: "BOX", UoMQuantity: 1,
+				ToLocationID: intPtr(5), UnitCost: floatPtr(3.5),
+			},
+			check: func(t *testing.T, req CreateMovementRequest, entered uomQuantity) {
+				if req.MovementType != MovementTypeOut || req.Quantity != 12 {
+					t.Errorf("got %s %d, want OUT 12", req.MovementType, req.Quantity)
+				}
+				if req.FromLocationID == nil || *req.FromLocationID != 5 || req.ToLocationID != nil {
+					t.Errorf("got from %v to %v, want from 5", req.FromLocationID, req.ToLocationID)
+				}
+				if req.OverrideStatus {
+					t.Error("OUT of available stock must not override status")
+				}
+				if entered.uom != "BOX" || entered.quantity != 1 {
+					t.Errorf("got entered %+v, want 1 BOX", entered)
+				}
+			},
+		},
+		{
+			name: "IN a un estado retenido saca stock no disponible",
+			original: Movement{
+				ID: 11, ProductID: 1, WarehouseID: 2, MovementType: MovementTypeIn, Quantity: 4, UoM: baseUoM, UoMQuantity: 4,
+				ToStatus: statusPtr(StockStatusQuarantine),
+			},
+			check: func(t *testing.T, req CreateMovementRequest, _ uomQuantity) {
+				if req.FromStatus != StockStatusQuarantine || !req.OverrideStatus {
+					t.Errorf("got from_status %q override %v, want QUARANTINE with override", req.FromStatus, req.OverrideStatus)
+				}
+			},
+		},
+		{
+			name: "OUT se revierte con un IN al costo de la salida",
+			original: Movement{
+				ID: 12, ProductID: 1, WarehouseID: 2, MovementType: MovementTypeOut, Quantity: 3, UoM: baseUoM, UoMQuantity: 3,
+				FromLocationID: intPtr(7), FromStatus: statusPtr(StockStatusDamaged), UnitCost: floatPtr(2.25),
+			},
+			check: func(t *testing.T, req CreateMovementRequest, _ uomQuantity) {
+				if req.MovementType != MovementTypeIn || req.Quantity != 3 {
+					t.Errorf("got %s %d, want IN 3", req.MovementType, req.Quantity)
+				}
+				if req.ToLocationID == nil || *req.ToLocationID != 7 {
+					t.Errorf("got to_location %v, want 7", req.ToLocationID)
+				}
+				if req.ToStatus != StockStatusDamaged {
+					t.Errorf("got to_status %q, want DAMAGED", req.ToStatus)
+				}
+				if req.UnitCost == nil || *req.UnitCost != 2.25 {
+					t.Errorf("got unit_cost %v, want 2.25", req.UnitCost)
+				}
+			},
+		},
+		{
+			name: "ADJUST negativo se revierte con uno positivo",
+			original: Movement{
+				ID: 13, ProductID: 1, WarehouseID: 2, MovementType: MovementTypeAdjust, Quantity: -24, UoM: "BOX", UoMQuantity: -2,
+				FromLocationID: intPtr(8), UnitCost: floatPtr(1.5),
+			},
+			check: func(t *testing.T, req CreateMovementRequest, entered uomQuantity) {
+				if req.MovementType != MovementTypeAdjust || req.Quantity != 24 || entered.quantity != 2 {
+					t.Errorf("got %s %d (%d entered), want ADJUST 24 (2 entered)", req.MovementType, req.Quantity, entered.quantity)
+				}
+				if req.ToLocationID == nil || *req.ToLocationID != 8 || req.FromLocationID != nil {
+					t.Errorf("got from %v to %v, want to 8", req.FromLocationID, req.ToLocationID)
+				}
+				if req.UnitCost == nil || *req.UnitCost != 1.5 {
+					t.Errorf("got unit_cost %v, want 1.5", req.UnitCost)
+				}
+			},
+		},
+		{
+			name: "ADJUST positivo se revierte con uno negativo sin costo",
+			original: Movement{
+				ID: 14, ProductID: 1, WarehouseID: 2, MovementType: MovementTypeAdjust, Quantity: 5, UoM: baseUoM, UoMQuantity: 5,
+				ToStatus: statusPtr(StockStatusOnHold), UnitCost: floatPtr(4),
+			},
+			check: func(t *testing.T, req CreateMovementRequest, _ uomQuantity) {
+				if req.Quantity != -5 {
+					t.Errorf("got quantity %d, want -5", req.Quantity)
+				}
+				if req.FromStatus != StockStatusOnHold || req.ToStatus != "" {
+					t.Errorf("got from %q to %q, want from ON_HOLD", req.FromStatus, req.ToStatus)
+				}
+				if req.UnitCost != nil {
+					t.Errorf("got unit_cost %v, want nil", *req.UnitCost)
+				}
+			},
+		},
+		{
+			name: "TRANSFER invierte almacenes y bins",
+			original: Movement{
+				ID: 15, ProductID: 1, WarehouseID: 2, ToWarehouseID: intPtr(3), MovementType: MovementTypeTransfer, Quantity: 6, UoM: baseUoM, UoMQuantity: 6,
+				FromLocationID: intPtr(20), ToLocationID: intPtr(30),
+			},
+			check: func(t *testing.T, req CreateMovementRequest, _ uomQuantity) {
+				if req.MovementType != MovementTypeTransfer || req.Quantity != 6 {
+					t.Errorf("got %s %d, want TRANSFER 6", req.MovementType, req.Quantity)
+				}
+				if req.WarehouseID != 3 || req.ToWarehouseID != 2 {
+					t.Errorf("got warehouse %d -> %d, want 3 -> 2", req.WarehouseID, req.ToWarehouseID)
+				}
+				if req.FromLocationID == nil || *req.FromLocationID != 30 || req.ToLocationID == nil || *req.ToLocationID != 20 {
+					t.Errorf("got location %v -> %v, want 30 -> 20", req.FromLocationID, req.ToLocationID)
+				}
+			},
+		},
+		{
+			name: "STATUS_CHANGE invierte los estados",
+			original: Movement{
+				ID: 16, ProductID: 1, WarehouseID: 2, MovementType: MovementTypeStatusChange, Quantity: 2, UoM: baseUoM, UoMQuantity: 2,
+				ToStatus: statusPtr(StockStatusQuarantine),
+			},
+			check: func(t *testing.T, req CreateMovementRequest, _ uomQuantity) {
+				if req.MovementType != MovementTypeStatusChange || req.Quantity != 2 {
+					t.Errorf("got %s %d, want STATUS_CHANGE 2", req.MovementType, req.Quantity)
+				}
+				if req.FromStatus != StockStatusQuarantine || req.ToStatus != "" {
+					t.Errorf("got %q -> %q, want QUARANTINE -> AVAILABLE", req.FromStatus, req.ToStatus)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, entered := reversalRequest(&tt.original, nil, nil, ReverseMovementRequest{CreatedBy: "admin@example.com"})
+
+			if req.reversalOf == nil || *req.reversalOf != tt.original.ID {
+				t.Errorf("got reversal_of %v, want %d", req.reversalOf, tt.original.ID)
+			}
+			if req.ReasonCode != reversalReasonCode || !req.system {
+				t.Errorf("got reason_code %q system %v, want %s as system code", req.ReasonCode, req.system, reversalReasonCode)
+			}
+			if req.ProductID != tt.original.ProductID || req.CreatedBy != "admin@example.com" {
+				t.Errorf("got product %d created_by %q", req.ProductID, req.CreatedBy)
+			}
+			if want := "reversal of movement"; !strings.HasPrefix(req.Reason, want) {
+				t.Errorf("got reason %q, want default %q", req.Reason, want)
+			}
+			tt.check(t, req, entered)
+		})
+	}
+}
+
+func TestReversalRequestLotsAndSerials(t *testing.T) {
+	original := &Movement{ID: 20, ProductID: 1, WarehouseID: 2, MovementType: MovementTypeIn, Quantity: 2, UoM: baseUoM, UoMQuantity: 2}
+	lots := []MovementLot{{LotID: 4, LotNumber: "L-001", Quantity: 2}}
+	serials := []string{"SN-1", "SN-2"}
+
+	req, _ := reversalRequest(original, lots, serials, ReverseMovementRequest{Reason: "wrong receipt"})
+
+	if req.LotNumber != "L-001" || len(req.lots) != 1 || req.lots[0].LotID != 4 {
+		t.Errorf("got lot %q %+v, want the original lot L-001", req.LotNumber, req.lots)
+	}
+	if len(req.Serials) != 2 || req.Serials[0] != "SN-1" {
+		t.Errorf("got serials %v, want %v", req.Serials, serials)
+	}
+	if req.Reason != "wrong receipt" {
+		t.Errorf("got reason %q, want wrong receipt", req.Reason)
+	}
+}
+
+func TestCheckReversible(t *testing.T) {
+	reconciliation := reconciliationReasonCode
+	tests := []struct {
+		name     string
+		original Movement
+		wantErr  string
+	}{
+		{name: "movimiento sin revertir", original: Movement{ID: 1}},
+		{name: "reversion de otro movimiento", original: Movement{ID: 2, ReversalOfID: intPtr(1)}, wantErr: "it is the reversal of movement 1"},
+		{name: "ya revertido", original: Movement{ID: 3, ReversedByID: intPtr(4)}, wantErr: "already reversed by movement 4"},
+		{name: "ajuste de conciliacion", original: Movement{ID: 5, ReasonCode: &reconciliation}, wantErr: "reconciliation adjustments"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkReversible(&tt.original)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) || !strings.Contains(err.Error(), "cannot") {
+				t.Fatalf("got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckAvailableStock(t *testing.T) {
+	tests := []struct {
+		name     string
+		onHand   int
+		reserved int
+		held     int
+		quantity int
+		wantErr  string
+	}{
+		{name: "transferencia de stock libre", onHand: 10, reserved: 3, held: 2, quantity: 5},
+		{name: "transferencia que toma unidades reservadas", onHand: 10, reserved: 4, held: 0, quantity: 7, wantErr: "available=6 (on_hand=10, reserved=4, held=0), request=7"},
+		{name: "transferencia que toma unidades retenidas", onHand: 10, reserved: 0, held: 5, quantity: 6, wantErr: "available=5 (on_hand=10, reserved=0, held=5), request=6"},
+		{name: "reservado y retenido mayores que la existencia", onHand: 4, reserved: 3, held: 3, quantity: 1, wantErr: "available=0 (on_hand=4, reserved=3, held=3), request=1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkAvailableStock(tt.onHand, tt.reserved, tt.held, tt.quantity)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) || !strings.HasPrefix(err.Error(), "insufficient stock") {
+				t.Fatalf("got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReconciliationRequest(t *testing.T) {
+	req := ReconciliationRequest(1, 2, -3, 9, "admin@example.com")
+
+	if req.MovementType != MovementTypeAdjust || req.Quantity != -3 || req.WarehouseID != 2 {
+		t.Errorf("got %s %d in warehouse %d, want ADJUST -3 in warehouse 2", req.MovementType, req.Quantity, req.WarehouseID)
+	}
+	if req.ReasonCode != reconciliationReasonCode || !req.system || !req.ledgerOnly {
+		t.Errorf("got reason_code %q system %v ledger_only %v, want %s as a ledger-only system code", req.ReasonCode, req.system, req.ledgerOnly, reconciliationReasonCode)
+	}
+	if req.Reason != "stock reconciliation #9" {
+		t.Errorf("got reason %q, want stock reconciliation #9", req.Reason)
+	}
+}
@@ -0,0 +1,191 @@
+package picking
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+type Handler struct {
+	service *Service
+	logger  zerolog.Logger
+}
+
+func NewHandler(service *Service, logger zerolog.Logger) *Handler {
+	moduleLogger := logger.With().Str("module", "picking").Logger()
+	return &Handler{
+		service: service,
+		logger:  moduleLogger,
+	}
+}
+
+// Create maneja POST /waves
+// agrupa las ordenes y responde con la lista de surtido generada
+func (h *Handler) Create(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	var req CreateWaveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn().Err(err).Msg("Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if email, exists := c.Get("email"); exists {
+		req.CreatedBy = email.(string)
+	}
+
+	wave, err := h.service.CreateWave(ctx, req)
+	if err != nil {
+		h.respondError(c, err, 0, "Error creating wave")
+		return
+	}
+
+	c.JSON(http.StatusCreated, wave)
+}
+
+// List maneja GET /waves
+func (h *Handler) List(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	var filters ListFilters
+	if err := c.ShouldBindQuery(&filters); err != nil {
+		h.logger.Warn().Err(err).Msg("Invalid query parameters for waves")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+
+	response, err := h.service.ListWaves(ctx, filters)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error().Err(err).Msg("Error listing waves")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error listing waves"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetByID maneja GET /waves/:id (incluye la lista de surtido en orden de recorrido)
+func (h *Handler) GetByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID, must be a number"})
+		return
+	}
+
+	wave, err := h.service.GetWave(c.Request.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error().Err(err).Int("wave_id", id).Msg("Error getting wave")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, wave)
+}
+
+// ConfirmPick maneja POST /waves/:id/tasks/:taskId/confirm
+func (h *Handler) ConfirmPick(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID, must be a number"})
+		return
+	}
+
+	taskID, err := strconv.Atoi(c.Param("taskId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID, must be a number"})
+		return
+	}
+
+	var req ConfirmPickRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if email, exists := c.Get("email"); exists {
+		req.PickedBy = email.(string)
+	}
+
+	if err := h.service.ConfirmPick(ctx, id, taskID, req); err != nil {
+		h.respondError(c, err, id, "Error confirming pick")
+		return
+	}
+
+	h.respondWave(c, id)
+}
+
+// Cancel maneja POST /waves/:id/cancel
+func (h *Handler) Cancel(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID, must be a number"})
+		return
+	}
+
+	if err := h.service.CancelWave(c.Request.Context(), id); err != nil {
+		h.respondError(c, err, id, "Error cancelling wave")
+		return
+	}
+
+	h.respondWave(c, id)
+}
+
+// respondError traduce los errores de las acciones sobre una ola
+// falta de stock o un estado que no permite la accion es un conflicto, no un error de datos
+func (h *Handler) respondError(c *gin.Context, err error, id int, message string) {
+	switch {
+	case strings.Contains(err.Error(), "cannot") || strings.Contains(err.Error(), "insufficient stock"):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "wave with id") || strings.Contains(err.Error(), "pick task ["):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "not found") ||
+		strings.Contains(err.Error(), "required"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error().Err(err).Int("wave_id", id).Msg(message)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+// responde con la ola actualizada
+func (h *Handler) respondWave(c *gin.Context, id int) {
+	wave, err := h.service.GetWave(c.Request.Context(), id)
+	if err != nil {
+		h.logger.Error().Err(err).Int("wave_id", id).Msg("Error getting wave")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, wave)
+}
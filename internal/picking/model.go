@@ -0,0 +1,133 @@
+package picking
+
+import "time"
+
+// WaveStatus es el estado de una ola de surtido
+// OPEN mientras tenga tareas pendientes -> COMPLETED; CANCELLED descarta las tareas pendientes
+type WaveStatus string
+
+const (
+	WaveStatusOpen      WaveStatus = "OPEN"
+	WaveStatusCompleted WaveStatus = "COMPLETED"
+	WaveStatusCancelled WaveStatus = "CANCELLED"
+)
+
+// isValid verifica si el estado es valido
+func (st WaveStatus) IsValid() bool {
+	return st == WaveStatusOpen || st == WaveStatusCompleted || st == WaveStatusCancelled
+}
+
+// TaskStatus es el estado de una tarea de surtido
+// SHORT: se confirmo con menos de lo pedido (faltante en el bin)
+type TaskStatus string
+
+const (
+	TaskStatusPending   TaskStatus = "PENDING"
+	TaskStatusPicked    TaskStatus = "PICKED"
+	TaskStatusShort     TaskStatus = "SHORT"
+	TaskStatusCancelled TaskStatus = "CANCELLED"
+)
+
+// Wave agrupa ordenes de venta asignadas de un almacen para surtirlas en un solo recorrido
+// lo surtido se lleva a la ubicacion de preparacion (staging) de la ola
+type Wave struct {
+	ID                int        `json:"id" db:"id"`
+	WarehouseID       int        `json:"warehouse_id" db:"warehouse_id"`
+	StagingLocationID int        `json:"staging_location_id" db:"staging_location_id"`
+	StagingPath       string     `json:"staging_path" db:"staging_path"`
+	Status            WaveStatus `json:"status" db:"status"`
+	Notes             *string    `json:"notes,omitempty" db:"notes"`
+	CreatedBy         string     `json:"created_by,omitempty" db:"created_by"`
+	CompletedAt       *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+	CancelledAt       *time.Time `json:"cancelled_at,omitempty" db:"cancelled_at"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at" db:"updated_at"`
+
+	SalesOrderIDs []int  `json:"sales_order_ids,omitempty" db:"-"`
+	Tasks         []Task `json:"tasks,omitempty" db:"-"` // lista de surtido en orden de recorrido
+}
+
+// Task es lo que hay que tomar de una ubicacion para una linea de venta
+// location_id nulo: el stock sin ubicar del almacen
+type Task struct {
+	ID               int        `json:"id" db:"id"`
+	WaveID           int        `json:"wave_id" db:"wave_id"`
+	Sequence         int        `json:"sequence" db:"sequence"` // orden de recorrido
+	SalesOrderID     int        `json:"sales_order_id" db:"sales_order_id"`
+	SalesOrderLineID int        `json:"sales_order_line_id" db:"sales_order_line_id"`
+	ProductID        int        `json:"product_id" db:"product_id"`
+	SKU              string     `json:"sku" db:"sku"`
+	LocationID       *int       `json:"location_id,omitempty" db:"location_id"`
+	LocationPath     *string    `json:"location_path,omitempty" db:"location_path"`
+	Quantity         int        `json:"quantity" db:"quantity"` // en la unidad base
+	PickedQuantity   int        `json:"picked_quantity" db:"picked_quantity"`
+	Status           TaskStatus `json:"status" db:"status"`
+	MovementID       *int       `json:"movement_id,omitempty" db:"movement_id"` // TRANSFER al staging
+	PickedBy         string     `json:"picked_by,omitempty" db:"picked_by"`
+	PickedAt         *time.Time `json:"picked_at,omitempty" db:"picked_at"`
+}
+
+type CreateWaveRequest struct {
+	SalesOrderIDs     []int  `json:"sales_order_ids" binding:"required,min=1,dive,min=1"`
+	StagingLocationID int    `json:"staging_location_id" binding:"required,min=1"`
+	Notes             string `json:"notes" binding:"max=255"`
+	CreatedBy         string `json:"-"`
+}
+
+// ConfirmPickRequest confirma una tarea, picked_quantity menor a lo pedido es un surtido corto
+type ConfirmPickRequest struct {
+	PickedQuantity *int   `json:"picked_quantity" binding:"required,min=0"`
+	PickedBy       string `json:"-"`
+}
+
+// ListFilters son los filtros opcionales de GET /waves
+type ListFilters struct {
+	Status      string `form:"status"`
+	WarehouseID *int   `form:"warehouse_id"`
+	Page        int    `form:"page"`
+	PageSize    int    `form:"page_size"`
+}
+
+type PaginatedResponse struct {
+	Data       interface{} `json:"data"`
+	Pagination Pagination  `json:"pagination"`
+}
+
+type Pagination struct {
+	Page       int `json:"page"`
+	PageSize   int `json:"page_size"`
+	Total      int `json:"total"`
+	TotalPages int `json:"total_pages"`
+}
+
+// orderRow es una orden de venta candidata a la ola
+type orderRow struct {
+	ID          int    `db:"id"`
+	WarehouseID int    `db:"warehouse_id"`
+	Status      string `db:"status"`
+}
+
+// lineRow es una linea de venta con lo que falta por surtir (quantity - lo surtido en olas anteriores)
+type lineRow struct {
+	ID           int    `db:"id"`
+	SalesOrderID int    `db:"sales_order_id"`
+	ProductID    int    `db:"product_id"`
+	SKU          string `db:"sku"`
+	Remaining    int    `db:"remaining"`
+}
+
+// binStock es el stock libre de un producto en un bin: lo que hay menos lo comprometido en tareas pendientes
+type binStock struct {
+	LocationID int    `db:"location_id"`
+	Path       string `db:"path"`
+	Quantity   int    `db:"quantity"`
+}
+
+// LocationInfo son los datos de la ubicacion de preparacion que se validan al crear la ola
+type LocationInfo struct {
+	ID           int    `db:"id"`
+	WarehouseID  int    `db:"warehouse_id"`
+	LocationType string `db:"location_type"`
+	Path         string `db:"path"`
+	IsActive     bool   `db:"is_active"`
+}
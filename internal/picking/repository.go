@@ -0,0 +1,400 @@
+package picking
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog"
+)
+
+const waveColumns = `
+	w.id, w.warehouse_id, w.staging_location_id, l.path AS staging_path, w.status, w.notes,
+	COALESCE(w.created_by, '') AS created_by, w.completed_at, w.cancelled_at, w.created_at, w.updated_at
+`
+
+type Repository struct {
+	db     *sqlx.DB
+	logger zerolog.Logger
+}
+
+func NewRepository(db *sqlx.DB, logger zerolog.Logger) *Repository {
+	moduleLogger := logger.With().Str("module", "picking").Logger()
+	return &Repository{db: db, logger: moduleLogger}
+}
+
+// bloquea las ordenes de venta en orden de id
+func (r *Repository) GetOrdersForUpdate(ctx context.Context, tx *sqlx.Tx, ids []int) ([]orderRow, error) {
+	query := `
+		SELECT id, warehouse_id, status
+		FROM sales_orders
+		WHERE id = ANY($1)
+		ORDER BY id
+		FOR UPDATE
+	`
+
+	var orders []orderRow
+	if err := tx.SelectContext(ctx, &orders, query, pq.Array(ids)); err != nil {
+		return nil, fmt.Errorf("error getting sales orders with lock: %w", err)
+	}
+	return orders, nil
+}
+
+// ordenes que ya estan en una ola abierta
+func (r *Repository) OrdersInOpenWave(ctx context.Context, tx *sqlx.Tx, ids []int) ([]int, error) {
+	query := `
+		SELECT wo.sales_order_id
+		FROM wave_orders wo
+		JOIN waves w ON w.id = wo.wave_id
+		WHERE w.status = 'OPEN' AND wo.sales_order_id = ANY($1)
+		ORDER BY wo.sales_order_id
+	`
+
+	var busy []int
+	if err := tx.SelectContext(ctx, &busy, query, pq.Array(ids)); err != nil {
+		return nil, fmt.Errorf("error checking open waves: %w", err)
+	}
+	return busy, nil
+}
+
+func (r *Repository) GetLocation(ctx context.Context, locationID int) (*LocationInfo, error) {
+	var location LocationInfo
+	query := `SELECT id, warehouse_id, location_type, path, is_active FROM locations WHERE id = $1`
+
+	if err := r.db.GetContext(ctx, &location, query, locationID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("invalid staging_location_id: location with id [%d] not found", locationID)
+		}
+		return nil, fmt.Errorf("error getting location: %w", err)
+	}
+	return &location, nil
+}
+
+// lineas de las ordenes con lo que falta por surtir (lo surtido en olas anteriores ya esta en staging)
+func (r *Repository) ListRemainingLines(ctx context.Context, tx *sqlx.Tx, orderIDs []int) ([]lineRow, error) {
+	query := `
+		SELECT sl.id, sl.sales_order_id, sl.product_id, p.sku,
+			sl.quantity - COALESCE((
+				SELECT SUM(pt.picked_quantity) FROM pick_tasks pt
+				WHERE pt.sales_order_line_id = sl.id AND pt.status IN ('PICKED', 'SHORT')
+			), 0) AS remaining
+		FROM sales_order_lines sl
+		JOIN products p ON p.id = sl.product_id
+		WHERE sl.sales_order_id = ANY($1)
+		ORDER BY sl.product_id, sl.sales_order_id
+	`
+
+	var lines []lineRow
+	if err := tx.SelectContext(ctx, &lines, query, pq.Array(orderIDs)); err != nil {
+		return nil, fmt.Errorf("error listing sales order lines: %w", err)
+	}
+	return lines, nil
+}
+
+// bloquea el stock del producto en el almacen (mismo lock que los movimientos)
+// serializa la generacion de tareas de olas que compiten por los mismos bins
+func (r *Repository) LockStockLevel(ctx context.Context, tx *sqlx.Tx, productID, warehouseID int) (int, error) {
+	var stock int
+	query := `
+		SELECT quantity
+		FROM stock_levels
+		WHERE product_id = $1 AND warehouse_id = $2
+		FOR UPDATE
+	`
+
+	err := tx.GetContext(ctx, &stock, query, productID, warehouseID)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, fmt.Errorf("error getting stock level with lock: %w", err)
+	}
+	return stock, nil
+}
+
+// stock libre del producto en los bins activos del almacen, en orden de recorrido (path)
+// se descuenta lo comprometido en tareas pendientes de otras olas; el staging no es origen de surtido
+func (r *Repository) ListBinStock(ctx context.Context, tx *sqlx.Tx, productID, warehouseID, stagingLocationID int) ([]binStock, error) {
+	query := `
+		SELECT ls.location_id, l.path,
+			ls.quantity - COALESCE((
+				SELECT SUM(pt.quantity) FROM pick_tasks pt
+				WHERE pt.product_id = ls.product_id AND pt.location_id = ls.location_id AND pt.status = 'PENDING'
+			), 0) AS quantity
+		FROM location_stock ls
+		JOIN locations l ON l.id = ls.location_id
+		WHERE ls.product_id = $1 AND l.warehouse_id = $2 AND l.id <> $3
+			AND l.is_active AND l.location_type = 'BIN' AND ls.quantity > 0
+		ORDER BY l.path
+	`
+
+	var bins []binStock
+	if err := tx.SelectContext(ctx, &bins, query, productID, warehouseID, stagingLocationID); err != nil {
+		return nil, fmt.Errorf("error listing bin stock: %w", err)
+	}
+	return bins, nil
+}
+
+// stock sin ubicar del producto en el almacen menos lo comprometido en tareas pendientes sin ubicacion
+func (r *Repository) GetUnlocatedStock(ctx context.Context, tx *sqlx.Tx, productID, warehouseID, warehouseStock int) (int, error) {
+	var located int
+	query := `
+		SELECT COALESCE(SUM(ls.quantity), 0)
+		FROM location_stock ls
+		JOIN locations l ON l.id = ls.location_id
+		WHERE ls.product_id = $1 AND l.warehouse_id = $2
+	`
+	if err := tx.GetContext(ctx, &located, query, productID, warehouseID); err != nil {
+		return 0, fmt.Errorf("error getting located stock: %w", err)
+	}
+
+	var pending int
+	pendingQuery := `
+		SELECT COALESCE(SUM(pt.quantity), 0)
+		FROM pick_tasks pt
+		JOIN waves w ON w.id = pt.wave_id
+		WHERE pt.product_id = $1 AND w.warehouse_id = $2 AND pt.location_id IS NULL AND pt.status = 'PENDING'
+	`
+	if err := tx.GetContext(ctx, &pending, pendingQuery, productID, warehouseID); err != nil {
+		return 0, fmt.Errorf("error getting pending pick tasks: %w", err)
+	}
+
+	return warehouseStock - located - pending, nil
+}
+
+func (r *Repository) CreateWave(ctx context.Context, tx *sqlx.Tx, warehouseID int, req CreateWaveRequest) (int, error) {
+	var id int
+	query := `
+		INSERT INTO waves (warehouse_id, staging_location_id, notes, created_by)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''))
+		RETURNING id
+	`
+
+	if err := tx.GetContext(ctx, &id, query, warehouseID, req.StagingLocationID, req.Notes, req.CreatedBy); err != nil {
+		return 0, fmt.Errorf("error creating wave: %w", err)
+	}
+
+	ordersQuery := `INSERT INTO wave_orders (wave_id, sales_order_id) SELECT $1, unnest($2::int[])`
+	if _, err := tx.ExecContext(ctx, ordersQuery, id, pq.Array(req.SalesOrderIDs)); err != nil {
+		return 0, fmt.Errorf("error adding orders to wave: %w", err)
+	}
+	return id, nil
+}
+
+func (r *Repository) CreateTask(ctx context.Context, tx *sqlx.Tx, task Task) error {
+	query := `
+		INSERT INTO pick_tasks (wave_id, sequence, sales_order_id, sales_order_line_id, product_id, location_id, quantity)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err := tx.ExecContext(ctx, query, task.WaveID, task.Sequence, task.SalesOrderID, task.SalesOrderLineID,
+		task.ProductID, task.LocationID, task.Quantity)
+	if err != nil {
+		return fmt.Errorf("error creating pick task: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) GetWave(ctx context.Context, id int) (*Wave, error) {
+	query := `SELECT ` + waveColumns + ` FROM waves w JOIN locations l ON l.id = w.staging_location_id WHERE w.id = $1`
+
+	var wave Wave
+	if err := r.db.GetContext(ctx, &wave, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("wave with id [%d] not found", id)
+		}
+		return nil, fmt.Errorf("error getting wave: %w", err)
+	}
+	return &wave, nil
+}
+
+// obtiene la ola con LOCK PESIMISTA
+func (r *Repository) GetWaveForUpdate(ctx context.Context, tx *sqlx.Tx, id int) (*Wave, error) {
+	query := `SELECT ` + waveColumns + ` FROM waves w JOIN locations l ON l.id = w.staging_location_id
+		WHERE w.id = $1 FOR UPDATE OF w`
+
+	var wave Wave
+	if err := tx.GetContext(ctx, &wave, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("wave with id [%d] not found", id)
+		}
+		return nil, fmt.Errorf("error getting wave: %w", err)
+	}
+	return &wave, nil
+}
+
+func (r *Repository) ListWaves(ctx context.Context, filters ListFilters) ([]Wave, int, error) {
+	var conditions []string
+	var args []interface{}
+	argPosition := 1
+
+	if filters.Status != "" {
+		conditions = append(conditions, fmt.Sprintf("w.status = $%d", argPosition))
+		args = append(args, filters.Status)
+		argPosition++
+	}
+
+	if filters.WarehouseID != nil {
+		conditions = append(conditions, fmt.Sprintf("w.warehouse_id = $%d", argPosition))
+		args = append(args, *filters.WarehouseID)
+		argPosition++
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := r.db.GetContext(ctx, &total, "SELECT COUNT(*) FROM waves w"+where, args...); err != nil {
+		return nil, 0, fmt.Errorf("error counting waves: %w", err)
+	}
+
+	offset := (filters.Page - 1) * filters.PageSize
+	query := "SELECT " + waveColumns + " FROM waves w JOIN locations l ON l.id = w.staging_location_id" + where +
+		fmt.Sprintf(" ORDER BY w.created_at DESC, w.id DESC LIMIT $%d OFFSET $%d", argPosition, argPosition+1)
+	args = append(args, filters.PageSize, offset)
+
+	var waves []Wave
+	if err := r.db.SelectContext(ctx, &waves, query, args...); err != nil {
+		return nil, 0, fmt.Errorf("error listing waves: %w", err)
+	}
+	return waves, total, nil
+}
+
+func (r *Repository) ListWaveOrderIDs(ctx context.Context, q sqlx.QueryerContext, waveID int) ([]int, error) {
+	query := `SELECT sales_order_id FROM wave_orders WHERE wave_id = $1 ORDER BY sales_order_id`
+
+	var ids []int
+	if err := sqlx.SelectContext(ctx, q, &ids, query, waveID); err != nil {
+		return nil, fmt.Errorf("error listing wave orders: %w", err)
+	}
+	return ids, nil
+}
+
+// lista de surtido de la ola en orden de recorrido
+func (r *Repository) ListTasks(ctx context.Context, waveID int) ([]Task, error) {
+	query := `
+		SELECT pt.id, pt.wave_id, pt.sequence, pt.sales_order_id, pt.sales_order_line_id, pt.product_id, p.sku,
+			pt.location_id, l.path AS location_path, pt.quantity, pt.picked_quantity, pt.status, pt.movement_id,
+			COALESCE(pt.picked_by, '') AS picked_by, pt.picked_at
+		FROM pick_tasks pt
+		JOIN products p ON p.id = pt.product_id
+		LEFT JOIN locations l ON l.id = pt.location_id
+		WHERE pt.wave_id = $1
+		ORDER BY pt.sequence
+	`
+
+	var tasks []Task
+	if err := r.db.SelectContext(ctx, &tasks, query, waveID); err != nil {
+		return nil, fmt.Errorf("error listing pick tasks: %w", err)
+	}
+	return tasks, nil
+}
+
+// obtiene la tarea de la ola con LOCK PESIMISTA
+func (r *Repository) GetTaskForUpdate(ctx context.Context, tx *sqlx.Tx, waveID, taskID int) (*Task, error) {
+	query := `
+		SELECT pt.id, pt.wave_id, pt.sequence, pt.sales_order_id, pt.sales_order_line_id, pt.product_id, p.sku,
+			pt.location_id, pt.quantity, pt.picked_quantity, pt.status, pt.movement_id,
+			COALESCE(pt.picked_by, '') AS picked_by, pt.picked_at
+		FROM pick_tasks pt
+		JOIN products p ON p.id = pt.product_id
+		WHERE pt.id = $1 AND pt.wave_id = $2
+		FOR UPDATE OF pt
+	`
+
+	var task Task
+	if err := tx.GetContext(ctx, &task, query, taskID, waveID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("pick task [%d] not found in wave %d", taskID, waveID)
+		}
+		return nil, fmt.Errorf("error getting pick task: %w", err)
+	}
+	return &task, nil
+}
+
+// estado de la orden de venta con LOCK PESIMISTA
+func (r *Repository) GetOrderStatusForUpdate(ctx context.Context, tx *sqlx.Tx, orderID int) (string, error) {
+	var status string
+	query := `SELECT status FROM sales_orders WHERE id = $1 FOR UPDATE`
+
+	if err := tx.GetContext(ctx, &status, query, orderID); err != nil {
+		return "", fmt.Errorf("error getting sales order status: %w", err)
+	}
+	return status, nil
+}
+
+func (r *Repository) ConfirmTask(ctx context.Context, tx *sqlx.Tx, taskID, picked int, status TaskStatus, movementID *int, pickedBy string) error {
+	query := `
+		UPDATE pick_tasks
+		SET picked_quantity = $1, status = $2, movement_id = $3, picked_by = NULLIF($4, ''), picked_at = CURRENT_TIMESTAMP
+		WHERE id = $5
+	`
+
+	if _, err := tx.ExecContext(ctx, query, picked, status, movementID, pickedBy, taskID); err != nil {
+		return fmt.Errorf("error confirming pick task: %w", err)
+	}
+	return nil
+}
+
+// tareas pendientes de la ola; con orderID > 0 solo las de esa orden
+func (r *Repository) CountPendingTasks(ctx context.Context, tx *sqlx.Tx, waveID, orderID int) (int, error) {
+	var count int
+	query := `
+		SELECT COUNT(*) FROM pick_tasks
+		WHERE wave_id = $1 AND status = 'PENDING' AND ($2 = 0 OR sales_order_id = $2)
+	`
+
+	if err := tx.GetContext(ctx, &count, query, waveID, orderID); err != nil {
+		return 0, fmt.Errorf("error counting pending pick tasks: %w", err)
+	}
+	return count, nil
+}
+
+// verifica que todas las lineas de la orden esten surtidas completas (sumando todas las olas)
+func (r *Repository) OrderFullyPicked(ctx context.Context, tx *sqlx.Tx, orderID int) (bool, error) {
+	var short bool
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM sales_order_lines sl
+			WHERE sl.sales_order_id = $1 AND sl.quantity > COALESCE((
+				SELECT SUM(pt.picked_quantity) FROM pick_tasks pt
+				WHERE pt.sales_order_line_id = sl.id AND pt.status IN ('PICKED', 'SHORT')
+			), 0)
+		)
+	`
+
+	if err := tx.GetContext(ctx, &short, query, orderID); err != nil {
+		return false, fmt.Errorf("error checking picked quantities: %w", err)
+	}
+	return !short, nil
+}
+
+// cambia el estado de la ola y registra cuando termino o se cancelo
+func (r *Repository) UpdateWaveStatus(ctx context.Context, tx *sqlx.Tx, id int, status WaveStatus) error {
+	query := `
+		UPDATE waves
+		SET status = $1,
+			completed_at = CASE WHEN $1 = 'COMPLETED' THEN CURRENT_TIMESTAMP ELSE completed_at END,
+			cancelled_at = CASE WHEN $1 = 'CANCELLED' THEN CURRENT_TIMESTAMP ELSE cancelled_at END
+		WHERE id = $2
+	`
+
+	if _, err := tx.ExecContext(ctx, query, status, id); err != nil {
+		return fmt.Errorf("error updating wave status: %w", err)
+	}
+	return nil
+}
+
+// descarta las tareas pendientes de la ola, lo ya surtido se queda en staging
+func (r *Repository) CancelPendingTasks(ctx context.Context, tx *sqlx.Tx, waveID int) (int, error) {
+	query := `UPDATE pick_tasks SET status = 'CANCELLED' WHERE wave_id = $1 AND status = 'PENDING'`
+
+	result, err := tx.ExecContext(ctx, query, waveID)
+	if err != nil {
+		return 0, fmt.Errorf("error cancelling pick tasks: %w", err)
+	}
+	cancelled, _ := result.RowsAffected()
+	return int(cancelled), nil
+}
@@ -0,0 +1,422 @@
+package picking
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+	"github.com/whoAngeel/wms-lite/internal/movement"
+	"github.com/whoAngeel/wms-lite/internal/sales"
+)
+
+type Service struct {
+	repo      *Repository
+	db        *sqlx.DB
+	movements *movement.Service
+	sales     *sales.Service
+	logger    zerolog.Logger
+}
+
+func NewService(repo *Repository, db *sqlx.DB, movements *movement.Service, sales *sales.Service, logger zerolog.Logger) *Service {
+	serviceLogger := logger.With().Str("service", "picking").Logger()
+	return &Service{repo: repo, db: db, movements: movements, sales: sales, logger: serviceLogger}
+}
+
+// CreateWave agrupa ordenes de venta asignadas (ALLOCATED) de un mismo almacen y genera su lista de surtido
+// cada linea se surte de los bins con stock en orden de recorrido (path) y al final del stock sin ubicar
+// las tareas quedan numeradas en orden de recorrido para hacer un solo paso por el almacen
+func (s *Service) CreateWave(ctx context.Context, req CreateWaveRequest) (wave *Wave, err error) {
+	seen := make(map[int]bool, len(req.SalesOrderIDs))
+	for _, id := range req.SalesOrderIDs {
+		if seen[id] {
+			return nil, fmt.Errorf("invalid sales_order_ids: sales order [%d] appears more than once", id)
+		}
+		seen[id] = true
+	}
+
+	staging, err := s.repo.GetLocation(ctx, req.StagingLocationID)
+	if err != nil {
+		return nil, err
+	}
+	if !staging.IsActive {
+		return nil, fmt.Errorf("invalid staging_location_id: location [%s] is inactive", staging.Path)
+	}
+	if staging.LocationType != "BIN" {
+		return nil, fmt.Errorf("invalid staging_location_id: location [%s] is not a BIN", staging.Path)
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	orders, err := s.repo.GetOrdersForUpdate(ctx, tx, req.SalesOrderIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(orders) != len(req.SalesOrderIDs) {
+		return nil, fmt.Errorf("invalid sales_order_ids: some sales orders were not found")
+	}
+
+	for _, order := range orders {
+		if order.Status != string(sales.StatusAllocated) {
+			return nil, fmt.Errorf("cannot add sales order %d to a wave: order is %s", order.ID, order.Status)
+		}
+		if order.WarehouseID != staging.WarehouseID {
+			return nil, fmt.Errorf("invalid sales_order_ids: sales order %d is not in the staging location warehouse", order.ID)
+		}
+	}
+
+	busy, err := s.repo.OrdersInOpenWave(ctx, tx, req.SalesOrderIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(busy) > 0 {
+		return nil, fmt.Errorf("cannot add sales order %d to a wave: order is already in an open wave", busy[0])
+	}
+
+	id, err := s.repo.CreateWave(ctx, tx, staging.WarehouseID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := s.planTasks(ctx, tx, id, staging, req.SalesOrderIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("cannot create wave: the sales orders have nothing left to pick")
+	}
+
+	for _, task := range tasks {
+		if err = s.repo.CreateTask(ctx, tx, task); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	s.logger.Info().Int("wave_id", id).Int("orders", len(orders)).Int("tasks", len(tasks)).Msg("Wave created")
+	return s.GetWave(ctx, id)
+}
+
+// reparte lo que falta por surtir de cada linea entre los bins y el stock sin ubicar
+// los productos se recorren en orden de product_id para respetar el orden de locks de stock_levels
+func (s *Service) planTasks(ctx context.Context, tx *sqlx.Tx, waveID int, staging *LocationInfo, orderIDs []int) ([]Task, error) {
+	lines, err := s.repo.ListRemainingLines(ctx, tx, orderIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []Task
+	paths := make(map[int]string)
+
+	for start := 0; start < len(lines); {
+		productID := lines[start].ProductID
+		end := start
+		for end < len(lines) && lines[end].ProductID == productID {
+			end++
+		}
+
+		warehouseStock, err := s.repo.LockStockLevel(ctx, tx, productID, staging.WarehouseID)
+		if err != nil {
+			return nil, err
+		}
+
+		bins, err := s.repo.ListBinStock(ctx, tx, productID, staging.WarehouseID, staging.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		unlocated, err := s.repo.GetUnlocatedStock(ctx, tx, productID, staging.WarehouseID, warehouseStock)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, line := range lines[start:end] {
+			remaining := line.Remaining
+			for i := range bins {
+				if remaining == 0 {
+					break
+				}
+				take := min(bins[i].Quantity, remaining)
+				if take <= 0 {
+					continue
+				}
+
+				locationID := bins[i].LocationID
+				paths[locationID] = bins[i].Path
+				tasks = append(tasks, Task{
+					WaveID: waveID, SalesOrderID: line.SalesOrderID, SalesOrderLineID: line.ID,
+					ProductID: productID, LocationID: &locationID, Quantity: take,
+				})
+				bins[i].Quantity -= take
+				remaining -= take
+			}
+
+			if remaining > 0 {
+				if unlocated < remaining {
+					return nil, fmt.Errorf("cannot create wave: not enough stock to pick line %d (%s) of sales order %d, missing=%d",
+						line.ID, line.SKU, line.SalesOrderID, remaining-max(unlocated, 0))
+				}
+				tasks = append(tasks, Task{
+					WaveID: waveID, SalesOrderID: line.SalesOrderID, SalesOrderLineID: line.ID,
+					ProductID: productID, Quantity: remaining,
+				})
+				unlocated -= remaining
+			}
+		}
+
+		start = end
+	}
+
+	// orden de recorrido: por path del bin, el stock sin ubicar al final
+	sort.SliceStable(tasks, func(i, j int) bool {
+		a, b := tasks[i].LocationID, tasks[j].LocationID
+		if a == nil || b == nil {
+			return a != nil && b == nil
+		}
+		if paths[*a] != paths[*b] {
+			return paths[*a] < paths[*b]
+		}
+		return tasks[i].SalesOrderID < tasks[j].SalesOrderID
+	})
+
+	for i := range tasks {
+		tasks[i].Sequence = i + 1
+	}
+	return tasks, nil
+}
+
+// obtiene la ola con sus ordenes y su lista de surtido
+func (s *Service) GetWave(ctx context.Context, id int) (*Wave, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("invalid ID: must be greater than 0")
+	}
+
+	wave, err := s.repo.GetWave(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	wave.SalesOrderIDs, err = s.repo.ListWaveOrderIDs(ctx, s.db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	wave.Tasks, err = s.repo.ListTasks(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return wave, nil
+}
+
+func (s *Service) ListWaves(ctx context.Context, filters ListFilters) (*PaginatedResponse, error) {
+	filters.Page, filters.PageSize = normalizePagination(filters.Page, filters.PageSize)
+
+	if filters.Status != "" {
+		status := WaveStatus(strings.ToUpper(filters.Status))
+		if !status.IsValid() {
+			return nil, fmt.Errorf("invalid status: must be OPEN, COMPLETED or CANCELLED")
+		}
+		filters.Status = string(status)
+	}
+
+	waves, total, err := s.repo.ListWaves(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	if waves == nil {
+		waves = []Wave{}
+	}
+
+	return &PaginatedResponse{
+		Data: waves,
+		Pagination: Pagination{
+			Page:       filters.Page,
+			PageSize:   filters.PageSize,
+			Total:      total,
+			TotalPages: (total + filters.PageSize - 1) / filters.PageSize,
+		},
+	}, nil
+}
+
+// ConfirmPick confirma una tarea y lleva lo surtido del bin al staging de la ola con un TRANSFER
+// con menos de lo pedido la tarea queda SHORT y la orden sigue ALLOCATED para surtir el faltante en otra ola
+// cuando una orden ya no tiene tareas pendientes y esta surtida completa pasa a PICKED
+// cuando la ola ya no tiene tareas pendientes pasa a COMPLETED
+func (s *Service) ConfirmPick(ctx context.Context, waveID, taskID int, req ConfirmPickRequest) (err error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	wave, err := s.repo.GetWaveForUpdate(ctx, tx, waveID)
+	if err != nil {
+		return err
+	}
+
+	if wave.Status != WaveStatusOpen {
+		return fmt.Errorf("cannot confirm pick: wave is %s", wave.Status)
+	}
+
+	task, err := s.repo.GetTaskForUpdate(ctx, tx, waveID, taskID)
+	if err != nil {
+		return err
+	}
+
+	if task.Status != TaskStatusPending {
+		return fmt.Errorf("cannot confirm pick: task is %s", task.Status)
+	}
+
+	picked := *req.PickedQuantity
+	if picked > task.Quantity {
+		return fmt.Errorf("invalid picked_quantity: task %d asks for %d units, picked=%d", task.ID, task.Quantity, picked)
+	}
+
+	orderStatus, err := s.repo.GetOrderStatusForUpdate(ctx, tx, task.SalesOrderID)
+	if err != nil {
+		return err
+	}
+	if orderStatus != string(sales.StatusAllocated) {
+		return fmt.Errorf("cannot confirm pick: sales order %d is %s", task.SalesOrderID, orderStatus)
+	}
+
+	var posted *movement.Movement
+	var movementID *int
+	if picked > 0 {
+		posted, err = s.movements.CreateMovementTx(ctx, tx, movement.CreateMovementRequest{
+			ProductID:      task.ProductID,
+			WarehouseID:    wave.WarehouseID,
+			MovementType:   movement.MovementTypeTransfer,
+			Quantity:       picked,
+			Reason:         fmt.Sprintf("wave #%d pick task #%d (sales order #%d)", wave.ID, task.ID, task.SalesOrderID),
+			CreatedBy:      req.PickedBy,
+			FromLocationID: task.LocationID,
+			ToLocationID:   &wave.StagingLocationID,
+		})
+		if err != nil {
+			return fmt.Errorf("error picking task %d (%s): %w", task.ID, task.SKU, err)
+		}
+		movementID = &posted.ID
+	}
+
+	status := TaskStatusPicked
+	if picked < task.Quantity {
+		status = TaskStatusShort
+	}
+
+	if err = s.repo.ConfirmTask(ctx, tx, task.ID, picked, status, movementID, req.PickedBy); err != nil {
+		return err
+	}
+
+	orderPending, err := s.repo.CountPendingTasks(ctx, tx, waveID, task.SalesOrderID)
+	if err != nil {
+		return err
+	}
+	if orderPending == 0 {
+		complete, err := s.repo.OrderFullyPicked(ctx, tx, task.SalesOrderID)
+		if err != nil {
+			return err
+		}
+		if complete {
+			if err = s.sales.PickTx(ctx, tx, task.SalesOrderID); err != nil {
+				return err
+			}
+		}
+	}
+
+	wavePending, err := s.repo.CountPendingTasks(ctx, tx, waveID, 0)
+	if err != nil {
+		return err
+	}
+	if wavePending == 0 {
+		if err = s.repo.UpdateWaveStatus(ctx, tx, waveID, WaveStatusCompleted); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	if posted != nil {
+		s.movements.AfterCommit(ctx, posted)
+	}
+
+	s.logger.Info().Int("wave_id", waveID).Int("task_id", taskID).Int("picked", picked).Str("status", string(status)).Msg("Pick confirmed")
+	return nil
+}
+
+// CancelWave descarta las tareas pendientes, lo ya surtido se queda en el staging
+func (s *Service) CancelWave(ctx context.Context, id int) (err error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	wave, err := s.repo.GetWaveForUpdate(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	if wave.Status != WaveStatusOpen {
+		return fmt.Errorf("cannot cancel wave: wave is %s", wave.Status)
+	}
+
+	cancelled, err := s.repo.CancelPendingTasks(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	if err = s.repo.UpdateWaveStatus(ctx, tx, id, WaveStatusCancelled); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	s.logger.Info().Int("wave_id", id).Int("cancelled_tasks", cancelled).Msg("Wave cancelled")
+	return nil
+}
+
+func normalizePagination(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	if pageSize > 100 {
+		pageSize = 100
+	}
+	return page, pageSize
+}
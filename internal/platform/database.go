@@ -2,6 +2,8 @@ package platform
 
 import (
 	"fmt"
+	"net/url"
+	"strings"
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq" // Driver de PostgreSQL
@@ -24,6 +26,13 @@ func NewDatabase(cfg DatabaseConfig) (*sqlx.DB, error) {
 		)
 	}
 
+	// la sesion trabaja en UTC: los TIMESTAMP (sin zona) se escriben con CURRENT_TIMESTAMP de la sesion
+	// y los reportes los comparan contra limites que se calculan en UTC
+	dsn, err := withUTCTimeZone(dsn)
+	if err != nil {
+		return nil, err
+	}
+
 	// Abrir conexión con sqlx (envuelve database/sql estándar)
 	db, err := sqlx.Connect("postgres", dsn)
 	if err != nil {
@@ -43,3 +52,20 @@ func NewDatabase(cfg DatabaseConfig) (*sqlx.DB, error) {
 	fmt.Println("✅ PostgresDB connected successfully")
 	return db, nil
 }
+
+// fija la zona horaria de la sesion en el DSN (formato URL o clave=valor)
+func withUTCTimeZone(dsn string) (string, error) {
+	if !strings.HasPrefix(dsn, "postgres://") && !strings.HasPrefix(dsn, "postgresql://") {
+		// en clave=valor el ultimo valor de una clave es el que se usa
+		return dsn + " timezone=UTC", nil
+	}
+
+	parsed, err := url.Parse(dsn)
+	if err != nil {
+		return "", fmt.Errorf("error parsing database URL: %w", err)
+	}
+	query := parsed.Query()
+	query.Set("timezone", "UTC")
+	parsed.RawQuery = query.Encode()
+	return parsed.String(), nil
+}
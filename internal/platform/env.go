@@ -12,10 +12,13 @@ import (
 
 // config contiene toda la configuracion de la app
 type Config struct {
-	Database DatabaseConfig
-	Server   ServerConfig
-	Auth     AuthConfig
-	Cache    CacheConfig
+	Database    DatabaseConfig
+	Server      ServerConfig
+	Auth        AuthConfig
+	Cache       CacheConfig
+	Reservation ReservationConfig
+	Costing     CostingConfig
+	Report      ReportConfig
 }
 
 type DatabaseConfig struct {
@@ -47,6 +50,22 @@ type CacheConfig struct {
 	DB       int
 }
 
+// ReservationConfig controla el barrido que libera las reservas vencidas
+type ReservationConfig struct {
+	SweepInterval time.Duration
+}
+
+// CostingConfig define como se valoran las salidas de inventario
+// FIFO consume las capas de costo de la mas antigua a la mas reciente, AVERAGE usa el costo promedio movil
+type CostingConfig struct {
+	Method string
+}
+
+// ReportConfig controla el job que guarda los checkpoints de stock para las consultas a una fecha
+type ReportConfig struct {
+	CheckpointInterval time.Duration
+}
+
 // loadConfig carga las variables de entorno
 func LoadConfig() (*Config, error) {
 	// cargar .env en desarrollo
@@ -70,6 +89,21 @@ func LoadConfig() (*Config, error) {
 		connMaxLifeTime = 5 * time.Minute
 	}
 
+	sweepInterval, err := time.ParseDuration(getEnv("RESERVATION_SWEEP_INTERVAL", "1m"))
+	if err != nil || sweepInterval <= 0 {
+		sweepInterval = time.Minute
+	}
+
+	checkpointInterval, err := time.ParseDuration(getEnv("STOCK_CHECKPOINT_INTERVAL", "1h"))
+	if err != nil || checkpointInterval <= 0 {
+		checkpointInterval = time.Hour
+	}
+
+	costingMethod := strings.ToUpper(getEnv("COSTING_METHOD", "FIFO"))
+	if costingMethod != "FIFO" && costingMethod != "AVERAGE" {
+		return nil, fmt.Errorf("invalid costing method: %s (must be FIFO or AVERAGE)", costingMethod)
+	}
+
 	jwtSecret := getEnv("JWT_SECRET", "")
 	if jwtSecret == "" {
 		return nil, fmt.Errorf("JWT_SECRET is not set")
@@ -101,6 +135,15 @@ func LoadConfig() (*Config, error) {
 			Password: getEnv("REDIS_PASSWORD", ""),
 			DB:       getEnvAsInt("REDIS_DB", 0),
 		},
+		Reservation: ReservationConfig{
+			SweepInterval: sweepInterval,
+		},
+		Costing: CostingConfig{
+			Method: costingMethod,
+		},
+		Report: ReportConfig{
+			CheckpointInterval: checkpointInterval,
+		},
 	}
 	return config, nil
 
@@ -0,0 +1,201 @@
+package platform
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// IdempotencyHeader es el header con el que el cliente identifica un request que puede reintentar
+	IdempotencyHeader = "Idempotency-Key"
+	// IdempotencyReplayHeader marca las respuestas que se repiten de un request anterior
+	IdempotencyReplayHeader = "Idempotent-Replayed"
+
+	idempotencyTTL           = 24 * time.Hour  // cuanto tiempo se repite la respuesta
+	idempotencyProcessingTTL = 2 * time.Minute // si el servidor cae a mitad del request, la clave se libera
+	idempotencyMaxKeyLength  = 255
+	idempotencyStoreTimeout  = 5 * time.Second // para guardar o liberar la clave aunque el cliente ya se haya desconectado
+)
+
+// idempotencyStore es lo que el middleware necesita del cache (Get devuelve redis.Nil si la clave no existe)
+type idempotencyStore interface {
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+	Del(ctx context.Context, keys ...string) error
+	SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error)
+}
+
+// idempotencyRecord es lo que se guarda por clave: el hash del request y, ya procesado, su respuesta
+// Status 0 = el request original todavia se esta procesando
+type idempotencyRecord struct {
+	RequestHash string `json:"request_hash"`
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// responseRecorder copia lo que escribe el handler para guardarlo
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(data string) (int, error) {
+	w.body.WriteString(data)
+	return w.ResponseWriter.WriteString(data)
+}
+
+// IdempotencyMiddleware hace reintentables los POST/PUT/PATCH/DELETE que traen el header Idempotency-Key
+// la primera vez se procesa el request y se guarda su respuesta en Redis (SETNX, por usuario y clave)
+// los reintentos con el mismo body reciben la respuesta guardada sin volver a ejecutar el handler
+// la misma clave con otro request es 422 y mientras el original se procesa es 409
+// solo se guardan las respuestas 2xx: con un 4xx, un 5xx o un panico del handler la clave se libera
+// y el cliente puede corregir o reintentar con la misma clave
+// va despues de RequireAuth (la clave se aisla por usuario) y de RequireRole en cada ruta que modifica;
+// si Redis falla el request se procesa sin idempotencia
+func IdempotencyMiddleware(cache *Cache) gin.HandlerFunc {
+	return idempotencyMiddleware(cache)
+}
+
+func idempotencyMiddleware(cache idempotencyStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyHeader)
+		if key == "" || c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead || c.Request.Method == http.MethodOptions {
+			c.Next()
+			return
+		}
+
+		if len(key) > idempotencyMaxKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid %s: must be at most %d characters", IdempotencyHeader, idempotencyMaxKeyLength)})
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		// el hash identifica el request: metodo, ruta, query string y body
+		hash := sha256.New()
+		hash.Write([]byte(c.Request.Method + " " + c.Request.URL.Path + "?" + c.Request.URL.RawQuery + "\n"))
+		hash.Write(body)
+		requestHash := hex.EncodeToString(hash.Sum(nil))
+
+		userID, _ := c.Get("user_id")
+		cacheKey := fmt.Sprintf("idempotency:%v:%s", userID, key)
+		ctx := c.Request.Context()
+
+		processing, _ := json.Marshal(idempotencyRecord{RequestHash: requestHash})
+		created, err := cache.SetNX(ctx, cacheKey, processing, idempotencyProcessingTTL)
+		if err != nil {
+			log.Warn().Err(err).Str("key", key).Msg("Idempotency store unavailable, processing request without idempotency")
+			c.Next()
+			return
+		}
+
+		if !created {
+			replayIdempotentResponse(c, cache, cacheKey, requestHash)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		// si el handler entra en panico la clave se libera antes de que Recovery responda 500
+		completed := false
+		defer func() {
+			if !completed {
+				releaseIdempotencyKey(c.Request.Context(), cache, cacheKey, key)
+			}
+		}()
+
+		c.Next()
+		completed = true
+
+		status := recorder.Status()
+		if status < http.StatusOK || status >= http.StatusMultipleChoices {
+			releaseIdempotencyKey(c.Request.Context(), cache, cacheKey, key)
+			return
+		}
+
+		stored, _ := json.Marshal(idempotencyRecord{
+			RequestHash: requestHash,
+			Status:      status,
+			ContentType: recorder.Header().Get("Content-Type"),
+			Body:        recorder.body.Bytes(),
+		})
+		// el request ya se proceso: se guarda aunque el cliente se haya desconectado
+		storeCtx, cancel := context.WithTimeout(context.WithoutCancel(c.Request.Context()), idempotencyStoreTimeout)
+		defer cancel()
+		if err := cache.Set(storeCtx, cacheKey, stored, idempotencyTTL); err != nil {
+			log.Warn().Err(err).Str("key", key).Msg("Failed to store idempotent response")
+		}
+	}
+}
+
+// libera la clave para que el cliente pueda reintentar; no depende de que el request siga vivo
+func releaseIdempotencyKey(ctx context.Context, cache idempotencyStore, cacheKey, key string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), idempotencyStoreTimeout)
+	defer cancel()
+	if err := cache.Del(ctx, cacheKey); err != nil {
+		log.Warn().Err(err).Str("key", key).Msg("Failed to release idempotency key")
+	}
+}
+
+// responde un reintento con lo guardado para la clave
+func replayIdempotentResponse(c *gin.Context, cache idempotencyStore, cacheKey, requestHash string) {
+	value, err := cache.Get(c.Request.Context(), cacheKey)
+	if err == redis.Nil {
+		// la clave expiro o se libero entre SETNX y GET
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "a request with this Idempotency-Key is being processed, retry later"})
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Error reading idempotency key")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	var record idempotencyRecord
+	if err := json.Unmarshal([]byte(value), &record); err != nil {
+		log.Error().Err(err).Msg("Error decoding idempotency record")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	if record.RequestHash != requestHash {
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": "Idempotency-Key was already used with a different request"})
+		return
+	}
+
+	if record.Status == 0 {
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "a request with this Idempotency-Key is being processed, retry later"})
+		return
+	}
+
+	c.Header(IdempotencyReplayHeader, "true")
+	contentType := record.ContentType
+	if contentType == "" {
+		contentType = "application/json; charset=utf-8"
+	}
+	c.Data(record.Status, contentType, record.Body)
+	c.Abort()
+}
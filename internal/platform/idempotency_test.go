@@ -0,0 +1,194 @@
+package platform
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// memoryStore es un idempotencyStore en memoria (sin TTL) para probar el middleware sin Redis
+type memoryStore struct {
+	mu     sync.Mutex
+	values map[string]string
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{values: make(map[string]string)}
+}
+
+func (m *memoryStore) Get(_ context.Context, key string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	value, found := m.values[key]
+	if !found {
+		return "", redis.Nil
+	}
+	return value, nil
+}
+
+func (m *memoryStore) Set(_ context.Context, key string, value interface{}, _ time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values[key] = fmt.Sprintf("%s", value)
+	return nil
+}
+
+func (m *memoryStore) Del(_ context.Context, keys ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, key := range keys {
+		delete(m.values, key)
+	}
+	return nil
+}
+
+func (m *memoryStore) SetNX(_ context.Context, key string, value interface{}, _ time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, found := m.values[key]; found {
+		return false, nil
+	}
+	m.values[key] = fmt.Sprintf("%s", value)
+	return true, nil
+}
+
+// router con el middleware en POST /orders; handler responde cada llamada (calls empieza en 1)
+func newIdempotencyRouter(store idempotencyStore, handler func(c *gin.Context, calls int)) (*gin.Engine, *int) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(gin.Recovery())
+
+	calls := 0
+	router.POST("/orders", idempotencyMiddleware(store), func(c *gin.Context) {
+		calls++
+		handler(c, calls)
+	})
+	return router, &calls
+}
+
+func sendIdempotent(router http.Handler, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(IdempotencyHeader, key)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	router, calls := newIdempotencyRouter(newMemoryStore(), func(c *gin.Context, calls int) {
+		c.JSON(http.StatusCreated, gin.H{"id": calls})
+	})
+
+	first := sendIdempotent(router, "key-1", `{"quantity":5}`)
+	if first.Code != http.StatusCreated || first.Header().Get(IdempotencyReplayHeader) != "" {
+		t.Fatalf("got %d replayed=%q, want 201 without replay", first.Code, first.Header().Get(IdempotencyReplayHeader))
+	}
+
+	retry := sendIdempotent(router, "key-1", `{"quantity":5}`)
+	if retry.Code != http.StatusCreated || retry.Header().Get(IdempotencyReplayHeader) != "true" {
+		t.Fatalf("got %d replayed=%q, want 201 replayed", retry.Code, retry.Header().Get(IdempotencyReplayHeader))
+	}
+	if retry.Body.String() != first.Body.String() {
+		t.Errorf("got body %s, want the original %s", retry.Body.String(), first.Body.String())
+	}
+	if *calls != 1 {
+		t.Errorf("handler ran %d times, want 1", *calls)
+	}
+}
+
+func TestIdempotencyDifferentRequest(t *testing.T) {
+	router, calls := newIdempotencyRouter(newMemoryStore(), func(c *gin.Context, _ int) {
+		c.JSON(http.StatusCreated, gin.H{"ok": true})
+	})
+
+	sendIdempotent(router, "key-1", `{"quantity":5}`)
+	w := sendIdempotent(router, "key-1", `{"quantity":6}`)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("got %d, want 422", w.Code)
+	}
+	if *calls != 1 {
+		t.Errorf("handler ran %d times, want 1", *calls)
+	}
+}
+
+func TestIdempotencyProcessing(t *testing.T) {
+	var router *gin.Engine
+	var retry *httptest.ResponseRecorder
+
+	// el reintento llega mientras el request original sigue en el handler
+	router, calls := newIdempotencyRouter(newMemoryStore(), func(c *gin.Context, calls int) {
+		if calls == 1 {
+			retry = sendIdempotent(router, "key-1", `{"quantity":5}`)
+		}
+		c.JSON(http.StatusCreated, gin.H{"ok": true})
+	})
+
+	first := sendIdempotent(router, "key-1", `{"quantity":5}`)
+
+	if first.Code != http.StatusCreated {
+		t.Fatalf("got %d for the original request, want 201", first.Code)
+	}
+	if retry == nil || retry.Code != http.StatusConflict {
+		t.Fatalf("got %v for the retry, want 409", retry)
+	}
+	if *calls != 1 {
+		t.Errorf("handler ran %d times, want 1", *calls)
+	}
+}
+
+func TestIdempotencyReleasesKey(t *testing.T) {
+	tests := []struct {
+		name  string
+		first func(c *gin.Context)
+	}{
+		{name: "error 5xx", first: func(c *gin.Context) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "boom"})
+		}},
+		{name: "panico del handler", first: func(c *gin.Context) {
+			panic("boom")
+		}},
+		{name: "error de negocio 4xx", first: func(c *gin.Context) {
+			c.JSON(http.StatusConflict, gin.H{"error": "cannot approve session"})
+		}},
+		{name: "rechazo 403", first: func(c *gin.Context) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMemoryStore()
+			router, calls := newIdempotencyRouter(store, func(c *gin.Context, calls int) {
+				if calls == 1 {
+					tt.first(c)
+					return
+				}
+				c.JSON(http.StatusCreated, gin.H{"ok": true})
+			})
+
+			sendIdempotent(router, "key-1", `{"quantity":5}`)
+			if len(store.values) != 0 {
+				t.Fatalf("got %d stored keys after the failure, want the key released", len(store.values))
+			}
+
+			retry := sendIdempotent(router, "key-1", `{"quantity":5}`)
+			if retry.Code != http.StatusCreated || retry.Header().Get(IdempotencyReplayHeader) != "" {
+				t.Fatalf("got %d replayed=%q, want the retry processed again", retry.Code, retry.Header().Get(IdempotencyReplayHeader))
+			}
+			if *calls != 2 {
+				t.Errorf("handler ran %d times, want 2", *calls)
+			}
+		})
+	}
+}
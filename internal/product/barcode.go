@@ -0,0 +1,73 @@
+package product
+
+import "fmt"
+
+// BarcodeType es la simbologia GS1 del codigo de barras
+type BarcodeType string
+
+const (
+	BarcodeTypeEAN13  BarcodeType = "EAN13"
+	BarcodeTypeUPCA   BarcodeType = "UPCA"
+	BarcodeTypeGTIN14 BarcodeType = "GTIN14"
+)
+
+// Length retorna cuantos digitos lleva el codigo (0 si el tipo no es valido)
+func (bt BarcodeType) Length() int {
+	switch bt {
+	case BarcodeTypeEAN13:
+		return 13
+	case BarcodeTypeUPCA:
+		return 12
+	case BarcodeTypeGTIN14:
+		return 14
+	}
+	return 0
+}
+
+// barcodeTypeForLength deduce el tipo a partir del numero de digitos
+func barcodeTypeForLength(length int) BarcodeType {
+	switch length {
+	case 12:
+		return BarcodeTypeUPCA
+	case 13:
+		return BarcodeTypeEAN13
+	case 14:
+		return BarcodeTypeGTIN14
+	}
+	return ""
+}
+
+// validateBarcode verifica longitud, que solo tenga digitos y el digito verificador GS1
+func validateBarcode(code string, barcodeType BarcodeType) error {
+	length := barcodeType.Length()
+	if length == 0 {
+		return fmt.Errorf("invalid type: must be EAN13, UPCA or GTIN14")
+	}
+
+	if len(code) != length {
+		return fmt.Errorf("invalid code: %s must have %d digits, got %d", barcodeType, length, len(code))
+	}
+
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return fmt.Errorf("invalid code: must contain only digits")
+		}
+	}
+
+	if expected := gs1CheckDigit(code[:length-1]); code[length-1] != expected {
+		return fmt.Errorf("invalid code: check digit must be %c", expected)
+	}
+	return nil
+}
+
+// gs1CheckDigit calcula el digito verificador (modulo 10)
+// de derecha a izquierda los digitos se ponderan 3, 1, 3, 1...
+func gs1CheckDigit(digits string) byte {
+	sum := 0
+	weight := 3
+	for i := len(digits) - 1; i >= 0; i-- {
+		sum += int(digits[i]-'0') * weight
+		weight = 4 - weight
+	}
+	return byte('0' + (10-sum%10)%10)
+}
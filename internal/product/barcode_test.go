@@ -0,0 +1,81 @@
+package product
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGS1CheckDigit(t *testing.T) {
+	tests := []struct {
+		name  string
+		code  string
+		valid bool
+	}{
+		{name: "EAN-8 valido", code: "96385074", valid: true},
+		{name: "EAN-8 valido con verificador 7", code: "73513537", valid: true},
+		{name: "EAN-8 con verificador incorrecto", code: "96385075"},
+		{name: "UPC-A valido", code: "036000291452", valid: true},
+		{name: "UPC-A con verificador incorrecto", code: "036000291453"},
+		{name: "EAN-13 valido", code: "4006381333931", valid: true},
+		{name: "EAN-13 con verificador incorrecto", code: "4006381333930"},
+		{name: "GTIN-14 valido", code: "10012345678902", valid: true},
+		{name: "GTIN-14 con verificador incorrecto", code: "10012345678909"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			last := len(tt.code) - 1
+			got := gs1CheckDigit(tt.code[:last])
+			if (got == tt.code[last]) != tt.valid {
+				t.Errorf("gs1CheckDigit(%s) = %c, code ends in %c, want valid=%v", tt.code[:last], got, tt.code[last], tt.valid)
+			}
+		})
+	}
+}
+
+func TestValidateBarcode(t *testing.T) {
+	tests := []struct {
+		name        string
+		code        string
+		barcodeType BarcodeType
+		wantErr     string
+	}{
+		{name: "UPC-A valido", code: "036000291452", barcodeType: BarcodeTypeUPCA},
+		{name: "UPC-A con ceros a la izquierda", code: "012345678905", barcodeType: BarcodeTypeUPCA},
+		{name: "UPC-A con verificador incorrecto", code: "036000291450", barcodeType: BarcodeTypeUPCA, wantErr: "check digit must be 2"},
+		{name: "EAN-13 valido", code: "5901234123457", barcodeType: BarcodeTypeEAN13},
+		{name: "EAN-13 con verificador incorrecto", code: "5901234123458", barcodeType: BarcodeTypeEAN13, wantErr: "check digit must be 7"},
+		{name: "GTIN-14 valido", code: "00012345600012", barcodeType: BarcodeTypeGTIN14},
+		{name: "GTIN-14 con verificador incorrecto", code: "00012345600013", barcodeType: BarcodeTypeGTIN14, wantErr: "check digit must be 2"},
+		{name: "EAN-8 no es un tipo soportado", code: "96385074", barcodeType: "EAN8", wantErr: "invalid type"},
+		{name: "EAN-8 como EAN-13", code: "96385074", barcodeType: BarcodeTypeEAN13, wantErr: "must have 13 digits, got 8"},
+		{name: "longitud de otro tipo", code: "4006381333931", barcodeType: BarcodeTypeGTIN14, wantErr: "must have 14 digits, got 13"},
+		{name: "caracteres que no son digitos", code: "40063813339A1", barcodeType: BarcodeTypeEAN13, wantErr: "only digits"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateBarcode(tt.code, tt.barcodeType)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBarcodeTypeForLength(t *testing.T) {
+	for _, barcodeType := range []BarcodeType{BarcodeTypeUPCA, BarcodeTypeEAN13, BarcodeTypeGTIN14} {
+		if got := barcodeTypeForLength(barcodeType.Length()); got != barcodeType {
+			t.Errorf("barcodeTypeForLength(%d) = %q, want %q", barcodeType.Length(), got, barcodeType)
+		}
+	}
+	if got := barcodeTypeForLength(8); got != "" {
+		t.Errorf("barcodeTypeForLength(8) = %q, want no type", got)
+	}
+}
@@ -2,6 +2,7 @@ package product
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
@@ -39,6 +40,10 @@ func (h *Handler) Create(c *gin.Context) {
 		return
 	}
 
+	if email, exists := c.Get("email"); exists {
+		req.CreatedBy = email.(string)
+	}
+
 	// llamar al servicio
 	product, err := h.service.Create(ctx, req)
 	if err != nil {
@@ -51,6 +56,14 @@ func (h *Handler) Create(c *gin.Context) {
 			return
 		}
 
+		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "cannot have initial stock") ||
+			strings.Contains(err.Error(), "invalid category_id") || strings.Contains(err.Error(), "greater than max_level") {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
 		h.logger.Error().Err(err).Str("sku", req.SKU).Msg("Error creating product")
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Error creating product",
@@ -89,6 +102,7 @@ func (h *Handler) GetByID(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", productETag(product.Version))
 	c.JSON(http.StatusOK, product)
 }
 
@@ -120,9 +134,108 @@ func (h *Handler) GetBySKU(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", productETag(product.Version))
 	c.JSON(http.StatusOK, product)
 }
 
+// GetByBarcode maneja GET /products/barcode/:code (lectura del escaner)
+func (h *Handler) GetByBarcode(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	code := c.Param("code")
+
+	product, err := h.service.GetByBarcode(ctx, code)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error().Err(err).Str("barcode", code).Msg("Error getting product by barcode")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error getting product by barcode"})
+		return
+	}
+
+	c.JSON(http.StatusOK, product)
+}
+
+// AddBarcode maneja POST /products/:id/barcodes
+func (h *Handler) AddBarcode(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID, must be a number"})
+		return
+	}
+
+	var req AddBarcodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	barcode, err := h.service.AddBarcode(ctx, id, req)
+	if err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error().Err(err).Int("product_id", id).Msg("Error adding barcode")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error adding barcode"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, barcode)
+}
+
+// RemoveBarcode maneja DELETE /products/:id/barcodes/:code
+func (h *Handler) RemoveBarcode(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID, must be a number"})
+		return
+	}
+
+	if err := h.service.RemoveBarcode(ctx, id, c.Param("code")); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error().Err(err).Int("product_id", id).Msg("Error removing barcode")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error removing barcode"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Barcode removed successfully"})
+}
+
 func (h *Handler) GetAll(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
@@ -164,6 +277,11 @@ func (h *Handler) Update(c *gin.Context) {
 		return
 	}
 
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
 	var req UpdateProductRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		h.logger.Warn().Err(err).Int("product_id", id).Msg("Invalid request body for update")
@@ -174,8 +292,13 @@ func (h *Handler) Update(c *gin.Context) {
 		return
 	}
 
-	product, err := h.service.Update(ctx, id, req)
+	product, err := h.service.Update(ctx, id, version, req)
 	if err != nil {
+		if strings.Contains(err.Error(), "precondition failed") {
+			h.respondPreconditionFailed(c, ctx, id, err)
+			return
+		}
+
 		if strings.Contains(err.Error(), "not found") {
 			c.JSON(http.StatusNotFound, gin.H{
 				"error": err.Error(),
@@ -183,6 +306,20 @@ func (h *Handler) Update(c *gin.Context) {
 			return
 		}
 
+		if strings.Contains(err.Error(), "cannot change") {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		if strings.Contains(err.Error(), "invalid category_id") || strings.Contains(err.Error(), "greater than max_level") {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
 		h.logger.Error().Err(err).Int("product_id", id).Msg("Error updating product")
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Error updating product",
@@ -190,6 +327,7 @@ func (h *Handler) Update(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", productETag(product.Version))
 	c.JSON(http.StatusOK, product)
 }
 
@@ -240,8 +378,18 @@ func (h *Handler) SoftDelete(c *gin.Context) {
 		return
 	}
 
-	err = h.service.SoftDelete(ctx, id)
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	err = h.service.SoftDelete(ctx, id, version)
 	if err != nil {
+		if strings.Contains(err.Error(), "precondition failed") {
+			h.respondPreconditionFailed(c, ctx, id, err)
+			return
+		}
+
 		if strings.Contains(err.Error(), "not found") {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
@@ -270,8 +418,18 @@ func (h *Handler) Restore(c *gin.Context) {
 		return
 	}
 
-	err = h.service.Restore(ctx, id)
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	err = h.service.Restore(ctx, id, version)
 	if err != nil {
+		if strings.Contains(err.Error(), "precondition failed") {
+			h.respondPreconditionFailed(c, ctx, id, err)
+			return
+		}
+
 		if strings.Contains(err.Error(), "not found") {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
@@ -324,7 +482,9 @@ func (h *Handler) Search(c *gin.Context) {
 			err.Error() == "min_stock cannot be greater than max_stock" ||
 			err.Error() == "invalid price format for min_price. expected float" ||
 			err.Error() == "invalid price format for max_price. expected float" ||
-			err.Error() == "min_price must be less than or equal to max_price" {
+			err.Error() == "min_price must be less than or equal to max_price" ||
+			err.Error() == "category_id must be greater than 0" ||
+			err.Error() == "include_descendants requires category_id" {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
@@ -339,3 +499,59 @@ func (h *Handler) Search(c *gin.Context) {
 		Msg("Products searched successfully")
 	c.JSON(http.StatusOK, response)
 }
+
+// productETag es el ETag del producto: su version de fila
+// es debil (W/) porque la representacion incluye stock, que cambia con movimientos sin subir la version
+func productETag(version int) string {
+	return fmt.Sprintf(`W/"%d"`, version)
+}
+
+// lee la version de un ETag de producto, debil (W/"3") o fuerte ("3")
+func parseProductETag(etag string) (int, error) {
+	version, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(etag, "W/"), `"`))
+	if err != nil || version <= 0 {
+		return 0, fmt.Errorf("invalid product ETag %q", etag)
+	}
+	return version, nil
+}
+
+// lee la version esperada del header If-Match (W/"3" o "3"); "*" acepta cualquier version (nil)
+// sin If-Match responde 428: las ediciones de producto deben partir de una version conocida
+func ifMatchVersion(c *gin.Context) (*int, bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		c.JSON(http.StatusPreconditionRequired, gin.H{
+			"error": "If-Match header is required, use the ETag returned by GET /products/:id",
+		})
+		return nil, false
+	}
+	if header == "*" {
+		return nil, true
+	}
+
+	version, err := parseProductETag(header)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid If-Match header, expected a single product ETag",
+		})
+		return nil, false
+	}
+	return &version, true
+}
+
+// responde 412 con la representacion actual del producto y su ETag para que el cliente
+// pueda reintentar sobre la version vigente
+func (h *Handler) respondPreconditionFailed(c *gin.Context, ctx context.Context, id int, err error) {
+	current, getErr := h.service.GetCurrent(ctx, id)
+	if getErr != nil {
+		h.logger.Warn().Err(getErr).Int("product_id", id).Msg("Failed to get current product for 412")
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("ETag", productETag(current.Version))
+	c.JSON(http.StatusPreconditionFailed, gin.H{
+		"error":   err.Error(),
+		"current": current,
+	})
+}
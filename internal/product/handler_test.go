@@ -0,0 +1,38 @@
+package product
+
+import "testing"
+
+func TestProductETag(t *testing.T) {
+	if got := productETag(3); got != `W/"3"` {
+		t.Errorf("productETag(3) = %s, want a weak ETag W/\"3\"", got)
+	}
+
+	tests := []struct {
+		name    string
+		etag    string
+		version int
+		wantErr bool
+	}{
+		{name: "ETag debil", etag: `W/"3"`, version: 3},
+		{name: "ETag fuerte de clientes anteriores", etag: `"3"`, version: 3},
+		{name: "sin comillas", etag: "3", version: 3},
+		{name: "version cero", etag: `W/"0"`, wantErr: true},
+		{name: "lista de ETags", etag: `W/"3", W/"4"`, wantErr: true},
+		{name: "no es una version", etag: `W/"abc"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, err := parseProductETag(tt.etag)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseProductETag(%s) = %d, want error", tt.etag, version)
+				}
+				return
+			}
+			if err != nil || version != tt.version {
+				t.Fatalf("parseProductETag(%s) = %d, %v, want %d", tt.etag, version, err, tt.version)
+			}
+		})
+	}
+}
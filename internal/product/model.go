@@ -3,14 +3,62 @@ package product
 import "time"
 
 type Product struct {
-	ID          int        `json:"id" db:"id"`
-	SKU         string     `json:"sku" db:"sku"`
-	Name        string     `json:"name" db:"name"`
-	Description string     `json:"description" db:"description"`
-	Stock       int        `json:"stock_quantity" db:"stock_quantity"`
-	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
-	DeletedAt   *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+	ID           int        `json:"id" db:"id"`
+	SKU          string     `json:"sku" db:"sku"`
+	Name         string     `json:"name" db:"name"`
+	Description  string     `json:"description" db:"description"`
+	Stock        int        `json:"stock_quantity" db:"stock_quantity"`
+	IsLotTracked bool       `json:"is_lot_tracked" db:"is_lot_tracked"`
+	IsSerialized bool       `json:"is_serialized" db:"is_serialized"`
+	CategoryID   *int       `json:"category_id,omitempty" db:"category_id"`
+	ReorderPoint *int       `json:"reorder_point,omitempty" db:"reorder_point"`
+	MinLevel     *int       `json:"min_level,omitempty" db:"min_level"`
+	MaxLevel     *int       `json:"max_level,omitempty" db:"max_level"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+
+	// version de la fila, sube en cada edicion del producto (no con movimientos de stock)
+	// y es el ETag (debil, W/"<version>") que se exige en If-Match para modificarlo
+	Version int `json:"version" db:"version"`
+
+	// stock fisico, lo apartado por reservas activas, lo retenido en estados no disponibles
+	// y lo que queda libre (on_hand - reserved - held)
+	OnHand    int `json:"on_hand" db:"on_hand"`
+	Reserved  int `json:"reserved" db:"reserved"`
+	Held      int `json:"held" db:"held"`
+	Available int `json:"available" db:"available"`
+
+	// desglose del stock por almacen y codigos de barras (solo en GetByID / GetBySKU)
+	StockByWarehouse []WarehouseStock `json:"stock_by_warehouse,omitempty" db:"-"`
+	Barcodes         []Barcode        `json:"barcodes,omitempty" db:"-"`
+}
+
+// Barcode es un codigo de barras con el que se escanea el producto
+type Barcode struct {
+	ID        int         `json:"id" db:"id"`
+	ProductID int         `json:"product_id" db:"product_id"`
+	Code      string      `json:"code" db:"code"`
+	Type      BarcodeType `json:"type" db:"barcode_type"`
+	CreatedAt time.Time   `json:"created_at" db:"created_at"`
+}
+
+// AddBarcodeRequest es el body de POST /products/:id/barcodes
+type AddBarcodeRequest struct {
+	Code string      `json:"code" binding:"required,max=14"`
+	Type BarcodeType `json:"type"` // opcional, se deduce por la longitud del codigo
+}
+
+// WarehouseStock es el stock del producto en un almacen
+type WarehouseStock struct {
+	WarehouseID   int    `json:"warehouse_id" db:"warehouse_id"`
+	WarehouseCode string `json:"warehouse_code" db:"warehouse_code"`
+	WarehouseName string `json:"warehouse_name" db:"warehouse_name"`
+	Quantity      int    `json:"quantity" db:"quantity"`
+	// stock retenido por estado, el resto de quantity esta AVAILABLE
+	Quarantine int `json:"quarantine" db:"quarantine"`
+	Damaged    int `json:"damaged" db:"damaged"`
+	OnHold     int `json:"on_hold" db:"on_hold"`
 }
 
 // create productRequest es el payload para crear un producto
@@ -19,6 +67,19 @@ type CreateProductRequest struct {
 	Name        string `json:"name" validate:"required,max=100"`
 	Description string `json:"description" validate:"omitempty,max=500"`
 	Stock       int    `json:"stock_quantity" binding:"min=0" validate:"required,min=0"`
+	WarehouseID int    `json:"warehouse_id" binding:"omitempty,min=1"` // almacen del stock inicial, por defecto el principal
+	// costo por unidad del stock inicial (opcional, sin costo se valora en cero)
+	UnitCost *float64 `json:"unit_cost" binding:"omitempty,min=0"`
+	// con lotes o series el stock inicial debe entrar con un movimiento IN que indique el lote/las series
+	IsLotTracked bool `json:"is_lot_tracked"`
+	IsSerialized bool `json:"is_serialized"`
+	CategoryID   *int `json:"category_id" binding:"omitempty,min=1"` // opcional
+	// niveles de reabasto (opcionales): alerta de stock bajo en reorder_point (o min_level) y reabasto hasta max_level
+	ReorderPoint *int `json:"reorder_point" binding:"omitempty,min=0"`
+	MinLevel     *int `json:"min_level" binding:"omitempty,min=0"`
+	MaxLevel     *int `json:"max_level" binding:"omitempty,min=0"`
+	// usuario que registra el movimiento de saldo inicial, lo llena el handler
+	CreatedBy string `json:"-"`
 }
 
 // updateProductRequest
@@ -26,16 +87,33 @@ type UpdateProductRequest struct {
 	Name        string `json:"name" validate:"omitempty,max=100"`
 	Description string `json:"description" validate:"omitempty,max=500"`
 	// STOCK no se actualiza aca, solo desde movements
+	IsLotTracked *bool `json:"is_lot_tracked"`                        // solo se puede cambiar si el producto no tiene stock
+	IsSerialized *bool `json:"is_serialized"`                         // solo se puede cambiar si el producto no tiene stock
+	CategoryID   *int  `json:"category_id" binding:"omitempty,min=0"` // 0 quita la categoria
+	ReorderPoint *int  `json:"reorder_point" binding:"omitempty,min=0"`
+	MinLevel     *int  `json:"min_level" binding:"omitempty,min=0"`
+	MaxLevel     *int  `json:"max_level" binding:"omitempty,min=0"`
 }
 
 type ProductResponse struct {
-	ID          int       `json:"id" db:"id"`
-	SKU         string    `json:"sku" db:"sku"`
-	Name        string    `json:"name" db:"name"`
-	Description string    `json:"description" db:"description"`
-	Stock       int       `json:"stock_quantity" db:"stock_quantity"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	ID           int       `json:"id" db:"id"`
+	SKU          string    `json:"sku" db:"sku"`
+	Name         string    `json:"name" db:"name"`
+	Description  string    `json:"description" db:"description"`
+	Stock        int       `json:"stock_quantity" db:"stock_quantity"`
+	IsLotTracked bool      `json:"is_lot_tracked" db:"is_lot_tracked"`
+	IsSerialized bool      `json:"is_serialized" db:"is_serialized"`
+	CategoryID   *int      `json:"category_id,omitempty" db:"category_id"`
+	ReorderPoint *int      `json:"reorder_point,omitempty" db:"reorder_point"`
+	MinLevel     *int      `json:"min_level,omitempty" db:"min_level"`
+	MaxLevel     *int      `json:"max_level,omitempty" db:"max_level"`
+	OnHand       int       `json:"on_hand" db:"on_hand"`
+	Reserved     int       `json:"reserved" db:"reserved"`
+	Held         int       `json:"held" db:"held"`
+	Available    int       `json:"available" db:"available"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+	Version      int       `json:"version" db:"version"`
 }
 
 // DeletedProductResponse DTO para productos eliminados (incluye deleted_at)
@@ -48,6 +126,7 @@ type DeletedProductResponse struct {
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at"`
 	DeletedAt   *time.Time `json:"deleted_at"`
+	Version     int        `json:"version"`
 }
 
 type ProductListResponse struct {
@@ -74,20 +153,34 @@ type SearchFilters struct {
 	MaxStock *int   `form:"max_stock"`
 	FromDate string `form:"from_date"`
 	ToDate   string `form:"to_date"`
-	Page     int    `form:"page"`
-	PageSize int    `form:"page_size"`
+	// categoria, con include_descendants tambien entran sus subcategorias
+	CategoryID         *int `form:"category_id"`
+	IncludeDescendants bool `form:"include_descendants"`
+	Page               int  `form:"page"`
+	PageSize           int  `form:"page_size"`
 }
 
 // Converters
 func (p *Product) ToResponse() ProductResponse {
 	return ProductResponse{
-		ID:          p.ID,
-		SKU:         p.SKU,
-		Name:        p.Name,
-		Description: p.Description,
-		Stock:       p.Stock,
-		CreatedAt:   p.CreatedAt,
-		UpdatedAt:   p.UpdatedAt,
+		ID:           p.ID,
+		SKU:          p.SKU,
+		Name:         p.Name,
+		Description:  p.Description,
+		Stock:        p.Stock,
+		IsLotTracked: p.IsLotTracked,
+		IsSerialized: p.IsSerialized,
+		CategoryID:   p.CategoryID,
+		ReorderPoint: p.ReorderPoint,
+		MinLevel:     p.MinLevel,
+		MaxLevel:     p.MaxLevel,
+		OnHand:       p.OnHand,
+		Reserved:     p.Reserved,
+		Held:         p.Held,
+		Available:    p.Available,
+		CreatedAt:    p.CreatedAt,
+		UpdatedAt:    p.UpdatedAt,
+		Version:      p.Version,
 	}
 }
 
@@ -102,5 +195,6 @@ func (p *Product) ToDeletedResponse() DeletedProductResponse {
 		CreatedAt:   p.CreatedAt,
 		UpdatedAt:   p.UpdatedAt,
 		DeletedAt:   p.DeletedAt,
+		Version:     p.Version,
 	}
 }
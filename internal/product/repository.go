@@ -15,34 +15,188 @@ type Repository struct {
 	logger zerolog.Logger
 }
 
+// reservedStock suma las reservas activas y vigentes del producto en todos los almacenes
+const reservedStock = `(
+	SELECT COALESCE(SUM(r.quantity - r.consumed_quantity), 0)
+	FROM reservations r
+	WHERE r.product_id = products.id AND r.status = 'ACTIVE'
+		AND (r.expires_at IS NULL OR r.expires_at > CURRENT_TIMESTAMP)
+)`
+
+// heldStock suma el stock retenido del producto (cuarentena, dañado, en espera) en todos los almacenes
+const heldStock = `(
+	SELECT COALESCE(SUM(ss.quantity), 0)
+	FROM stock_status_levels ss
+	WHERE ss.product_id = products.id
+)`
+
+// stockColumns son el stock fisico, lo reservado, lo retenido y lo disponible del producto
+const stockColumns = `stock_quantity AS on_hand, ` + reservedStock + ` AS reserved, ` + heldStock + ` AS held, ` +
+	`stock_quantity - ` + reservedStock + ` - ` + heldStock + ` AS available`
+
 func NewRepository(db *sqlx.DB, logger zerolog.Logger) *Repository {
 	moduleLogger := logger.With().Str("module", "product").Logger()
 	return &Repository{db: db, logger: moduleLogger}
 }
 
-// inserta un nuevo producto
-func (r *Repository) Create(ctx context.Context, req CreateProductRequest) (*Product, error) {
+// inserta un nuevo producto sin stock dentro de la transaccion del llamador
+// el stock inicial lo registra despues el movimiento de saldo inicial
+func (r *Repository) Create(ctx context.Context, tx *sqlx.Tx, req CreateProductRequest) (*Product, error) {
 	query := `
-		INSERT INTO products (sku, name, description, stock_quantity)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, sku, name, description, stock_quantity, created_at, updated_at
+		INSERT INTO products (
+			sku, name, description, stock_quantity, is_lot_tracked, is_serialized, category_id,
+			reorder_point, min_level, max_level
+		)
+		VALUES ($1, $2, $3, 0, $4, $5, $6, $7, $8, $9)
+		RETURNING id, sku, name, description, stock_quantity, is_lot_tracked, is_serialized, category_id, reorder_point, min_level, max_level, created_at, updated_at, version
 	`
 
 	var product Product
-	err := r.db.QueryRowContext(
-		ctx, query, req.SKU, req.Name, req.Description, req.Stock,
-	).Scan(&product.ID, &product.SKU, &product.Name, &product.Description, &product.Stock, &product.CreatedAt, &product.UpdatedAt)
+	err := tx.QueryRowContext(
+		ctx, query, req.SKU, req.Name, req.Description, req.IsLotTracked, req.IsSerialized, req.CategoryID,
+		req.ReorderPoint, req.MinLevel, req.MaxLevel,
+	).Scan(&product.ID, &product.SKU, &product.Name, &product.Description, &product.Stock, &product.IsLotTracked, &product.IsSerialized, &product.CategoryID,
+		&product.ReorderPoint, &product.MinLevel, &product.MaxLevel, &product.CreatedAt, &product.UpdatedAt, &product.Version)
 
 	if err != nil {
 		return nil, fmt.Errorf("error creating product: %w", err)
 
 	}
+
 	return &product, nil
 }
 
+// obtiene el desglose de stock por almacen de un producto
+func (r *Repository) GetStockByWarehouse(ctx context.Context, productID int) ([]WarehouseStock, error) {
+	query := `
+		SELECT w.id AS warehouse_id, w.code AS warehouse_code, w.name AS warehouse_name, sl.quantity,
+			COALESCE(SUM(ss.quantity) FILTER (WHERE ss.status = 'QUARANTINE'), 0) AS quarantine,
+			COALESCE(SUM(ss.quantity) FILTER (WHERE ss.status = 'DAMAGED'), 0) AS damaged,
+			COALESCE(SUM(ss.quantity) FILTER (WHERE ss.status = 'ON_HOLD'), 0) AS on_hold
+		FROM stock_levels sl
+		JOIN warehouses w ON w.id = sl.warehouse_id
+		LEFT JOIN stock_status_levels ss ON ss.product_id = sl.product_id AND ss.warehouse_id = sl.warehouse_id
+		WHERE sl.product_id = $1 AND sl.quantity > 0
+		GROUP BY w.id, w.code, w.name, sl.quantity
+		ORDER BY w.code
+	`
+
+	var levels []WarehouseStock
+	err := r.db.SelectContext(ctx, &levels, query, productID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting stock by warehouse: %w", err)
+	}
+	return levels, nil
+}
+
+// resuelve el almacen: el indicado (si existe) o el almacen por defecto
+func (r *Repository) ResolveWarehouseID(ctx context.Context, warehouseID int) (int, error) {
+	query := `SELECT id FROM warehouses WHERE is_default AND deleted_at IS NULL`
+	args := []interface{}{}
+	if warehouseID > 0 {
+		query = `SELECT id FROM warehouses WHERE id = $1 AND deleted_at IS NULL`
+		args = append(args, warehouseID)
+	}
+
+	var id int
+	err := r.db.GetContext(ctx, &id, query, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			if warehouseID > 0 {
+				return 0, fmt.Errorf("warehouse with id [%d] not found", warehouseID)
+			}
+			return 0, fmt.Errorf("default warehouse not found")
+		}
+		return 0, fmt.Errorf("error getting warehouse: %w", err)
+	}
+	return id, nil
+}
+
+// verifica que la categoria exista y este activa
+func (r *Repository) CategoryIsActive(ctx context.Context, categoryID int) (bool, error) {
+	var active bool
+	query := `SELECT is_active FROM categories WHERE id = $1`
+
+	err := r.db.GetContext(ctx, &active, query, categoryID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, fmt.Errorf("category with id [%d] not found", categoryID)
+		}
+		return false, fmt.Errorf("error getting category: %w", err)
+	}
+	return active, nil
+}
+
+// lista los codigos de barras del producto
+func (r *Repository) ListBarcodes(ctx context.Context, productID int) ([]Barcode, error) {
+	query := `
+		SELECT id, product_id, code, barcode_type, created_at
+		FROM product_barcodes
+		WHERE product_id = $1
+		ORDER BY id
+	`
+
+	var barcodes []Barcode
+	if err := r.db.SelectContext(ctx, &barcodes, query, productID); err != nil {
+		return nil, fmt.Errorf("error listing barcodes: %w", err)
+	}
+	return barcodes, nil
+}
+
+func (r *Repository) CreateBarcode(ctx context.Context, productID int, code string, barcodeType BarcodeType) (*Barcode, error) {
+	query := `
+		INSERT INTO product_barcodes (product_id, code, barcode_type)
+		VALUES ($1, $2, $3)
+		RETURNING id, product_id, code, barcode_type, created_at
+	`
+
+	var barcode Barcode
+	if err := r.db.GetContext(ctx, &barcode, query, productID, code, barcodeType); err != nil {
+		return nil, fmt.Errorf("error creating barcode: %w", err)
+	}
+	return &barcode, nil
+}
+
+func (r *Repository) DeleteBarcode(ctx context.Context, productID int, code string) error {
+	query := `DELETE FROM product_barcodes WHERE product_id = $1 AND code = $2`
+
+	result, err := r.db.ExecContext(ctx, query, productID, code)
+	if err != nil {
+		return fmt.Errorf("error deleting barcode: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("barcode [%s] not found for product %d", code, productID)
+	}
+	return nil
+}
+
+// obtiene el id del producto (no eliminado) al que pertenece el codigo de barras
+func (r *Repository) GetProductIDByBarcode(ctx context.Context, code string) (int, error) {
+	var id int
+	query := `
+		SELECT p.id
+		FROM product_barcodes b
+		JOIN products p ON p.id = b.product_id
+		WHERE b.code = $1 AND p.deleted_at IS NULL
+	`
+
+	if err := r.db.GetContext(ctx, &id, query, code); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("product with barcode [%s] not found", code)
+		}
+		return 0, fmt.Errorf("error getting product by barcode: %w", err)
+	}
+	return id, nil
+}
+
 func (r *Repository) GetByID(ctx context.Context, id int) (*Product, error) {
 	query := `
-		SELECT id, sku, name, description, stock_quantity, created_at, updated_at
+		SELECT id, sku, name, description, stock_quantity, is_lot_tracked, is_serialized, category_id, reorder_point, min_level, max_level, created_at, updated_at, version, ` + stockColumns + `
 		FROM products
 		WHERE id = $1 AND deleted_at IS NULL
 	`
@@ -58,9 +212,49 @@ func (r *Repository) GetByID(ctx context.Context, id int) (*Product, error) {
 	return &product, nil
 }
 
+// obtiene el producto aunque este eliminado, leido directo de la bd (sin desglose de stock)
+func (r *Repository) GetByIDWithDeleted(ctx context.Context, id int) (*Product, error) {
+	query := `
+		SELECT id, sku, name, description, stock_quantity, is_lot_tracked, is_serialized, category_id, reorder_point, min_level, max_level, created_at, updated_at, version, deleted_at, ` + stockColumns + `
+		FROM products
+		WHERE id = $1
+	`
+
+	var product Product
+	err := r.db.GetContext(ctx, &product, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("product with id [%d] not found", id)
+		}
+		return nil, fmt.Errorf("error getting product: %w", err)
+	}
+	return &product, nil
+}
+
+// explica por que una escritura condicionada por version no afecto filas: si el producto sigue
+// en el estado esperado (eliminado o no) es porque su version ya no es la enviada en If-Match
+func (r *Repository) checkVersion(ctx context.Context, id int, version *int, deleted bool, notFound error) error {
+	if version == nil {
+		return notFound
+	}
+
+	var current int
+	query := `SELECT version FROM products WHERE id = $1 AND (deleted_at IS NOT NULL) = $2`
+	if err := r.db.GetContext(ctx, &current, query, id, deleted); err != nil {
+		if err == sql.ErrNoRows {
+			return notFound
+		}
+		return fmt.Errorf("error getting product version: %w", err)
+	}
+	if current != *version {
+		return fmt.Errorf("precondition failed: product %d is at version %d, not %d", id, current, *version)
+	}
+	return notFound
+}
+
 func (r *Repository) GetBySKU(ctx context.Context, sku string) (*Product, error) {
 	query := `
-		SELECT id, sku, name, description, stock_quantity, created_at, updated_at
+		SELECT id, sku, name, description, stock_quantity, is_lot_tracked, is_serialized, category_id, reorder_point, min_level, max_level, created_at, updated_at, version, ` + stockColumns + `
 		FROM products
 		WHERE sku = $1 AND deleted_at IS NULL
 	`
@@ -78,7 +272,7 @@ func (r *Repository) GetBySKU(ctx context.Context, sku string) (*Product, error)
 
 func (r *Repository) GetAll(ctx context.Context, limit, offset int) ([]Product, error) {
 	query := `
-		SELECT id, sku, name, description, stock_quantity, created_at, updated_at
+		SELECT id, sku, name, description, stock_quantity, is_lot_tracked, is_serialized, category_id, reorder_point, min_level, max_level, created_at, updated_at, version, ` + stockColumns + `
 		FROM products
 		WHERE deleted_at IS NULL
 		ORDER BY created_at DESC
@@ -104,28 +298,46 @@ func (r *Repository) Count(ctx context.Context) (int, error) {
 	return count, nil
 }
 
-func (r *Repository) Update(ctx context.Context, id int, req UpdateProductRequest) (*Product, error) {
+// actualiza el producto si su version sigue siendo la esperada (nil = cualquier version)
+// y sube la version
+func (r *Repository) Update(ctx context.Context, id int, version *int, req UpdateProductRequest) (*Product, error) {
 	query := `
 		UPDATE products
-		SET name = $1, description = $2, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $3
-		RETURNING id, sku, name, description, stock_quantity, created_at, updated_at	
+		SET name = $1, description = $2, is_lot_tracked = COALESCE($3, is_lot_tracked),
+			is_serialized = COALESCE($4, is_serialized),
+			category_id = CASE WHEN $5::int IS NULL THEN category_id ELSE NULLIF($5::int, 0) END,
+			reorder_point = COALESCE($6, reorder_point), min_level = COALESCE($7, min_level),
+			max_level = COALESCE($8, max_level), updated_at = CURRENT_TIMESTAMP, version = version + 1
+		WHERE id = $9 AND deleted_at IS NULL AND ($10::int IS NULL OR version = $10)
+		RETURNING id, sku, name, description, stock_quantity, is_lot_tracked, is_serialized, category_id, reorder_point, min_level, max_level, created_at, updated_at, version, ` + stockColumns + `
 	`
 
 	var product Product
-	err := r.db.QueryRowContext(ctx, query, req.Name, req.Description, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, req.Name, req.Description, req.IsLotTracked, req.IsSerialized, req.CategoryID,
+		req.ReorderPoint, req.MinLevel, req.MaxLevel, id, version).Scan(
 		&product.ID,
 		&product.SKU,
 		&product.Name,
 		&product.Description,
 		&product.Stock,
+		&product.IsLotTracked,
+		&product.IsSerialized,
+		&product.CategoryID,
+		&product.ReorderPoint,
+		&product.MinLevel,
+		&product.MaxLevel,
 		&product.CreatedAt,
 		&product.UpdatedAt,
+		&product.Version,
+		&product.OnHand,
+		&product.Reserved,
+		&product.Held,
+		&product.Available,
 	)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("product with id [%d] not found", id)
+			return nil, r.checkVersion(ctx, id, version, false, fmt.Errorf("product with id [%d] not found", id))
 		}
 		return nil, fmt.Errorf("error updating product: %w", err)
 	}
@@ -154,13 +366,13 @@ func (r *Repository) Delete(ctx context.Context, id int) error {
 	return nil
 }
 
-func (r *Repository) SoftDelete(ctx context.Context, id int) error {
+func (r *Repository) SoftDelete(ctx context.Context, id int, version *int) error {
 	query := `
 		UPDATE products 
-		SET deleted_at = CURRENT_TIMESTAMP
-		WHERE id = $1 AND deleted_at IS NULL
+		SET deleted_at = CURRENT_TIMESTAMP, version = version + 1
+		WHERE id = $1 AND deleted_at IS NULL AND ($2::int IS NULL OR version = $2)
 	`
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := r.db.ExecContext(ctx, query, id, version)
 	if err != nil {
 		r.logger.Error().Err(err).Int("product_id", id).Msg("Failed to soft delete product")
 		return err
@@ -172,8 +384,8 @@ func (r *Repository) SoftDelete(ctx context.Context, id int) error {
 	}
 
 	if rowsAffected == 0 {
-		r.logger.Warn().Int("product_id", id).Msg("Product not found or already deleted")
-		return fmt.Errorf("product not found or already deleted")
+		r.logger.Warn().Int("product_id", id).Msg("Product not found, already deleted or modified")
+		return r.checkVersion(ctx, id, version, false, fmt.Errorf("product not found or already deleted"))
 	}
 
 	r.logger.Info().Int("product_id", id).Msg("Product soft deleted successfully")
@@ -181,14 +393,14 @@ func (r *Repository) SoftDelete(ctx context.Context, id int) error {
 
 }
 
-func (r *Repository) Restore(ctx context.Context, id int) error {
+func (r *Repository) Restore(ctx context.Context, id int, version *int) error {
 	query := `
 		UPDATE products
-		SET deleted_at = NULL
-		WHERE id = $1 AND deleted_at IS NOT NULL
+		SET deleted_at = NULL, version = version + 1
+		WHERE id = $1 AND deleted_at IS NOT NULL AND ($2::int IS NULL OR version = $2)
 	`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := r.db.ExecContext(ctx, query, id, version)
 	if err != nil {
 		r.logger.Error().Err(err).Int("product_id", id).Msg("Failed to restore product")
 		return err
@@ -200,8 +412,8 @@ func (r *Repository) Restore(ctx context.Context, id int) error {
 	}
 
 	if rowsAffected == 0 {
-		r.logger.Warn().Int("product_id", id).Msg("Product not found or already active")
-		return fmt.Errorf("product not found or already active")
+		r.logger.Warn().Int("product_id", id).Msg("Product not found, already active or modified")
+		return r.checkVersion(ctx, id, version, true, fmt.Errorf("product not found or already active"))
 	}
 
 	r.logger.Info().Int("product_id", id).Msg("Product restored successfully")
@@ -212,7 +424,7 @@ func (r *Repository) GetDeleted(ctx context.Context, page, pageSize int) ([]Prod
 	offset := (page - 1) * pageSize
 
 	query := `
-		SELECT id, sku, name, description, stock_quantity, created_at, updated_at, deleted_at
+		SELECT id, sku, name, description, stock_quantity, is_lot_tracked, is_serialized, category_id, reorder_point, min_level, max_level, created_at, updated_at, version, deleted_at
 		FROM products
 		WHERE deleted_at IS NOT NULL
 		ORDER BY deleted_at DESC
@@ -245,7 +457,7 @@ func (r *Repository) GetDeleted(ctx context.Context, page, pageSize int) ([]Prod
 func (r *Repository) Search(ctx context.Context, filters SearchFilters) ([]Product, int, error) {
 	// query base
 	baseQuery := `
-		SELECT id, sku, name, description, stock_quantity, created_at, updated_at, deleted_at
+		SELECT id, sku, name, description, stock_quantity, is_lot_tracked, is_serialized, category_id, reorder_point, min_level, max_level, created_at, updated_at, version, deleted_at, ` + stockColumns + `
 		FROM products
 		WHERE deleted_at IS NULL
 	`
@@ -292,6 +504,20 @@ func (r *Repository) Search(ctx context.Context, filters SearchFilters) ([]Produ
 		argPosition++
 	}
 
+	if filters.CategoryID != nil {
+		if filters.IncludeDescendants {
+			// la categoria y todas las que estan debajo de ella (prefijo del path)
+			conditions = append(conditions, fmt.Sprintf(` category_id IN (
+				SELECT c.id FROM categories c, categories root
+				WHERE root.id = $%d AND (c.id = root.id OR left(c.path, length(root.path) + 1) = root.path || '/')
+			)`, argPosition))
+		} else {
+			conditions = append(conditions, fmt.Sprintf(" category_id = $%d", argPosition))
+		}
+		args = append(args, *filters.CategoryID)
+		argPosition++
+	}
+
 	if len(conditions) > 0 {
 		baseQuery += " AND " + strings.Join(conditions, " AND ")
 	}
@@ -4,26 +4,33 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
+	"github.com/whoAngeel/wms-lite/internal/alert"
+	"github.com/whoAngeel/wms-lite/internal/movement"
 	"github.com/whoAngeel/wms-lite/internal/platform"
 )
 
 type Service struct {
-	repo   *Repository
-	logger zerolog.Logger
-	cache  *platform.Cache
+	repo      *Repository
+	db        *sqlx.DB
+	movements *movement.Service
+	logger    zerolog.Logger
+	cache     *platform.Cache
+	alerts    *alert.Service
 }
 
-func NewService(repo *Repository, logger zerolog.Logger, cache *platform.Cache) *Service {
+func NewService(repo *Repository, db *sqlx.DB, movements *movement.Service, logger zerolog.Logger, cache *platform.Cache, alerts *alert.Service) *Service {
 	serviceLogger := logger.With().Str("service", "product").Logger()
-	return &Service{repo: repo, logger: serviceLogger, cache: cache}
+	return &Service{repo: repo, db: db, movements: movements, logger: serviceLogger, cache: cache, alerts: alerts}
 }
 
-func (s *Service) Create(ctx context.Context, req CreateProductRequest) (*Product, error) {
+func (s *Service) Create(ctx context.Context, req CreateProductRequest) (product *Product, err error) {
 	// 1 validaciones de negocio
 	if err := s.validateCreateRequest(req); err != nil {
 		return nil, err
@@ -37,8 +44,31 @@ func (s *Service) Create(ctx context.Context, req CreateProductRequest) (*Produc
 
 	// si el error es no encontrado, continuamos
 
-	// 3 crear el producto
-	product, err := s.repo.Create(ctx, req)
+	if req.CategoryID != nil {
+		if err := s.checkCategory(ctx, *req.CategoryID); err != nil {
+			return nil, err
+		}
+	}
+
+	// el stock inicial entra al almacen indicado o al almacen por defecto
+	warehouseID, err := s.repo.ResolveWarehouseID(ctx, req.WarehouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3 crear el producto y registrar su stock inicial en la misma transaccion
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	product, err = s.repo.Create(ctx, tx, req)
 	if err != nil {
 		if strings.Contains(err.Error(), "duplicate key") {
 			return nil, fmt.Errorf("sku [%s] already exists", req.SKU)
@@ -46,6 +76,32 @@ func (s *Service) Create(ctx context.Context, req CreateProductRequest) (*Produc
 		return nil, fmt.Errorf("error creating product: %w", err)
 	}
 
+	// el stock inicial entra con un movimiento de saldo inicial (OPENING_BALANCE) y su capa de costo
+	// como cualquier entrada, asi el ledger de movimientos lo explica
+	var opening *movement.Movement
+	if req.Stock > 0 {
+		opening, err = s.movements.CreateMovementTx(ctx, tx, movement.OpeningBalanceRequest(product.ID, warehouseID, req.Stock, req.UnitCost, req.CreatedBy))
+		if err != nil {
+			return nil, fmt.Errorf("error registering opening balance: %w", err)
+		}
+		product.Stock = opening.Quantity
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	// un producto nuevo no tiene reservas ni stock retenido
+	product.OnHand, product.Available = product.Stock, product.Stock
+
+	// el stock inicial puede nacer ya por debajo del punto de reorden
+	if opening != nil {
+		s.movements.AfterCommit(ctx, opening)
+	} else {
+		s.alerts.CheckProduct(ctx, product.ID)
+	}
+
 	return product, nil
 }
 
@@ -88,6 +144,16 @@ func (s *Service) GetByID(ctx context.Context, id int) (*Product, error) {
 		return nil, err
 	}
 
+	product.StockByWarehouse, err = s.repo.GetStockByWarehouse(ctx, product.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	product.Barcodes, err = s.repo.ListBarcodes(ctx, product.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	// guardar en redis
 	jsonData, _ := json.Marshal(product)
 	if setErr := s.cache.Set(ctx, cacheKey, jsonData, 5*time.Minute); setErr != nil {
@@ -133,6 +199,16 @@ func (s *Service) GetBySKU(ctx context.Context, sku string) (*Product, error) {
 		return nil, err
 	}
 
+	product.StockByWarehouse, err = s.repo.GetStockByWarehouse(ctx, product.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	product.Barcodes, err = s.repo.ListBarcodes(ctx, product.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	// guardar en redis
 	jsonData, _ := json.Marshal(product)
 	if setErr := s.cache.Set(ctx, cacheKey, jsonData, 5*time.Minute); setErr != nil {
@@ -144,6 +220,111 @@ func (s *Service) GetBySKU(ctx context.Context, sku string) (*Product, error) {
 	return product, nil
 }
 
+// GetByBarcode busca el producto por cualquiera de sus codigos de barras
+// en redis se guarda codigo -> id del producto y el producto se lee con GetByID (que tiene su propia cache),
+// asi la invalidacion por id/sku de los movimientos tambien aplica a los escaneos
+func (s *Service) GetByBarcode(ctx context.Context, code string) (*Product, error) {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return nil, fmt.Errorf("invalid barcode: must not be empty")
+	}
+
+	cacheKey := fmt.Sprintf("product:barcode:%s", code)
+
+	cached, err := s.cache.Get(ctx, cacheKey)
+	if err == nil {
+		if id, convErr := strconv.Atoi(cached); convErr == nil {
+			s.logger.Debug().Str("barcode", code).Str("source", "cache").Msg("Barcode resolved from cache")
+			return s.GetByID(ctx, id)
+		}
+		s.logger.Warn().Str("barcode", code).Str("value", cached).Msg("Invalid cached barcode value")
+	} else if err != redis.Nil {
+		s.logger.Warn().Str("barcode", code).Err(err).Msg("Redis error while getting barcode")
+	}
+
+	// cache miss
+	id, err := s.repo.GetProductIDByBarcode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	if setErr := s.cache.Set(ctx, cacheKey, strconv.Itoa(id), 5*time.Minute); setErr != nil {
+		s.logger.Warn().Str("barcode", code).Err(setErr).Msg("Failed to cache barcode")
+	}
+
+	return s.GetByID(ctx, id)
+}
+
+// AddBarcode asigna un codigo de barras al producto (un codigo solo puede pertenecer a un producto)
+func (s *Service) AddBarcode(ctx context.Context, productID int, req AddBarcodeRequest) (*Barcode, error) {
+	if productID <= 0 {
+		return nil, fmt.Errorf("invalid ID: must be greater than 0")
+	}
+
+	req.Code = strings.TrimSpace(req.Code)
+	req.Type = BarcodeType(strings.ToUpper(strings.TrimSpace(string(req.Type))))
+	if req.Type == "" {
+		req.Type = barcodeTypeForLength(len(req.Code))
+		if req.Type == "" {
+			return nil, fmt.Errorf("invalid code: must have 12 (UPC-A), 13 (EAN-13) or 14 (GTIN-14) digits")
+		}
+	}
+
+	if err := validateBarcode(req.Code, req.Type); err != nil {
+		return nil, err
+	}
+
+	product, err := s.repo.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	barcode, err := s.repo.CreateBarcode(ctx, productID, req.Code, req.Type)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			return nil, fmt.Errorf("barcode [%s] already exists", req.Code)
+		}
+		return nil, err
+	}
+
+	s.invalidateBarcodeCache(ctx, product, req.Code)
+	s.logger.Info().Int("product_id", productID).Str("barcode", req.Code).Msg("Barcode added")
+	return barcode, nil
+}
+
+// RemoveBarcode quita un codigo de barras del producto
+func (s *Service) RemoveBarcode(ctx context.Context, productID int, code string) error {
+	if productID <= 0 {
+		return fmt.Errorf("invalid ID: must be greater than 0")
+	}
+	code = strings.TrimSpace(code)
+
+	product, err := s.repo.GetByID(ctx, productID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteBarcode(ctx, productID, code); err != nil {
+		return err
+	}
+
+	s.invalidateBarcodeCache(ctx, product, code)
+	return nil
+}
+
+// el producto cacheado incluye sus codigos, y el codigo apunta al producto
+func (s *Service) invalidateBarcodeCache(ctx context.Context, product *Product, code string) {
+	cacheKeys := []string{
+		fmt.Sprintf("product:%d", product.ID),
+		fmt.Sprintf("product:sku:%s", product.SKU),
+		fmt.Sprintf("product:barcode:%s", code),
+	}
+
+	if delErr := s.cache.Del(ctx, cacheKeys...); delErr != nil {
+		s.logger.Warn().Err(delErr).Msg("Failed to invalidate cache")
+	}
+}
+
 func (s *Service) GetAll(ctx context.Context, page, pageSize int) (*ProductListResponse, error) {
 	if page <= 0 {
 		page = 1
@@ -178,7 +359,8 @@ func (s *Service) GetAll(ctx context.Context, page, pageSize int) (*ProductListR
 	}, nil
 }
 
-func (s *Service) Update(ctx context.Context, id int, req UpdateProductRequest) (*Product, error) {
+// actualiza el producto; version es la del If-Match (nil = cualquier version)
+func (s *Service) Update(ctx context.Context, id int, version *int, req UpdateProductRequest) (*Product, error) {
 	// validar id
 	if id <= 0 {
 		return nil, fmt.Errorf("invalid ID: it must be greater than 0")
@@ -194,8 +376,37 @@ func (s *Service) Update(ctx context.Context, id int, req UpdateProductRequest)
 		return nil, fmt.Errorf("product not found")
 	}
 
+	// con una version vieja las validaciones de abajo se harian contra datos que el cliente no ha visto
+	if err := matchVersion(existingProduct, version); err != nil {
+		return nil, err
+	}
+
+	// cambiar el control por lotes/series con stock dejaria unidades sin lote o sin serie
+	if req.IsLotTracked != nil && *req.IsLotTracked != existingProduct.IsLotTracked && existingProduct.Stock > 0 {
+		return nil, fmt.Errorf("cannot change is_lot_tracked: product has %d units in stock", existingProduct.Stock)
+	}
+	if req.IsSerialized != nil && *req.IsSerialized != existingProduct.IsSerialized && existingProduct.Stock > 0 {
+		return nil, fmt.Errorf("cannot change is_serialized: product has %d units in stock", existingProduct.Stock)
+	}
+
+	// los niveles que no vienen en el request conservan su valor actual
+	if err := validateLevels(
+		coalesceLevel(req.ReorderPoint, existingProduct.ReorderPoint),
+		coalesceLevel(req.MinLevel, existingProduct.MinLevel),
+		coalesceLevel(req.MaxLevel, existingProduct.MaxLevel),
+	); err != nil {
+		return nil, err
+	}
+
+	// category_id = 0 quita la categoria, no hay nada que validar
+	if req.CategoryID != nil && *req.CategoryID > 0 {
+		if err := s.checkCategory(ctx, *req.CategoryID); err != nil {
+			return nil, err
+		}
+	}
+
 	// actualizar en bd
-	product, err := s.repo.Update(ctx, id, req)
+	product, err := s.repo.Update(ctx, id, version, req)
 	if err != nil {
 		return nil, fmt.Errorf("error updating product: %w", err)
 	}
@@ -214,6 +425,11 @@ func (s *Service) Update(ctx context.Context, id int, req UpdateProductRequest)
 		Strs("cache_keys", cacheKeys).
 		Msg("Product updated successfully")
 
+	// con el nuevo umbral la alerta puede abrirse o resolverse sin que cambie el stock
+	if req.ReorderPoint != nil || req.MinLevel != nil || req.MaxLevel != nil {
+		s.alerts.CheckProduct(ctx, id)
+	}
+
 	return product, nil
 }
 
@@ -248,6 +464,54 @@ func (s *Service) validateCreateRequest(req CreateProductRequest) error {
 		return fmt.Errorf("the stock cannot be negative")
 	}
 
+	if req.UnitCost != nil && *req.UnitCost < 0 {
+		return fmt.Errorf("the unit cost cannot be negative")
+	}
+
+	if req.IsLotTracked && req.Stock > 0 {
+		return fmt.Errorf("lot-tracked products cannot have initial stock: register it with an IN movement and a lot_number")
+	}
+
+	if req.IsSerialized && req.Stock > 0 {
+		return fmt.Errorf("serialized products cannot have initial stock: register it with an IN movement and its serials")
+	}
+
+	return validateLevels(req.ReorderPoint, req.MinLevel, req.MaxLevel)
+}
+
+// el maximo es el nivel al que se reabastece: no puede quedar por debajo del minimo ni del punto de reorden
+func validateLevels(reorderPoint, minLevel, maxLevel *int) error {
+	if maxLevel == nil {
+		return nil
+	}
+	if minLevel != nil && *minLevel > *maxLevel {
+		return fmt.Errorf("invalid min_level: %d is greater than max_level %d", *minLevel, *maxLevel)
+	}
+	if reorderPoint != nil && *reorderPoint > *maxLevel {
+		return fmt.Errorf("invalid reorder_point: %d is greater than max_level %d", *reorderPoint, *maxLevel)
+	}
+	return nil
+}
+
+func coalesceLevel(value, current *int) *int {
+	if value != nil {
+		return value
+	}
+	return current
+}
+
+// solo se asignan categorias existentes y activas
+func (s *Service) checkCategory(ctx context.Context, categoryID int) error {
+	active, err := s.repo.CategoryIsActive(ctx, categoryID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return fmt.Errorf("invalid category_id: %w", err)
+		}
+		return err
+	}
+	if !active {
+		return fmt.Errorf("invalid category_id: category [%d] is inactive", categoryID)
+	}
 	return nil
 }
 
@@ -265,7 +529,7 @@ func (s *Service) validateUpdateRequest(req UpdateProductRequest) error {
 	return nil
 }
 
-func (s *Service) SoftDelete(ctx context.Context, id int) error {
+func (s *Service) SoftDelete(ctx context.Context, id int, version *int) error {
 	// validar id
 	if id == 0 {
 		return fmt.Errorf("invalid product id")
@@ -275,9 +539,12 @@ func (s *Service) SoftDelete(ctx context.Context, id int) error {
 	if err != nil {
 		return err
 	}
+	if err := matchVersion(product, version); err != nil {
+		return err
+	}
 
 	// intentar hacer soft delete
-	err = s.repo.SoftDelete(ctx, id)
+	err = s.repo.SoftDelete(ctx, id, version)
 	if err != nil {
 		return err
 	}
@@ -296,20 +563,30 @@ func (s *Service) SoftDelete(ctx context.Context, id int) error {
 		Strs("cache_keys", cacheKeys).
 		Msg("Product soft deleted successfully")
 
+	// un producto eliminado ya no se reabastece, su alerta abierta se resuelve
+	s.alerts.CheckProduct(ctx, id)
+
 	return nil
 }
 
-func (s *Service) Restore(ctx context.Context, id int) error {
+func (s *Service) Restore(ctx context.Context, id int, version *int) error {
 	if id == 0 {
 		return fmt.Errorf("invalid product id")
 	}
 
-	product, err := s.repo.GetByID(ctx, id)
+	// el producto a restaurar esta eliminado, GetByID no lo encontraria
+	product, err := s.repo.GetByIDWithDeleted(ctx, id)
 	if err != nil {
 		return err
 	}
+	if product.DeletedAt == nil {
+		return fmt.Errorf("product not found or already active")
+	}
+	if err := matchVersion(product, version); err != nil {
+		return err
+	}
 
-	err = s.repo.Restore(ctx, id)
+	err = s.repo.Restore(ctx, id, version)
 	if err != nil {
 		return err
 	}
@@ -331,6 +608,20 @@ func (s *Service) Restore(ctx context.Context, id int) error {
 	return nil
 }
 
+// obtiene la representacion actual del producto (eliminado o no) sin pasar por cache,
+// es la que acompaña al 412 cuando el If-Match trae una version vieja
+func (s *Service) GetCurrent(ctx context.Context, id int) (*Product, error) {
+	return s.repo.GetByIDWithDeleted(ctx, id)
+}
+
+// compara la version leida con la enviada en If-Match (nil = cualquier version)
+func matchVersion(product *Product, version *int) error {
+	if version != nil && product.Version != *version {
+		return fmt.Errorf("precondition failed: product %d is at version %d, not %d", product.ID, product.Version, *version)
+	}
+	return nil
+}
+
 func (s *Service) GetDeleted(ctx context.Context, page, pageSize int) (*PaginatedResponse, error) {
 	if page <= 0 {
 		page = 1
@@ -397,6 +688,14 @@ func (s *Service) Search(
 		return nil, fmt.Errorf("max_stock cannot be negative")
 	}
 
+	if filters.CategoryID != nil && *filters.CategoryID <= 0 {
+		return nil, fmt.Errorf("category_id must be greater than 0")
+	}
+
+	if filters.IncludeDescendants && filters.CategoryID == nil {
+		return nil, fmt.Errorf("include_descendants requires category_id")
+	}
+
 	// validar rango de fechas (YYYY-MM-DD)
 	if filters.FromDate != "" {
 		if !isValidDateFormat(filters.FromDate) {
@@ -0,0 +1,379 @@
+package purchasing
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+type Handler struct {
+	service *Service
+	logger  zerolog.Logger
+}
+
+func NewHandler(service *Service, logger zerolog.Logger) *Handler {
+	moduleLogger := logger.With().Str("module", "purchasing").Logger()
+	return &Handler{
+		service: service,
+		logger:  moduleLogger,
+	}
+}
+
+// CreateSupplier maneja POST /suppliers
+func (h *Handler) CreateSupplier(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	var req CreateSupplierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn().Err(err).Msg("Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	supplier, err := h.service.CreateSupplier(ctx, req)
+	if err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error().Err(err).Str("code", req.Code).Msg("Error creating supplier")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creating supplier"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, supplier)
+}
+
+// ListSuppliers maneja GET /suppliers
+// por defecto solo lista los activos, ?include_inactive=true lista todos
+func (h *Handler) ListSuppliers(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	activeOnly := c.Query("include_inactive") != "true"
+
+	suppliers, err := h.service.ListSuppliers(ctx, activeOnly)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Error listing suppliers")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error listing suppliers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": suppliers})
+}
+
+// GetSupplier maneja GET /suppliers/:id
+func (h *Handler) GetSupplier(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID, must be a number"})
+		return
+	}
+
+	supplier, err := h.service.GetSupplier(c.Request.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error().Err(err).Int("supplier_id", id).Msg("Error getting supplier")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, supplier)
+}
+
+// UpdateSupplier maneja PUT /suppliers/:id
+func (h *Handler) UpdateSupplier(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID, must be a number"})
+		return
+	}
+
+	var req UpdateSupplierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	supplier, err := h.service.UpdateSupplier(ctx, id, req)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error().Err(err).Int("supplier_id", id).Msg("Error updating supplier")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error updating supplier"})
+		return
+	}
+
+	c.JSON(http.StatusOK, supplier)
+}
+
+// DeleteSupplier maneja DELETE /suppliers/:id (desactiva el proveedor)
+func (h *Handler) DeleteSupplier(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID, must be a number"})
+		return
+	}
+
+	if err := h.service.DeactivateSupplier(ctx, id); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error().Err(err).Int("supplier_id", id).Msg("Failed to deactivate supplier")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to deactivate supplier"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Supplier deactivated successfully"})
+}
+
+// CreateOrder maneja POST /purchase-orders
+func (h *Handler) CreateOrder(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	var req CreatePurchaseOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn().Err(err).Msg("Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if email, exists := c.Get("email"); exists {
+		req.CreatedBy = email.(string)
+	}
+
+	order, err := h.service.CreateOrder(ctx, req)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error().Err(err).Msg("Error creating purchase order")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creating purchase order"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, order)
+}
+
+// ListOrders maneja GET /purchase-orders
+func (h *Handler) ListOrders(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	var filters ListFilters
+	if err := c.ShouldBindQuery(&filters); err != nil {
+		h.logger.Warn().Err(err).Msg("Invalid query parameters for purchase orders")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+
+	response, err := h.service.ListOrders(ctx, filters)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error().Err(err).Msg("Error listing purchase orders")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error listing purchase orders"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetOrder maneja GET /purchase-orders/:id (incluye lineas y recepciones)
+func (h *Handler) GetOrder(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID, must be a number"})
+		return
+	}
+
+	order, err := h.service.GetOrder(c.Request.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error().Err(err).Int("purchase_order_id", id).Msg("Error getting purchase order")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// UpdateOrder maneja PUT /purchase-orders/:id (solo en DRAFT)
+func (h *Handler) UpdateOrder(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID, must be a number"})
+		return
+	}
+
+	var req UpdatePurchaseOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.service.UpdateOrder(ctx, id, req); err != nil {
+		h.respondError(c, err, id, "Error updating purchase order")
+		return
+	}
+
+	h.respondOrder(c, id)
+}
+
+// SendOrder maneja POST /purchase-orders/:id/send
+func (h *Handler) SendOrder(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID, must be a number"})
+		return
+	}
+
+	if err := h.service.Send(c.Request.Context(), id); err != nil {
+		h.respondError(c, err, id, "Error sending purchase order")
+		return
+	}
+
+	h.respondOrder(c, id)
+}
+
+// ReceiveOrder maneja POST /purchase-orders/:id/receive
+// registra lo recibido como movimientos IN
+func (h *Handler) ReceiveOrder(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID, must be a number"})
+		return
+	}
+
+	var req ReceiveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	receivedBy := ""
+	if email, exists := c.Get("email"); exists {
+		receivedBy = email.(string)
+	}
+
+	if err := h.service.Receive(ctx, id, req, receivedBy); err != nil {
+		h.respondError(c, err, id, "Error receiving purchase order")
+		return
+	}
+
+	h.respondOrder(c, id)
+}
+
+// CloseOrder maneja POST /purchase-orders/:id/close
+func (h *Handler) CloseOrder(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID, must be a number"})
+		return
+	}
+
+	if err := h.service.Close(c.Request.Context(), id); err != nil {
+		h.respondError(c, err, id, "Error closing purchase order")
+		return
+	}
+
+	h.respondOrder(c, id)
+}
+
+// respondError traduce los errores de las acciones sobre una orden
+// recibir de mas o en un estado que no lo permite es un conflicto, no un error de datos
+func (h *Handler) respondError(c *gin.Context, err error, id int, message string) {
+	switch {
+	case strings.Contains(err.Error(), "cannot") || strings.Contains(err.Error(), "already exists"):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "purchase order with id"):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "not found") ||
+		strings.Contains(err.Error(), "required"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error().Err(err).Int("purchase_order_id", id).Msg(message)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+// responde con la orden actualizada
+func (h *Handler) respondOrder(c *gin.Context, id int) {
+	order, err := h.service.GetOrder(c.Request.Context(), id)
+	if err != nil {
+		h.logger.Error().Err(err).Int("purchase_order_id", id).Msg("Error getting purchase order")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
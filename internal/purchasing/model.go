@@ -0,0 +1,184 @@
+package purchasing
+
+import "time"
+
+// Status es el estado de una orden de compra
+// DRAFT -> SENT -> PARTIALLY_RECEIVED -> RECEIVED -> CLOSED
+// cerrar una orden sin recibir todo acepta el faltante (recepcion incompleta)
+type Status string
+
+const (
+	StatusDraft             Status = "DRAFT"
+	StatusSent              Status = "SENT"
+	StatusPartiallyReceived Status = "PARTIALLY_RECEIVED"
+	StatusReceived          Status = "RECEIVED"
+	StatusClosed            Status = "CLOSED"
+)
+
+// isValid verifica si el estado es valido
+func (st Status) IsValid() bool {
+	return st == StatusDraft || st == StatusSent || st == StatusPartiallyReceived ||
+		st == StatusReceived || st == StatusClosed
+}
+
+// canReceive indica si la orden acepta recepciones
+func (st Status) canReceive() bool {
+	return st == StatusSent || st == StatusPartiallyReceived
+}
+
+type Supplier struct {
+	ID          int       `json:"id" db:"id"`
+	Code        string    `json:"code" db:"code"`
+	Name        string    `json:"name" db:"name"`
+	ContactName string    `json:"contact_name,omitempty" db:"contact_name"`
+	Email       string    `json:"email,omitempty" db:"email"`
+	Phone       string    `json:"phone,omitempty" db:"phone"`
+	IsActive    bool      `json:"is_active" db:"is_active"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type CreateSupplierRequest struct {
+	Code        string `json:"code" binding:"required,max=20"`
+	Name        string `json:"name" binding:"required,max=255"`
+	ContactName string `json:"contact_name" binding:"max=100"`
+	Email       string `json:"email" binding:"omitempty,email,max=255"`
+	Phone       string `json:"phone" binding:"max=30"`
+}
+
+// UpdateSupplierRequest solo actualiza los campos enviados
+type UpdateSupplierRequest struct {
+	Name        *string `json:"name" binding:"omitempty,max=255"`
+	ContactName *string `json:"contact_name" binding:"omitempty,max=100"`
+	Email       *string `json:"email" binding:"omitempty,email,max=255"`
+	Phone       *string `json:"phone" binding:"omitempty,max=30"`
+	IsActive    *bool   `json:"is_active"`
+}
+
+type PurchaseOrder struct {
+	ID                int        `json:"id" db:"id"`
+	SupplierID        int        `json:"supplier_id" db:"supplier_id"`
+	SupplierCode      string     `json:"supplier_code" db:"supplier_code"`
+	SupplierName      string     `json:"supplier_name" db:"supplier_name"`
+	WarehouseID       int        `json:"warehouse_id" db:"warehouse_id"` // almacen que recibe
+	Status            Status     `json:"status" db:"status"`
+	SupplierReference string     `json:"supplier_reference,omitempty" db:"supplier_reference"`
+	ExpectedAt        *time.Time `json:"expected_at,omitempty" db:"expected_at"`
+	// porcentaje que se puede recibir de mas sobre lo pedido en cada linea
+	OverReceiptTolerance int        `json:"over_receipt_tolerance" db:"over_receipt_tolerance"`
+	Notes                string     `json:"notes,omitempty" db:"notes"`
+	CreatedBy            string     `json:"created_by,omitempty" db:"created_by"`
+	SentAt               *time.Time `json:"sent_at,omitempty" db:"sent_at"`
+	ClosedAt             *time.Time `json:"closed_at,omitempty" db:"closed_at"`
+	CreatedAt            time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time  `json:"updated_at" db:"updated_at"`
+	Lines                []Line     `json:"lines,omitempty" db:"-"`
+	Receipts             []Receipt  `json:"receipts,omitempty" db:"-"`
+}
+
+// Line es un producto pedido, las cantidades van en la unidad base
+type Line struct {
+	ID               int    `json:"id" db:"id"`
+	PurchaseOrderID  int    `json:"purchase_order_id" db:"purchase_order_id"`
+	ProductID        int    `json:"product_id" db:"product_id"`
+	SKU              string `json:"sku" db:"sku"`
+	ProductName      string `json:"product_name" db:"product_name"`
+	OrderedQuantity  int    `json:"ordered_quantity" db:"ordered_quantity"`
+	ReceivedQuantity int    `json:"received_quantity" db:"received_quantity"`
+	// costo pactado por unidad base, valora las entradas al recibir
+	UnitCost *float64 `json:"unit_cost,omitempty" db:"unit_cost"`
+	// lo que falta por recibir y lo recibido de mas (calculados)
+	OutstandingQuantity  int `json:"outstanding_quantity" db:"-"`
+	OverReceivedQuantity int `json:"over_received_quantity" db:"-"`
+}
+
+// calcula faltante y excedente contra lo pedido
+func (l *Line) computeBalance() {
+	l.OutstandingQuantity = max(l.OrderedQuantity-l.ReceivedQuantity, 0)
+	l.OverReceivedQuantity = max(l.ReceivedQuantity-l.OrderedQuantity, 0)
+}
+
+// maximo que acepta la linea con la tolerancia de la orden
+func (l *Line) receiveLimit(tolerance int) int {
+	return l.OrderedQuantity + l.OrderedQuantity*tolerance/100
+}
+
+// Receipt es una recepcion contra una linea, ligada al movimiento IN que genero
+type Receipt struct {
+	ID              int       `json:"id" db:"id"`
+	PurchaseOrderID int       `json:"purchase_order_id" db:"purchase_order_id"`
+	LineID          int       `json:"line_id" db:"line_id"`
+	ProductID       int       `json:"product_id" db:"product_id"`
+	MovementID      int       `json:"movement_id" db:"movement_id"`
+	Quantity        int       `json:"quantity" db:"quantity"` // en la unidad base
+	ReceivedBy      string    `json:"received_by,omitempty" db:"received_by"`
+	ReceivedAt      time.Time `json:"received_at" db:"received_at"`
+}
+
+type CreatePurchaseOrderRequest struct {
+	SupplierID           int           `json:"supplier_id" binding:"required,min=1"`
+	WarehouseID          int           `json:"warehouse_id" binding:"omitempty,min=1"` // opcional, si se omite usa el almacen por defecto
+	SupplierReference    string        `json:"supplier_reference" binding:"max=50"`
+	ExpectedAt           string        `json:"expected_at" binding:"omitempty,datetime=2006-01-02"`
+	OverReceiptTolerance int           `json:"over_receipt_tolerance" binding:"omitempty,min=0,max=100"`
+	Notes                string        `json:"notes" binding:"max=255"`
+	Lines                []LineRequest `json:"lines" binding:"required,min=1,dive"`
+	CreatedBy            string        `json:"-"`
+}
+
+type LineRequest struct {
+	ProductID int      `json:"product_id" binding:"required,min=1"`
+	Quantity  int      `json:"quantity" binding:"required,min=1"`   // en la unidad base
+	UnitCost  *float64 `json:"unit_cost" binding:"omitempty,min=0"` // opcional, costo por unidad base
+}
+
+// UpdatePurchaseOrderRequest solo aplica en DRAFT, lines (si se envia) reemplaza todas las lineas
+type UpdatePurchaseOrderRequest struct {
+	SupplierReference    *string       `json:"supplier_reference" binding:"omitempty,max=50"`
+	ExpectedAt           *string       `json:"expected_at" binding:"omitempty,datetime=2006-01-02"`
+	OverReceiptTolerance *int          `json:"over_receipt_tolerance" binding:"omitempty,min=0,max=100"`
+	Notes                *string       `json:"notes" binding:"omitempty,max=255"`
+	Lines                []LineRequest `json:"lines" binding:"omitempty,min=1,dive"`
+}
+
+// ReceiveRequest registra lo que llego contra las lineas de la orden
+// una linea puede venir varias veces (ej. en distintos lotes o bins)
+type ReceiveRequest struct {
+	Lines  []ReceiveLine `json:"lines" binding:"required,min=1,dive"`
+	Reason string        `json:"reason" binding:"max=200"` // se agrega a la razon del movimiento
+}
+
+type ReceiveLine struct {
+	LineID       int    `json:"line_id" binding:"required,min=1"`
+	Quantity     int    `json:"quantity" binding:"required,min=1"`
+	UoM          string `json:"uom" binding:"max=10"`                     // opcional, por defecto la unidad base (EA)
+	ToLocationID *int   `json:"to_location_id" binding:"omitempty,min=1"` // opcional, bin donde se acomoda
+	// opcional, costo por unidad base si difiere del pactado en la linea
+	UnitCost *float64 `json:"unit_cost" binding:"omitempty,min=0"`
+	// lotes y series igual que en un movimiento IN
+	LotNumber      string   `json:"lot_number" binding:"max=50"`
+	ManufacturedAt string   `json:"manufactured_at" binding:"omitempty,datetime=2006-01-02"`
+	ExpiresAt      string   `json:"expires_at" binding:"omitempty,datetime=2006-01-02"`
+	Serials        []string `json:"serials" binding:"omitempty,dive,required,max=100"`
+}
+
+// ListFilters son los filtros opcionales de GET /purchase-orders
+type ListFilters struct {
+	SupplierID  *int   `form:"supplier_id"`
+	WarehouseID *int   `form:"warehouse_id"`
+	Status      string `form:"status"`
+	Page        int    `form:"page"`
+	PageSize    int    `form:"page_size"`
+}
+
+type PaginatedResponse struct {
+	Data       interface{} `json:"data"`
+	Pagination Pagination  `json:"pagination"`
+}
+
+type Pagination struct {
+	Page       int `json:"page"`
+	PageSize   int `json:"page_size"`
+	Total      int `json:"total"`
+	TotalPages int `json:"total_pages"`
+}
@@ -0,0 +1,340 @@
+package purchasing
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog"
+)
+
+const supplierColumns = `
+	id, code, name, COALESCE(contact_name, '') AS contact_name, COALESCE(email, '') AS email,
+	COALESCE(phone, '') AS phone, is_active, created_at, updated_at
+`
+
+const orderColumns = `
+	po.id, po.supplier_id, s.code AS supplier_code, s.name AS supplier_name, po.warehouse_id, po.status,
+	COALESCE(po.supplier_reference, '') AS supplier_reference, po.expected_at, po.over_receipt_tolerance,
+	COALESCE(po.notes, '') AS notes, COALESCE(po.created_by, '') AS created_by,
+	po.sent_at, po.closed_at, po.created_at, po.updated_at
+`
+
+type Repository struct {
+	db     *sqlx.DB
+	logger zerolog.Logger
+}
+
+func NewRepository(db *sqlx.DB, logger zerolog.Logger) *Repository {
+	moduleLogger := logger.With().Str("module", "purchasing").Logger()
+	return &Repository{db: db, logger: moduleLogger}
+}
+
+func (r *Repository) CreateSupplier(ctx context.Context, req CreateSupplierRequest) (*Supplier, error) {
+	query := `
+		INSERT INTO suppliers (code, name, contact_name, email, phone)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, ''))
+		RETURNING ` + supplierColumns
+
+	var supplier Supplier
+	err := r.db.GetContext(ctx, &supplier, query, req.Code, req.Name, req.ContactName, req.Email, req.Phone)
+	if err != nil {
+		return nil, fmt.Errorf("error creating supplier: %w", err)
+	}
+	return &supplier, nil
+}
+
+func (r *Repository) GetSupplier(ctx context.Context, id int) (*Supplier, error) {
+	query := `SELECT ` + supplierColumns + ` FROM suppliers WHERE id = $1`
+
+	var supplier Supplier
+	err := r.db.GetContext(ctx, &supplier, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("supplier with id [%d] not found", id)
+		}
+		return nil, fmt.Errorf("error getting supplier: %w", err)
+	}
+	return &supplier, nil
+}
+
+// lista los proveedores, opcionalmente solo los activos
+func (r *Repository) ListSuppliers(ctx context.Context, activeOnly bool) ([]Supplier, error) {
+	query := `SELECT ` + supplierColumns + ` FROM suppliers`
+	if activeOnly {
+		query += ` WHERE is_active`
+	}
+	query += ` ORDER BY code`
+
+	var suppliers []Supplier
+	if err := r.db.SelectContext(ctx, &suppliers, query); err != nil {
+		return nil, fmt.Errorf("error listing suppliers: %w", err)
+	}
+	return suppliers, nil
+}
+
+// actualiza solo los campos enviados
+func (r *Repository) UpdateSupplier(ctx context.Context, id int, req UpdateSupplierRequest) (*Supplier, error) {
+	query := `
+		UPDATE suppliers
+		SET name = COALESCE($1, name),
+			contact_name = COALESCE(NULLIF($2, ''), contact_name),
+			email = COALESCE(NULLIF($3, ''), email),
+			phone = COALESCE(NULLIF($4, ''), phone),
+			is_active = COALESCE($5, is_active)
+		WHERE id = $6
+		RETURNING ` + supplierColumns
+
+	var supplier Supplier
+	err := r.db.GetContext(ctx, &supplier, query, req.Name, req.ContactName, req.Email, req.Phone, req.IsActive, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("supplier with id [%d] not found", id)
+		}
+		return nil, fmt.Errorf("error updating supplier: %w", err)
+	}
+	return &supplier, nil
+}
+
+// inserta la orden en DRAFT con sus lineas
+func (r *Repository) CreateOrder(ctx context.Context, tx *sqlx.Tx, req CreatePurchaseOrderRequest) (int, error) {
+	var id int
+	query := `
+		INSERT INTO purchase_orders (supplier_id, warehouse_id, supplier_reference, expected_at,
+			over_receipt_tolerance, notes, created_by)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, '')::date, $5, NULLIF($6, ''), NULLIF($7, ''))
+		RETURNING id
+	`
+
+	err := tx.GetContext(ctx, &id, query, req.SupplierID, req.WarehouseID, req.SupplierReference, req.ExpectedAt,
+		req.OverReceiptTolerance, req.Notes, req.CreatedBy)
+	if err != nil {
+		return 0, fmt.Errorf("error creating purchase order: %w", err)
+	}
+
+	if err := r.insertLines(ctx, tx, id, req.Lines); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (r *Repository) insertLines(ctx context.Context, tx *sqlx.Tx, orderID int, lines []LineRequest) error {
+	query := `
+		INSERT INTO purchase_order_lines (purchase_order_id, product_id, ordered_quantity, unit_cost)
+		VALUES ($1, $2, $3, $4)
+	`
+	for _, line := range lines {
+		if _, err := tx.ExecContext(ctx, query, orderID, line.ProductID, line.Quantity, line.UnitCost); err != nil {
+			return fmt.Errorf("error creating purchase order line: %w", err)
+		}
+	}
+	return nil
+}
+
+// reemplaza las lineas de una orden en DRAFT
+func (r *Repository) ReplaceLines(ctx context.Context, tx *sqlx.Tx, orderID int, lines []LineRequest) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM purchase_order_lines WHERE purchase_order_id = $1`, orderID); err != nil {
+		return fmt.Errorf("error deleting purchase order lines: %w", err)
+	}
+	return r.insertLines(ctx, tx, orderID, lines)
+}
+
+// actualiza solo los campos enviados de la cabecera
+func (r *Repository) UpdateOrder(ctx context.Context, tx *sqlx.Tx, id int, req UpdatePurchaseOrderRequest) error {
+	query := `
+		UPDATE purchase_orders
+		SET supplier_reference = COALESCE($1, supplier_reference),
+			expected_at = COALESCE(NULLIF($2, '')::date, expected_at),
+			over_receipt_tolerance = COALESCE($3, over_receipt_tolerance),
+			notes = COALESCE($4, notes)
+		WHERE id = $5
+	`
+
+	_, err := tx.ExecContext(ctx, query, req.SupplierReference, req.ExpectedAt, req.OverReceiptTolerance, req.Notes, id)
+	if err != nil {
+		return fmt.Errorf("error updating purchase order: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) GetOrder(ctx context.Context, id int) (*PurchaseOrder, error) {
+	query := `SELECT ` + orderColumns + ` FROM purchase_orders po JOIN suppliers s ON s.id = po.supplier_id WHERE po.id = $1`
+
+	var order PurchaseOrder
+	err := r.db.GetContext(ctx, &order, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("purchase order with id [%d] not found", id)
+		}
+		return nil, fmt.Errorf("error getting purchase order: %w", err)
+	}
+	return &order, nil
+}
+
+// obtiene la orden con LOCK PESIMISTA para serializar recepciones y cambios de estado
+func (r *Repository) GetOrderForUpdate(ctx context.Context, tx *sqlx.Tx, id int) (*PurchaseOrder, error) {
+	query := `SELECT ` + orderColumns + ` FROM purchase_orders po JOIN suppliers s ON s.id = po.supplier_id
+		WHERE po.id = $1 FOR UPDATE OF po`
+
+	var order PurchaseOrder
+	err := tx.GetContext(ctx, &order, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("purchase order with id [%d] not found", id)
+		}
+		return nil, fmt.Errorf("error getting purchase order: %w", err)
+	}
+	return &order, nil
+}
+
+func (r *Repository) ListOrders(ctx context.Context, filters ListFilters) ([]PurchaseOrder, int, error) {
+	var conditions []string
+	var args []interface{}
+	argPosition := 1
+
+	if filters.SupplierID != nil {
+		conditions = append(conditions, fmt.Sprintf("po.supplier_id = $%d", argPosition))
+		args = append(args, *filters.SupplierID)
+		argPosition++
+	}
+
+	if filters.WarehouseID != nil {
+		conditions = append(conditions, fmt.Sprintf("po.warehouse_id = $%d", argPosition))
+		args = append(args, *filters.WarehouseID)
+		argPosition++
+	}
+
+	if filters.Status != "" {
+		conditions = append(conditions, fmt.Sprintf("po.status = $%d", argPosition))
+		args = append(args, filters.Status)
+		argPosition++
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	err := r.db.GetContext(ctx, &total, "SELECT COUNT(*) FROM purchase_orders po"+where, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error counting purchase orders: %w", err)
+	}
+
+	offset := (filters.Page - 1) * filters.PageSize
+	query := "SELECT " + orderColumns + " FROM purchase_orders po JOIN suppliers s ON s.id = po.supplier_id" + where +
+		fmt.Sprintf(" ORDER BY po.created_at DESC, po.id DESC LIMIT $%d OFFSET $%d", argPosition, argPosition+1)
+	args = append(args, filters.PageSize, offset)
+
+	var orders []PurchaseOrder
+	err = r.db.SelectContext(ctx, &orders, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error listing purchase orders: %w", err)
+	}
+
+	return orders, total, nil
+}
+
+// lista las lineas de una orden (acepta la conexion o una transaccion)
+func (r *Repository) ListLines(ctx context.Context, q sqlx.QueryerContext, orderID int) ([]Line, error) {
+	query := `
+		SELECT pl.id, pl.purchase_order_id, pl.product_id, p.sku, p.name AS product_name,
+			pl.ordered_quantity, pl.received_quantity, pl.unit_cost
+		FROM purchase_order_lines pl
+		JOIN products p ON p.id = pl.product_id
+		WHERE pl.purchase_order_id = $1
+		ORDER BY pl.id
+	`
+
+	var lines []Line
+	if err := sqlx.SelectContext(ctx, q, &lines, query, orderID); err != nil {
+		return nil, fmt.Errorf("error listing purchase order lines: %w", err)
+	}
+	return lines, nil
+}
+
+func (r *Repository) ListReceipts(ctx context.Context, orderID int) ([]Receipt, error) {
+	query := `
+		SELECT id, purchase_order_id, line_id, product_id, movement_id, quantity,
+			COALESCE(received_by, '') AS received_by, received_at
+		FROM purchase_receipts
+		WHERE purchase_order_id = $1
+		ORDER BY received_at, id
+	`
+
+	var receipts []Receipt
+	if err := r.db.SelectContext(ctx, &receipts, query, orderID); err != nil {
+		return nil, fmt.Errorf("error listing purchase receipts: %w", err)
+	}
+	return receipts, nil
+}
+
+// suma lo recibido a la linea y guarda la recepcion con su movimiento
+func (r *Repository) RecordReceipt(ctx context.Context, tx *sqlx.Tx, orderID int, line *Line, movementID, quantity int, receivedBy string) error {
+	query := `UPDATE purchase_order_lines SET received_quantity = received_quantity + $1 WHERE id = $2`
+	if _, err := tx.ExecContext(ctx, query, quantity, line.ID); err != nil {
+		return fmt.Errorf("error updating received quantity: %w", err)
+	}
+
+	receiptQuery := `
+		INSERT INTO purchase_receipts (purchase_order_id, line_id, product_id, movement_id, quantity, received_by)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''))
+	`
+	if _, err := tx.ExecContext(ctx, receiptQuery, orderID, line.ID, line.ProductID, movementID, quantity, receivedBy); err != nil {
+		return fmt.Errorf("error creating purchase receipt: %w", err)
+	}
+	return nil
+}
+
+// cambia el estado y registra cuando se envio o se cerro la orden
+func (r *Repository) UpdateStatus(ctx context.Context, tx *sqlx.Tx, id int, status Status) error {
+	query := `
+		UPDATE purchase_orders
+		SET status = $1,
+			sent_at = CASE WHEN $1 = 'SENT' THEN CURRENT_TIMESTAMP ELSE sent_at END,
+			closed_at = CASE WHEN $1 = 'CLOSED' THEN CURRENT_TIMESTAMP ELSE closed_at END
+		WHERE id = $2
+	`
+
+	if _, err := tx.ExecContext(ctx, query, status, id); err != nil {
+		return fmt.Errorf("error updating purchase order status: %w", err)
+	}
+	return nil
+}
+
+// cuenta cuantos de los productos existen (y no estan eliminados)
+func (r *Repository) CountProducts(ctx context.Context, productIDs []int) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM products WHERE id = ANY($1) AND deleted_at IS NULL`
+
+	if err := r.db.GetContext(ctx, &count, query, pq.Array(productIDs)); err != nil {
+		return 0, fmt.Errorf("error checking products: %w", err)
+	}
+	return count, nil
+}
+
+func (r *Repository) GetDefaultWarehouseID(ctx context.Context) (int, error) {
+	var id int
+	query := `SELECT id FROM warehouses WHERE is_default AND deleted_at IS NULL`
+
+	if err := r.db.GetContext(ctx, &id, query); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("default warehouse not found")
+		}
+		return 0, fmt.Errorf("error getting default warehouse: %w", err)
+	}
+	return id, nil
+}
+
+func (r *Repository) WarehouseExists(ctx context.Context, warehouseID int) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM warehouses WHERE id = $1 AND deleted_at IS NULL)`
+
+	if err := r.db.GetContext(ctx, &exists, query, warehouseID); err != nil {
+		return false, fmt.Errorf("error checking warehouse existence: %w", err)
+	}
+	return exists, nil
+}
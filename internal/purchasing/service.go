@@ -0,0 +1,446 @@
+package purchasing
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+	"github.com/whoAngeel/wms-lite/internal/movement"
+)
+
+type Service struct {
+	repo      *Repository
+	db        *sqlx.DB
+	movements *movement.Service
+	logger    zerolog.Logger
+}
+
+func NewService(repo *Repository, db *sqlx.DB, movements *movement.Service, logger zerolog.Logger) *Service {
+	serviceLogger := logger.With().Str("service", "purchasing").Logger()
+	return &Service{repo: repo, db: db, movements: movements, logger: serviceLogger}
+}
+
+func (s *Service) CreateSupplier(ctx context.Context, req CreateSupplierRequest) (*Supplier, error) {
+	req.Code = strings.TrimSpace(strings.ToUpper(req.Code))
+	req.Name = strings.TrimSpace(req.Name)
+
+	if req.Code == "" {
+		return nil, fmt.Errorf("invalid code: must not be empty")
+	}
+
+	supplier, err := s.repo.CreateSupplier(ctx, req)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			return nil, fmt.Errorf("supplier [%s] already exists", req.Code)
+		}
+		return nil, err
+	}
+
+	s.logger.Info().Int("supplier_id", supplier.ID).Str("code", supplier.Code).Msg("Supplier created")
+	return supplier, nil
+}
+
+func (s *Service) GetSupplier(ctx context.Context, id int) (*Supplier, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("invalid ID: must be greater than 0")
+	}
+	return s.repo.GetSupplier(ctx, id)
+}
+
+func (s *Service) ListSuppliers(ctx context.Context, activeOnly bool) ([]Supplier, error) {
+	suppliers, err := s.repo.ListSuppliers(ctx, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	if suppliers == nil {
+		suppliers = []Supplier{}
+	}
+	return suppliers, nil
+}
+
+func (s *Service) UpdateSupplier(ctx context.Context, id int, req UpdateSupplierRequest) (*Supplier, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("invalid ID: must be greater than 0")
+	}
+	return s.repo.UpdateSupplier(ctx, id, req)
+}
+
+// DeactivateSupplier desactiva un proveedor (no se borra porque las ordenes lo referencian)
+// un proveedor inactivo no acepta ordenes nuevas, las abiertas se pueden seguir recibiendo
+func (s *Service) DeactivateSupplier(ctx context.Context, id int) error {
+	inactive := false
+	_, err := s.UpdateSupplier(ctx, id, UpdateSupplierRequest{IsActive: &inactive})
+	return err
+}
+
+// CreateOrder crea una orden de compra en DRAFT
+func (s *Service) CreateOrder(ctx context.Context, req CreatePurchaseOrderRequest) (order *PurchaseOrder, err error) {
+	supplier, err := s.repo.GetSupplier(ctx, req.SupplierID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, fmt.Errorf("invalid supplier_id: %w", err)
+		}
+		return nil, err
+	}
+	if !supplier.IsActive {
+		return nil, fmt.Errorf("invalid supplier_id: supplier [%s] is inactive", supplier.Code)
+	}
+
+	if req.WarehouseID == 0 {
+		req.WarehouseID, err = s.repo.GetDefaultWarehouseID(ctx)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		exists, err := s.repo.WarehouseExists(ctx, req.WarehouseID)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, fmt.Errorf("invalid warehouse_id: warehouse with id [%d] not found", req.WarehouseID)
+		}
+	}
+
+	if err := s.validateLines(ctx, req.Lines); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	id, err := s.repo.CreateOrder(ctx, tx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	s.logger.Info().Int("purchase_order_id", id).Int("supplier_id", req.SupplierID).Int("lines", len(req.Lines)).Msg("Purchase order created")
+	return s.GetOrder(ctx, id)
+}
+
+// un producto solo puede aparecer en una linea de la orden
+func (s *Service) validateLines(ctx context.Context, lines []LineRequest) error {
+	seen := make(map[int]bool, len(lines))
+	productIDs := make([]int, 0, len(lines))
+	for _, line := range lines {
+		if seen[line.ProductID] {
+			return fmt.Errorf("invalid lines: product [%d] appears more than once", line.ProductID)
+		}
+		seen[line.ProductID] = true
+		productIDs = append(productIDs, line.ProductID)
+	}
+
+	count, err := s.repo.CountProducts(ctx, productIDs)
+	if err != nil {
+		return err
+	}
+	if count != len(productIDs) {
+		return fmt.Errorf("invalid lines: some products were not found")
+	}
+	return nil
+}
+
+// obtiene la orden con sus lineas (faltante/excedente) y sus recepciones
+func (s *Service) GetOrder(ctx context.Context, id int) (*PurchaseOrder, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("invalid ID: must be greater than 0")
+	}
+
+	order, err := s.repo.GetOrder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := s.repo.ListLines(ctx, s.db, id)
+	if err != nil {
+		return nil, err
+	}
+	for i := range lines {
+		lines[i].computeBalance()
+	}
+	order.Lines = lines
+
+	receipts, err := s.repo.ListReceipts(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	order.Receipts = receipts
+
+	return order, nil
+}
+
+func (s *Service) ListOrders(ctx context.Context, filters ListFilters) (*PaginatedResponse, error) {
+	filters.Page, filters.PageSize = normalizePagination(filters.Page, filters.PageSize)
+
+	if filters.Status != "" {
+		status := Status(strings.ToUpper(filters.Status))
+		if !status.IsValid() {
+			return nil, fmt.Errorf("invalid status: must be DRAFT, SENT, PARTIALLY_RECEIVED, RECEIVED or CLOSED")
+		}
+		filters.Status = string(status)
+	}
+
+	orders, total, err := s.repo.ListOrders(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	if orders == nil {
+		orders = []PurchaseOrder{}
+	}
+
+	return &PaginatedResponse{
+		Data: orders,
+		Pagination: Pagination{
+			Page:       filters.Page,
+			PageSize:   filters.PageSize,
+			Total:      total,
+			TotalPages: (total + filters.PageSize - 1) / filters.PageSize,
+		},
+	}, nil
+}
+
+// UpdateOrder cambia la cabecera o reemplaza las lineas mientras la orden sigue en DRAFT
+func (s *Service) UpdateOrder(ctx context.Context, id int, req UpdatePurchaseOrderRequest) (err error) {
+	if req.Lines != nil {
+		if err := s.validateLines(ctx, req.Lines); err != nil {
+			return err
+		}
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	order, err := s.repo.GetOrderForUpdate(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	if order.Status != StatusDraft {
+		return fmt.Errorf("cannot update purchase order: order is %s", order.Status)
+	}
+
+	if err = s.repo.UpdateOrder(ctx, tx, id, req); err != nil {
+		return err
+	}
+
+	if req.Lines != nil {
+		if err = s.repo.ReplaceLines(ctx, tx, id, req.Lines); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+	return nil
+}
+
+// Send marca la orden como enviada al proveedor, desde aqui ya se puede recibir
+func (s *Service) Send(ctx context.Context, id int) error {
+	return s.transition(ctx, id, StatusSent, func(order *PurchaseOrder) error {
+		if order.Status != StatusDraft {
+			return fmt.Errorf("cannot send purchase order: order is %s", order.Status)
+		}
+		return nil
+	})
+}
+
+// Close cierra la orden, lo que falte por recibir ya no se espera (recepcion incompleta)
+func (s *Service) Close(ctx context.Context, id int) error {
+	return s.transition(ctx, id, StatusClosed, func(order *PurchaseOrder) error {
+		if order.Status == StatusClosed {
+			return fmt.Errorf("cannot close purchase order: order is already CLOSED")
+		}
+		return nil
+	})
+}
+
+// cambia el estado de la orden si check lo permite
+func (s *Service) transition(ctx context.Context, id int, to Status, check func(order *PurchaseOrder) error) (err error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	order, err := s.repo.GetOrderForUpdate(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	if err = check(order); err != nil {
+		return err
+	}
+
+	if err = s.repo.UpdateStatus(ctx, tx, id, to); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	s.logger.Info().Int("purchase_order_id", id).Str("status", string(to)).Msg("Purchase order status changed")
+	return nil
+}
+
+// Receive registra la mercancia recibida como movimientos IN en una sola transaccion
+// cada linea acepta hasta lo pedido mas la tolerancia de la orden, lo demas se rechaza (recepcion de mas)
+// al cubrir todas las lineas la orden pasa a RECEIVED, si no a PARTIALLY_RECEIVED
+// las lineas se aplican en orden de product_id para respetar el orden de locks de stock_levels
+func (s *Service) Receive(ctx context.Context, id int, req ReceiveRequest, receivedBy string) (err error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	order, err := s.repo.GetOrderForUpdate(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	if !order.Status.canReceive() {
+		return fmt.Errorf("cannot receive purchase order: order is %s", order.Status)
+	}
+
+	lines, err := s.repo.ListLines(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	linesByID := make(map[int]*Line, len(lines))
+	for i := range lines {
+		linesByID[lines[i].ID] = &lines[i]
+	}
+
+	for _, entry := range req.Lines {
+		if linesByID[entry.LineID] == nil {
+			return fmt.Errorf("purchase order line [%d] not found in order %d", entry.LineID, id)
+		}
+	}
+
+	entries := append([]ReceiveLine(nil), req.Lines...)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return linesByID[entries[i].LineID].ProductID < linesByID[entries[j].LineID].ProductID
+	})
+
+	reason := fmt.Sprintf("purchase order #%d", order.ID)
+	if req.Reason != "" {
+		reason += ": " + req.Reason
+	}
+
+	var posted []*movement.Movement
+	for _, entry := range entries {
+		line := linesByID[entry.LineID]
+
+		unitCost := line.UnitCost
+		if entry.UnitCost != nil {
+			unitCost = entry.UnitCost
+		}
+
+		received, err := s.movements.CreateMovementTx(ctx, tx, movement.CreateMovementRequest{
+			ProductID:      line.ProductID,
+			WarehouseID:    order.WarehouseID,
+			MovementType:   movement.MovementTypeIn,
+			Quantity:       entry.Quantity,
+			UoM:            entry.UoM,
+			Reason:         reason,
+			CreatedBy:      receivedBy,
+			ToLocationID:   entry.ToLocationID,
+			LotNumber:      entry.LotNumber,
+			ManufacturedAt: entry.ManufacturedAt,
+			ExpiresAt:      entry.ExpiresAt,
+			Serials:        entry.Serials,
+			UnitCost:       unitCost,
+		})
+		if err != nil {
+			return fmt.Errorf("error receiving line %d (%s): %w", line.ID, line.SKU, err)
+		}
+
+		// la cantidad del movimiento ya esta en la unidad base, igual que lo pedido
+		limit := line.receiveLimit(order.OverReceiptTolerance)
+		if line.ReceivedQuantity+received.Quantity > limit {
+			return fmt.Errorf("cannot receive %d units on line %d (%s): ordered %d, already received %d, limit %d with %d%% tolerance",
+				received.Quantity, line.ID, line.SKU, line.OrderedQuantity, line.ReceivedQuantity, limit, order.OverReceiptTolerance)
+		}
+
+		if err = s.repo.RecordReceipt(ctx, tx, id, line, received.ID, received.Quantity, receivedBy); err != nil {
+			return err
+		}
+		line.ReceivedQuantity += received.Quantity
+		posted = append(posted, received)
+	}
+
+	status := StatusReceived
+	for _, line := range lines {
+		if line.ReceivedQuantity < line.OrderedQuantity {
+			status = StatusPartiallyReceived
+			break
+		}
+	}
+
+	if status != order.Status {
+		if err = s.repo.UpdateStatus(ctx, tx, id, status); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	s.movements.AfterCommit(ctx, posted...)
+
+	s.logger.Info().Int("purchase_order_id", id).Int("movements", len(posted)).Str("status", string(status)).Msg("Purchase order received")
+	return nil
+}
+
+func normalizePagination(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	if pageSize > 100 {
+		pageSize = 100
+	}
+	return page, pageSize
+}
@@ -0,0 +1,151 @@
+package reasoncode
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+type Handler struct {
+	service *Service
+	logger  zerolog.Logger
+}
+
+func NewHandler(service *Service, logger zerolog.Logger) *Handler {
+	moduleLogger := logger.With().Str("module", "reasoncode").Logger()
+	return &Handler{
+		service: service,
+		logger:  moduleLogger,
+	}
+}
+
+// Create maneja POST /reason-codes
+func (h *Handler) Create(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	var req CreateReasonCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn().Err(err).Msg("Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	reasonCode, err := h.service.Create(ctx, req)
+	if err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error().Err(err).Str("code", req.Code).Msg("Error creating reason code")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creating reason code"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, reasonCode)
+}
+
+// List maneja GET /reason-codes
+// por defecto solo lista los activos, ?include_inactive=true lista todos
+func (h *Handler) List(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	activeOnly := c.Query("include_inactive") != "true"
+
+	reasonCodes, err := h.service.List(ctx, activeOnly)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Error listing reason codes")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error listing reason codes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": reasonCodes})
+}
+
+// GetByCode maneja GET /reason-codes/:code
+func (h *Handler) GetByCode(c *gin.Context) {
+	code := c.Param("code")
+
+	reasonCode, err := h.service.GetByCode(c.Request.Context(), code)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error().Err(err).Str("code", code).Msg("Error getting reason code")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, reasonCode)
+}
+
+// Update maneja PUT /reason-codes/:code
+func (h *Handler) Update(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	code := c.Param("code")
+
+	var req UpdateReasonCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	reasonCode, err := h.service.Update(ctx, code, req)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error().Err(err).Str("code", code).Msg("Error updating reason code")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error updating reason code"})
+		return
+	}
+
+	c.JSON(http.StatusOK, reasonCode)
+}
+
+// Delete maneja DELETE /reason-codes/:code (desactiva el codigo)
+func (h *Handler) Delete(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	code := c.Param("code")
+
+	if err := h.service.Deactivate(ctx, code); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error().Err(err).Str("code", code).Msg("Failed to deactivate reason code")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to deactivate reason code"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Reason code deactivated successfully"})
+}
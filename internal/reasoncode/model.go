@@ -0,0 +1,39 @@
+package reasoncode
+
+import "time"
+
+// Direction indica hacia donde puede ajustar el stock un codigo de razon
+type Direction string
+
+const (
+	DirectionIncrease Direction = "INCREASE"
+	DirectionDecrease Direction = "DECREASE"
+	DirectionBoth     Direction = "BOTH"
+)
+
+// isValid verifica si la direccion es valida
+func (d Direction) IsValid() bool {
+	return d == DirectionIncrease || d == DirectionDecrease || d == DirectionBoth
+}
+
+type ReasonCode struct {
+	Code        string    `json:"code" db:"code"`
+	Description string    `json:"description" db:"description"`
+	Direction   Direction `json:"direction" db:"direction"`
+	IsActive    bool      `json:"is_active" db:"is_active"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type CreateReasonCodeRequest struct {
+	Code        string    `json:"code" binding:"required,max=30"`
+	Description string    `json:"description" binding:"required,max=255"`
+	Direction   Direction `json:"direction"` // opcional, por defecto BOTH
+}
+
+// UpdateReasonCodeRequest solo actualiza los campos enviados
+type UpdateReasonCodeRequest struct {
+	Description *string    `json:"description" binding:"omitempty,max=255"`
+	Direction   *Direction `json:"direction"`
+	IsActive    *bool      `json:"is_active"`
+}
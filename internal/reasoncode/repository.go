@@ -0,0 +1,86 @@
+package reasoncode
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+)
+
+const reasonCodeColumns = `code, description, direction, is_active, created_at, updated_at`
+
+type Repository struct {
+	db     *sqlx.DB
+	logger zerolog.Logger
+}
+
+func NewRepository(db *sqlx.DB, logger zerolog.Logger) *Repository {
+	moduleLogger := logger.With().Str("module", "reasoncode").Logger()
+	return &Repository{db: db, logger: moduleLogger}
+}
+
+func (r *Repository) Create(ctx context.Context, req CreateReasonCodeRequest) (*ReasonCode, error) {
+	query := `
+		INSERT INTO reason_codes (code, description, direction)
+		VALUES ($1, $2, $3)
+		RETURNING ` + reasonCodeColumns
+
+	var reasonCode ReasonCode
+	err := r.db.GetContext(ctx, &reasonCode, query, req.Code, req.Description, req.Direction)
+	if err != nil {
+		return nil, fmt.Errorf("error creating reason code: %w", err)
+	}
+	return &reasonCode, nil
+}
+
+func (r *Repository) GetByCode(ctx context.Context, code string) (*ReasonCode, error) {
+	query := `SELECT ` + reasonCodeColumns + ` FROM reason_codes WHERE code = $1`
+
+	var reasonCode ReasonCode
+	err := r.db.GetContext(ctx, &reasonCode, query, code)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("reason code [%s] not found", code)
+		}
+		return nil, fmt.Errorf("error getting reason code: %w", err)
+	}
+	return &reasonCode, nil
+}
+
+// lista el catalogo, opcionalmente solo los codigos activos
+func (r *Repository) List(ctx context.Context, activeOnly bool) ([]ReasonCode, error) {
+	query := `SELECT ` + reasonCodeColumns + ` FROM reason_codes`
+	if activeOnly {
+		query += ` WHERE is_active`
+	}
+	query += ` ORDER BY code`
+
+	var reasonCodes []ReasonCode
+	if err := r.db.SelectContext(ctx, &reasonCodes, query); err != nil {
+		return nil, fmt.Errorf("error listing reason codes: %w", err)
+	}
+	return reasonCodes, nil
+}
+
+// actualiza solo los campos enviados
+func (r *Repository) Update(ctx context.Context, code string, req UpdateReasonCodeRequest) (*ReasonCode, error) {
+	query := `
+		UPDATE reason_codes
+		SET description = COALESCE($1, description),
+			direction = COALESCE($2, direction),
+			is_active = COALESCE($3, is_active)
+		WHERE code = $4
+		RETURNING ` + reasonCodeColumns
+
+	var reasonCode ReasonCode
+	err := r.db.GetContext(ctx, &reasonCode, query, req.Description, req.Direction, req.IsActive, code)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("reason code [%s] not found", code)
+		}
+		return nil, fmt.Errorf("error updating reason code: %w", err)
+	}
+	return &reasonCode, nil
+}
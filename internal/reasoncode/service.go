@@ -0,0 +1,82 @@
+package reasoncode
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+type Service struct {
+	repo   *Repository
+	logger zerolog.Logger
+}
+
+func NewService(repo *Repository, logger zerolog.Logger) *Service {
+	serviceLogger := logger.With().Str("service", "reasoncode").Logger()
+	return &Service{repo: repo, logger: serviceLogger}
+}
+
+func (s *Service) Create(ctx context.Context, req CreateReasonCodeRequest) (*ReasonCode, error) {
+	req.Code = strings.TrimSpace(strings.ToUpper(req.Code))
+	req.Description = strings.TrimSpace(req.Description)
+	req.Direction = Direction(strings.ToUpper(string(req.Direction)))
+
+	if req.Code == "" {
+		return nil, fmt.Errorf("invalid code: must not be empty")
+	}
+
+	if req.Direction == "" {
+		req.Direction = DirectionBoth
+	}
+	if !req.Direction.IsValid() {
+		return nil, fmt.Errorf("invalid direction: must be INCREASE, DECREASE or BOTH")
+	}
+
+	reasonCode, err := s.repo.Create(ctx, req)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			return nil, fmt.Errorf("reason code [%s] already exists", req.Code)
+		}
+		return nil, err
+	}
+
+	s.logger.Info().Str("code", reasonCode.Code).Msg("Reason code created")
+	return reasonCode, nil
+}
+
+func (s *Service) GetByCode(ctx context.Context, code string) (*ReasonCode, error) {
+	return s.repo.GetByCode(ctx, strings.ToUpper(strings.TrimSpace(code)))
+}
+
+func (s *Service) List(ctx context.Context, activeOnly bool) ([]ReasonCode, error) {
+	reasonCodes, err := s.repo.List(ctx, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	if reasonCodes == nil {
+		reasonCodes = []ReasonCode{}
+	}
+	return reasonCodes, nil
+}
+
+func (s *Service) Update(ctx context.Context, code string, req UpdateReasonCodeRequest) (*ReasonCode, error) {
+	if req.Direction != nil {
+		direction := Direction(strings.ToUpper(string(*req.Direction)))
+		if !direction.IsValid() {
+			return nil, fmt.Errorf("invalid direction: must be INCREASE, DECREASE or BOTH")
+		}
+		req.Direction = &direction
+	}
+
+	return s.repo.Update(ctx, strings.ToUpper(strings.TrimSpace(code)), req)
+}
+
+// Deactivate desactiva un codigo (no se borra porque los movimientos lo referencian)
+func (s *Service) Deactivate(ctx context.Context, code string) error {
+	inactive := false
+	_, err := s.Update(ctx, code, UpdateReasonCodeRequest{IsActive: &inactive})
+	return err
+}
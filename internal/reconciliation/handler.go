@@ -0,0 +1,132 @@
+package reconciliation
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+type Handler struct {
+	service *Service
+	logger  zerolog.Logger
+}
+
+func NewHandler(service *Service, logger zerolog.Logger) *Handler {
+	moduleLogger := logger.With().Str("module", "reconciliation").Logger()
+	return &Handler{
+		service: service,
+		logger:  moduleLogger,
+	}
+}
+
+// Check maneja GET /reconciliation?product_id=
+// @Summary Comparar el stock contra el ledger de movimientos
+// @Description Lista los productos cuyo stock_quantity, la suma de sus almacenes o el stock de algun almacen no cuadra con lo que explican los movimientos (solo admins)
+// @Tags reconciliation
+// @Produce json
+// @Param product_id query int false "Revisar solo este producto"
+// @Success 200 {object} ReconciliationReport
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /reconciliation [get]
+func (h *Handler) Check(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 60*time.Second)
+	defer cancel()
+
+	var filters CheckFilters
+	if err := c.ShouldBindQuery(&filters); err != nil {
+		h.logger.Warn().Err(err).Msg("Invalid query parameters for reconciliation")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+
+	report, err := h.service.Check(ctx, filters)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Error checking stock against the movement ledger")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error checking stock reconciliation"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// Repair maneja POST /reconciliation/repair
+// @Summary Reparar las diferencias de stock contra el ledger
+// @Description Registra un ajuste RECONCILIATION por la diferencia de cada almacen y deja stock_quantity igual a la suma de los almacenes, todo en una transaccion (solo admins)
+// @Description Si un producto falla no se repara ninguno y el error indica el producto
+// @Tags reconciliation
+// @Accept json
+// @Produce json
+// @Param request body RepairRequest false "Producto a reparar (sin body se reparan todos)"
+// @Success 200 {object} ReconciliationReport
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /reconciliation/repair [post]
+func (h *Handler) Repair(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 60*time.Second)
+	defer cancel()
+
+	// el body es opcional: sin product_id se reparan todos los productos
+	var req RepairRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.logger.Warn().Err(err).Msg("Invalid request body for reconciliation repair")
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid data",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	if email, exists := c.Get("email"); exists {
+		req.CreatedBy = email.(string)
+	}
+
+	report, err := h.service.Repair(ctx, req)
+	if err != nil {
+		h.respondRepairError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// mapea el fallo de una reparacion: el producto que fallo va en el mensaje
+// un producto que ya no existe es 404, una validacion del ajuste es 409 y lo demas 500
+func (h *Handler) respondRepairError(c *gin.Context, err error) {
+	var repairErr *RepairError
+	if !errors.As(err, &repairErr) {
+		h.logger.Error().Err(err).Msg("Error repairing stock discrepancies")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error repairing stock discrepancies"})
+		return
+	}
+
+	statusCode := http.StatusInternalServerError
+	cause := repairErr.Err.Error()
+	switch {
+	case strings.Contains(cause, "not found"):
+		statusCode = http.StatusNotFound
+	case strings.Contains(cause, "invalid") || strings.Contains(cause, "must be") || strings.Contains(cause, "cannot"):
+		statusCode = http.StatusConflict
+	}
+
+	if statusCode == http.StatusInternalServerError {
+		h.logger.Error().Err(err).Int("product_id", repairErr.ProductID).Msg("Error repairing stock discrepancies")
+	} else {
+		h.logger.Warn().Err(err).Int("product_id", repairErr.ProductID).Msg("Stock repair rejected")
+	}
+	c.JSON(statusCode, gin.H{"error": repairErr.Error()})
+}
+
+// ErrorResponse representa un error HTTP
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
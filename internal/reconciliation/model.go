@@ -0,0 +1,100 @@
+package reconciliation
+
+import (
+	"fmt"
+	"time"
+)
+
+// CheckFilters son los filtros opcionales de GET /reconciliation
+type CheckFilters struct {
+	ProductID *int `form:"product_id" binding:"omitempty,min=1"`
+}
+
+// RepairRequest es el body de POST /reconciliation/repair (sin product_id repara todos)
+type RepairRequest struct {
+	ProductID *int   `json:"product_id" binding:"omitempty,min=1"`
+	CreatedBy string `json:"-"`
+}
+
+// WarehouseDiscrepancy es la diferencia en un almacen entre stock_levels y lo que explica el ledger
+type WarehouseDiscrepancy struct {
+	WarehouseID    int    `json:"warehouse_id"`
+	WarehouseCode  string `json:"warehouse_code"`
+	StockQuantity  int    `json:"stock_quantity"`
+	LedgerQuantity int    `json:"ledger_quantity"`
+	Difference     int    `json:"difference"` // stock_quantity - ledger_quantity
+	// ajuste RECONCILIATION que registro la reparacion
+	MovementID *int `json:"movement_id,omitempty"`
+}
+
+// ProductDiscrepancy es un producto cuyo stock no cuadra con el ledger de movimientos
+// StockQuantity es products.stock_quantity y WarehouseTotal la suma de sus stock_levels
+type ProductDiscrepancy struct {
+	ProductID      int                    `json:"product_id"`
+	SKU            string                 `json:"sku"`
+	ProductName    string                 `json:"product_name"`
+	StockQuantity  int                    `json:"stock_quantity"`
+	WarehouseTotal int                    `json:"warehouse_total"`
+	LedgerQuantity int                    `json:"ledger_quantity"`
+	Difference     int                    `json:"difference"` // stock_quantity - ledger_quantity
+	Warehouses     []WarehouseDiscrepancy `json:"warehouses"`
+	Repaired       bool                   `json:"repaired"`
+	// reparacion registrada (stock_reconciliations): el stock_quantity que tenia el producto y el que quedo
+	ReconciliationID      *int `json:"reconciliation_id,omitempty"`
+	PreviousStockQuantity *int `json:"previous_stock_quantity,omitempty"`
+	RepairedStockQuantity *int `json:"repaired_stock_quantity,omitempty"`
+}
+
+// productRepair es lo que registro la reparacion de un producto
+type productRepair struct {
+	reconciliationID int
+	previousStock    int
+	stock            int
+	warehouses       []WarehouseDiscrepancy
+}
+
+// RepairError es el fallo al reparar un producto: la transaccion se revierte y no queda reparado ninguno
+type RepairError struct {
+	ProductID int
+	SKU       string
+	Err       error
+}
+
+func (e *RepairError) Error() string {
+	return fmt.Sprintf("cannot repair product [%s] (id %d), no product was repaired: %v", e.SKU, e.ProductID, e.Err)
+}
+
+func (e *RepairError) Unwrap() error {
+	return e.Err
+}
+
+// ReconciliationReport es el resultado de comparar el stock contra el ledger
+type ReconciliationReport struct {
+	CheckedAt     time.Time            `json:"checked_at"`
+	Repair        bool                 `json:"repair"`
+	Discrepancies int                  `json:"discrepancies"`
+	Products      []ProductDiscrepancy `json:"products"`
+}
+
+// discrepancyRow es una fila de la consulta de diferencias: el producto y uno de sus almacenes
+// (los campos del almacen son nulos si el producto no tiene stock_levels ni movimientos)
+type discrepancyRow struct {
+	ProductID      int     `db:"product_id"`
+	SKU            string  `db:"sku"`
+	ProductName    string  `db:"product_name"`
+	ProductStock   int     `db:"product_stock"`
+	WarehouseTotal int     `db:"warehouse_total"`
+	LedgerTotal    int     `db:"ledger_total"`
+	WarehouseID    *int    `db:"warehouse_id"`
+	WarehouseCode  *string `db:"warehouse_code"`
+	StockQuantity  *int    `db:"stock_quantity"`
+	LedgerQuantity *int    `db:"ledger_quantity"`
+}
+
+// warehouseBalance es el stock de un producto en un almacen segun stock_levels y segun el ledger
+type warehouseBalance struct {
+	WarehouseID    int    `db:"warehouse_id"`
+	WarehouseCode  string `db:"warehouse_code"`
+	StockQuantity  int    `db:"stock_quantity"`
+	LedgerQuantity int    `db:"ledger_quantity"`
+}
@@ -0,0 +1,170 @@
+package reconciliation
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+)
+
+type Repository struct {
+	db     *sqlx.DB
+	logger zerolog.Logger
+}
+
+func NewRepository(db *sqlx.DB, logger zerolog.Logger) *Repository {
+	moduleLogger := logger.With().Str("module", "reconciliation").Logger()
+	return &Repository{db: db, logger: moduleLogger}
+}
+
+// ledgerBalances calcula `balances`: por producto y almacen el stock de stock_levels y el que explica
+// el ledger (ultimo checkpoint del par mas los movimientos desde ese checkpoint; como cada dia cerrado
+// tiene checkpoint de lo que se movio, solo se recorre el ledger posterior al ultimo dia cerrado)
+// $1 filtra por producto (nulo = todos)
+const ledgerBalances = `
+	WITH closed AS (
+		SELECT COALESCE(MAX(checkpoint_at), '-infinity'::timestamp) AS checkpoint_at
+		FROM stock_checkpoint_runs
+	),
+	last_checkpoints AS (
+		SELECT c.product_id, c.warehouse_id, c.checkpoint_at, c.quantity
+		FROM stock_levels sl
+		CROSS JOIN LATERAL (
+			SELECT sc.product_id, sc.warehouse_id, sc.checkpoint_at, sc.quantity
+			FROM stock_checkpoints sc
+			WHERE sc.product_id = sl.product_id AND sc.warehouse_id = sl.warehouse_id
+			ORDER BY sc.checkpoint_at DESC
+			LIMIT 1
+		) c
+		WHERE $1::int IS NULL OR sl.product_id = $1
+	),
+	ledger AS (
+		SELECT product_id, warehouse_id, SUM(quantity) AS quantity
+		FROM (
+			SELECT product_id, warehouse_id, quantity FROM last_checkpoints
+			UNION ALL
+			SELECT l.product_id, l.warehouse_id, l.quantity
+			FROM stock_ledger l
+			CROSS JOIN closed
+			LEFT JOIN last_checkpoints c ON c.product_id = l.product_id AND c.warehouse_id = l.warehouse_id
+			WHERE l.created_at >= closed.checkpoint_at
+				AND l.created_at >= COALESCE(c.checkpoint_at, '-infinity'::timestamp)
+				AND ($1::int IS NULL OR l.product_id = $1)
+		) b
+		GROUP BY product_id, warehouse_id
+	),
+	balances AS (
+		SELECT COALESCE(sl.product_id, l.product_id) AS product_id, COALESCE(sl.warehouse_id, l.warehouse_id) AS warehouse_id,
+			COALESCE(sl.quantity, 0) AS stock_quantity, COALESCE(l.quantity, 0) AS ledger_quantity
+		FROM (
+			SELECT product_id, warehouse_id, quantity
+			FROM stock_levels
+			WHERE $1::int IS NULL OR product_id = $1
+		) sl
+		FULL JOIN ledger l ON l.product_id = sl.product_id AND l.warehouse_id = sl.warehouse_id
+	)`
+
+// ListDiscrepancies devuelve los productos cuyo stock_quantity, la suma de sus almacenes o alguno de
+// sus almacenes no cuadra con el ledger; una fila por producto y almacen
+func (r *Repository) ListDiscrepancies(ctx context.Context, productID *int) ([]discrepancyRow, error) {
+	query := ledgerBalances + `,
+		checked AS (
+			SELECT p.id AS product_id, p.sku, p.name AS product_name, p.stock_quantity AS product_stock,
+				b.warehouse_id, b.stock_quantity, b.ledger_quantity,
+				COALESCE(SUM(b.stock_quantity) OVER w, 0) AS warehouse_total,
+				COALESCE(SUM(b.ledger_quantity) OVER w, 0) AS ledger_total,
+				COALESCE(BOOL_OR(b.stock_quantity <> b.ledger_quantity) OVER w, FALSE) AS warehouse_drift
+			FROM products p
+			LEFT JOIN balances b ON b.product_id = p.id
+			WHERE $1::int IS NULL OR p.id = $1
+			WINDOW w AS (PARTITION BY p.id)
+		)
+		SELECT c.product_id, c.sku, c.product_name, c.product_stock, c.warehouse_total, c.ledger_total,
+			c.warehouse_id, wh.code AS warehouse_code, c.stock_quantity, c.ledger_quantity
+		FROM checked c
+		LEFT JOIN warehouses wh ON wh.id = c.warehouse_id
+		WHERE c.warehouse_drift OR c.product_stock <> c.warehouse_total OR c.product_stock <> c.ledger_total
+		ORDER BY c.sku, wh.code
+	`
+
+	var rows []discrepancyRow
+	if err := r.db.SelectContext(ctx, &rows, query, productID); err != nil {
+		return nil, fmt.Errorf("error listing stock discrepancies: %w", err)
+	}
+	return rows, nil
+}
+
+// LockStockLevels bloquea las filas de stock_levels del producto en orden ascendente de almacen
+// mientras estan bloqueadas ningun movimiento del producto puede confirmarse
+func (r *Repository) LockStockLevels(ctx context.Context, tx *sqlx.Tx, productID int) error {
+	query := `
+		SELECT warehouse_id
+		FROM stock_levels
+		WHERE product_id = $1
+		ORDER BY warehouse_id
+		FOR UPDATE
+	`
+
+	var warehouseIDs []int
+	if err := tx.SelectContext(ctx, &warehouseIDs, query, productID); err != nil {
+		return fmt.Errorf("error locking stock levels: %w", err)
+	}
+	return nil
+}
+
+// GetBalances obtiene por almacen el stock del producto segun stock_levels y segun el ledger
+func (r *Repository) GetBalances(ctx context.Context, tx *sqlx.Tx, productID int) ([]warehouseBalance, error) {
+	query := ledgerBalances + `
+		SELECT b.warehouse_id, wh.code AS warehouse_code, b.stock_quantity, b.ledger_quantity
+		FROM balances b
+		JOIN warehouses wh ON wh.id = b.warehouse_id
+		ORDER BY b.warehouse_id
+	`
+
+	var balances []warehouseBalance
+	if err := tx.SelectContext(ctx, &balances, query, productID); err != nil {
+		return nil, fmt.Errorf("error getting stock balances: %w", err)
+	}
+	return balances, nil
+}
+
+// GetProductStockForUpdate obtiene products.stock_quantity con lock (despues de bloquear sus stock_levels)
+func (r *Repository) GetProductStockForUpdate(ctx context.Context, tx *sqlx.Tx, productID int) (int, error) {
+	query := `SELECT stock_quantity FROM products WHERE id = $1 FOR UPDATE`
+
+	var stock int
+	if err := tx.GetContext(ctx, &stock, query, productID); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("product with ID [%d] not found", productID)
+		}
+		return 0, fmt.Errorf("error getting product stock: %w", err)
+	}
+	return stock, nil
+}
+
+// CreateReconciliation registra la reparacion de un producto con el stock_quantity que tenia y el que queda
+func (r *Repository) CreateReconciliation(ctx context.Context, tx *sqlx.Tx, productID, previousStock, stock int, createdBy string) (int, error) {
+	query := `
+		INSERT INTO stock_reconciliations (product_id, previous_stock_quantity, stock_quantity, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+
+	var id int
+	if err := tx.QueryRowxContext(ctx, query, productID, previousStock, stock, createdBy).Scan(&id); err != nil {
+		return 0, fmt.Errorf("error creating stock reconciliation: %w", err)
+	}
+	return id, nil
+}
+
+// SetProductStock deja el total del producto igual a la suma de sus almacenes
+func (r *Repository) SetProductStock(ctx context.Context, tx *sqlx.Tx, productID, quantity int) error {
+	query := `UPDATE products SET stock_quantity = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $1`
+
+	if _, err := tx.ExecContext(ctx, query, productID, quantity); err != nil {
+		return fmt.Errorf("error updating product stock: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+package reconciliation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+	"github.com/whoAngeel/wms-lite/internal/alert"
+	"github.com/whoAngeel/wms-lite/internal/movement"
+	"github.com/whoAngeel/wms-lite/internal/platform"
+)
+
+type Service struct {
+	repo      *Repository
+	db        *sqlx.DB
+	movements *movement.Service
+	cache     *platform.Cache
+	alerts    *alert.Service
+	logger    zerolog.Logger
+}
+
+func NewService(repo *Repository, db *sqlx.DB, movements *movement.Service, cache *platform.Cache, alerts *alert.Service, logger zerolog.Logger) *Service {
+	serviceLogger := logger.With().Str("service", "reconciliation").Logger()
+	return &Service{repo: repo, db: db, movements: movements, cache: cache, alerts: alerts, logger: serviceLogger}
+}
+
+// Check compara el stock de cada producto (products.stock_quantity y stock_levels por almacen)
+// contra el que explica el ledger de movimientos y devuelve los que no cuadran
+func (s *Service) Check(ctx context.Context, filters CheckFilters) (*ReconciliationReport, error) {
+	rows, err := s.repo.ListDiscrepancies(ctx, filters.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ReconciliationReport{
+		CheckedAt: time.Now().UTC(),
+		Products:  []ProductDiscrepancy{},
+	}
+	for _, row := range rows {
+		if n := len(report.Products); n == 0 || report.Products[n-1].ProductID != row.ProductID {
+			report.Products = append(report.Products, ProductDiscrepancy{
+				ProductID:      row.ProductID,
+				SKU:            row.SKU,
+				ProductName:    row.ProductName,
+				StockQuantity:  row.ProductStock,
+				WarehouseTotal: row.WarehouseTotal,
+				LedgerQuantity: row.LedgerTotal,
+				Difference:     row.ProductStock - row.LedgerTotal,
+				Warehouses:     []WarehouseDiscrepancy{},
+			})
+		}
+
+		// solo se listan los almacenes que no cuadran
+		if row.WarehouseID == nil || *row.StockQuantity == *row.LedgerQuantity {
+			continue
+		}
+		product := &report.Products[len(report.Products)-1]
+		product.Warehouses = append(product.Warehouses, WarehouseDiscrepancy{
+			WarehouseID:    *row.WarehouseID,
+			WarehouseCode:  *row.WarehouseCode,
+			StockQuantity:  *row.StockQuantity,
+			LedgerQuantity: *row.LedgerQuantity,
+			Difference:     *row.StockQuantity - *row.LedgerQuantity,
+		})
+	}
+	report.Discrepancies = len(report.Products)
+
+	return report, nil
+}
+
+// Repair concilia los productos con diferencias en una sola transaccion:
+// 1. por producto (en orden de product_id) bloquea sus stock_levels y despues el producto
+// 2. recalcula por almacen el stock contra el ledger con los datos ya bloqueados
+// 3. registra la reparacion (stock_reconciliations) con el stock_quantity que tenia y el que queda
+// 4. registra un ajuste RECONCILIATION por la diferencia de cada almacen con movement.CreateMovementTx
+// 5. deja products.stock_quantity igual a la suma de sus almacenes
+// stock_levels se toma como el stock real: es lo que bloquean y validan todos los movimientos
+// los ajustes solo se registran (no mueven stock, lotes, series ni valor), asi el ledger cuadra con stock_levels
+// si un producto falla no se repara ninguno y el error (*RepairError) indica cual fue
+func (s *Service) Repair(ctx context.Context, req RepairRequest) (report *ReconciliationReport, err error) {
+	report, err = s.Check(ctx, CheckFilters{ProductID: req.ProductID})
+	if err != nil {
+		return nil, err
+	}
+	report.Repair = true
+
+	if report.Discrepancies == 0 {
+		return report, nil
+	}
+
+	// los locks se toman en orden ascendente de producto, como los demas modulos que mueven varios productos
+	order := make([]*ProductDiscrepancy, len(report.Products))
+	for i := range report.Products {
+		order[i] = &report.Products[i]
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return order[i].ProductID < order[j].ProductID
+	})
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	for _, product := range order {
+		repair, err := s.repairProduct(ctx, tx, product.ProductID, req.CreatedBy)
+		if err != nil {
+			return nil, &RepairError{ProductID: product.ProductID, SKU: product.SKU, Err: err}
+		}
+		product.Warehouses = repair.warehouses
+		product.Repaired = true
+		product.ReconciliationID = &repair.reconciliationID
+		product.PreviousStockQuantity = &repair.previousStock
+		product.RepairedStockQuantity = &repair.stock
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	for i := range report.Products {
+		product := &report.Products[i]
+		if *product.RepairedStockQuantity != *product.PreviousStockQuantity {
+			s.logger.Info().Int("product_id", product.ProductID).Int("previous_stock", *product.PreviousStockQuantity).
+				Int("stock", *product.RepairedStockQuantity).Str("created_by", req.CreatedBy).Msg("Product stock corrected")
+		}
+		s.afterRepair(ctx, product)
+	}
+
+	s.logger.Info().Int("products", report.Discrepancies).Str("created_by", req.CreatedBy).Msg("Stock discrepancies repaired")
+	return report, nil
+}
+
+// concilia un producto dentro de la transaccion de Repair y devuelve lo registrado
+func (s *Service) repairProduct(ctx context.Context, tx *sqlx.Tx, productID int, createdBy string) (*productRepair, error) {
+	if err := s.repo.LockStockLevels(ctx, tx, productID); err != nil {
+		return nil, err
+	}
+
+	balances, err := s.repo.GetBalances(ctx, tx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	previousStock, err := s.repo.GetProductStockForUpdate(ctx, tx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	repair := &productRepair{previousStock: previousStock, warehouses: []WarehouseDiscrepancy{}}
+	for _, balance := range balances {
+		repair.stock += balance.StockQuantity
+	}
+
+	repair.reconciliationID, err = s.repo.CreateReconciliation(ctx, tx, productID, previousStock, repair.stock, createdBy)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, balance := range balances {
+		difference := balance.StockQuantity - balance.LedgerQuantity
+		if difference == 0 {
+			continue
+		}
+
+		req := movement.ReconciliationRequest(productID, balance.WarehouseID, difference, repair.reconciliationID, createdBy)
+		adjustment, err := s.movements.CreateMovementTx(ctx, tx, req)
+		if err != nil {
+			return nil, fmt.Errorf("error posting reconciliation adjustment in warehouse %s: %w", balance.WarehouseCode, err)
+		}
+		repair.warehouses = append(repair.warehouses, WarehouseDiscrepancy{
+			WarehouseID:    balance.WarehouseID,
+			WarehouseCode:  balance.WarehouseCode,
+			StockQuantity:  balance.StockQuantity,
+			LedgerQuantity: balance.LedgerQuantity,
+			Difference:     difference,
+			MovementID:     &adjustment.ID,
+		})
+	}
+
+	if repair.stock != previousStock {
+		if err := s.repo.SetProductStock(ctx, tx, productID, repair.stock); err != nil {
+			return nil, err
+		}
+	}
+	return repair, nil
+}
+
+// invalida la cache del producto (puede cambiar su stock_quantity) y revisa su alerta de stock
+// un fallo de cache no revierte nada, solo se registra
+func (s *Service) afterRepair(ctx context.Context, product *ProductDiscrepancy) {
+	cacheKeys := []string{
+		fmt.Sprintf("product:%d", product.ProductID),
+		fmt.Sprintf("product:sku:%s", product.SKU),
+	}
+
+	if err := s.cache.Del(ctx, cacheKeys...); err != nil {
+		s.logger.Warn().Err(err).Int("product_id", product.ProductID).Msg("Failed to invalidate product cache")
+	}
+	s.alerts.CheckProduct(ctx, product.ProductID)
+}
@@ -0,0 +1,117 @@
+package report
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+type Handler struct {
+	service *Service
+	logger  zerolog.Logger
+}
+
+func NewHandler(service *Service, logger zerolog.Logger) *Handler {
+	moduleLogger := logger.With().Str("module", "report").Logger()
+	return &Handler{
+		service: service,
+		logger:  moduleLogger,
+	}
+}
+
+// Valuation maneja GET /reports/valuation?as_of=YYYY-MM-DD&warehouse_id=
+func (h *Handler) Valuation(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	var filters ValuationFilters
+	if err := c.ShouldBindQuery(&filters); err != nil {
+		h.logger.Warn().Err(err).Msg("Invalid query parameters for valuation report")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+
+	report, err := h.service.Valuation(ctx, filters)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error().Err(err).Msg("Error calculating valuation report")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error calculating valuation report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// StockSnapshot maneja GET /reports/stock-snapshot?as_of=&warehouse_id=
+func (h *Handler) StockSnapshot(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	var filters StockAsOfFilters
+	if err := c.ShouldBindQuery(&filters); err != nil {
+		h.logger.Warn().Err(err).Msg("Invalid query parameters for stock snapshot")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+
+	report, err := h.service.StockSnapshot(ctx, filters)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error().Err(err).Msg("Error calculating stock snapshot")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error calculating stock snapshot"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// ProductStock maneja GET /products/:id/stock?as_of=&warehouse_id=
+func (h *Handler) ProductStock(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid product ID, must be a number"})
+		return
+	}
+
+	var filters StockAsOfFilters
+	if err := c.ShouldBindQuery(&filters); err != nil {
+		h.logger.Warn().Err(err).Msg("Invalid query parameters for product stock")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+
+	stock, err := h.service.ProductStock(ctx, id, filters)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error().Err(err).Int("product_id", id).Msg("Error calculating product stock")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error calculating product stock"})
+		return
+	}
+
+	c.JSON(http.StatusOK, stock)
+}
@@ -0,0 +1,69 @@
+package report
+
+// ValuationFilters son los filtros opcionales de GET /reports/valuation
+type ValuationFilters struct {
+	AsOf        string `form:"as_of" binding:"omitempty,datetime=2006-01-02"` // valor al cierre del dia indicado, por defecto el actual
+	WarehouseID *int   `form:"warehouse_id" binding:"omitempty,min=1"`
+}
+
+// ValuationLine es el stock de un producto y lo que vale
+// UnitCost es el costo promedio del stock (value / quantity)
+type ValuationLine struct {
+	ProductID   int     `json:"product_id" db:"product_id"`
+	SKU         string  `json:"sku" db:"sku"`
+	ProductName string  `json:"product_name" db:"product_name"`
+	Quantity    int     `json:"quantity" db:"quantity"` // en la unidad base
+	UnitCost    float64 `json:"unit_cost" db:"-"`
+	Value       float64 `json:"value" db:"value"`
+}
+
+// ValuationReport es el valor del inventario a una fecha
+type ValuationReport struct {
+	AsOf          string          `json:"as_of,omitempty"`
+	WarehouseID   *int            `json:"warehouse_id,omitempty"`
+	CostingMethod string          `json:"costing_method"`
+	TotalQuantity int             `json:"total_quantity"`
+	TotalValue    float64         `json:"total_value"`
+	Lines         []ValuationLine `json:"lines"`
+}
+
+// StockAsOfFilters son los filtros de GET /products/:id/stock y GET /reports/stock-snapshot
+// as_of acepta una fecha (stock al cierre del dia) o un instante RFC3339, por defecto el actual
+type StockAsOfFilters struct {
+	AsOf        string `form:"as_of"`
+	WarehouseID *int   `form:"warehouse_id" binding:"omitempty,min=1"`
+}
+
+// StockSnapshotLine es el stock de un producto en un almacen a una fecha
+type StockSnapshotLine struct {
+	ProductID     int    `json:"product_id" db:"product_id"`
+	SKU           string `json:"sku" db:"sku"`
+	ProductName   string `json:"product_name" db:"product_name"`
+	WarehouseID   int    `json:"warehouse_id" db:"warehouse_id"`
+	WarehouseCode string `json:"warehouse_code" db:"warehouse_code"`
+	Quantity      int    `json:"quantity" db:"quantity"` // en la unidad base
+}
+
+// StockSnapshotReport es el stock de todos los productos a una fecha
+type StockSnapshotReport struct {
+	AsOf          string              `json:"as_of,omitempty"`
+	WarehouseID   *int                `json:"warehouse_id,omitempty"`
+	TotalQuantity int                 `json:"total_quantity"`
+	Lines         []StockSnapshotLine `json:"lines"`
+}
+
+// ProductStockAsOf es el stock de un producto a una fecha con su desglose por almacen
+type ProductStockAsOf struct {
+	ProductID   int                 `json:"product_id"`
+	SKU         string              `json:"sku"`
+	ProductName string              `json:"product_name"`
+	AsOf        string              `json:"as_of,omitempty"`
+	Quantity    int                 `json:"quantity"`
+	Warehouses  []StockSnapshotLine `json:"warehouses"`
+}
+
+// productInfo identifica al producto de GET /products/:id/stock (aunque este eliminado)
+type productInfo struct {
+	SKU  string `db:"sku"`
+	Name string `db:"name"`
+}
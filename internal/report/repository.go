@@ -0,0 +1,235 @@
+package report
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+)
+
+type Repository struct {
+	db     *sqlx.DB
+	logger zerolog.Logger
+}
+
+func NewRepository(db *sqlx.DB, logger zerolog.Logger) *Repository {
+	moduleLogger := logger.With().Str("module", "report").Logger()
+	return &Repository{db: db, logger: moduleLogger}
+}
+
+// Valuation calcula el stock y su valor por producto
+// parte del valor actual de las capas de costo y revierte los movimientos registrados desde `since`
+// (since nulo = valor actual); las transferencias restan en el almacen origen y suman en el destino
+// los ajustes RECONCILIATION no tienen costo: cambian la cantidad del ledger pero no el valor
+func (r *Repository) Valuation(ctx context.Context, warehouseID *int, since *time.Time) ([]ValuationLine, error) {
+	query := `
+		WITH current_stock AS (
+			SELECT sl.product_id, sl.warehouse_id, sl.quantity,
+				COALESCE((
+					SELECT SUM(cl.remaining_quantity * cl.unit_cost)
+					FROM cost_layers cl
+					WHERE cl.product_id = sl.product_id AND cl.warehouse_id = sl.warehouse_id AND cl.remaining_quantity > 0
+				), 0) AS value
+			FROM stock_levels sl
+		),
+		later_changes AS (
+			SELECT product_id, warehouse_id,
+				CASE WHEN movement_type IN ('OUT', 'TRANSFER') THEN -quantity ELSE quantity END AS quantity,
+				CASE WHEN movement_type IN ('OUT', 'TRANSFER') OR quantity < 0 THEN -1 ELSE 1 END * COALESCE(total_cost, 0) AS value
+			FROM movements
+			WHERE created_at >= $2::timestamp AND movement_type <> 'STATUS_CHANGE'
+				AND (movement_type <> 'TRANSFER' OR to_warehouse_id <> warehouse_id)
+			UNION ALL
+			SELECT product_id, to_warehouse_id, quantity, COALESCE(total_cost, 0)
+			FROM movements
+			WHERE created_at >= $2::timestamp AND movement_type = 'TRANSFER' AND to_warehouse_id <> warehouse_id
+		),
+		balances AS (
+			SELECT product_id, warehouse_id, quantity, value FROM current_stock
+			UNION ALL
+			SELECT product_id, warehouse_id, -quantity, -value FROM later_changes
+		)
+		SELECT p.id AS product_id, p.sku, p.name AS product_name,
+			SUM(b.quantity) AS quantity, ROUND(SUM(b.value), 4) AS value
+		FROM balances b
+		JOIN products p ON p.id = b.product_id
+		WHERE $1::int IS NULL OR b.warehouse_id = $1
+		GROUP BY p.id, p.sku, p.name
+		HAVING SUM(b.quantity) <> 0 OR ROUND(SUM(b.value), 4) <> 0
+		ORDER BY p.sku
+	`
+
+	var lines []ValuationLine
+	if err := r.db.SelectContext(ctx, &lines, query, warehouseID, since); err != nil {
+		return nil, fmt.Errorf("error calculating valuation: %w", err)
+	}
+	return lines, nil
+}
+
+func (r *Repository) WarehouseExists(ctx context.Context, warehouseID int) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM warehouses WHERE id = $1 AND deleted_at IS NULL)`
+
+	if err := r.db.GetContext(ctx, &exists, query, warehouseID); err != nil {
+		return false, fmt.Errorf("error checking warehouse existence: %w", err)
+	}
+	return exists, nil
+}
+
+// StockAsOf reconstruye el stock por producto y almacen con lo registrado antes de `until`
+// parte del ultimo checkpoint de cada par (saldo inicial o cierre de dia) y le suma el ledger desde
+// ese checkpoint; como cada dia cerrado tiene checkpoint de todo lo que se movio, solo hace falta
+// recorrer el ledger posterior al ultimo dia cerrado
+func (r *Repository) StockAsOf(ctx context.Context, until time.Time, productID, warehouseID *int) ([]StockSnapshotLine, error) {
+	query := `
+		WITH closed AS (
+			SELECT COALESCE(MAX(checkpoint_at), '-infinity'::timestamp) AS checkpoint_at
+			FROM stock_checkpoint_runs
+			WHERE checkpoint_at <= $1::timestamp
+		),
+		last_checkpoints AS (
+			SELECT c.product_id, c.warehouse_id, c.checkpoint_at, c.quantity
+			FROM stock_levels sl
+			CROSS JOIN LATERAL (
+				SELECT sc.product_id, sc.warehouse_id, sc.checkpoint_at, sc.quantity
+				FROM stock_checkpoints sc
+				WHERE sc.product_id = sl.product_id AND sc.warehouse_id = sl.warehouse_id AND sc.checkpoint_at <= $1::timestamp
+				ORDER BY sc.checkpoint_at DESC
+				LIMIT 1
+			) c
+			WHERE ($2::int IS NULL OR sl.product_id = $2) AND ($3::int IS NULL OR sl.warehouse_id = $3)
+		),
+		balances AS (
+			SELECT product_id, warehouse_id, quantity FROM last_checkpoints
+			UNION ALL
+			SELECT l.product_id, l.warehouse_id, l.quantity
+			FROM stock_ledger l
+			CROSS JOIN closed
+			LEFT JOIN last_checkpoints c ON c.product_id = l.product_id AND c.warehouse_id = l.warehouse_id
+			WHERE l.created_at >= closed.checkpoint_at AND l.created_at < $1::timestamp
+				AND l.created_at >= COALESCE(c.checkpoint_at, '-infinity'::timestamp)
+				AND ($2::int IS NULL OR l.product_id = $2) AND ($3::int IS NULL OR l.warehouse_id = $3)
+		)
+		SELECT p.id AS product_id, p.sku, p.name AS product_name, w.id AS warehouse_id, w.code AS warehouse_code,
+			SUM(b.quantity) AS quantity
+		FROM balances b
+		JOIN products p ON p.id = b.product_id
+		JOIN warehouses w ON w.id = b.warehouse_id
+		GROUP BY p.id, p.sku, p.name, w.id, w.code
+		HAVING SUM(b.quantity) <> 0
+		ORDER BY p.sku, w.code
+	`
+
+	var lines []StockSnapshotLine
+	if err := r.db.SelectContext(ctx, &lines, query, until, productID, warehouseID); err != nil {
+		return nil, fmt.Errorf("error calculating stock as of %s: %w", until.Format(time.RFC3339), err)
+	}
+	return lines, nil
+}
+
+// obtiene sku y nombre del producto, eliminado o no (su historial sigue consultable)
+func (r *Repository) GetProductInfo(ctx context.Context, productID int) (*productInfo, error) {
+	var info productInfo
+	query := `SELECT sku, name FROM products WHERE id = $1`
+
+	if err := r.db.GetContext(ctx, &info, query, productID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("product with id [%d] not found", productID)
+		}
+		return nil, fmt.Errorf("error getting product: %w", err)
+	}
+	return &info, nil
+}
+
+// LastCheckpointRun devuelve el ultimo dia cerrado por el job de checkpoints (nil si nunca corrio)
+func (r *Repository) LastCheckpointRun(ctx context.Context) (*time.Time, error) {
+	var last *time.Time
+	query := `SELECT MAX(checkpoint_at) FROM stock_checkpoint_runs`
+
+	if err := r.db.GetContext(ctx, &last, query); err != nil {
+		return nil, fmt.Errorf("error getting last checkpoint run: %w", err)
+	}
+	return last, nil
+}
+
+// FirstLedgerDay devuelve el inicio del dia del primer movimiento (nil si no hay movimientos)
+func (r *Repository) FirstLedgerDay(ctx context.Context) (*time.Time, error) {
+	var first *time.Time
+	query := `SELECT date_trunc('day', MIN(created_at)) FROM movements`
+
+	if err := r.db.GetContext(ctx, &first, query); err != nil {
+		return nil, fmt.Errorf("error getting first movement day: %w", err)
+	}
+	return first, nil
+}
+
+// CloseDay guarda el checkpoint al final del dia (dayEnd) de cada par producto/almacen que se movio
+// ese dia: su checkpoint anterior mas el ledger desde entonces
+// el registro del dia en stock_checkpoint_runs evita que dos instancias lo cierren a la vez
+func (r *Repository) CloseDay(ctx context.Context, dayEnd time.Time) (int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO stock_checkpoint_runs (checkpoint_at) VALUES ($1::timestamp)
+		ON CONFLICT (checkpoint_at) DO NOTHING
+	`, dayEnd)
+	if err != nil {
+		return 0, fmt.Errorf("error registering checkpoint run: %w", err)
+	}
+	registered, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error getting rows affected: %w", err)
+	}
+	if registered == 0 {
+		// otra instancia ya cerro el dia
+		return 0, nil
+	}
+
+	query := `
+		INSERT INTO stock_checkpoints (product_id, warehouse_id, checkpoint_at, quantity)
+		SELECT m.product_id, m.warehouse_id, $2::timestamp,
+			COALESCE(c.quantity, 0) + (
+				SELECT COALESCE(SUM(l.quantity), 0)
+				FROM stock_ledger l
+				WHERE l.product_id = m.product_id AND l.warehouse_id = m.warehouse_id
+					AND l.created_at >= COALESCE(c.checkpoint_at, '-infinity'::timestamp) AND l.created_at < $2::timestamp
+			)
+		FROM (
+			SELECT DISTINCT product_id, warehouse_id
+			FROM stock_ledger
+			WHERE created_at >= $1::timestamp AND created_at < $2::timestamp
+		) m
+		LEFT JOIN LATERAL (
+			SELECT sc.checkpoint_at, sc.quantity
+			FROM stock_checkpoints sc
+			WHERE sc.product_id = m.product_id AND sc.warehouse_id = m.warehouse_id AND sc.checkpoint_at < $2::timestamp
+			ORDER BY sc.checkpoint_at DESC
+			LIMIT 1
+		) c ON true
+		ON CONFLICT (product_id, warehouse_id, checkpoint_at) DO NOTHING
+	`
+	result, err = tx.ExecContext(ctx, query, dayEnd.AddDate(0, 0, -1), dayEnd)
+	if err != nil {
+		return 0, fmt.Errorf("error creating stock checkpoints: %w", err)
+	}
+	checkpoints, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE stock_checkpoint_runs SET checkpoints = $2 WHERE checkpoint_at = $1::timestamp`, dayEnd, checkpoints); err != nil {
+		return 0, fmt.Errorf("error updating checkpoint run: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error committing transaction: %w", err)
+	}
+	return int(checkpoints), nil
+}
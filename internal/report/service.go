@@ -0,0 +1,229 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+type Service struct {
+	repo          *Repository
+	costingMethod string
+	logger        zerolog.Logger
+}
+
+func NewService(repo *Repository, costingMethod string, logger zerolog.Logger) *Service {
+	serviceLogger := logger.With().Str("service", "report").Logger()
+	return &Service{repo: repo, costingMethod: costingMethod, logger: serviceLogger}
+}
+
+// Valuation obtiene el valor del inventario actual o al cierre del dia as_of
+// el costo de cada producto sale de sus capas de costo (FIFO o promedio movil segun la configuracion)
+func (s *Service) Valuation(ctx context.Context, filters ValuationFilters) (*ValuationReport, error) {
+	since, err := endOfDay(filters.AsOf)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkWarehouse(ctx, filters.WarehouseID); err != nil {
+		return nil, err
+	}
+
+	lines, err := s.repo.Valuation(ctx, filters.WarehouseID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ValuationReport{
+		AsOf:          filters.AsOf,
+		WarehouseID:   filters.WarehouseID,
+		CostingMethod: s.costingMethod,
+		Lines:         []ValuationLine{},
+	}
+	for _, line := range lines {
+		if line.Quantity != 0 {
+			line.UnitCost = math.Round(line.Value/float64(line.Quantity)*10000) / 10000
+		}
+		report.TotalQuantity += line.Quantity
+		report.TotalValue += line.Value
+		report.Lines = append(report.Lines, line)
+	}
+	report.TotalValue = math.Round(report.TotalValue*10000) / 10000
+
+	return report, nil
+}
+
+// StockSnapshot reconstruye el stock de todos los productos por almacen a la fecha as_of
+// a partir del ledger de movimientos y sus checkpoints
+func (s *Service) StockSnapshot(ctx context.Context, filters StockAsOfFilters) (*StockSnapshotReport, error) {
+	until, err := asOfBound(filters.AsOf)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkWarehouse(ctx, filters.WarehouseID); err != nil {
+		return nil, err
+	}
+
+	lines, err := s.repo.StockAsOf(ctx, until, nil, filters.WarehouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &StockSnapshotReport{
+		AsOf:        filters.AsOf,
+		WarehouseID: filters.WarehouseID,
+		Lines:       []StockSnapshotLine{},
+	}
+	for _, line := range lines {
+		report.TotalQuantity += line.Quantity
+		report.Lines = append(report.Lines, line)
+	}
+	return report, nil
+}
+
+// ProductStock reconstruye el stock de un producto a la fecha as_of con su desglose por almacen
+func (s *Service) ProductStock(ctx context.Context, productID int, filters StockAsOfFilters) (*ProductStockAsOf, error) {
+	if productID <= 0 {
+		return nil, fmt.Errorf("invalid product ID: must be greater than 0")
+	}
+
+	until, err := asOfBound(filters.AsOf)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkWarehouse(ctx, filters.WarehouseID); err != nil {
+		return nil, err
+	}
+
+	info, err := s.repo.GetProductInfo(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := s.repo.StockAsOf(ctx, until, &productID, filters.WarehouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	stock := &ProductStockAsOf{
+		ProductID:   productID,
+		SKU:         info.SKU,
+		ProductName: info.Name,
+		AsOf:        filters.AsOf,
+		Warehouses:  []StockSnapshotLine{},
+	}
+	for _, line := range lines {
+		stock.Quantity += line.Quantity
+		stock.Warehouses = append(stock.Warehouses, line)
+	}
+	return stock, nil
+}
+
+// checkpointGrace es lo que se espera despues de medianoche para cerrar un dia: un movimiento
+// queda con la hora de inicio de su transaccion y puede confirmarse un poco despues
+const checkpointGrace = time.Hour
+
+// CreateCheckpoints cierra los dias completos pendientes desde el ultimo cierre (o desde el primer
+// movimiento) guardando el stock de cada par producto/almacen que se movio en el dia
+// los dias son de UTC, igual que los created_at de la sesion de la base
+func (s *Service) CreateCheckpoints(ctx context.Context) (int, error) {
+	last, err := s.repo.LastCheckpointRun(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if last == nil {
+		if last, err = s.repo.FirstLedgerDay(ctx); err != nil || last == nil {
+			return 0, err
+		}
+	}
+
+	until := time.Now().UTC().Add(-checkpointGrace).Truncate(24 * time.Hour)
+	total := 0
+	for day := last.AddDate(0, 0, 1); !day.After(until); day = day.AddDate(0, 0, 1) {
+		checkpoints, err := s.repo.CloseDay(ctx, day)
+		if err != nil {
+			return total, fmt.Errorf("error closing day %s: %w", day.Format("2006-01-02"), err)
+		}
+		total += checkpoints
+	}
+
+	if total > 0 {
+		s.logger.Info().Int("checkpoints", total).Msg("Stock checkpoints created")
+	}
+	return total, nil
+}
+
+// RunCheckpointer crea los checkpoints de stock cada interval hasta que se cancele ctx
+// la primera pasada es inmediata para ponerse al dia con el historial existente
+func (s *Service) RunCheckpointer(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	s.logger.Info().Dur("interval", interval).Msg("Stock checkpointer started")
+	for {
+		if _, err := s.CreateCheckpoints(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Error().Err(err).Msg("Failed to create stock checkpoints")
+		}
+
+		select {
+		case <-ctx.Done():
+			s.logger.Info().Msg("Stock checkpointer stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Service) checkWarehouse(ctx context.Context, warehouseID *int) error {
+	if warehouseID == nil {
+		return nil
+	}
+
+	exists, err := s.repo.WarehouseExists(ctx, *warehouseID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("invalid warehouse_id: warehouse with id [%d] not found", *warehouseID)
+	}
+	return nil
+}
+
+// convierte as_of en el limite (exclusivo) de lo que cuenta en el stock: una fecha YYYY-MM-DD
+// cuenta hasta el cierre del dia y un instante RFC3339 hasta ese instante inclusive; sin as_of, todo
+// los dias son de UTC, la zona en la que la sesion de la base escribe created_at
+func asOfBound(value string) (time.Time, error) {
+	if value == "" {
+		return time.Now().UTC().Add(time.Microsecond), nil
+	}
+
+	if date, err := time.Parse("2006-01-02", value); err == nil {
+		return date.AddDate(0, 0, 1), nil
+	}
+
+	instant, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid as_of: must be YYYY-MM-DD or RFC3339")
+	}
+	// el ledger guarda microsegundos, lo registrado en el mismo instante cuenta
+	return instant.UTC().Add(time.Microsecond), nil
+}
+
+// convierte la fecha YYYY-MM-DD en el inicio del dia siguiente (lo registrado desde ahi no cuenta)
+// sin fecha devuelve nulo
+func endOfDay(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid as_of: must be YYYY-MM-DD")
+	}
+
+	next := date.AddDate(0, 0, 1)
+	return &next, nil
+}
@@ -0,0 +1,71 @@
+package report
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAsOfBound(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{name: "fecha cuenta todo el dia", value: "2026-03-15", want: time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC)},
+		{name: "ultimo dia del año", value: "2025-12-31", want: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "instante UTC inclusive", value: "2026-03-15T10:30:00Z", want: time.Date(2026, 3, 15, 10, 30, 0, 1000, time.UTC)},
+		{name: "instante con zona se convierte a UTC", value: "2026-03-15T22:30:00-06:00", want: time.Date(2026, 3, 16, 4, 30, 0, 1000, time.UTC)},
+		{name: "fecha invalida", value: "2026-02-30", wantErr: true},
+		{name: "formato invalido", value: "15/03/2026", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := asOfBound(tt.value)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "invalid as_of") {
+					t.Fatalf("got %v, %v, want invalid as_of error", got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !got.Equal(tt.want) || got.Location() != time.UTC {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAsOfBoundNow(t *testing.T) {
+	before := time.Now().UTC()
+	got, err := asOfBound("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !got.After(before) || got.Location() != time.UTC {
+		t.Errorf("got %v, want an UTC instant after %v", got, before)
+	}
+}
+
+func TestEndOfDay(t *testing.T) {
+	got, err := endOfDay("")
+	if err != nil || got != nil {
+		t.Fatalf("got %v, %v, want no bound", got, err)
+	}
+
+	got, err = endOfDay("2026-02-28")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	if _, err := endOfDay("2026-03-15T10:30:00Z"); err == nil {
+		t.Error("expected an error for a non-date value")
+	}
+}
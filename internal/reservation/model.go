@@ -0,0 +1,67 @@
+package reservation
+
+import "time"
+
+// Status es el estado de una reserva
+// ACTIVE aparta stock; CONSUMED ya salio con movimientos OUT; RELEASED se libero a mano; EXPIRED la libero el barrido
+type Status string
+
+const (
+	StatusActive   Status = "ACTIVE"
+	StatusConsumed Status = "CONSUMED"
+	StatusReleased Status = "RELEASED"
+	StatusExpired  Status = "EXPIRED"
+)
+
+// isValid verifica si el estado es valido
+func (st Status) IsValid() bool {
+	return st == StatusActive || st == StatusConsumed || st == StatusReleased || st == StatusExpired
+}
+
+// Reservation aparta stock de un producto en un almacen para un dueño (orden, cliente, etc.)
+type Reservation struct {
+	ID                int        `json:"id" db:"id"`
+	ProductID         int        `json:"product_id" db:"product_id"`
+	SKU               string     `json:"sku" db:"sku"`
+	WarehouseID       int        `json:"warehouse_id" db:"warehouse_id"`
+	Quantity          int        `json:"quantity" db:"quantity"` // en la unidad base
+	ConsumedQuantity  int        `json:"consumed_quantity" db:"consumed_quantity"`
+	RemainingQuantity int        `json:"remaining_quantity" db:"remaining_quantity"`
+	OwnerReference    string     `json:"owner_reference" db:"owner_reference"`
+	Status            Status     `json:"status" db:"status"`
+	ExpiresAt         *time.Time `json:"expires_at,omitempty" db:"expires_at"` // nulo: no vence
+	CreatedBy         string     `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+	ReleasedAt        *time.Time `json:"released_at,omitempty" db:"released_at"`
+}
+
+type CreateReservationRequest struct {
+	ProductID      int        `json:"product_id" binding:"required,min=1"`
+	WarehouseID    int        `json:"warehouse_id" binding:"omitempty,min=1"` // opcional, si se omite usa el almacen por defecto
+	Quantity       int        `json:"quantity" binding:"required,min=1"`
+	OwnerReference string     `json:"owner_reference" binding:"required,max=100"`
+	ExpiresAt      *time.Time `json:"expires_at"` // opcional, RFC3339
+	CreatedBy      string     `json:"-"`
+}
+
+// ListFilters son los filtros opcionales de GET /reservations
+type ListFilters struct {
+	ProductID      *int   `form:"product_id"`
+	WarehouseID    *int   `form:"warehouse_id"`
+	OwnerReference string `form:"owner_reference"`
+	Status         string `form:"status"`
+	Page           int    `form:"page"`
+	PageSize       int    `form:"page_size"`
+}
+
+type PaginatedResponse struct {
+	Data       interface{} `json:"data"`
+	Pagination Pagination  `json:"pagination"`
+}
+
+type Pagination struct {
+	Page       int `json:"page"`
+	PageSize   int `json:"page_size"`
+	Total      int `json:"total"`
+	TotalPages int `json:"total_pages"`
+}
@@ -0,0 +1,247 @@
+package reservation
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+)
+
+const reservationColumns = `
+	r.id, r.product_id, p.sku, r.warehouse_id, r.quantity, r.consumed_quantity,
+	r.quantity - r.consumed_quantity AS remaining_quantity, r.owner_reference, r.status, r.expires_at,
+	COALESCE(r.created_by, '') AS created_by, r.created_at, r.released_at
+`
+
+type Repository struct {
+	db     *sqlx.DB
+	logger zerolog.Logger
+}
+
+func NewRepository(db *sqlx.DB, logger zerolog.Logger) *Repository {
+	moduleLogger := logger.With().Str("module", "reservation").Logger()
+	return &Repository{db: db, logger: moduleLogger}
+}
+
+// obtiene el stock del producto en el almacen con LOCK PESIMISTA
+// el mismo lock que toman los movimientos: reservas y salidas del producto se serializan
+func (r *Repository) GetStockLevelForUpdate(ctx context.Context, tx *sqlx.Tx, productID, warehouseID int) (int, error) {
+	var stock int
+	query := `
+		SELECT quantity
+		FROM stock_levels
+		WHERE product_id = $1 AND warehouse_id = $2
+		FOR UPDATE
+	`
+
+	err := tx.GetContext(ctx, &stock, query, productID, warehouseID)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, fmt.Errorf("error getting stock level with lock: %w", err)
+	}
+	return stock, nil
+}
+
+// suma lo reservado y no consumido del producto en el almacen (reservas activas y vigentes)
+func (r *Repository) GetReservedStock(ctx context.Context, tx *sqlx.Tx, productID, warehouseID int) (int, error) {
+	var reserved int
+	query := `
+		SELECT COALESCE(SUM(quantity - consumed_quantity), 0)
+		FROM reservations
+		WHERE product_id = $1 AND warehouse_id = $2 AND status = 'ACTIVE'
+			AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+	`
+
+	if err := tx.GetContext(ctx, &reserved, query, productID, warehouseID); err != nil {
+		return 0, fmt.Errorf("error getting reserved stock: %w", err)
+	}
+	return reserved, nil
+}
+
+// suma el stock retenido del producto en el almacen (cuarentena, dañado, en espera)
+// lo retenido no se puede reservar
+func (r *Repository) GetHeldStock(ctx context.Context, tx *sqlx.Tx, productID, warehouseID int) (int, error) {
+	var held int
+	query := `SELECT COALESCE(SUM(quantity), 0) FROM stock_status_levels WHERE product_id = $1 AND warehouse_id = $2`
+
+	if err := tx.GetContext(ctx, &held, query, productID, warehouseID); err != nil {
+		return 0, fmt.Errorf("error getting held stock: %w", err)
+	}
+	return held, nil
+}
+
+func (r *Repository) Create(ctx context.Context, tx *sqlx.Tx, req CreateReservationRequest) (int, error) {
+	var id int
+	query := `
+		INSERT INTO reservations (product_id, warehouse_id, quantity, owner_reference, expires_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''))
+		RETURNING id
+	`
+
+	err := tx.GetContext(ctx, &id, query, req.ProductID, req.WarehouseID, req.Quantity, req.OwnerReference, req.ExpiresAt, req.CreatedBy)
+	if err != nil {
+		return 0, fmt.Errorf("error creating reservation: %w", err)
+	}
+	return id, nil
+}
+
+// obtiene la reserva (acepta la conexion o una transaccion)
+func (r *Repository) GetByID(ctx context.Context, q sqlx.QueryerContext, id int) (*Reservation, error) {
+	query := `SELECT ` + reservationColumns + ` FROM reservations r JOIN products p ON p.id = r.product_id WHERE r.id = $1`
+
+	var reservation Reservation
+	err := sqlx.GetContext(ctx, q, &reservation, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("reservation with id [%d] not found", id)
+		}
+		return nil, fmt.Errorf("error getting reservation: %w", err)
+	}
+	return &reservation, nil
+}
+
+// obtiene la reserva con LOCK PESIMISTA
+func (r *Repository) GetForUpdate(ctx context.Context, tx *sqlx.Tx, id int) (*Reservation, error) {
+	query := `SELECT ` + reservationColumns + ` FROM reservations r JOIN products p ON p.id = r.product_id
+		WHERE r.id = $1 FOR UPDATE OF r`
+
+	var reservation Reservation
+	err := tx.GetContext(ctx, &reservation, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("reservation with id [%d] not found", id)
+		}
+		return nil, fmt.Errorf("error getting reservation: %w", err)
+	}
+	return &reservation, nil
+}
+
+// reservas activas de un dueño con LOCK PESIMISTA
+func (r *Repository) ListActiveByOwnerForUpdate(ctx context.Context, tx *sqlx.Tx, ownerReference string) ([]Reservation, error) {
+	query := `SELECT ` + reservationColumns + ` FROM reservations r JOIN products p ON p.id = r.product_id
+		WHERE r.owner_reference = $1 AND r.status = 'ACTIVE'
+		ORDER BY r.id
+		FOR UPDATE OF r`
+
+	var reservations []Reservation
+	if err := tx.SelectContext(ctx, &reservations, query, ownerReference); err != nil {
+		return nil, fmt.Errorf("error listing owner reservations: %w", err)
+	}
+	return reservations, nil
+}
+
+func (r *Repository) List(ctx context.Context, filters ListFilters) ([]Reservation, int, error) {
+	var conditions []string
+	var args []interface{}
+	argPosition := 1
+
+	if filters.ProductID != nil {
+		conditions = append(conditions, fmt.Sprintf("r.product_id = $%d", argPosition))
+		args = append(args, *filters.ProductID)
+		argPosition++
+	}
+
+	if filters.WarehouseID != nil {
+		conditions = append(conditions, fmt.Sprintf("r.warehouse_id = $%d", argPosition))
+		args = append(args, *filters.WarehouseID)
+		argPosition++
+	}
+
+	if filters.OwnerReference != "" {
+		conditions = append(conditions, fmt.Sprintf("r.owner_reference = $%d", argPosition))
+		args = append(args, filters.OwnerReference)
+		argPosition++
+	}
+
+	if filters.Status != "" {
+		conditions = append(conditions, fmt.Sprintf("r.status = $%d", argPosition))
+		args = append(args, filters.Status)
+		argPosition++
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	err := r.db.GetContext(ctx, &total, "SELECT COUNT(*) FROM reservations r"+where, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error counting reservations: %w", err)
+	}
+
+	offset := (filters.Page - 1) * filters.PageSize
+	query := "SELECT " + reservationColumns + " FROM reservations r JOIN products p ON p.id = r.product_id" + where +
+		fmt.Sprintf(" ORDER BY r.created_at DESC, r.id DESC LIMIT $%d OFFSET $%d", argPosition, argPosition+1)
+	args = append(args, filters.PageSize, offset)
+
+	var reservations []Reservation
+	err = r.db.SelectContext(ctx, &reservations, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error listing reservations: %w", err)
+	}
+
+	return reservations, total, nil
+}
+
+// cierra la reserva con el estado indicado (RELEASED o EXPIRED), lo no consumido vuelve a estar disponible
+func (r *Repository) Close(ctx context.Context, tx *sqlx.Tx, id int, status Status) error {
+	query := `UPDATE reservations SET status = $1, released_at = CURRENT_TIMESTAMP WHERE id = $2`
+
+	if _, err := tx.ExecContext(ctx, query, status, id); err != nil {
+		return fmt.Errorf("error closing reservation: %w", err)
+	}
+	return nil
+}
+
+// marca como EXPIRED las reservas activas vencidas y retorna los productos afectados
+func (r *Repository) ExpireDue(ctx context.Context) ([]Reservation, error) {
+	query := `
+		UPDATE reservations r
+		SET status = 'EXPIRED', released_at = CURRENT_TIMESTAMP
+		FROM products p
+		WHERE p.id = r.product_id AND r.status = 'ACTIVE' AND r.expires_at <= CURRENT_TIMESTAMP
+		RETURNING ` + reservationColumns
+
+	var reservations []Reservation
+	if err := r.db.SelectContext(ctx, &reservations, query); err != nil {
+		return nil, fmt.Errorf("error expiring reservations: %w", err)
+	}
+	return reservations, nil
+}
+
+// verifica que el producto exista (y no este eliminado)
+func (r *Repository) ProductExists(ctx context.Context, productID int) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM products WHERE id = $1 AND deleted_at IS NULL)`
+
+	if err := r.db.GetContext(ctx, &exists, query, productID); err != nil {
+		return false, fmt.Errorf("error checking product existence: %w", err)
+	}
+	return exists, nil
+}
+
+func (r *Repository) GetDefaultWarehouseID(ctx context.Context) (int, error) {
+	var id int
+	query := `SELECT id FROM warehouses WHERE is_default AND deleted_at IS NULL`
+
+	if err := r.db.GetContext(ctx, &id, query); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("default warehouse not found")
+		}
+		return 0, fmt.Errorf("error getting default warehouse: %w", err)
+	}
+	return id, nil
+}
+
+func (r *Repository) WarehouseExists(ctx context.Context, warehouseID int) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM warehouses WHERE id = $1 AND deleted_at IS NULL)`
+
+	if err := r.db.GetContext(ctx, &exists, query, warehouseID); err != nil {
+		return false, fmt.Errorf("error checking warehouse existence: %w", err)
+	}
+	return exists, nil
+}
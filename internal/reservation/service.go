@@ -0,0 +1,290 @@
+package reservation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+	"github.com/whoAngeel/wms-lite/internal/platform"
+)
+
+type Service struct {
+	repo   *Repository
+	db     *sqlx.DB
+	cache  *platform.Cache
+	logger zerolog.Logger
+}
+
+func NewService(repo *Repository, db *sqlx.DB, cache *platform.Cache, logger zerolog.Logger) *Service {
+	serviceLogger := logger.With().Str("service", "reservation").Logger()
+	return &Service{repo: repo, db: db, cache: cache, logger: serviceLogger}
+}
+
+// Create aparta stock del producto en un almacen
+// solo se puede reservar lo disponible: stock del almacen - reservas activas
+func (s *Service) Create(ctx context.Context, req CreateReservationRequest) (reservation *Reservation, err error) {
+	req.OwnerReference = strings.TrimSpace(req.OwnerReference)
+	if req.OwnerReference == "" {
+		return nil, fmt.Errorf("invalid owner_reference: must not be empty")
+	}
+
+	exists, err := s.repo.ProductExists(ctx, req.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("invalid product_id: product with id [%d] not found", req.ProductID)
+	}
+
+	if req.WarehouseID == 0 {
+		req.WarehouseID, err = s.repo.GetDefaultWarehouseID(ctx)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		exists, err := s.repo.WarehouseExists(ctx, req.WarehouseID)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, fmt.Errorf("invalid warehouse_id: warehouse with id [%d] not found", req.WarehouseID)
+		}
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	reservation, err = s.ReserveTx(ctx, tx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	s.AfterCommit(ctx, reservation)
+	return reservation, nil
+}
+
+// ReserveTx crea la reserva dentro de una transaccion existente (req.WarehouseID ya resuelto)
+// toma el lock de stock_levels del producto, el mismo que los movimientos
+func (s *Service) ReserveTx(ctx context.Context, tx *sqlx.Tx, req CreateReservationRequest) (*Reservation, error) {
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("invalid expires_at: must be in the future")
+	}
+
+	onHand, err := s.repo.GetStockLevelForUpdate(ctx, tx, req.ProductID, req.WarehouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	reserved, err := s.repo.GetReservedStock(ctx, tx, req.ProductID, req.WarehouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	held, err := s.repo.GetHeldStock(ctx, tx, req.ProductID, req.WarehouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	if available := onHand - reserved - held; available < req.Quantity {
+		return nil, fmt.Errorf("insufficient stock: available=%d (on_hand=%d, reserved=%d, held=%d), request=%d",
+			max(available, 0), onHand, reserved, held, req.Quantity)
+	}
+
+	id, err := s.repo.Create(ctx, tx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info().Int("reservation_id", id).Int("product_id", req.ProductID).Int("warehouse_id", req.WarehouseID).
+		Int("quantity", req.Quantity).Str("owner_reference", req.OwnerReference).Msg("Stock reserved")
+	return s.repo.GetByID(ctx, tx, id)
+}
+
+func (s *Service) GetByID(ctx context.Context, id int) (*Reservation, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("invalid ID: must be greater than 0")
+	}
+	return s.repo.GetByID(ctx, s.db, id)
+}
+
+func (s *Service) List(ctx context.Context, filters ListFilters) (*PaginatedResponse, error) {
+	filters.Page, filters.PageSize = normalizePagination(filters.Page, filters.PageSize)
+
+	if filters.Status != "" {
+		status := Status(strings.ToUpper(filters.Status))
+		if !status.IsValid() {
+			return nil, fmt.Errorf("invalid status: must be ACTIVE, CONSUMED, RELEASED or EXPIRED")
+		}
+		filters.Status = string(status)
+	}
+
+	reservations, total, err := s.repo.List(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	if reservations == nil {
+		reservations = []Reservation{}
+	}
+
+	return &PaginatedResponse{
+		Data: reservations,
+		Pagination: Pagination{
+			Page:       filters.Page,
+			PageSize:   filters.PageSize,
+			Total:      total,
+			TotalPages: (total + filters.PageSize - 1) / filters.PageSize,
+		},
+	}, nil
+}
+
+// Release libera la reserva, lo no consumido vuelve a estar disponible
+func (s *Service) Release(ctx context.Context, id int) (err error) {
+	if id <= 0 {
+		return fmt.Errorf("invalid ID: must be greater than 0")
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	reservation, err := s.repo.GetForUpdate(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	if reservation.Status != StatusActive {
+		return fmt.Errorf("cannot release reservation: reservation is %s", reservation.Status)
+	}
+
+	if err = s.repo.Close(ctx, tx, id, StatusReleased); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	s.AfterCommit(ctx, reservation)
+
+	s.logger.Info().Int("reservation_id", id).Int("remaining_quantity", reservation.RemainingQuantity).Msg("Reservation released")
+	return nil
+}
+
+// ReleaseByOwnerTx libera dentro de una transaccion existente las reservas activas del dueño
+// retorna las reservas liberadas para invalidar cache con AfterCommit
+func (s *Service) ReleaseByOwnerTx(ctx context.Context, tx *sqlx.Tx, ownerReference string) ([]*Reservation, error) {
+	reservations, err := s.repo.ListActiveByOwnerForUpdate(ctx, tx, ownerReference)
+	if err != nil {
+		return nil, err
+	}
+
+	released := make([]*Reservation, 0, len(reservations))
+	for i := range reservations {
+		if err := s.repo.Close(ctx, tx, reservations[i].ID, StatusReleased); err != nil {
+			return nil, err
+		}
+		released = append(released, &reservations[i])
+	}
+	return released, nil
+}
+
+// ReleaseExpired marca como EXPIRED las reservas activas vencidas
+// e invalida la cache del producto de cada una, igual que Release
+func (s *Service) ReleaseExpired(ctx context.Context) (int, error) {
+	expired, err := s.repo.ExpireDue(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(expired) == 0 {
+		return 0, nil
+	}
+
+	// el UPDATE ya se confirmo: la cache se invalida aunque el barrido se cancele (shutdown),
+	// si no el producto cacheado seguiria mostrando esas unidades como reservadas
+	cacheCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), expiredCacheTimeout)
+	defer cancel()
+
+	for i := range expired {
+		s.AfterCommit(cacheCtx, &expired[i])
+	}
+
+	s.logger.Info().Int("reservations", len(expired)).Msg("Expired reservations released")
+	return len(expired), nil
+}
+
+// expiredCacheTimeout limita la invalidacion de cache de un barrido
+const expiredCacheTimeout = 5 * time.Second
+
+// RunSweeper libera las reservas vencidas cada interval hasta que se cancele ctx
+// las reservas vencidas ya no cuentan como reservadas, el barrido cierra su estado e invalida la cache de sus productos
+func (s *Service) RunSweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	s.logger.Info().Dur("interval", interval).Msg("Reservation sweeper started")
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info().Msg("Reservation sweeper stopped")
+			return
+		case <-ticker.C:
+			if _, err := s.ReleaseExpired(ctx); err != nil {
+				s.logger.Error().Err(err).Msg("Failed to release expired reservations")
+			}
+		}
+	}
+}
+
+// AfterCommit invalida la cache de los productos de reservas ya confirmadas (cambia su disponible)
+// un fallo de cache no revierte nada, solo se registra
+func (s *Service) AfterCommit(ctx context.Context, reservations ...*Reservation) {
+	for _, reservation := range reservations {
+		cacheKeys := []string{
+			fmt.Sprintf("product:%d", reservation.ProductID),
+			fmt.Sprintf("product:sku:%s", reservation.SKU),
+		}
+
+		if err := s.cache.Del(ctx, cacheKeys...); err != nil {
+			s.logger.Warn().Err(err).Int("product_id", reservation.ProductID).Msg("Failed to invalidate product cache")
+		}
+	}
+}
+
+func normalizePagination(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	if pageSize > 100 {
+		pageSize = 100
+	}
+	return page, pageSize
+}
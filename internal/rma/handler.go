@@ -0,0 +1,191 @@
+package rma
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+type Handler struct {
+	service *Service
+	logger  zerolog.Logger
+}
+
+func NewHandler(service *Service, logger zerolog.Logger) *Handler {
+	moduleLogger := logger.With().Str("module", "rma").Logger()
+	return &Handler{
+		service: service,
+		logger:  moduleLogger,
+	}
+}
+
+// Create maneja POST /rmas
+func (h *Handler) Create(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	var req CreateRMARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn().Err(err).Msg("Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if email, exists := c.Get("email"); exists {
+		req.CreatedBy = email.(string)
+	}
+
+	rma, err := h.service.Create(ctx, req)
+	if err != nil {
+		h.respondError(c, err, 0, "Error creating rma")
+		return
+	}
+
+	c.JSON(http.StatusCreated, rma)
+}
+
+// List maneja GET /rmas
+func (h *Handler) List(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	var filters ListFilters
+	if err := c.ShouldBindQuery(&filters); err != nil {
+		h.logger.Warn().Err(err).Msg("Invalid query parameters for rmas")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+
+	response, err := h.service.List(ctx, filters)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error().Err(err).Msg("Error listing rmas")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error listing rmas"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetByID maneja GET /rmas/:id (incluye lineas)
+func (h *Handler) GetByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID, must be a number"})
+		return
+	}
+
+	rma, err := h.service.GetByID(c.Request.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error().Err(err).Int("rma_id", id).Msg("Error getting rma")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rma)
+}
+
+// InspectLine maneja POST /rmas/:id/lines/:lineId/inspect
+// asigna la disposicion de la linea y reingresa la mercancia si corresponde
+func (h *Handler) InspectLine(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID, must be a number"})
+		return
+	}
+
+	lineID, err := strconv.Atoi(c.Param("lineId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid line ID, must be a number"})
+		return
+	}
+
+	var req InspectLineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if email, exists := c.Get("email"); exists {
+		req.InspectedBy = email.(string)
+	}
+
+	if err := h.service.InspectLine(ctx, id, lineID, req); err != nil {
+		h.respondError(c, err, id, "Error inspecting rma line")
+		return
+	}
+
+	h.respondRMA(c, id)
+}
+
+// Cancel maneja POST /rmas/:id/cancel
+func (h *Handler) Cancel(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID, must be a number"})
+		return
+	}
+
+	if err := h.service.Cancel(c.Request.Context(), id); err != nil {
+		h.respondError(c, err, id, "Error cancelling rma")
+		return
+	}
+
+	h.respondRMA(c, id)
+}
+
+// respondError traduce los errores de las acciones sobre una devolucion
+// un estado que no permite la accion es un conflicto, no un error de datos
+func (h *Handler) respondError(c *gin.Context, err error, id int, message string) {
+	switch {
+	case strings.Contains(err.Error(), "cannot") || strings.Contains(err.Error(), "insufficient stock"):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "rma with id") || strings.Contains(err.Error(), "rma line ["):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "not found") ||
+		strings.Contains(err.Error(), "required"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error().Err(err).Int("rma_id", id).Msg(message)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+// responde con la devolucion actualizada
+func (h *Handler) respondRMA(c *gin.Context, id int) {
+	rma, err := h.service.GetByID(c.Request.Context(), id)
+	if err != nil {
+		h.logger.Error().Err(err).Int("rma_id", id).Msg("Error getting rma")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rma)
+}
@@ -0,0 +1,157 @@
+package rma
+
+import (
+	"time"
+
+	"github.com/whoAngeel/wms-lite/internal/movement"
+)
+
+// Status es el estado de una devolucion
+// OPEN mientras tenga lineas sin inspeccionar -> CLOSED; CANCELLED si la mercancia nunca llego
+type Status string
+
+const (
+	StatusOpen      Status = "OPEN"
+	StatusClosed    Status = "CLOSED"
+	StatusCancelled Status = "CANCELLED"
+)
+
+// isValid verifica si el estado es valido
+func (st Status) IsValid() bool {
+	return st == StatusOpen || st == StatusClosed || st == StatusCancelled
+}
+
+// Disposition es el destino de la mercancia devuelta despues de inspeccionarla
+// toda la mercancia devuelta entra al almacen, la disposicion define en que estado del inventario queda
+type Disposition string
+
+const (
+	DispositionRestock        Disposition = "RESTOCK"
+	DispositionQuarantine     Disposition = "QUARANTINE"
+	DispositionScrap          Disposition = "SCRAP"
+	DispositionReturnToVendor Disposition = "RETURN_TO_VENDOR"
+)
+
+// isValid verifica si la disposicion es valida
+func (d Disposition) IsValid() bool {
+	return d == DispositionRestock || d == DispositionQuarantine || d == DispositionScrap || d == DispositionReturnToVendor
+}
+
+// stockStatus es el estado en el que entra la mercancia
+// RESTOCK vuelve al stock vendible, SCRAP queda como dañada hasta desecharla
+// y RETURN_TO_VENDOR queda retenida hasta regresarla al proveedor
+func (d Disposition) stockStatus() movement.StockStatus {
+	switch d {
+	case DispositionQuarantine:
+		return movement.StockStatusQuarantine
+	case DispositionScrap:
+		return movement.StockStatusDamaged
+	case DispositionReturnToVendor:
+		return movement.StockStatusOnHold
+	}
+	return movement.StockStatusAvailable
+}
+
+// reasonCode es el codigo de razon de las entradas por devolucion
+const reasonCode = "CUSTOMER_RETURN"
+
+// RMA es una autorizacion de devolucion ligada a la orden de venta o al movimiento de salida original
+type RMA struct {
+	ID                int        `json:"id" db:"id"`
+	SalesOrderID      *int       `json:"sales_order_id,omitempty" db:"sales_order_id"`
+	MovementID        *int       `json:"movement_id,omitempty" db:"movement_id"`
+	WarehouseID       int        `json:"warehouse_id" db:"warehouse_id"`
+	CustomerReference *string    `json:"customer_reference,omitempty" db:"customer_reference"`
+	Reason            *string    `json:"reason,omitempty" db:"reason"`
+	Status            Status     `json:"status" db:"status"`
+	CreatedBy         string     `json:"created_by,omitempty" db:"created_by"`
+	ClosedAt          *time.Time `json:"closed_at,omitempty" db:"closed_at"`
+	CancelledAt       *time.Time `json:"cancelled_at,omitempty" db:"cancelled_at"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at" db:"updated_at"`
+
+	Lines []Line `json:"lines,omitempty" db:"-"`
+}
+
+// Line es un producto devuelto; la disposicion se asigna al inspeccionarlo
+type Line struct {
+	ID               int          `json:"id" db:"id"`
+	RMAID            int          `json:"rma_id" db:"rma_id"`
+	ProductID        int          `json:"product_id" db:"product_id"`
+	SKU              string       `json:"sku" db:"sku"`
+	SalesOrderLineID *int         `json:"sales_order_line_id,omitempty" db:"sales_order_line_id"`
+	Quantity         int          `json:"quantity" db:"quantity"` // en la unidad base
+	Reason           *string      `json:"reason,omitempty" db:"reason"`
+	Disposition      *Disposition `json:"disposition,omitempty" db:"disposition"`
+	LocationID       *int         `json:"location_id,omitempty" db:"location_id"`
+	SupplierID       *int         `json:"supplier_id,omitempty" db:"supplier_id"`
+	MovementID       *int         `json:"movement_id,omitempty" db:"movement_id"` // IN generado al inspeccionar
+	InspectionNotes  *string      `json:"inspection_notes,omitempty" db:"inspection_notes"`
+	InspectedBy      string       `json:"inspected_by,omitempty" db:"inspected_by"`
+	InspectedAt      *time.Time   `json:"inspected_at,omitempty" db:"inspected_at"`
+}
+
+// CreateRMARequest: sales_order_id o movement_id (la salida original), solo uno de los dos
+type CreateRMARequest struct {
+	SalesOrderID      *int          `json:"sales_order_id" binding:"omitempty,min=1"`
+	MovementID        *int          `json:"movement_id" binding:"omitempty,min=1"`
+	CustomerReference string        `json:"customer_reference" binding:"max=50"`
+	Reason            string        `json:"reason" binding:"max=255"`
+	Lines             []LineRequest `json:"lines" binding:"required,min=1,dive"`
+	CreatedBy         string        `json:"-"`
+}
+
+type LineRequest struct {
+	ProductID int    `json:"product_id" binding:"required,min=1"`
+	Quantity  int    `json:"quantity" binding:"required,min=1"` // en la unidad base
+	Reason    string `json:"reason" binding:"max=255"`
+}
+
+// InspectLineRequest asigna la disposicion de una linea
+// location_id: opcional, bin donde se guarda; supplier_id: obligatorio en RETURN_TO_VENDOR
+// lot_number y serials se piden como en cualquier entrada de productos con lotes o series
+type InspectLineRequest struct {
+	Disposition Disposition `json:"disposition" binding:"required"`
+	LocationID  *int        `json:"location_id" binding:"omitempty,min=1"`
+	SupplierID  *int        `json:"supplier_id" binding:"omitempty,min=1"`
+	LotNumber   string      `json:"lot_number" binding:"max=50"`
+	Serials     []string    `json:"serials" binding:"omitempty,dive,required,max=100"`
+	Notes       string      `json:"notes" binding:"max=255"`
+	InspectedBy string      `json:"-"`
+}
+
+// ListFilters son los filtros opcionales de GET /rmas
+type ListFilters struct {
+	SalesOrderID *int   `form:"sales_order_id"`
+	Status       string `form:"status"`
+	Page         int    `form:"page"`
+	PageSize     int    `form:"page_size"`
+}
+
+type PaginatedResponse struct {
+	Data       interface{} `json:"data"`
+	Pagination Pagination  `json:"pagination"`
+}
+
+type Pagination struct {
+	Page       int `json:"page"`
+	PageSize   int `json:"page_size"`
+	Total      int `json:"total"`
+	TotalPages int `json:"total_pages"`
+}
+
+// sourceLine es lo que salio en la orden o el movimiento original y lo que ya se devolvio de ello
+type sourceLine struct {
+	SalesOrderLineID *int   `db:"sales_order_line_id"`
+	ProductID        int    `db:"product_id"`
+	SKU              string `db:"sku"`
+	Shipped          int    `db:"shipped"`
+	Returned         int    `db:"returned"`
+}
+
+// sourceHeader es la orden o el movimiento original de la devolucion
+type sourceHeader struct {
+	WarehouseID       int     `db:"warehouse_id"`
+	Status            string  `db:"status"`
+	CustomerReference *string `db:"customer_reference"`
+}
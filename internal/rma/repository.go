@@ -0,0 +1,284 @@
+package rma
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+)
+
+const rmaColumns = `
+	id, sales_order_id, movement_id, warehouse_id, customer_reference, reason, status,
+	COALESCE(created_by, '') AS created_by, closed_at, cancelled_at, created_at, updated_at
+`
+
+type Repository struct {
+	db     *sqlx.DB
+	logger zerolog.Logger
+}
+
+func NewRepository(db *sqlx.DB, logger zerolog.Logger) *Repository {
+	moduleLogger := logger.With().Str("module", "rma").Logger()
+	return &Repository{db: db, logger: moduleLogger}
+}
+
+// obtiene la orden de venta original con LOCK PESIMISTA
+// serializa las devoluciones de la misma orden para no devolver de mas
+func (r *Repository) GetOrderSourceForUpdate(ctx context.Context, tx *sqlx.Tx, orderID int) (*sourceHeader, error) {
+	query := `SELECT warehouse_id, status, customer_reference FROM sales_orders WHERE id = $1 FOR UPDATE`
+
+	var source sourceHeader
+	if err := tx.GetContext(ctx, &source, query, orderID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("invalid sales_order_id: sales order with id [%d] not found", orderID)
+		}
+		return nil, fmt.Errorf("error getting sales order: %w", err)
+	}
+	return &source, nil
+}
+
+// lineas enviadas de la orden con lo ya devuelto en devoluciones no canceladas
+func (r *Repository) ListOrderSourceLines(ctx context.Context, tx *sqlx.Tx, orderID int) ([]sourceLine, error) {
+	query := `
+		SELECT sl.id AS sales_order_line_id, sl.product_id, p.sku, sl.shipped_quantity AS shipped,
+			COALESCE((
+				SELECT SUM(rl.quantity) FROM rma_lines rl
+				JOIN rmas r ON r.id = rl.rma_id
+				WHERE rl.sales_order_line_id = sl.id AND r.status <> 'CANCELLED'
+			), 0) AS returned
+		FROM sales_order_lines sl
+		JOIN products p ON p.id = sl.product_id
+		WHERE sl.sales_order_id = $1
+	`
+
+	var lines []sourceLine
+	if err := tx.SelectContext(ctx, &lines, query, orderID); err != nil {
+		return nil, fmt.Errorf("error listing sales order lines: %w", err)
+	}
+	return lines, nil
+}
+
+// obtiene el movimiento original con LOCK PESIMISTA (status es el tipo de movimiento)
+func (r *Repository) GetMovementSourceForUpdate(ctx context.Context, tx *sqlx.Tx, movementID int) (*sourceHeader, error) {
+	query := `SELECT warehouse_id, movement_type AS status, NULL AS customer_reference FROM movements WHERE id = $1 FOR UPDATE`
+
+	var source sourceHeader
+	if err := tx.GetContext(ctx, &source, query, movementID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("invalid movement_id: movement with id [%d] not found", movementID)
+		}
+		return nil, fmt.Errorf("error getting movement: %w", err)
+	}
+	return &source, nil
+}
+
+// lo que salio en el movimiento con lo ya devuelto contra el
+func (r *Repository) GetMovementSourceLine(ctx context.Context, tx *sqlx.Tx, movementID int) (*sourceLine, error) {
+	query := `
+		SELECT NULL AS sales_order_line_id, m.product_id, p.sku, m.quantity AS shipped,
+			COALESCE((
+				SELECT SUM(rl.quantity) FROM rma_lines rl
+				JOIN rmas r ON r.id = rl.rma_id
+				WHERE r.movement_id = m.id AND r.status <> 'CANCELLED'
+			), 0) AS returned
+		FROM movements m
+		JOIN products p ON p.id = m.product_id
+		WHERE m.id = $1
+	`
+
+	var line sourceLine
+	if err := tx.GetContext(ctx, &line, query, movementID); err != nil {
+		return nil, fmt.Errorf("error getting movement: %w", err)
+	}
+	return &line, nil
+}
+
+func (r *Repository) Create(ctx context.Context, tx *sqlx.Tx, req CreateRMARequest, warehouseID int) (int, error) {
+	var id int
+	query := `
+		INSERT INTO rmas (sales_order_id, movement_id, warehouse_id, customer_reference, reason, created_by)
+		VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), NULLIF($6, ''))
+		RETURNING id
+	`
+
+	err := tx.GetContext(ctx, &id, query, req.SalesOrderID, req.MovementID, warehouseID, req.CustomerReference, req.Reason, req.CreatedBy)
+	if err != nil {
+		return 0, fmt.Errorf("error creating rma: %w", err)
+	}
+	return id, nil
+}
+
+func (r *Repository) CreateLine(ctx context.Context, tx *sqlx.Tx, rmaID int, line LineRequest, salesOrderLineID *int) error {
+	query := `
+		INSERT INTO rma_lines (rma_id, product_id, sales_order_line_id, quantity, reason)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+	`
+
+	if _, err := tx.ExecContext(ctx, query, rmaID, line.ProductID, salesOrderLineID, line.Quantity, line.Reason); err != nil {
+		return fmt.Errorf("error creating rma line: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) GetByID(ctx context.Context, id int) (*RMA, error) {
+	query := `SELECT ` + rmaColumns + ` FROM rmas WHERE id = $1`
+
+	var rma RMA
+	if err := r.db.GetContext(ctx, &rma, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("rma with id [%d] not found", id)
+		}
+		return nil, fmt.Errorf("error getting rma: %w", err)
+	}
+	return &rma, nil
+}
+
+// obtiene la devolucion con LOCK PESIMISTA
+func (r *Repository) GetForUpdate(ctx context.Context, tx *sqlx.Tx, id int) (*RMA, error) {
+	query := `SELECT ` + rmaColumns + ` FROM rmas WHERE id = $1 FOR UPDATE`
+
+	var rma RMA
+	if err := tx.GetContext(ctx, &rma, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("rma with id [%d] not found", id)
+		}
+		return nil, fmt.Errorf("error getting rma: %w", err)
+	}
+	return &rma, nil
+}
+
+func (r *Repository) List(ctx context.Context, filters ListFilters) ([]RMA, int, error) {
+	var conditions []string
+	var args []interface{}
+	argPosition := 1
+
+	if filters.SalesOrderID != nil {
+		conditions = append(conditions, fmt.Sprintf("sales_order_id = $%d", argPosition))
+		args = append(args, *filters.SalesOrderID)
+		argPosition++
+	}
+
+	if filters.Status != "" {
+		conditions = append(conditions, fmt.Sprintf("status = $%d", argPosition))
+		args = append(args, filters.Status)
+		argPosition++
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := r.db.GetContext(ctx, &total, "SELECT COUNT(*) FROM rmas"+where, args...); err != nil {
+		return nil, 0, fmt.Errorf("error counting rmas: %w", err)
+	}
+
+	offset := (filters.Page - 1) * filters.PageSize
+	query := "SELECT " + rmaColumns + " FROM rmas" + where +
+		fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d", argPosition, argPosition+1)
+	args = append(args, filters.PageSize, offset)
+
+	var rmas []RMA
+	if err := r.db.SelectContext(ctx, &rmas, query, args...); err != nil {
+		return nil, 0, fmt.Errorf("error listing rmas: %w", err)
+	}
+	return rmas, total, nil
+}
+
+const lineColumns = `
+	rl.id, rl.rma_id, rl.product_id, p.sku, rl.sales_order_line_id, rl.quantity, rl.reason, rl.disposition,
+	rl.location_id, rl.supplier_id, rl.movement_id, rl.inspection_notes,
+	COALESCE(rl.inspected_by, '') AS inspected_by, rl.inspected_at
+`
+
+func (r *Repository) ListLines(ctx context.Context, rmaID int) ([]Line, error) {
+	query := `SELECT ` + lineColumns + ` FROM rma_lines rl JOIN products p ON p.id = rl.product_id
+		WHERE rl.rma_id = $1 ORDER BY rl.id`
+
+	var lines []Line
+	if err := r.db.SelectContext(ctx, &lines, query, rmaID); err != nil {
+		return nil, fmt.Errorf("error listing rma lines: %w", err)
+	}
+	return lines, nil
+}
+
+// obtiene la linea de la devolucion con LOCK PESIMISTA
+func (r *Repository) GetLineForUpdate(ctx context.Context, tx *sqlx.Tx, rmaID, lineID int) (*Line, error) {
+	query := `SELECT ` + lineColumns + ` FROM rma_lines rl JOIN products p ON p.id = rl.product_id
+		WHERE rl.id = $1 AND rl.rma_id = $2 FOR UPDATE OF rl`
+
+	var line Line
+	if err := tx.GetContext(ctx, &line, query, lineID, rmaID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("rma line [%d] not found in rma %d", lineID, rmaID)
+		}
+		return nil, fmt.Errorf("error getting rma line: %w", err)
+	}
+	return &line, nil
+}
+
+func (r *Repository) InspectLine(ctx context.Context, tx *sqlx.Tx, lineID int, req InspectLineRequest, movementID *int) error {
+	query := `
+		UPDATE rma_lines
+		SET disposition = $1, location_id = $2, supplier_id = $3, movement_id = $4,
+			inspection_notes = NULLIF($5, ''), inspected_by = NULLIF($6, ''), inspected_at = CURRENT_TIMESTAMP
+		WHERE id = $7
+	`
+
+	_, err := tx.ExecContext(ctx, query, req.Disposition, req.LocationID, req.SupplierID, movementID, req.Notes, req.InspectedBy, lineID)
+	if err != nil {
+		return fmt.Errorf("error inspecting rma line: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) CountPendingLines(ctx context.Context, tx *sqlx.Tx, rmaID int) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM rma_lines WHERE rma_id = $1 AND disposition IS NULL`
+
+	if err := tx.GetContext(ctx, &count, query, rmaID); err != nil {
+		return 0, fmt.Errorf("error counting pending rma lines: %w", err)
+	}
+	return count, nil
+}
+
+func (r *Repository) CountInspectedLines(ctx context.Context, tx *sqlx.Tx, rmaID int) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM rma_lines WHERE rma_id = $1 AND disposition IS NOT NULL`
+
+	if err := tx.GetContext(ctx, &count, query, rmaID); err != nil {
+		return 0, fmt.Errorf("error counting inspected rma lines: %w", err)
+	}
+	return count, nil
+}
+
+// cambia el estado y registra cuando se cerro o se cancelo
+func (r *Repository) UpdateStatus(ctx context.Context, tx *sqlx.Tx, id int, status Status) error {
+	query := `
+		UPDATE rmas
+		SET status = $1,
+			closed_at = CASE WHEN $1 = 'CLOSED' THEN CURRENT_TIMESTAMP ELSE closed_at END,
+			cancelled_at = CASE WHEN $1 = 'CANCELLED' THEN CURRENT_TIMESTAMP ELSE cancelled_at END
+		WHERE id = $2
+	`
+
+	if _, err := tx.ExecContext(ctx, query, status, id); err != nil {
+		return fmt.Errorf("error updating rma status: %w", err)
+	}
+	return nil
+}
+
+// verifica que el proveedor exista y este activo
+func (r *Repository) SupplierIsActive(ctx context.Context, supplierID int) (bool, error) {
+	var active bool
+	query := `SELECT EXISTS(SELECT 1 FROM suppliers WHERE id = $1 AND is_active)`
+
+	if err := r.db.GetContext(ctx, &active, query, supplierID); err != nil {
+		return false, fmt.Errorf("error checking supplier: %w", err)
+	}
+	return active, nil
+}
@@ -0,0 +1,335 @@
+package rma
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+	"github.com/whoAngeel/wms-lite/internal/movement"
+)
+
+type Service struct {
+	repo      *Repository
+	db        *sqlx.DB
+	movements *movement.Service
+	logger    zerolog.Logger
+}
+
+func NewService(repo *Repository, db *sqlx.DB, movements *movement.Service, logger zerolog.Logger) *Service {
+	serviceLogger := logger.With().Str("service", "rma").Logger()
+	return &Service{repo: repo, db: db, movements: movements, logger: serviceLogger}
+}
+
+// Create autoriza una devolucion contra la orden de venta enviada o contra el movimiento OUT original
+// no se puede devolver mas de lo que salio (descontando otras devoluciones no canceladas)
+// la mercancia no entra al stock hasta inspeccionar cada linea
+func (s *Service) Create(ctx context.Context, req CreateRMARequest) (rma *RMA, err error) {
+	req.CustomerReference = strings.TrimSpace(req.CustomerReference)
+
+	if (req.SalesOrderID == nil) == (req.MovementID == nil) {
+		return nil, fmt.Errorf("invalid request: provide either sales_order_id or movement_id")
+	}
+
+	seen := make(map[int]bool, len(req.Lines))
+	for _, line := range req.Lines {
+		if seen[line.ProductID] {
+			return nil, fmt.Errorf("invalid lines: product [%d] appears more than once", line.ProductID)
+		}
+		seen[line.ProductID] = true
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var source *sourceHeader
+	var sources []sourceLine
+	if req.SalesOrderID != nil {
+		source, err = s.repo.GetOrderSourceForUpdate(ctx, tx, *req.SalesOrderID)
+		if err != nil {
+			return nil, err
+		}
+		if source.Status != "SHIPPED" {
+			return nil, fmt.Errorf("cannot create rma: sales order is %s, only shipped orders can be returned", source.Status)
+		}
+
+		sources, err = s.repo.ListOrderSourceLines(ctx, tx, *req.SalesOrderID)
+		if err != nil {
+			return nil, err
+		}
+
+		if req.CustomerReference == "" && source.CustomerReference != nil {
+			req.CustomerReference = *source.CustomerReference
+		}
+	} else {
+		source, err = s.repo.GetMovementSourceForUpdate(ctx, tx, *req.MovementID)
+		if err != nil {
+			return nil, err
+		}
+		if source.Status != string(movement.MovementTypeOut) {
+			return nil, fmt.Errorf("invalid movement_id: movement %d is %s, only OUT movements can be returned", *req.MovementID, source.Status)
+		}
+
+		line, err := s.repo.GetMovementSourceLine(ctx, tx, *req.MovementID)
+		if err != nil {
+			return nil, err
+		}
+		sources = []sourceLine{*line}
+	}
+
+	byProduct := make(map[int]sourceLine, len(sources))
+	for _, line := range sources {
+		byProduct[line.ProductID] = line
+	}
+
+	for _, line := range req.Lines {
+		original, ok := byProduct[line.ProductID]
+		if !ok {
+			return nil, fmt.Errorf("invalid lines: product [%d] did not ship in the original order or movement", line.ProductID)
+		}
+		if left := original.Shipped - original.Returned; line.Quantity > left {
+			return nil, fmt.Errorf("invalid quantity: %s has %d units left to return, request=%d", original.SKU, max(left, 0), line.Quantity)
+		}
+	}
+
+	id, err := s.repo.Create(ctx, tx, req, source.WarehouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, line := range req.Lines {
+		if err = s.repo.CreateLine(ctx, tx, id, line, byProduct[line.ProductID].SalesOrderLineID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	s.logger.Info().Int("rma_id", id).Int("lines", len(req.Lines)).Msg("RMA created")
+	return s.GetByID(ctx, id)
+}
+
+// obtiene la devolucion con sus lineas
+func (s *Service) GetByID(ctx context.Context, id int) (*RMA, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("invalid ID: must be greater than 0")
+	}
+
+	rma, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	rma.Lines, err = s.repo.ListLines(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return rma, nil
+}
+
+func (s *Service) List(ctx context.Context, filters ListFilters) (*PaginatedResponse, error) {
+	filters.Page, filters.PageSize = normalizePagination(filters.Page, filters.PageSize)
+
+	if filters.Status != "" {
+		status := Status(strings.ToUpper(filters.Status))
+		if !status.IsValid() {
+			return nil, fmt.Errorf("invalid status: must be OPEN, CLOSED or CANCELLED")
+		}
+		filters.Status = string(status)
+	}
+
+	rmas, total, err := s.repo.List(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	if rmas == nil {
+		rmas = []RMA{}
+	}
+
+	return &PaginatedResponse{
+		Data: rmas,
+		Pagination: Pagination{
+			Page:       filters.Page,
+			PageSize:   filters.PageSize,
+			Total:      total,
+			TotalPages: (total + filters.PageSize - 1) / filters.PageSize,
+		},
+	}, nil
+}
+
+// InspectLine asigna la disposicion de una linea y reingresa la mercancia segun ella
+// registra un IN con el codigo de razon CUSTOMER_RETURN en el estado que corresponde a la disposicion
+// (RESTOCK disponible, QUARANTINE en cuarentena, SCRAP dañado, RETURN_TO_VENDOR en espera)
+// cuando todas las lineas tienen disposicion la devolucion se cierra
+func (s *Service) InspectLine(ctx context.Context, id, lineID int, req InspectLineRequest) (err error) {
+	req.Disposition = Disposition(strings.ToUpper(string(req.Disposition)))
+	if err := s.validateInspection(ctx, req); err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	rma, err := s.repo.GetForUpdate(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	if rma.Status != StatusOpen {
+		return fmt.Errorf("cannot inspect line: rma is %s", rma.Status)
+	}
+
+	line, err := s.repo.GetLineForUpdate(ctx, tx, id, lineID)
+	if err != nil {
+		return err
+	}
+
+	if line.Disposition != nil {
+		return fmt.Errorf("cannot inspect line: line %d was already inspected as %s", line.ID, *line.Disposition)
+	}
+
+	movementReq := movement.CreateMovementRequest{
+		ProductID:    line.ProductID,
+		WarehouseID:  rma.WarehouseID,
+		MovementType: movement.MovementTypeIn,
+		Quantity:     line.Quantity,
+		Reason:       fmt.Sprintf("rma #%d %s", rma.ID, strings.ToLower(string(req.Disposition))),
+		ReasonCode:   reasonCode,
+		CreatedBy:    req.InspectedBy,
+		ToLocationID: req.LocationID,
+		LotNumber:    req.LotNumber,
+		Serials:      req.Serials,
+	}
+	if status := req.Disposition.stockStatus(); status != movement.StockStatusAvailable {
+		movementReq.ToStatus = status
+	}
+
+	posted, err := s.movements.CreateMovementTx(ctx, tx, movementReq)
+	if err != nil {
+		return fmt.Errorf("error receiving line %d (%s): %w", line.ID, line.SKU, err)
+	}
+
+	if err = s.repo.InspectLine(ctx, tx, line.ID, req, &posted.ID); err != nil {
+		return err
+	}
+
+	pending, err := s.repo.CountPendingLines(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if pending == 0 {
+		if err = s.repo.UpdateStatus(ctx, tx, id, StatusClosed); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	s.movements.AfterCommit(ctx, posted)
+
+	s.logger.Info().Int("rma_id", id).Int("line_id", lineID).Str("disposition", string(req.Disposition)).Msg("RMA line inspected")
+	return nil
+}
+
+// Cancel cancela una devolucion que aun no tiene lineas inspeccionadas
+func (s *Service) Cancel(ctx context.Context, id int) (err error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	rma, err := s.repo.GetForUpdate(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	if rma.Status != StatusOpen {
+		return fmt.Errorf("cannot cancel rma: rma is %s", rma.Status)
+	}
+
+	inspected, err := s.repo.CountInspectedLines(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if inspected > 0 {
+		return fmt.Errorf("cannot cancel rma: %d lines were already inspected", inspected)
+	}
+
+	if err = s.repo.UpdateStatus(ctx, tx, id, StatusCancelled); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	s.logger.Info().Int("rma_id", id).Msg("RMA cancelled")
+	return nil
+}
+
+// valida los datos que exige cada disposicion
+func (s *Service) validateInspection(ctx context.Context, req InspectLineRequest) error {
+	if !req.Disposition.IsValid() {
+		return fmt.Errorf("invalid disposition: must be RESTOCK, QUARANTINE, SCRAP or RETURN_TO_VENDOR")
+	}
+
+	if req.Disposition == DispositionReturnToVendor {
+		if req.SupplierID == nil {
+			return fmt.Errorf("invalid supplier_id: RETURN_TO_VENDOR requires the supplier")
+		}
+		active, err := s.repo.SupplierIsActive(ctx, *req.SupplierID)
+		if err != nil {
+			return err
+		}
+		if !active {
+			return fmt.Errorf("invalid supplier_id: supplier with id [%d] not found or inactive", *req.SupplierID)
+		}
+	} else if req.SupplierID != nil {
+		return fmt.Errorf("invalid supplier_id: only RETURN_TO_VENDOR accepts a supplier")
+	}
+	return nil
+}
+
+func normalizePagination(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	if pageSize > 100 {
+		pageSize = 100
+	}
+	return page, pageSize
+}
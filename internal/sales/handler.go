@@ -0,0 +1,246 @@
+package sales
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+type Handler struct {
+	service *Service
+	logger  zerolog.Logger
+}
+
+func NewHandler(service *Service, logger zerolog.Logger) *Handler {
+	moduleLogger := logger.With().Str("module", "sales").Logger()
+	return &Handler{
+		service: service,
+		logger:  moduleLogger,
+	}
+}
+
+// Create maneja POST /sales-orders
+func (h *Handler) Create(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	var req CreateSalesOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn().Err(err).Msg("Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if email, exists := c.Get("email"); exists {
+		req.CreatedBy = email.(string)
+	}
+
+	order, err := h.service.CreateOrder(ctx, req)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error().Err(err).Msg("Error creating sales order")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creating sales order"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, order)
+}
+
+// List maneja GET /sales-orders
+func (h *Handler) List(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	var filters ListFilters
+	if err := c.ShouldBindQuery(&filters); err != nil {
+		h.logger.Warn().Err(err).Msg("Invalid query parameters for sales orders")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+
+	response, err := h.service.ListOrders(ctx, filters)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error().Err(err).Msg("Error listing sales orders")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error listing sales orders"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetByID maneja GET /sales-orders/:id (incluye lineas)
+func (h *Handler) GetByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID, must be a number"})
+		return
+	}
+
+	order, err := h.service.GetOrder(c.Request.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error().Err(err).Int("sales_order_id", id).Msg("Error getting sales order")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// Allocate maneja POST /sales-orders/:id/allocate
+func (h *Handler) Allocate(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID, must be a number"})
+		return
+	}
+
+	if err := h.service.Allocate(ctx, id); err != nil {
+		h.respondError(c, err, id, "Error allocating sales order")
+		return
+	}
+
+	h.respondOrder(c, id)
+}
+
+// Pick maneja POST /sales-orders/:id/pick
+func (h *Handler) Pick(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID, must be a number"})
+		return
+	}
+
+	if err := h.service.Pick(c.Request.Context(), id); err != nil {
+		h.respondError(c, err, id, "Error picking sales order")
+		return
+	}
+
+	h.respondOrder(c, id)
+}
+
+// Pack maneja POST /sales-orders/:id/pack
+func (h *Handler) Pack(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID, must be a number"})
+		return
+	}
+
+	if err := h.service.Pack(c.Request.Context(), id); err != nil {
+		h.respondError(c, err, id, "Error packing sales order")
+		return
+	}
+
+	h.respondOrder(c, id)
+}
+
+// Ship maneja POST /sales-orders/:id/ship
+// registra la salida de todas las lineas como movimientos OUT
+func (h *Handler) Ship(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID, must be a number"})
+		return
+	}
+
+	// el body es opcional
+	var req ShipRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid data",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	shippedBy := ""
+	if email, exists := c.Get("email"); exists {
+		shippedBy = email.(string)
+	}
+
+	if err := h.service.Ship(ctx, id, req, shippedBy); err != nil {
+		h.respondError(c, err, id, "Error shipping sales order")
+		return
+	}
+
+	h.respondOrder(c, id)
+}
+
+// Cancel maneja POST /sales-orders/:id/cancel
+func (h *Handler) Cancel(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID, must be a number"})
+		return
+	}
+
+	if err := h.service.Cancel(c.Request.Context(), id); err != nil {
+		h.respondError(c, err, id, "Error cancelling sales order")
+		return
+	}
+
+	h.respondOrder(c, id)
+}
+
+// respondError traduce los errores de las acciones sobre una orden
+// falta de stock o un estado que no permite la accion es un conflicto, no un error de datos
+func (h *Handler) respondError(c *gin.Context, err error, id int, message string) {
+	switch {
+	case strings.Contains(err.Error(), "cannot") || strings.Contains(err.Error(), "insufficient stock"):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "sales order with id"):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "not found") ||
+		strings.Contains(err.Error(), "required"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error().Err(err).Int("sales_order_id", id).Msg(message)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+// responde con la orden actualizada
+func (h *Handler) respondOrder(c *gin.Context, id int) {
+	order, err := h.service.GetOrder(c.Request.Context(), id)
+	if err != nil {
+		h.logger.Error().Err(err).Int("sales_order_id", id).Msg("Error getting sales order")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
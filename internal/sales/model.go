@@ -0,0 +1,118 @@
+package sales
+
+import (
+	"fmt"
+	"time"
+)
+
+// Status es el estado de una orden de venta
+// NEW -> ALLOCATED -> PICKED -> PACKED -> SHIPPED, CANCELLED desde cualquier estado antes de SHIPPED
+type Status string
+
+const (
+	StatusNew       Status = "NEW"
+	StatusAllocated Status = "ALLOCATED"
+	StatusPicked    Status = "PICKED"
+	StatusPacked    Status = "PACKED"
+	StatusShipped   Status = "SHIPPED"
+	StatusCancelled Status = "CANCELLED"
+)
+
+// isValid verifica si el estado es valido
+func (st Status) IsValid() bool {
+	return st == StatusNew || st == StatusAllocated || st == StatusPicked ||
+		st == StatusPacked || st == StatusShipped || st == StatusCancelled
+}
+
+// isFinal indica si la orden ya no admite cambios
+func (st Status) isFinal() bool {
+	return st == StatusShipped || st == StatusCancelled
+}
+
+type SalesOrder struct {
+	ID                int        `json:"id" db:"id"`
+	CustomerReference string     `json:"customer_reference" db:"customer_reference"`
+	CustomerName      string     `json:"customer_name,omitempty" db:"customer_name"`
+	WarehouseID       int        `json:"warehouse_id" db:"warehouse_id"` // almacen que surte
+	Status            Status     `json:"status" db:"status"`
+	Notes             string     `json:"notes,omitempty" db:"notes"`
+	CreatedBy         string     `json:"created_by,omitempty" db:"created_by"`
+	AllocatedAt       *time.Time `json:"allocated_at,omitempty" db:"allocated_at"`
+	PickedAt          *time.Time `json:"picked_at,omitempty" db:"picked_at"`
+	PackedAt          *time.Time `json:"packed_at,omitempty" db:"packed_at"`
+	ShippedAt         *time.Time `json:"shipped_at,omitempty" db:"shipped_at"`
+	CancelledAt       *time.Time `json:"cancelled_at,omitempty" db:"cancelled_at"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at" db:"updated_at"`
+	Lines             []Line     `json:"lines,omitempty" db:"-"`
+}
+
+// Line es un producto pedido por el cliente, las cantidades van en la unidad base
+// allocated_quantity es el stock apartado para la linea con su reserva mientras la orden no se envia ni se cancela
+type Line struct {
+	ID                int    `json:"id" db:"id"`
+	SalesOrderID      int    `json:"sales_order_id" db:"sales_order_id"`
+	ProductID         int    `json:"product_id" db:"product_id"`
+	SKU               string `json:"sku" db:"sku"`
+	ProductName       string `json:"product_name" db:"product_name"`
+	Quantity          int    `json:"quantity" db:"quantity"`
+	AllocatedQuantity int    `json:"allocated_quantity" db:"allocated_quantity"`
+	ShippedQuantity   int    `json:"shipped_quantity" db:"shipped_quantity"`
+	ReservationID     *int   `json:"reservation_id,omitempty" db:"reservation_id"` // reserva que aparta la linea
+	MovementID        *int   `json:"movement_id,omitempty" db:"movement_id"`       // OUT generado al enviar
+}
+
+type CreateSalesOrderRequest struct {
+	CustomerReference string        `json:"customer_reference" binding:"required,max=50"`
+	CustomerName      string        `json:"customer_name" binding:"max=255"`
+	WarehouseID       int           `json:"warehouse_id" binding:"omitempty,min=1"` // opcional, si se omite usa el almacen por defecto
+	Notes             string        `json:"notes" binding:"max=255"`
+	Lines             []LineRequest `json:"lines" binding:"required,min=1,dive"`
+	CreatedBy         string        `json:"-"`
+}
+
+type LineRequest struct {
+	ProductID int `json:"product_id" binding:"required,min=1"`
+	Quantity  int `json:"quantity" binding:"required,min=1"` // en la unidad base
+}
+
+// ShipRequest indica de donde sale cada linea al enviar (opcional)
+// sin detalle las salidas usan el stock sin ubicar y FEFO para lotes; los productos serializados requieren sus series
+// from_location_id es el bin de salida de las lineas sin detalle (ej. la ubicacion de preparacion de la ola)
+type ShipRequest struct {
+	FromLocationID *int       `json:"from_location_id" binding:"omitempty,min=1"`
+	Lines          []ShipLine `json:"lines" binding:"omitempty,dive"`
+}
+
+type ShipLine struct {
+	LineID         int      `json:"line_id" binding:"required,min=1"`
+	FromLocationID *int     `json:"from_location_id" binding:"omitempty,min=1"`
+	LotNumber      string   `json:"lot_number" binding:"max=50"`
+	Serials        []string `json:"serials" binding:"omitempty,dive,required,max=100"`
+}
+
+// ownerReference es el dueño de las reservas de la orden
+func ownerReference(orderID int) string {
+	return fmt.Sprintf("sales_order:%d", orderID)
+}
+
+// ListFilters son los filtros opcionales de GET /sales-orders
+type ListFilters struct {
+	WarehouseID       *int   `form:"warehouse_id"`
+	Status            string `form:"status"`
+	CustomerReference string `form:"customer_reference"`
+	Page              int    `form:"page"`
+	PageSize          int    `form:"page_size"`
+}
+
+type PaginatedResponse struct {
+	Data       interface{} `json:"data"`
+	Pagination Pagination  `json:"pagination"`
+}
+
+type Pagination struct {
+	Page       int `json:"page"`
+	PageSize   int `json:"page_size"`
+	Total      int `json:"total"`
+	TotalPages int `json:"total_pages"`
+}
@@ -0,0 +1,237 @@
+package sales
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog"
+)
+
+const orderColumns = `
+	id, customer_reference, COALESCE(customer_name, '') AS customer_name, warehouse_id, status,
+	COALESCE(notes, '') AS notes, COALESCE(created_by, '') AS created_by,
+	allocated_at, picked_at, packed_at, shipped_at, cancelled_at, created_at, updated_at
+`
+
+type Repository struct {
+	db     *sqlx.DB
+	logger zerolog.Logger
+}
+
+func NewRepository(db *sqlx.DB, logger zerolog.Logger) *Repository {
+	moduleLogger := logger.With().Str("module", "sales").Logger()
+	return &Repository{db: db, logger: moduleLogger}
+}
+
+// inserta la orden en NEW con sus lineas
+func (r *Repository) CreateOrder(ctx context.Context, tx *sqlx.Tx, req CreateSalesOrderRequest) (int, error) {
+	var id int
+	query := `
+		INSERT INTO sales_orders (customer_reference, customer_name, warehouse_id, notes, created_by)
+		VALUES ($1, NULLIF($2, ''), $3, NULLIF($4, ''), NULLIF($5, ''))
+		RETURNING id
+	`
+
+	err := tx.GetContext(ctx, &id, query, req.CustomerReference, req.CustomerName, req.WarehouseID, req.Notes, req.CreatedBy)
+	if err != nil {
+		return 0, fmt.Errorf("error creating sales order: %w", err)
+	}
+
+	lineQuery := `
+		INSERT INTO sales_order_lines (sales_order_id, product_id, quantity)
+		VALUES ($1, $2, $3)
+	`
+	for _, line := range req.Lines {
+		if _, err := tx.ExecContext(ctx, lineQuery, id, line.ProductID, line.Quantity); err != nil {
+			return 0, fmt.Errorf("error creating sales order line: %w", err)
+		}
+	}
+
+	return id, nil
+}
+
+func (r *Repository) GetOrder(ctx context.Context, id int) (*SalesOrder, error) {
+	query := `SELECT ` + orderColumns + ` FROM sales_orders WHERE id = $1`
+
+	var order SalesOrder
+	err := r.db.GetContext(ctx, &order, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("sales order with id [%d] not found", id)
+		}
+		return nil, fmt.Errorf("error getting sales order: %w", err)
+	}
+	return &order, nil
+}
+
+// obtiene la orden con LOCK PESIMISTA para serializar cambios de estado
+func (r *Repository) GetOrderForUpdate(ctx context.Context, tx *sqlx.Tx, id int) (*SalesOrder, error) {
+	query := `SELECT ` + orderColumns + ` FROM sales_orders WHERE id = $1 FOR UPDATE`
+
+	var order SalesOrder
+	err := tx.GetContext(ctx, &order, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("sales order with id [%d] not found", id)
+		}
+		return nil, fmt.Errorf("error getting sales order: %w", err)
+	}
+	return &order, nil
+}
+
+func (r *Repository) ListOrders(ctx context.Context, filters ListFilters) ([]SalesOrder, int, error) {
+	var conditions []string
+	var args []interface{}
+	argPosition := 1
+
+	if filters.WarehouseID != nil {
+		conditions = append(conditions, fmt.Sprintf("warehouse_id = $%d", argPosition))
+		args = append(args, *filters.WarehouseID)
+		argPosition++
+	}
+
+	if filters.Status != "" {
+		conditions = append(conditions, fmt.Sprintf("status = $%d", argPosition))
+		args = append(args, filters.Status)
+		argPosition++
+	}
+
+	if filters.CustomerReference != "" {
+		conditions = append(conditions, fmt.Sprintf("customer_reference = $%d", argPosition))
+		args = append(args, filters.CustomerReference)
+		argPosition++
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	err := r.db.GetContext(ctx, &total, "SELECT COUNT(*) FROM sales_orders"+where, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error counting sales orders: %w", err)
+	}
+
+	offset := (filters.Page - 1) * filters.PageSize
+	query := "SELECT " + orderColumns + " FROM sales_orders" + where +
+		fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d", argPosition, argPosition+1)
+	args = append(args, filters.PageSize, offset)
+
+	var orders []SalesOrder
+	err = r.db.SelectContext(ctx, &orders, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error listing sales orders: %w", err)
+	}
+
+	return orders, total, nil
+}
+
+// lista las lineas de una orden (acepta la conexion o una transaccion)
+func (r *Repository) ListLines(ctx context.Context, q sqlx.QueryerContext, orderID int) ([]Line, error) {
+	query := `
+		SELECT sl.id, sl.sales_order_id, sl.product_id, p.sku, p.name AS product_name,
+			sl.quantity, sl.allocated_quantity, sl.shipped_quantity, sl.reservation_id, sl.movement_id
+		FROM sales_order_lines sl
+		JOIN products p ON p.id = sl.product_id
+		WHERE sl.sales_order_id = $1
+		ORDER BY sl.id
+	`
+
+	var lines []Line
+	if err := sqlx.SelectContext(ctx, q, &lines, query, orderID); err != nil {
+		return nil, fmt.Errorf("error listing sales order lines: %w", err)
+	}
+	return lines, nil
+}
+
+// guarda lo apartado para la linea y la reserva que lo respalda
+func (r *Repository) SetLineAllocation(ctx context.Context, tx *sqlx.Tx, lineID, quantity, reservationID int) error {
+	query := `UPDATE sales_order_lines SET allocated_quantity = $1, reservation_id = $2 WHERE id = $3`
+
+	if _, err := tx.ExecContext(ctx, query, quantity, reservationID, lineID); err != nil {
+		return fmt.Errorf("error allocating sales order line: %w", err)
+	}
+	return nil
+}
+
+// libera todo lo apartado por la orden (las reservas se liberan aparte)
+func (r *Repository) ReleaseAllocations(ctx context.Context, tx *sqlx.Tx, orderID int) error {
+	query := `UPDATE sales_order_lines SET allocated_quantity = 0 WHERE sales_order_id = $1`
+
+	if _, err := tx.ExecContext(ctx, query, orderID); err != nil {
+		return fmt.Errorf("error releasing sales order allocation: %w", err)
+	}
+	return nil
+}
+
+// marca la linea como enviada: lo apartado se convierte en la salida del movimiento
+func (r *Repository) SetLineShipped(ctx context.Context, tx *sqlx.Tx, lineID, quantity, movementID int) error {
+	query := `
+		UPDATE sales_order_lines
+		SET shipped_quantity = $1, allocated_quantity = 0, movement_id = $2
+		WHERE id = $3
+	`
+
+	if _, err := tx.ExecContext(ctx, query, quantity, movementID, lineID); err != nil {
+		return fmt.Errorf("error shipping sales order line: %w", err)
+	}
+	return nil
+}
+
+// cambia el estado y registra cuando ocurrio cada paso
+func (r *Repository) UpdateStatus(ctx context.Context, tx *sqlx.Tx, id int, status Status) error {
+	query := `
+		UPDATE sales_orders
+		SET status = $1,
+			allocated_at = CASE WHEN $1 = 'ALLOCATED' THEN CURRENT_TIMESTAMP ELSE allocated_at END,
+			picked_at = CASE WHEN $1 = 'PICKED' THEN CURRENT_TIMESTAMP ELSE picked_at END,
+			packed_at = CASE WHEN $1 = 'PACKED' THEN CURRENT_TIMESTAMP ELSE packed_at END,
+			shipped_at = CASE WHEN $1 = 'SHIPPED' THEN CURRENT_TIMESTAMP ELSE shipped_at END,
+			cancelled_at = CASE WHEN $1 = 'CANCELLED' THEN CURRENT_TIMESTAMP ELSE cancelled_at END
+		WHERE id = $2
+	`
+
+	if _, err := tx.ExecContext(ctx, query, status, id); err != nil {
+		return fmt.Errorf("error updating sales order status: %w", err)
+	}
+	return nil
+}
+
+// cuenta cuantos de los productos existen (y no estan eliminados)
+func (r *Repository) CountProducts(ctx context.Context, productIDs []int) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM products WHERE id = ANY($1) AND deleted_at IS NULL`
+
+	if err := r.db.GetContext(ctx, &count, query, pq.Array(productIDs)); err != nil {
+		return 0, fmt.Errorf("error checking products: %w", err)
+	}
+	return count, nil
+}
+
+func (r *Repository) GetDefaultWarehouseID(ctx context.Context) (int, error) {
+	var id int
+	query := `SELECT id FROM warehouses WHERE is_default AND deleted_at IS NULL`
+
+	if err := r.db.GetContext(ctx, &id, query); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("default warehouse not found")
+		}
+		return 0, fmt.Errorf("error getting default warehouse: %w", err)
+	}
+	return id, nil
+}
+
+func (r *Repository) WarehouseExists(ctx context.Context, warehouseID int) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM warehouses WHERE id = $1 AND deleted_at IS NULL)`
+
+	if err := r.db.GetContext(ctx, &exists, query, warehouseID); err != nil {
+		return false, fmt.Errorf("error checking warehouse existence: %w", err)
+	}
+	return exists, nil
+}
@@ -0,0 +1,437 @@
+package sales
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+	"github.com/whoAngeel/wms-lite/internal/movement"
+	"github.com/whoAngeel/wms-lite/internal/reservation"
+)
+
+type Service struct {
+	repo         *Repository
+	db           *sqlx.DB
+	movements    *movement.Service
+	reservations *reservation.Service
+	logger       zerolog.Logger
+}
+
+func NewService(repo *Repository, db *sqlx.DB, movements *movement.Service, reservations *reservation.Service, logger zerolog.Logger) *Service {
+	serviceLogger := logger.With().Str("service", "sales").Logger()
+	return &Service{repo: repo, db: db, movements: movements, reservations: reservations, logger: serviceLogger}
+}
+
+// CreateOrder crea una orden de venta en NEW, todavia sin stock apartado
+func (s *Service) CreateOrder(ctx context.Context, req CreateSalesOrderRequest) (order *SalesOrder, err error) {
+	req.CustomerReference = strings.TrimSpace(req.CustomerReference)
+	if req.CustomerReference == "" {
+		return nil, fmt.Errorf("invalid customer_reference: must not be empty")
+	}
+
+	if req.WarehouseID == 0 {
+		req.WarehouseID, err = s.repo.GetDefaultWarehouseID(ctx)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		exists, err := s.repo.WarehouseExists(ctx, req.WarehouseID)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, fmt.Errorf("invalid warehouse_id: warehouse with id [%d] not found", req.WarehouseID)
+		}
+	}
+
+	seen := make(map[int]bool, len(req.Lines))
+	productIDs := make([]int, 0, len(req.Lines))
+	for _, line := range req.Lines {
+		if seen[line.ProductID] {
+			return nil, fmt.Errorf("invalid lines: product [%d] appears more than once", line.ProductID)
+		}
+		seen[line.ProductID] = true
+		productIDs = append(productIDs, line.ProductID)
+	}
+
+	count, err := s.repo.CountProducts(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	if count != len(productIDs) {
+		return nil, fmt.Errorf("invalid lines: some products were not found")
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	id, err := s.repo.CreateOrder(ctx, tx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	s.logger.Info().Int("sales_order_id", id).Str("customer_reference", req.CustomerReference).Int("lines", len(req.Lines)).Msg("Sales order created")
+	return s.GetOrder(ctx, id)
+}
+
+// obtiene la orden con sus lineas
+func (s *Service) GetOrder(ctx context.Context, id int) (*SalesOrder, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("invalid ID: must be greater than 0")
+	}
+
+	order, err := s.repo.GetOrder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := s.repo.ListLines(ctx, s.db, id)
+	if err != nil {
+		return nil, err
+	}
+	order.Lines = lines
+
+	return order, nil
+}
+
+func (s *Service) ListOrders(ctx context.Context, filters ListFilters) (*PaginatedResponse, error) {
+	filters.Page, filters.PageSize = normalizePagination(filters.Page, filters.PageSize)
+
+	if filters.Status != "" {
+		status := Status(strings.ToUpper(filters.Status))
+		if !status.IsValid() {
+			return nil, fmt.Errorf("invalid status: must be NEW, ALLOCATED, PICKED, PACKED, SHIPPED or CANCELLED")
+		}
+		filters.Status = string(status)
+	}
+
+	orders, total, err := s.repo.ListOrders(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	if orders == nil {
+		orders = []SalesOrder{}
+	}
+
+	return &PaginatedResponse{
+		Data: orders,
+		Pagination: Pagination{
+			Page:       filters.Page,
+			PageSize:   filters.PageSize,
+			Total:      total,
+			TotalPages: (total + filters.PageSize - 1) / filters.PageSize,
+		},
+	}, nil
+}
+
+// Allocate aparta el stock de todas las lineas con una reserva por linea (todo o nada)
+// disponible = stock del almacen - reservas activas (de otras ordenes o manuales)
+// las lineas se asignan en orden de product_id para respetar el orden de locks de stock_levels
+func (s *Service) Allocate(ctx context.Context, id int) (err error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	order, err := s.repo.GetOrderForUpdate(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	if order.Status != StatusNew {
+		return fmt.Errorf("cannot allocate sales order: order is %s", order.Status)
+	}
+
+	lines, err := s.repo.ListLines(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].ProductID < lines[j].ProductID
+	})
+
+	var reserved []*reservation.Reservation
+	for _, line := range lines {
+		held, err := s.reservations.ReserveTx(ctx, tx, reservation.CreateReservationRequest{
+			ProductID:      line.ProductID,
+			WarehouseID:    order.WarehouseID,
+			Quantity:       line.Quantity,
+			OwnerReference: ownerReference(order.ID),
+			CreatedBy:      order.CreatedBy,
+		})
+		if err != nil {
+			return fmt.Errorf("error allocating line %d (%s): %w", line.ID, line.SKU, err)
+		}
+
+		if err = s.repo.SetLineAllocation(ctx, tx, line.ID, line.Quantity, held.ID); err != nil {
+			return err
+		}
+		reserved = append(reserved, held)
+	}
+
+	if err = s.repo.UpdateStatus(ctx, tx, id, StatusAllocated); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	s.reservations.AfterCommit(ctx, reserved...)
+
+	s.logger.Info().Int("sales_order_id", id).Int("lines", len(lines)).Msg("Sales order allocated")
+	return nil
+}
+
+// Pick marca la orden como surtida del rack
+func (s *Service) Pick(ctx context.Context, id int) error {
+	return s.transition(ctx, id, StatusAllocated, StatusPicked)
+}
+
+// Pack marca la orden como empacada
+func (s *Service) Pack(ctx context.Context, id int) error {
+	return s.transition(ctx, id, StatusPicked, StatusPacked)
+}
+
+// cambia el estado de la orden si esta en el estado esperado
+func (s *Service) transition(ctx context.Context, id int, from, to Status) (err error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = s.transitionTx(ctx, tx, id, from, to); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	s.logger.Info().Int("sales_order_id", id).Str("status", string(to)).Msg("Sales order status changed")
+	return nil
+}
+
+// PackTx marca la orden como empacada dentro de una transaccion existente
+func (s *Service) PackTx(ctx context.Context, tx *sqlx.Tx, id int) error {
+	return s.transitionTx(ctx, tx, id, StatusPicked, StatusPacked)
+}
+
+// PickTx marca la orden como surtida dentro de una transaccion existente (ej. al confirmar su surtido en una ola)
+func (s *Service) PickTx(ctx context.Context, tx *sqlx.Tx, id int) error {
+	return s.transitionTx(ctx, tx, id, StatusAllocated, StatusPicked)
+}
+
+func (s *Service) transitionTx(ctx context.Context, tx *sqlx.Tx, id int, from, to Status) error {
+	order, err := s.repo.GetOrderForUpdate(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	if order.Status != from {
+		return fmt.Errorf("cannot move sales order to %s: order is %s", to, order.Status)
+	}
+
+	return s.repo.UpdateStatus(ctx, tx, id, to)
+}
+
+// Ship registra la salida de todas las lineas como movimientos OUT en una sola transaccion
+// cada salida consume la reserva de su linea
+// si un movimiento falla (ej. stock insuficiente) no se envia nada
+func (s *Service) Ship(ctx context.Context, id int, req ShipRequest, shippedBy string) (err error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	posted, err := s.ShipTx(ctx, tx, id, req, shippedBy)
+	if err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	s.movements.AfterCommit(ctx, posted...)
+
+	s.logger.Info().Int("sales_order_id", id).Int("movements", len(posted)).Msg("Sales order shipped")
+	return nil
+}
+
+// ShipTx envia la orden dentro de una transaccion existente (ej. al confirmar un embarque)
+// las lineas que ya tienen su OUT no se vuelven a registrar
+// las lineas se aplican en orden de product_id para respetar el orden de locks de stock_levels
+// retorna los movimientos creados para llamar AfterCommit despues del COMMIT
+func (s *Service) ShipTx(ctx context.Context, tx *sqlx.Tx, id int, req ShipRequest, shippedBy string) ([]*movement.Movement, error) {
+	order, err := s.repo.GetOrderForUpdate(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.Status != StatusPacked {
+		return nil, fmt.Errorf("cannot ship sales order: order is %s", order.Status)
+	}
+
+	lines, err := s.repo.ListLines(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	details := make(map[int]ShipLine, len(req.Lines))
+	for _, detail := range req.Lines {
+		details[detail.LineID] = detail
+	}
+	for lineID := range details {
+		if !containsLine(lines, lineID) {
+			return nil, fmt.Errorf("sales order line [%d] not found in order %d", lineID, id)
+		}
+	}
+
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].ProductID < lines[j].ProductID
+	})
+
+	var posted []*movement.Movement
+	for _, line := range lines {
+		if line.MovementID != nil {
+			continue
+		}
+
+		detail, ok := details[line.ID]
+		if !ok {
+			detail.FromLocationID = req.FromLocationID
+		}
+
+		shipped, err := s.movements.CreateMovementTx(ctx, tx, movement.CreateMovementRequest{
+			ProductID:      line.ProductID,
+			WarehouseID:    order.WarehouseID,
+			MovementType:   movement.MovementTypeOut,
+			Quantity:       line.Quantity,
+			Reason:         fmt.Sprintf("sales order #%d (%s)", order.ID, order.CustomerReference),
+			CreatedBy:      shippedBy,
+			FromLocationID: detail.FromLocationID,
+			LotNumber:      detail.LotNumber,
+			Serials:        detail.Serials,
+			ReservationID:  line.ReservationID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error shipping line %d (%s): %w", line.ID, line.SKU, err)
+		}
+
+		if err = s.repo.SetLineShipped(ctx, tx, line.ID, shipped.Quantity, shipped.ID); err != nil {
+			return nil, err
+		}
+		posted = append(posted, shipped)
+	}
+
+	if err = s.repo.UpdateStatus(ctx, tx, id, StatusShipped); err != nil {
+		return nil, err
+	}
+
+	return posted, nil
+}
+
+// Cancel cancela la orden y libera sus reservas
+func (s *Service) Cancel(ctx context.Context, id int) (err error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	order, err := s.repo.GetOrderForUpdate(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	if order.Status.isFinal() {
+		return fmt.Errorf("cannot cancel sales order: order is %s", order.Status)
+	}
+
+	released, err := s.reservations.ReleaseByOwnerTx(ctx, tx, ownerReference(order.ID))
+	if err != nil {
+		return err
+	}
+
+	if err = s.repo.ReleaseAllocations(ctx, tx, id); err != nil {
+		return err
+	}
+
+	if err = s.repo.UpdateStatus(ctx, tx, id, StatusCancelled); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	s.reservations.AfterCommit(ctx, released...)
+
+	s.logger.Info().Int("sales_order_id", id).Str("previous_status", string(order.Status)).Msg("Sales order cancelled")
+	return nil
+}
+
+func containsLine(lines []Line, lineID int) bool {
+	for _, line := range lines {
+		if line.ID == lineID {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizePagination(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	if pageSize > 100 {
+		pageSize = 100
+	}
+	return page, pageSize
+}
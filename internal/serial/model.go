@@ -0,0 +1,32 @@
+package serial
+
+import "time"
+
+// Serial es una unidad serializada con su estado actual
+type Serial struct {
+	ID           int       `json:"id" db:"id"`
+	ProductID    int       `json:"product_id" db:"product_id"`
+	SKU          string    `json:"sku" db:"sku"`
+	SerialNumber string    `json:"serial_number" db:"serial_number"`
+	Status       string    `json:"status" db:"status"`                       // IN_STOCK, SHIPPED o WRITTEN_OFF
+	WarehouseID  *int      `json:"warehouse_id,omitempty" db:"warehouse_id"` // nulo si la unidad ya salio
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+
+	// movimientos de la unidad, del mas antiguo al mas reciente
+	History []SerialMovement `json:"history" db:"-"`
+}
+
+// SerialMovement es un movimiento en el que participo la unidad
+type SerialMovement struct {
+	MovementID     int       `json:"movement_id" db:"movement_id"`
+	MovementType   string    `json:"movement_type" db:"movement_type"`
+	WarehouseID    int       `json:"warehouse_id" db:"warehouse_id"`
+	ToWarehouseID  *int      `json:"to_warehouse_id,omitempty" db:"to_warehouse_id"`
+	FromLocationID *int      `json:"from_location_id,omitempty" db:"from_location_id"`
+	ToLocationID   *int      `json:"to_location_id,omitempty" db:"to_location_id"`
+	Reason         string    `json:"reason,omitempty" db:"reason"`
+	ReasonCode     *string   `json:"reason_code,omitempty" db:"reason_code"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	CreatedBy      string    `json:"created_by,omitempty" db:"created_by"`
+}
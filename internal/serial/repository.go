@@ -0,0 +1,57 @@
+package serial
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+)
+
+type Repository struct {
+	db     *sqlx.DB
+	logger zerolog.Logger
+}
+
+func NewRepository(db *sqlx.DB, logger zerolog.Logger) *Repository {
+	moduleLogger := logger.With().Str("module", "serial").Logger()
+	return &Repository{db: db, logger: moduleLogger}
+}
+
+func (r *Repository) GetByNumber(ctx context.Context, serialNumber string) (*Serial, error) {
+	query := `
+		SELECT s.id, s.product_id, p.sku, s.serial_number, s.status, s.warehouse_id, s.created_at, s.updated_at
+		FROM serials s
+		JOIN products p ON p.id = s.product_id
+		WHERE s.serial_number = $1
+	`
+
+	var serial Serial
+	err := r.db.GetContext(ctx, &serial, query, serialNumber)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("serial [%s] not found", serialNumber)
+		}
+		return nil, fmt.Errorf("error getting serial: %w", err)
+	}
+	return &serial, nil
+}
+
+// movimientos de la unidad en orden cronologico
+func (r *Repository) ListMovements(ctx context.Context, serialID int) ([]SerialMovement, error) {
+	query := `
+		SELECT m.id AS movement_id, m.movement_type, m.warehouse_id, m.to_warehouse_id,
+			m.from_location_id, m.to_location_id, m.reason, m.reason_code, m.created_at, m.created_by
+		FROM movement_serials ms
+		JOIN movements m ON m.id = ms.movement_id
+		WHERE ms.serial_id = $1
+		ORDER BY m.created_at, m.id
+	`
+
+	history := []SerialMovement{}
+	if err := r.db.SelectContext(ctx, &history, query, serialID); err != nil {
+		return nil, fmt.Errorf("error getting serial history: %w", err)
+	}
+	return history, nil
+}
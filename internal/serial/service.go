@@ -0,0 +1,40 @@
+package serial
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+type Service struct {
+	repo   *Repository
+	logger zerolog.Logger
+}
+
+func NewService(repo *Repository, logger zerolog.Logger) *Service {
+	serviceLogger := logger.With().Str("service", "serial").Logger()
+	return &Service{repo: repo, logger: serviceLogger}
+}
+
+// obtiene la unidad con todos sus movimientos
+func (s *Service) GetByNumber(ctx context.Context, serialNumber string) (*Serial, error) {
+	serialNumber = strings.TrimSpace(serialNumber)
+	if serialNumber == "" {
+		return nil, fmt.Errorf("invalid serial: must not be empty")
+	}
+
+	serial, err := s.repo.GetByNumber(ctx, serialNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := s.repo.ListMovements(ctx, serial.ID)
+	if err != nil {
+		return nil, err
+	}
+	serial.History = history
+
+	return serial, nil
+}
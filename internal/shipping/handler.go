@@ -0,0 +1,235 @@
+package shipping
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+type Handler struct {
+	service *Service
+	logger  zerolog.Logger
+}
+
+func NewHandler(service *Service, logger zerolog.Logger) *Handler {
+	moduleLogger := logger.With().Str("module", "shipping").Logger()
+	return &Handler{
+		service: service,
+		logger:  moduleLogger,
+	}
+}
+
+// Create maneja POST /shipments
+func (h *Handler) Create(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	var req CreateShipmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn().Err(err).Msg("Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if email, exists := c.Get("email"); exists {
+		req.CreatedBy = email.(string)
+	}
+
+	shipment, err := h.service.Create(ctx, req)
+	if err != nil {
+		h.respondError(c, err, 0, "Error creating shipment")
+		return
+	}
+
+	c.JSON(http.StatusCreated, shipment)
+}
+
+// List maneja GET /shipments
+func (h *Handler) List(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	var filters ListFilters
+	if err := c.ShouldBindQuery(&filters); err != nil {
+		h.logger.Warn().Err(err).Msg("Invalid query parameters for shipments")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+
+	response, err := h.service.List(ctx, filters)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error().Err(err).Msg("Error listing shipments")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error listing shipments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetByID maneja GET /shipments/:id (incluye cajas)
+func (h *Handler) GetByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID, must be a number"})
+		return
+	}
+
+	shipment, err := h.service.GetByID(c.Request.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error().Err(err).Int("shipment_id", id).Msg("Error getting shipment")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, shipment)
+}
+
+// AddPackage maneja POST /shipments/:id/packages
+func (h *Handler) AddPackage(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID, must be a number"})
+		return
+	}
+
+	var req AddPackageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.service.AddPackage(c.Request.Context(), id, req); err != nil {
+		h.respondError(c, err, id, "Error adding package")
+		return
+	}
+
+	h.respondShipment(c, id, http.StatusCreated)
+}
+
+// RemovePackage maneja DELETE /shipments/:id/packages/:packageId
+func (h *Handler) RemovePackage(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID, must be a number"})
+		return
+	}
+
+	packageID, err := strconv.Atoi(c.Param("packageId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid package ID, must be a number"})
+		return
+	}
+
+	if err := h.service.RemovePackage(c.Request.Context(), id, packageID); err != nil {
+		h.respondError(c, err, id, "Error removing package")
+		return
+	}
+
+	h.respondShipment(c, id, http.StatusOK)
+}
+
+// Confirm maneja POST /shipments/:id/confirm
+// envia la orden: registra los OUT de las lineas que no los tengan
+func (h *Handler) Confirm(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID, must be a number"})
+		return
+	}
+
+	// el body es opcional
+	var req ConfirmShipmentRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid data",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	if email, exists := c.Get("email"); exists {
+		req.ConfirmedBy = email.(string)
+	}
+
+	if err := h.service.Confirm(ctx, id, req); err != nil {
+		h.respondError(c, err, id, "Error confirming shipment")
+		return
+	}
+
+	h.respondShipment(c, id, http.StatusOK)
+}
+
+// Cancel maneja POST /shipments/:id/cancel
+func (h *Handler) Cancel(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID, must be a number"})
+		return
+	}
+
+	if err := h.service.Cancel(c.Request.Context(), id); err != nil {
+		h.respondError(c, err, id, "Error cancelling shipment")
+		return
+	}
+
+	h.respondShipment(c, id, http.StatusOK)
+}
+
+// respondError traduce los errores de las acciones sobre un embarque
+// falta de stock o un estado que no permite la accion es un conflicto, no un error de datos
+func (h *Handler) respondError(c *gin.Context, err error, id int, message string) {
+	switch {
+	case strings.Contains(err.Error(), "cannot") || strings.Contains(err.Error(), "insufficient stock"):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "shipment with id") || strings.Contains(err.Error(), "package ["):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "not found") ||
+		strings.Contains(err.Error(), "required"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error().Err(err).Int("shipment_id", id).Msg(message)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+// responde con el embarque actualizado
+func (h *Handler) respondShipment(c *gin.Context, id, status int) {
+	shipment, err := h.service.GetByID(c.Request.Context(), id)
+	if err != nil {
+		h.logger.Error().Err(err).Int("shipment_id", id).Msg("Error getting shipment")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(status, shipment)
+}
@@ -0,0 +1,128 @@
+package shipping
+
+import (
+	"time"
+
+	"github.com/whoAngeel/wms-lite/internal/sales"
+)
+
+// Status es el estado de un embarque
+// OPEN mientras se empacan cajas -> CONFIRMED al salir del almacen; CANCELLED lo descarta
+type Status string
+
+const (
+	StatusOpen      Status = "OPEN"
+	StatusConfirmed Status = "CONFIRMED"
+	StatusCancelled Status = "CANCELLED"
+)
+
+// isValid verifica si el estado es valido
+func (st Status) IsValid() bool {
+	return st == StatusOpen || st == StatusConfirmed || st == StatusCancelled
+}
+
+// Shipment es la salida fisica de una orden de venta: transportista, guia y cajas
+type Shipment struct {
+	ID             int        `json:"id" db:"id"`
+	SalesOrderID   int        `json:"sales_order_id" db:"sales_order_id"`
+	Carrier        *string    `json:"carrier,omitempty" db:"carrier"`
+	TrackingNumber *string    `json:"tracking_number,omitempty" db:"tracking_number"`
+	FromLocationID *int       `json:"from_location_id,omitempty" db:"from_location_id"` // bin de donde sale (ej. staging de la ola)
+	Status         Status     `json:"status" db:"status"`
+	Notes          *string    `json:"notes,omitempty" db:"notes"`
+	CreatedBy      string     `json:"created_by,omitempty" db:"created_by"`
+	ConfirmedBy    string     `json:"confirmed_by,omitempty" db:"confirmed_by"`
+	ConfirmedAt    *time.Time `json:"confirmed_at,omitempty" db:"confirmed_at"`
+	CancelledAt    *time.Time `json:"cancelled_at,omitempty" db:"cancelled_at"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+
+	Packages []Package `json:"packages,omitempty" db:"-"`
+}
+
+// Package es una caja del embarque con sus medidas y las lineas que lleva
+type Package struct {
+	ID             int       `json:"id" db:"id"`
+	ShipmentID     int       `json:"shipment_id" db:"shipment_id"`
+	PackageNumber  int       `json:"package_number" db:"package_number"`
+	TrackingNumber *string   `json:"tracking_number,omitempty" db:"tracking_number"` // guia de la caja si el transportista la da por caja
+	LengthCm       float64   `json:"length_cm" db:"length_cm"`
+	WidthCm        float64   `json:"width_cm" db:"width_cm"`
+	HeightCm       float64   `json:"height_cm" db:"height_cm"`
+	WeightKg       float64   `json:"weight_kg" db:"weight_kg"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+
+	Lines []PackageLine `json:"lines" db:"-"`
+}
+
+// PackageLine es la cantidad de una linea de la orden que va en la caja (en la unidad base)
+type PackageLine struct {
+	PackageID        int    `json:"-" db:"package_id"`
+	SalesOrderLineID int    `json:"sales_order_line_id" db:"sales_order_line_id"`
+	SKU              string `json:"sku" db:"sku"`
+	Quantity         int    `json:"quantity" db:"quantity"`
+}
+
+type CreateShipmentRequest struct {
+	SalesOrderID   int    `json:"sales_order_id" binding:"required,min=1"`
+	Carrier        string `json:"carrier" binding:"max=50"`
+	TrackingNumber string `json:"tracking_number" binding:"max=100"`
+	// opcional, por defecto el staging de la ultima ola que surtio la orden
+	FromLocationID *int   `json:"from_location_id" binding:"omitempty,min=1"`
+	Notes          string `json:"notes" binding:"max=255"`
+	CreatedBy      string `json:"-"`
+}
+
+type AddPackageRequest struct {
+	TrackingNumber string               `json:"tracking_number" binding:"max=100"`
+	LengthCm       float64              `json:"length_cm" binding:"required,gt=0"`
+	WidthCm        float64              `json:"width_cm" binding:"required,gt=0"`
+	HeightCm       float64              `json:"height_cm" binding:"required,gt=0"`
+	WeightKg       float64              `json:"weight_kg" binding:"required,gt=0"`
+	Lines          []PackageLineRequest `json:"lines" binding:"required,min=1,dive"`
+}
+
+type PackageLineRequest struct {
+	SalesOrderLineID int `json:"sales_order_line_id" binding:"required,min=1"`
+	Quantity         int `json:"quantity" binding:"required,min=1"`
+}
+
+// ConfirmShipmentRequest cierra el embarque (body opcional)
+// carrier y tracking_number completan lo que no se capturo al crearlo
+// lines indica lote o series de las lineas que los requieren
+type ConfirmShipmentRequest struct {
+	Carrier        string           `json:"carrier" binding:"max=50"`
+	TrackingNumber string           `json:"tracking_number" binding:"max=100"`
+	Lines          []sales.ShipLine `json:"lines" binding:"omitempty,dive"`
+	ConfirmedBy    string           `json:"-"`
+}
+
+// ListFilters son los filtros opcionales de GET /shipments
+type ListFilters struct {
+	SalesOrderID   *int   `form:"sales_order_id"`
+	Status         string `form:"status"`
+	Carrier        string `form:"carrier"`
+	TrackingNumber string `form:"tracking_number"`
+	Page           int    `form:"page"`
+	PageSize       int    `form:"page_size"`
+}
+
+type PaginatedResponse struct {
+	Data       interface{} `json:"data"`
+	Pagination Pagination  `json:"pagination"`
+}
+
+type Pagination struct {
+	Page       int `json:"page"`
+	PageSize   int `json:"page_size"`
+	Total      int `json:"total"`
+	TotalPages int `json:"total_pages"`
+}
+
+// lineBalance es una linea de la orden con lo que ya va empacado en las cajas del embarque
+type lineBalance struct {
+	ID       int    `db:"id"`
+	SKU      string `db:"sku"`
+	Quantity int    `db:"quantity"`
+	Packed   int    `db:"packed"`
+}
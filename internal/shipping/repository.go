@@ -0,0 +1,297 @@
+package shipping
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+)
+
+const shipmentColumns = `
+	id, sales_order_id, carrier, tracking_number, from_location_id, status, notes,
+	COALESCE(created_by, '') AS created_by, COALESCE(confirmed_by, '') AS confirmed_by,
+	confirmed_at, cancelled_at, created_at, updated_at
+`
+
+type Repository struct {
+	db     *sqlx.DB
+	logger zerolog.Logger
+}
+
+func NewRepository(db *sqlx.DB, logger zerolog.Logger) *Repository {
+	moduleLogger := logger.With().Str("module", "shipping").Logger()
+	return &Repository{db: db, logger: moduleLogger}
+}
+
+// estado de la orden de venta (sales_orders.status)
+func (r *Repository) GetOrderStatus(ctx context.Context, orderID int) (string, error) {
+	var status string
+	query := `SELECT status FROM sales_orders WHERE id = $1`
+
+	if err := r.db.GetContext(ctx, &status, query, orderID); err != nil {
+		if err == sql.ErrNoRows {
+			return "", fmt.Errorf("invalid sales_order_id: sales order with id [%d] not found", orderID)
+		}
+		return "", fmt.Errorf("error getting sales order: %w", err)
+	}
+	return status, nil
+}
+
+// estado de la orden de venta con LOCK PESIMISTA
+func (r *Repository) GetOrderStatusForUpdate(ctx context.Context, tx *sqlx.Tx, orderID int) (string, error) {
+	var status string
+	query := `SELECT status FROM sales_orders WHERE id = $1 FOR UPDATE`
+
+	if err := tx.GetContext(ctx, &status, query, orderID); err != nil {
+		return "", fmt.Errorf("error getting sales order status: %w", err)
+	}
+	return status, nil
+}
+
+// staging de la ultima ola que surtio algo de la orden (0 si no paso por olas)
+func (r *Repository) GetPickStagingLocation(ctx context.Context, orderID int) (int, error) {
+	var locationID int
+	query := `
+		SELECT w.staging_location_id
+		FROM pick_tasks pt
+		JOIN waves w ON w.id = pt.wave_id
+		WHERE pt.sales_order_id = $1 AND pt.picked_quantity > 0
+		ORDER BY pt.picked_at DESC
+		LIMIT 1
+	`
+
+	err := r.db.GetContext(ctx, &locationID, query, orderID)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, fmt.Errorf("error getting pick staging location: %w", err)
+	}
+	return locationID, nil
+}
+
+func (r *Repository) Create(ctx context.Context, req CreateShipmentRequest) (int, error) {
+	var id int
+	query := `
+		INSERT INTO shipments (sales_order_id, carrier, tracking_number, from_location_id, notes, created_by)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), $4, NULLIF($5, ''), NULLIF($6, ''))
+		RETURNING id
+	`
+
+	err := r.db.GetContext(ctx, &id, query, req.SalesOrderID, req.Carrier, req.TrackingNumber, req.FromLocationID, req.Notes, req.CreatedBy)
+	if err != nil {
+		if strings.Contains(err.Error(), "idx_shipments_sales_order_active") {
+			return 0, fmt.Errorf("cannot create shipment: sales order %d already has a shipment", req.SalesOrderID)
+		}
+		return 0, fmt.Errorf("error creating shipment: %w", err)
+	}
+	return id, nil
+}
+
+func (r *Repository) GetByID(ctx context.Context, id int) (*Shipment, error) {
+	query := `SELECT ` + shipmentColumns + ` FROM shipments WHERE id = $1`
+
+	var shipment Shipment
+	if err := r.db.GetContext(ctx, &shipment, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("shipment with id [%d] not found", id)
+		}
+		return nil, fmt.Errorf("error getting shipment: %w", err)
+	}
+	return &shipment, nil
+}
+
+// obtiene el embarque con LOCK PESIMISTA
+func (r *Repository) GetForUpdate(ctx context.Context, tx *sqlx.Tx, id int) (*Shipment, error) {
+	query := `SELECT ` + shipmentColumns + ` FROM shipments WHERE id = $1 FOR UPDATE`
+
+	var shipment Shipment
+	if err := tx.GetContext(ctx, &shipment, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("shipment with id [%d] not found", id)
+		}
+		return nil, fmt.Errorf("error getting shipment: %w", err)
+	}
+	return &shipment, nil
+}
+
+func (r *Repository) List(ctx context.Context, filters ListFilters) ([]Shipment, int, error) {
+	var conditions []string
+	var args []interface{}
+	argPosition := 1
+
+	if filters.SalesOrderID != nil {
+		conditions = append(conditions, fmt.Sprintf("sales_order_id = $%d", argPosition))
+		args = append(args, *filters.SalesOrderID)
+		argPosition++
+	}
+
+	if filters.Status != "" {
+		conditions = append(conditions, fmt.Sprintf("status = $%d", argPosition))
+		args = append(args, filters.Status)
+		argPosition++
+	}
+
+	if filters.Carrier != "" {
+		conditions = append(conditions, fmt.Sprintf("carrier ILIKE $%d", argPosition))
+		args = append(args, filters.Carrier)
+		argPosition++
+	}
+
+	// la guia puede ser la del embarque o la de alguna de sus cajas
+	if filters.TrackingNumber != "" {
+		conditions = append(conditions, fmt.Sprintf(`(tracking_number = $%d OR EXISTS(
+			SELECT 1 FROM shipment_packages sp WHERE sp.shipment_id = shipments.id AND sp.tracking_number = $%d
+		))`, argPosition, argPosition))
+		args = append(args, filters.TrackingNumber)
+		argPosition++
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := r.db.GetContext(ctx, &total, "SELECT COUNT(*) FROM shipments"+where, args...); err != nil {
+		return nil, 0, fmt.Errorf("error counting shipments: %w", err)
+	}
+
+	offset := (filters.Page - 1) * filters.PageSize
+	query := "SELECT " + shipmentColumns + " FROM shipments" + where +
+		fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d", argPosition, argPosition+1)
+	args = append(args, filters.PageSize, offset)
+
+	var shipments []Shipment
+	if err := r.db.SelectContext(ctx, &shipments, query, args...); err != nil {
+		return nil, 0, fmt.Errorf("error listing shipments: %w", err)
+	}
+	return shipments, total, nil
+}
+
+// cajas del embarque con sus lineas
+func (r *Repository) ListPackages(ctx context.Context, shipmentID int) ([]Package, error) {
+	query := `
+		SELECT id, shipment_id, package_number, tracking_number, length_cm, width_cm, height_cm, weight_kg, created_at
+		FROM shipment_packages
+		WHERE shipment_id = $1
+		ORDER BY package_number
+	`
+
+	var packages []Package
+	if err := r.db.SelectContext(ctx, &packages, query, shipmentID); err != nil {
+		return nil, fmt.Errorf("error listing shipment packages: %w", err)
+	}
+
+	linesQuery := `
+		SELECT spl.package_id, spl.sales_order_line_id, p.sku, spl.quantity
+		FROM shipment_package_lines spl
+		JOIN shipment_packages sp ON sp.id = spl.package_id
+		JOIN sales_order_lines sl ON sl.id = spl.sales_order_line_id
+		JOIN products p ON p.id = sl.product_id
+		WHERE sp.shipment_id = $1
+		ORDER BY spl.sales_order_line_id
+	`
+
+	var lines []PackageLine
+	if err := r.db.SelectContext(ctx, &lines, linesQuery, shipmentID); err != nil {
+		return nil, fmt.Errorf("error listing shipment package lines: %w", err)
+	}
+
+	byPackage := make(map[int][]PackageLine, len(packages))
+	for _, line := range lines {
+		byPackage[line.PackageID] = append(byPackage[line.PackageID], line)
+	}
+	for i := range packages {
+		packages[i].Lines = byPackage[packages[i].ID]
+		if packages[i].Lines == nil {
+			packages[i].Lines = []PackageLine{}
+		}
+	}
+	return packages, nil
+}
+
+// lineas de la orden con lo que ya va empacado en el embarque
+func (r *Repository) ListLineBalances(ctx context.Context, tx *sqlx.Tx, shipmentID, orderID int) ([]lineBalance, error) {
+	query := `
+		SELECT sl.id, p.sku, sl.quantity,
+			COALESCE((
+				SELECT SUM(spl.quantity)
+				FROM shipment_package_lines spl
+				JOIN shipment_packages sp ON sp.id = spl.package_id
+				WHERE sp.shipment_id = $1 AND spl.sales_order_line_id = sl.id
+			), 0) AS packed
+		FROM sales_order_lines sl
+		JOIN products p ON p.id = sl.product_id
+		WHERE sl.sales_order_id = $2
+		ORDER BY sl.id
+	`
+
+	var lines []lineBalance
+	if err := tx.SelectContext(ctx, &lines, query, shipmentID, orderID); err != nil {
+		return nil, fmt.Errorf("error listing packed quantities: %w", err)
+	}
+	return lines, nil
+}
+
+func (r *Repository) CreatePackage(ctx context.Context, tx *sqlx.Tx, shipmentID int, req AddPackageRequest) (int, error) {
+	var id int
+	query := `
+		INSERT INTO shipment_packages (shipment_id, package_number, tracking_number, length_cm, width_cm, height_cm, weight_kg)
+		VALUES ($1, (SELECT COALESCE(MAX(package_number), 0) + 1 FROM shipment_packages WHERE shipment_id = $1),
+			NULLIF($2, ''), $3, $4, $5, $6)
+		RETURNING id
+	`
+
+	err := tx.GetContext(ctx, &id, query, shipmentID, req.TrackingNumber, req.LengthCm, req.WidthCm, req.HeightCm, req.WeightKg)
+	if err != nil {
+		return 0, fmt.Errorf("error creating shipment package: %w", err)
+	}
+
+	for _, line := range req.Lines {
+		lineQuery := `INSERT INTO shipment_package_lines (package_id, sales_order_line_id, quantity) VALUES ($1, $2, $3)`
+		if _, err := tx.ExecContext(ctx, lineQuery, id, line.SalesOrderLineID, line.Quantity); err != nil {
+			return 0, fmt.Errorf("error adding line to shipment package: %w", err)
+		}
+	}
+	return id, nil
+}
+
+func (r *Repository) DeletePackage(ctx context.Context, tx *sqlx.Tx, shipmentID, packageID int) error {
+	query := `DELETE FROM shipment_packages WHERE id = $1 AND shipment_id = $2`
+
+	result, err := tx.ExecContext(ctx, query, packageID, shipmentID)
+	if err != nil {
+		return fmt.Errorf("error deleting shipment package: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("package [%d] not found in shipment %d", packageID, shipmentID)
+	}
+	return nil
+}
+
+// completa transportista y guia (los vacios conservan lo capturado) y cierra el embarque
+func (r *Repository) Confirm(ctx context.Context, tx *sqlx.Tx, id int, carrier, trackingNumber, confirmedBy string) error {
+	query := `
+		UPDATE shipments
+		SET carrier = COALESCE(NULLIF($1, ''), carrier), tracking_number = COALESCE(NULLIF($2, ''), tracking_number),
+			status = 'CONFIRMED', confirmed_by = NULLIF($3, ''), confirmed_at = CURRENT_TIMESTAMP
+		WHERE id = $4
+	`
+
+	if _, err := tx.ExecContext(ctx, query, carrier, trackingNumber, confirmedBy, id); err != nil {
+		return fmt.Errorf("error confirming shipment: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) Cancel(ctx context.Context, tx *sqlx.Tx, id int) error {
+	query := `UPDATE shipments SET status = 'CANCELLED', cancelled_at = CURRENT_TIMESTAMP WHERE id = $1`
+
+	if _, err := tx.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("error cancelling shipment: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,335 @@
+package shipping
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+	"github.com/whoAngeel/wms-lite/internal/movement"
+	"github.com/whoAngeel/wms-lite/internal/sales"
+)
+
+type Service struct {
+	repo      *Repository
+	db        *sqlx.DB
+	movements *movement.Service
+	sales     *sales.Service
+	logger    zerolog.Logger
+}
+
+func NewService(repo *Repository, db *sqlx.DB, movements *movement.Service, sales *sales.Service, logger zerolog.Logger) *Service {
+	serviceLogger := logger.With().Str("service", "shipping").Logger()
+	return &Service{repo: repo, db: db, movements: movements, sales: sales, logger: serviceLogger}
+}
+
+// Create abre un embarque para una orden ya surtida (PICKED) o empacada (PACKED)
+// sin from_location_id el embarque sale del staging de la ultima ola que surtio la orden
+func (s *Service) Create(ctx context.Context, req CreateShipmentRequest) (*Shipment, error) {
+	req.Carrier = strings.TrimSpace(req.Carrier)
+	req.TrackingNumber = strings.TrimSpace(req.TrackingNumber)
+
+	status, err := s.repo.GetOrderStatus(ctx, req.SalesOrderID)
+	if err != nil {
+		return nil, err
+	}
+	if status != string(sales.StatusPicked) && status != string(sales.StatusPacked) {
+		return nil, fmt.Errorf("cannot create shipment: sales order is %s", status)
+	}
+
+	if req.FromLocationID == nil {
+		staging, err := s.repo.GetPickStagingLocation(ctx, req.SalesOrderID)
+		if err != nil {
+			return nil, err
+		}
+		if staging > 0 {
+			req.FromLocationID = &staging
+		}
+	}
+
+	id, err := s.repo.Create(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info().Int("shipment_id", id).Int("sales_order_id", req.SalesOrderID).Msg("Shipment created")
+	return s.GetByID(ctx, id)
+}
+
+// obtiene el embarque con sus cajas
+func (s *Service) GetByID(ctx context.Context, id int) (*Shipment, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("invalid ID: must be greater than 0")
+	}
+
+	shipment, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	shipment.Packages, err = s.repo.ListPackages(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return shipment, nil
+}
+
+func (s *Service) List(ctx context.Context, filters ListFilters) (*PaginatedResponse, error) {
+	filters.Page, filters.PageSize = normalizePagination(filters.Page, filters.PageSize)
+
+	if filters.Status != "" {
+		status := Status(strings.ToUpper(filters.Status))
+		if !status.IsValid() {
+			return nil, fmt.Errorf("invalid status: must be OPEN, CONFIRMED or CANCELLED")
+		}
+		filters.Status = string(status)
+	}
+
+	shipments, total, err := s.repo.List(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	if shipments == nil {
+		shipments = []Shipment{}
+	}
+
+	return &PaginatedResponse{
+		Data: shipments,
+		Pagination: Pagination{
+			Page:       filters.Page,
+			PageSize:   filters.PageSize,
+			Total:      total,
+			TotalPages: (total + filters.PageSize - 1) / filters.PageSize,
+		},
+	}, nil
+}
+
+// AddPackage registra una caja con las lineas que lleva
+// entre todas las cajas no se puede empacar mas de lo pedido en cada linea
+func (s *Service) AddPackage(ctx context.Context, id int, req AddPackageRequest) (err error) {
+	req.TrackingNumber = strings.TrimSpace(req.TrackingNumber)
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	shipment, err := s.repo.GetForUpdate(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	if shipment.Status != StatusOpen {
+		return fmt.Errorf("cannot add package: shipment is %s", shipment.Status)
+	}
+
+	balances, err := s.repo.ListLineBalances(ctx, tx, id, shipment.SalesOrderID)
+	if err != nil {
+		return err
+	}
+
+	byLine := make(map[int]lineBalance, len(balances))
+	for _, balance := range balances {
+		byLine[balance.ID] = balance
+	}
+
+	seen := make(map[int]bool, len(req.Lines))
+	for _, line := range req.Lines {
+		if seen[line.SalesOrderLineID] {
+			return fmt.Errorf("invalid lines: sales order line [%d] appears more than once", line.SalesOrderLineID)
+		}
+		seen[line.SalesOrderLineID] = true
+
+		balance, ok := byLine[line.SalesOrderLineID]
+		if !ok {
+			return fmt.Errorf("invalid lines: sales order line [%d] not found in order %d", line.SalesOrderLineID, shipment.SalesOrderID)
+		}
+
+		if left := balance.Quantity - balance.Packed; line.Quantity > left {
+			return fmt.Errorf("invalid quantity: line %d (%s) has %d units left to pack, request=%d",
+				balance.ID, balance.SKU, left, line.Quantity)
+		}
+	}
+
+	packageID, err := s.repo.CreatePackage(ctx, tx, id, req)
+	if err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	s.logger.Info().Int("shipment_id", id).Int("package_id", packageID).Int("lines", len(req.Lines)).Msg("Package added to shipment")
+	return nil
+}
+
+// RemovePackage quita una caja de un embarque abierto (ej. se reempaca)
+func (s *Service) RemovePackage(ctx context.Context, id, packageID int) (err error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	shipment, err := s.repo.GetForUpdate(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	if shipment.Status != StatusOpen {
+		return fmt.Errorf("cannot remove package: shipment is %s", shipment.Status)
+	}
+
+	if err = s.repo.DeletePackage(ctx, tx, id, packageID); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	s.logger.Info().Int("shipment_id", id).Int("package_id", packageID).Msg("Package removed from shipment")
+	return nil
+}
+
+// Confirm cierra el embarque y envia la orden en una sola transaccion
+// todas las lineas deben ir empacadas completas y el embarque debe tener transportista y guia
+// la orden pasa a PACKED (si estaba PICKED) y despues a SHIPPED con los OUT de las lineas
+// que todavia no tienen su salida registrada
+func (s *Service) Confirm(ctx context.Context, id int, req ConfirmShipmentRequest) (err error) {
+	req.Carrier = strings.TrimSpace(req.Carrier)
+	req.TrackingNumber = strings.TrimSpace(req.TrackingNumber)
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	shipment, err := s.repo.GetForUpdate(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	if shipment.Status != StatusOpen {
+		return fmt.Errorf("cannot confirm shipment: shipment is %s", shipment.Status)
+	}
+
+	if req.Carrier == "" && shipment.Carrier == nil {
+		return fmt.Errorf("invalid carrier: a carrier is required to confirm the shipment")
+	}
+	if req.TrackingNumber == "" && shipment.TrackingNumber == nil {
+		return fmt.Errorf("invalid tracking_number: a tracking number is required to confirm the shipment")
+	}
+
+	balances, err := s.repo.ListLineBalances(ctx, tx, id, shipment.SalesOrderID)
+	if err != nil {
+		return err
+	}
+	for _, balance := range balances {
+		if balance.Packed != balance.Quantity {
+			return fmt.Errorf("cannot confirm shipment: line %d (%s) is not fully packed, packed=%d, quantity=%d",
+				balance.ID, balance.SKU, balance.Packed, balance.Quantity)
+		}
+	}
+
+	orderStatus, err := s.repo.GetOrderStatusForUpdate(ctx, tx, shipment.SalesOrderID)
+	if err != nil {
+		return err
+	}
+	if orderStatus == string(sales.StatusPicked) {
+		if err = s.sales.PackTx(ctx, tx, shipment.SalesOrderID); err != nil {
+			return err
+		}
+	}
+
+	posted, err := s.sales.ShipTx(ctx, tx, shipment.SalesOrderID, sales.ShipRequest{
+		FromLocationID: shipment.FromLocationID,
+		Lines:          req.Lines,
+	}, req.ConfirmedBy)
+	if err != nil {
+		return err
+	}
+
+	if err = s.repo.Confirm(ctx, tx, id, req.Carrier, req.TrackingNumber, req.ConfirmedBy); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	s.movements.AfterCommit(ctx, posted...)
+
+	s.logger.Info().Int("shipment_id", id).Int("sales_order_id", shipment.SalesOrderID).Int("movements", len(posted)).Msg("Shipment confirmed")
+	return nil
+}
+
+// Cancel descarta un embarque abierto, la orden queda como estaba
+func (s *Service) Cancel(ctx context.Context, id int) (err error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	shipment, err := s.repo.GetForUpdate(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	if shipment.Status != StatusOpen {
+		return fmt.Errorf("cannot cancel shipment: shipment is %s", shipment.Status)
+	}
+
+	if err = s.repo.Cancel(ctx, tx, id); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	s.logger.Info().Int("shipment_id", id).Msg("Shipment cancelled")
+	return nil
+}
+
+func normalizePagination(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	if pageSize > 100 {
+		pageSize = 100
+	}
+	return page, pageSize
+}
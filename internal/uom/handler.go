@@ -0,0 +1,216 @@
+package uom
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+type Handler struct {
+	service *Service
+	logger  zerolog.Logger
+}
+
+func NewHandler(service *Service, logger zerolog.Logger) *Handler {
+	moduleLogger := logger.With().Str("module", "uom").Logger()
+	return &Handler{
+		service: service,
+		logger:  moduleLogger,
+	}
+}
+
+// Create maneja POST /uoms
+func (h *Handler) Create(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	var req CreateUoMRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn().Err(err).Msg("Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	uom, err := h.service.Create(ctx, req)
+	if err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error().Err(err).Str("code", req.Code).Msg("Error creating unit of measure")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creating unit of measure"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, uom)
+}
+
+// List maneja GET /uoms
+// por defecto solo lista las activas, ?include_inactive=true lista todas
+func (h *Handler) List(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	activeOnly := c.Query("include_inactive") != "true"
+
+	uoms, err := h.service.List(ctx, activeOnly)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Error listing units of measure")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error listing units of measure"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": uoms})
+}
+
+// GetByCode maneja GET /uoms/:code
+func (h *Handler) GetByCode(c *gin.Context) {
+	code := c.Param("code")
+
+	uom, err := h.service.GetByCode(c.Request.Context(), code)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error().Err(err).Str("code", code).Msg("Error getting unit of measure")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, uom)
+}
+
+// Update maneja PUT /uoms/:code
+func (h *Handler) Update(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	code := c.Param("code")
+
+	var req UpdateUoMRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	uom, err := h.service.Update(ctx, code, req)
+	if err != nil {
+		h.respondError(c, err, "Error updating unit of measure")
+		return
+	}
+
+	c.JSON(http.StatusOK, uom)
+}
+
+// Delete maneja DELETE /uoms/:code (desactiva la unidad)
+func (h *Handler) Delete(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	code := c.Param("code")
+
+	if err := h.service.Deactivate(ctx, code); err != nil {
+		h.respondError(c, err, "Failed to deactivate unit of measure")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Unit of measure deactivated successfully"})
+}
+
+// ListProductUoMs maneja GET /products/:id/uoms
+func (h *Handler) ListProductUoMs(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID, must be a number"})
+		return
+	}
+
+	uoms, err := h.service.ListProductUoMs(c.Request.Context(), productID)
+	if err != nil {
+		h.respondError(c, err, "Error listing product units of measure")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": uoms})
+}
+
+// SetProductUoM maneja PUT /products/:id/uoms/:code
+func (h *Handler) SetProductUoM(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID, must be a number"})
+		return
+	}
+
+	var req SetProductUoMRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	uom, err := h.service.SetProductUoM(ctx, productID, c.Param("code"), req)
+	if err != nil {
+		h.respondError(c, err, "Error saving product unit of measure")
+		return
+	}
+
+	c.JSON(http.StatusOK, uom)
+}
+
+// DeleteProductUoM maneja DELETE /products/:id/uoms/:code
+func (h *Handler) DeleteProductUoM(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID, must be a number"})
+		return
+	}
+
+	if err := h.service.DeleteProductUoM(ctx, productID, c.Param("code")); err != nil {
+		h.respondError(c, err, "Error deleting product unit of measure")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Product unit of measure deleted successfully"})
+}
+
+// respondError traduce los errores del servicio a codigos HTTP
+func (h *Handler) respondError(c *gin.Context, err error, message string) {
+	if strings.Contains(err.Error(), "not found") {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if strings.Contains(err.Error(), "invalid") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Error().Err(err).Msg(message)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+}
@@ -0,0 +1,40 @@
+package uom
+
+import "time"
+
+// BaseUoM es la unidad en la que se guarda todo el stock (piezas)
+const BaseUoM = "EA"
+
+type UnitOfMeasure struct {
+	Code      string    `json:"code" db:"code"`
+	Name      string    `json:"name" db:"name"`
+	IsActive  bool      `json:"is_active" db:"is_active"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type CreateUoMRequest struct {
+	Code string `json:"code" binding:"required,max=10"`
+	Name string `json:"name" binding:"required,max=50"`
+}
+
+// UpdateUoMRequest solo actualiza los campos enviados
+type UpdateUoMRequest struct {
+	Name     *string `json:"name" binding:"omitempty,max=50"`
+	IsActive *bool   `json:"is_active"`
+}
+
+// ProductUoM es la conversion de una unidad a piezas para un producto
+type ProductUoM struct {
+	ProductID int       `json:"product_id" db:"product_id"`
+	UoM       string    `json:"uom" db:"uom_code"`
+	Name      string    `json:"name" db:"name"`
+	Factor    int       `json:"factor" db:"factor"` // piezas por unidad
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// SetProductUoMRequest es el body de PUT /products/:id/uoms/:code
+type SetProductUoMRequest struct {
+	Factor int `json:"factor" binding:"required,min=1"`
+}
@@ -0,0 +1,151 @@
+package uom
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+)
+
+const uomColumns = `code, name, is_active, created_at, updated_at`
+
+type Repository struct {
+	db     *sqlx.DB
+	logger zerolog.Logger
+}
+
+func NewRepository(db *sqlx.DB, logger zerolog.Logger) *Repository {
+	moduleLogger := logger.With().Str("module", "uom").Logger()
+	return &Repository{db: db, logger: moduleLogger}
+}
+
+func (r *Repository) Create(ctx context.Context, req CreateUoMRequest) (*UnitOfMeasure, error) {
+	query := `
+		INSERT INTO units_of_measure (code, name)
+		VALUES ($1, $2)
+		RETURNING ` + uomColumns
+
+	var uom UnitOfMeasure
+	err := r.db.GetContext(ctx, &uom, query, req.Code, req.Name)
+	if err != nil {
+		return nil, fmt.Errorf("error creating unit of measure: %w", err)
+	}
+	return &uom, nil
+}
+
+func (r *Repository) GetByCode(ctx context.Context, code string) (*UnitOfMeasure, error) {
+	query := `SELECT ` + uomColumns + ` FROM units_of_measure WHERE code = $1`
+
+	var uom UnitOfMeasure
+	err := r.db.GetContext(ctx, &uom, query, code)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("unit of measure [%s] not found", code)
+		}
+		return nil, fmt.Errorf("error getting unit of measure: %w", err)
+	}
+	return &uom, nil
+}
+
+// lista el catalogo, opcionalmente solo las unidades activas
+func (r *Repository) List(ctx context.Context, activeOnly bool) ([]UnitOfMeasure, error) {
+	query := `SELECT ` + uomColumns + ` FROM units_of_measure`
+	if activeOnly {
+		query += ` WHERE is_active`
+	}
+	query += ` ORDER BY code`
+
+	var uoms []UnitOfMeasure
+	if err := r.db.SelectContext(ctx, &uoms, query); err != nil {
+		return nil, fmt.Errorf("error listing units of measure: %w", err)
+	}
+	return uoms, nil
+}
+
+// actualiza solo los campos enviados
+func (r *Repository) Update(ctx context.Context, code string, req UpdateUoMRequest) (*UnitOfMeasure, error) {
+	query := `
+		UPDATE units_of_measure
+		SET name = COALESCE($1, name),
+			is_active = COALESCE($2, is_active)
+		WHERE code = $3
+		RETURNING ` + uomColumns
+
+	var uom UnitOfMeasure
+	err := r.db.GetContext(ctx, &uom, query, req.Name, req.IsActive, code)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("unit of measure [%s] not found", code)
+		}
+		return nil, fmt.Errorf("error updating unit of measure: %w", err)
+	}
+	return &uom, nil
+}
+
+func (r *Repository) ProductExists(ctx context.Context, productID int) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM products WHERE id = $1 AND deleted_at IS NULL)`
+
+	if err := r.db.GetContext(ctx, &exists, query, productID); err != nil {
+		return false, fmt.Errorf("error checking product existence: %w", err)
+	}
+	return exists, nil
+}
+
+// conversiones configuradas del producto
+func (r *Repository) ListProductUoMs(ctx context.Context, productID int) ([]ProductUoM, error) {
+	query := `
+		SELECT pu.product_id, pu.uom_code, u.name, pu.factor, pu.created_at, pu.updated_at
+		FROM product_uoms pu
+		JOIN units_of_measure u ON u.code = pu.uom_code
+		WHERE pu.product_id = $1
+		ORDER BY pu.factor
+	`
+
+	var uoms []ProductUoM
+	if err := r.db.SelectContext(ctx, &uoms, query, productID); err != nil {
+		return nil, fmt.Errorf("error listing product units of measure: %w", err)
+	}
+	return uoms, nil
+}
+
+// crea o reemplaza la conversion de la unidad para el producto
+func (r *Repository) SetProductUoM(ctx context.Context, productID int, code string, factor int) (*ProductUoM, error) {
+	query := `
+		WITH saved AS (
+			INSERT INTO product_uoms (product_id, uom_code, factor)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (product_id, uom_code) DO UPDATE SET factor = EXCLUDED.factor
+			RETURNING product_id, uom_code, factor, created_at, updated_at
+		)
+		SELECT s.product_id, s.uom_code, u.name, s.factor, s.created_at, s.updated_at
+		FROM saved s
+		JOIN units_of_measure u ON u.code = s.uom_code
+	`
+
+	var uom ProductUoM
+	if err := r.db.GetContext(ctx, &uom, query, productID, code, factor); err != nil {
+		return nil, fmt.Errorf("error saving product unit of measure: %w", err)
+	}
+	return &uom, nil
+}
+
+func (r *Repository) DeleteProductUoM(ctx context.Context, productID int, code string) error {
+	query := `DELETE FROM product_uoms WHERE product_id = $1 AND uom_code = $2`
+
+	result, err := r.db.ExecContext(ctx, query, productID, code)
+	if err != nil {
+		return fmt.Errorf("error deleting product unit of measure: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("unit of measure [%s] not found for product %d", code, productID)
+	}
+	return nil
+}
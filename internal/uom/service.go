@@ -0,0 +1,160 @@
+package uom
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+type Service struct {
+	repo   *Repository
+	logger zerolog.Logger
+}
+
+func NewService(repo *Repository, logger zerolog.Logger) *Service {
+	serviceLogger := logger.With().Str("service", "uom").Logger()
+	return &Service{repo: repo, logger: serviceLogger}
+}
+
+func (s *Service) Create(ctx context.Context, req CreateUoMRequest) (*UnitOfMeasure, error) {
+	req.Code = strings.TrimSpace(strings.ToUpper(req.Code))
+	req.Name = strings.TrimSpace(req.Name)
+
+	if req.Code == "" {
+		return nil, fmt.Errorf("invalid code: must not be empty")
+	}
+	if req.Name == "" {
+		return nil, fmt.Errorf("invalid name: must not be empty")
+	}
+
+	uom, err := s.repo.Create(ctx, req)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			return nil, fmt.Errorf("unit of measure [%s] already exists", req.Code)
+		}
+		return nil, err
+	}
+
+	s.logger.Info().Str("code", uom.Code).Msg("Unit of measure created")
+	return uom, nil
+}
+
+func (s *Service) GetByCode(ctx context.Context, code string) (*UnitOfMeasure, error) {
+	return s.repo.GetByCode(ctx, normalizeCode(code))
+}
+
+func (s *Service) List(ctx context.Context, activeOnly bool) ([]UnitOfMeasure, error) {
+	uoms, err := s.repo.List(ctx, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	if uoms == nil {
+		uoms = []UnitOfMeasure{}
+	}
+	return uoms, nil
+}
+
+func (s *Service) Update(ctx context.Context, code string, req UpdateUoMRequest) (*UnitOfMeasure, error) {
+	code = normalizeCode(code)
+
+	// la unidad base siempre debe estar disponible
+	if code == BaseUoM && req.IsActive != nil && !*req.IsActive {
+		return nil, fmt.Errorf("invalid is_active: the base unit [%s] cannot be deactivated", BaseUoM)
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, fmt.Errorf("invalid name: must not be empty")
+		}
+		req.Name = &name
+	}
+
+	return s.repo.Update(ctx, code, req)
+}
+
+// Deactivate desactiva una unidad (no se borra porque los movimientos la referencian)
+func (s *Service) Deactivate(ctx context.Context, code string) error {
+	inactive := false
+	_, err := s.Update(ctx, code, UpdateUoMRequest{IsActive: &inactive})
+	return err
+}
+
+// lista las conversiones del producto
+func (s *Service) ListProductUoMs(ctx context.Context, productID int) ([]ProductUoM, error) {
+	if err := s.checkProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	uoms, err := s.repo.ListProductUoMs(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if uoms == nil {
+		uoms = []ProductUoM{}
+	}
+	return uoms, nil
+}
+
+// define cuantas piezas trae una unidad del producto
+func (s *Service) SetProductUoM(ctx context.Context, productID int, code string, req SetProductUoMRequest) (*ProductUoM, error) {
+	code = normalizeCode(code)
+
+	if req.Factor < 1 {
+		return nil, fmt.Errorf("invalid factor: must be greater than 0")
+	}
+	if code == BaseUoM {
+		return nil, fmt.Errorf("invalid uom: [%s] is the base unit, its factor is always 1", BaseUoM)
+	}
+
+	if err := s.checkProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	uom, err := s.repo.GetByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if !uom.IsActive {
+		return nil, fmt.Errorf("invalid uom: [%s] is inactive", uom.Code)
+	}
+
+	productUoM, err := s.repo.SetProductUoM(ctx, productID, code, req.Factor)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info().Int("product_id", productID).Str("uom", code).Int("factor", req.Factor).Msg("Product unit of measure saved")
+	return productUoM, nil
+}
+
+// quita la conversion: los movimientos ya registrados conservan su cantidad en piezas
+func (s *Service) DeleteProductUoM(ctx context.Context, productID int, code string) error {
+	if err := s.checkProduct(ctx, productID); err != nil {
+		return err
+	}
+	return s.repo.DeleteProductUoM(ctx, productID, normalizeCode(code))
+}
+
+func (s *Service) checkProduct(ctx context.Context, productID int) error {
+	if productID <= 0 {
+		return fmt.Errorf("invalid product ID: must be greater than 0")
+	}
+
+	exists, err := s.repo.ProductExists(ctx, productID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("product with ID [%d] not found", productID)
+	}
+	return nil
+}
+
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
@@ -0,0 +1,54 @@
+package warehouse
+
+import "time"
+
+type Warehouse struct {
+	ID        int        `json:"id" db:"id"`
+	Code      string     `json:"code" db:"code"`
+	Name      string     `json:"name" db:"name"`
+	Address   string     `json:"address" db:"address"`
+	IsDefault bool       `json:"is_default" db:"is_default"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+}
+
+// CreateWarehouseRequest es el payload para crear un almacen
+type CreateWarehouseRequest struct {
+	Code      string `json:"code" binding:"required,max=20"`
+	Name      string `json:"name" binding:"required,max=255"`
+	Address   string `json:"address" binding:"max=500"`
+	IsDefault bool   `json:"is_default"`
+}
+
+// UpdateWarehouseRequest solo actualiza los campos enviados
+type UpdateWarehouseRequest struct {
+	Name      *string `json:"name" binding:"omitempty,max=255"`
+	Address   *string `json:"address" binding:"omitempty,max=500"`
+	IsDefault *bool   `json:"is_default"`
+}
+
+// StockLevel es el stock de un producto dentro de un almacen
+type StockLevel struct {
+	ProductID   int    `json:"product_id" db:"product_id"`
+	SKU         string `json:"sku" db:"sku"`
+	ProductName string `json:"product_name" db:"product_name"`
+	Quantity    int    `json:"quantity" db:"quantity"`
+	// stock retenido por estado, el resto de quantity esta AVAILABLE
+	Quarantine int       `json:"quarantine" db:"quarantine"`
+	Damaged    int       `json:"damaged" db:"damaged"`
+	OnHold     int       `json:"on_hold" db:"on_hold"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type PaginatedResponse struct {
+	Data       interface{} `json:"data"`
+	Pagination Pagination  `json:"pagination"`
+}
+
+type Pagination struct {
+	Page       int `json:"page"`
+	PageSize   int `json:"page_size"`
+	Total      int `json:"total"`
+	TotalPages int `json:"total_pages"`
+}
@@ -0,0 +1,237 @@
+package warehouse
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+)
+
+type Repository struct {
+	db     *sqlx.DB
+	logger zerolog.Logger
+}
+
+func NewRepository(db *sqlx.DB, logger zerolog.Logger) *Repository {
+	moduleLogger := logger.With().Str("module", "warehouse").Logger()
+	return &Repository{db: db, logger: moduleLogger}
+}
+
+// inserta un nuevo almacen
+// si es el almacen por defecto se desmarca el anterior dentro de la misma transaccion
+func (r *Repository) Create(ctx context.Context, req CreateWarehouseRequest) (*Warehouse, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if req.IsDefault {
+		if err := r.clearDefault(ctx, tx); err != nil {
+			return nil, err
+		}
+	}
+
+	query := `
+		INSERT INTO warehouses (code, name, address, is_default)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, code, name, COALESCE(address, '') AS address, is_default, created_at, updated_at
+	`
+
+	var warehouse Warehouse
+	err = tx.GetContext(ctx, &warehouse, query, req.Code, req.Name, req.Address, req.IsDefault)
+	if err != nil {
+		return nil, fmt.Errorf("error creating warehouse: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %w", err)
+	}
+	return &warehouse, nil
+}
+
+func (r *Repository) GetByID(ctx context.Context, id int) (*Warehouse, error) {
+	query := `
+		SELECT id, code, name, COALESCE(address, '') AS address, is_default, created_at, updated_at
+		FROM warehouses
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	var warehouse Warehouse
+	err := r.db.GetContext(ctx, &warehouse, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("warehouse with id [%d] not found", id)
+		}
+		return nil, fmt.Errorf("error getting warehouse: %w", err)
+	}
+	return &warehouse, nil
+}
+
+func (r *Repository) GetByCode(ctx context.Context, code string) (*Warehouse, error) {
+	query := `
+		SELECT id, code, name, COALESCE(address, '') AS address, is_default, created_at, updated_at
+		FROM warehouses
+		WHERE code = $1
+	`
+
+	var warehouse Warehouse
+	err := r.db.GetContext(ctx, &warehouse, query, code)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("warehouse with code [%s] not found", code)
+		}
+		return nil, fmt.Errorf("error getting warehouse: %w", err)
+	}
+	return &warehouse, nil
+}
+
+func (r *Repository) List(ctx context.Context, page, pageSize int) ([]Warehouse, int, error) {
+	offset := (page - 1) * pageSize
+	query := `
+		SELECT id, code, name, COALESCE(address, '') AS address, is_default, created_at, updated_at
+		FROM warehouses
+		WHERE deleted_at IS NULL
+		ORDER BY code
+		LIMIT $1 OFFSET $2
+	`
+
+	var warehouses []Warehouse
+	err := r.db.SelectContext(ctx, &warehouses, query, pageSize, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error listing warehouses: %w", err)
+	}
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM warehouses WHERE deleted_at IS NULL`
+	err = r.db.GetContext(ctx, &total, countQuery)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error counting warehouses: %w", err)
+	}
+
+	return warehouses, total, nil
+}
+
+// actualiza solo los campos enviados (COALESCE mantiene el valor actual)
+func (r *Repository) Update(ctx context.Context, id int, req UpdateWarehouseRequest) (*Warehouse, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if req.IsDefault != nil && *req.IsDefault {
+		if err := r.clearDefault(ctx, tx); err != nil {
+			return nil, err
+		}
+	}
+
+	query := `
+		UPDATE warehouses
+		SET name = COALESCE($1, name),
+			address = COALESCE($2, address),
+			is_default = COALESCE($3, is_default)
+		WHERE id = $4 AND deleted_at IS NULL
+		RETURNING id, code, name, COALESCE(address, '') AS address, is_default, created_at, updated_at
+	`
+
+	var warehouse Warehouse
+	err = tx.GetContext(ctx, &warehouse, query, req.Name, req.Address, req.IsDefault, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("warehouse with id [%d] not found", id)
+		}
+		return nil, fmt.Errorf("error updating warehouse: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %w", err)
+	}
+	return &warehouse, nil
+}
+
+func (r *Repository) SoftDelete(ctx context.Context, id int) error {
+	query := `
+		UPDATE warehouses
+		SET deleted_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		r.logger.Error().Err(err).Int("warehouse_id", id).Msg("Failed to soft delete warehouse")
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("warehouse with id [%d] not found", id)
+	}
+	return nil
+}
+
+// suma el stock que queda en el almacen (para impedir borrarlo con stock)
+func (r *Repository) TotalStock(ctx context.Context, id int) (int, error) {
+	var total int
+	query := `SELECT COALESCE(SUM(quantity), 0) FROM stock_levels WHERE warehouse_id = $1`
+
+	err := r.db.GetContext(ctx, &total, query, id)
+	if err != nil {
+		return 0, fmt.Errorf("error getting warehouse stock: %w", err)
+	}
+	return total, nil
+}
+
+// lista el stock de cada producto dentro del almacen
+func (r *Repository) ListStock(ctx context.Context, id, page, pageSize int) ([]StockLevel, int, error) {
+	offset := (page - 1) * pageSize
+	query := `
+		SELECT sl.product_id, p.sku, p.name AS product_name, sl.quantity,
+			COALESCE(SUM(ss.quantity) FILTER (WHERE ss.status = 'QUARANTINE'), 0) AS quarantine,
+			COALESCE(SUM(ss.quantity) FILTER (WHERE ss.status = 'DAMAGED'), 0) AS damaged,
+			COALESCE(SUM(ss.quantity) FILTER (WHERE ss.status = 'ON_HOLD'), 0) AS on_hold,
+			sl.updated_at
+		FROM stock_levels sl
+		JOIN products p ON p.id = sl.product_id
+		LEFT JOIN stock_status_levels ss ON ss.product_id = sl.product_id AND ss.warehouse_id = sl.warehouse_id
+		WHERE sl.warehouse_id = $1 AND sl.quantity > 0 AND p.deleted_at IS NULL
+		GROUP BY sl.product_id, p.sku, p.name, sl.quantity, sl.updated_at
+		ORDER BY p.sku
+		LIMIT $2 OFFSET $3
+	`
+
+	var levels []StockLevel
+	err := r.db.SelectContext(ctx, &levels, query, id, pageSize, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error listing warehouse stock: %w", err)
+	}
+
+	var total int
+	countQuery := `
+		SELECT COUNT(*)
+		FROM stock_levels sl
+		JOIN products p ON p.id = sl.product_id
+		WHERE sl.warehouse_id = $1 AND sl.quantity > 0 AND p.deleted_at IS NULL
+	`
+	err = r.db.GetContext(ctx, &total, countQuery, id)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error counting warehouse stock: %w", err)
+	}
+
+	return levels, total, nil
+}
+
+func (r *Repository) clearDefault(ctx context.Context, tx *sqlx.Tx) error {
+	query := `UPDATE warehouses SET is_default = FALSE WHERE is_default`
+
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("error clearing default warehouse: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package warehouse
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+type Service struct {
+	repo   *Repository
+	logger zerolog.Logger
+}
+
+func NewService(repo *Repository, logger zerolog.Logger) *Service {
+	serviceLogger := logger.With().Str("service", "warehouse").Logger()
+	return &Service{repo: repo, logger: serviceLogger}
+}
+
+func (s *Service) Create(ctx context.Context, req CreateWarehouseRequest) (*Warehouse, error) {
+	// normalizar codigo (mayus, sin espacios)
+	req.Code = strings.TrimSpace(strings.ToUpper(req.Code))
+	req.Name = strings.TrimSpace(req.Name)
+
+	if len(req.Code) < 2 {
+		return nil, fmt.Errorf("the code must be at least 2 chars long")
+	}
+
+	if len(req.Name) < 3 {
+		return nil, fmt.Errorf("the name must be at least 3 chars long")
+	}
+
+	// codigo unico (incluye almacenes eliminados)
+	existing, err := s.repo.GetByCode(ctx, req.Code)
+	if err == nil && existing != nil {
+		return nil, fmt.Errorf("warehouse code [%s] already exists", req.Code)
+	}
+
+	warehouse, err := s.repo.Create(ctx, req)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			return nil, fmt.Errorf("warehouse code [%s] already exists", req.Code)
+		}
+		return nil, err
+	}
+
+	s.logger.Info().Int("warehouse_id", warehouse.ID).Str("code", warehouse.Code).Msg("Warehouse created")
+	return warehouse, nil
+}
+
+func (s *Service) GetByID(ctx context.Context, id int) (*Warehouse, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("invalid ID: must be greater than 0")
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *Service) List(ctx context.Context, page, pageSize int) (*PaginatedResponse, error) {
+	page, pageSize = normalizePagination(page, pageSize)
+
+	warehouses, total, err := s.repo.List(ctx, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PaginatedResponse{
+		Data: warehouses,
+		Pagination: Pagination{
+			Page:       page,
+			PageSize:   pageSize,
+			Total:      total,
+			TotalPages: (total + pageSize - 1) / pageSize,
+		},
+	}, nil
+}
+
+func (s *Service) Update(ctx context.Context, id int, req UpdateWarehouseRequest) (*Warehouse, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("invalid ID: must be greater than 0")
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if len(name) < 3 {
+			return nil, fmt.Errorf("the name must be at least 3 chars long")
+		}
+		req.Name = &name
+	}
+
+	// siempre debe existir un almacen por defecto: se cambia marcando otro
+	if req.IsDefault != nil && !*req.IsDefault {
+		current, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if current.IsDefault {
+			return nil, fmt.Errorf("invalid request: mark another warehouse as default instead")
+		}
+	}
+
+	return s.repo.Update(ctx, id, req)
+}
+
+func (s *Service) Delete(ctx context.Context, id int) error {
+	if id <= 0 {
+		return fmt.Errorf("invalid ID: must be greater than 0")
+	}
+
+	warehouse, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if warehouse.IsDefault {
+		return fmt.Errorf("cannot delete the default warehouse")
+	}
+
+	// no borrar almacenes que todavia tienen mercancia
+	stock, err := s.repo.TotalStock(ctx, id)
+	if err != nil {
+		return err
+	}
+	if stock > 0 {
+		return fmt.Errorf("cannot delete warehouse with stock: %d units remaining", stock)
+	}
+
+	if err := s.repo.SoftDelete(ctx, id); err != nil {
+		return err
+	}
+
+	s.logger.Info().Int("warehouse_id", id).Msg("Warehouse deleted")
+	return nil
+}
+
+func (s *Service) ListStock(ctx context.Context, id, page, pageSize int) (*PaginatedResponse, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("invalid ID: must be greater than 0")
+	}
+	page, pageSize = normalizePagination(page, pageSize)
+
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	levels, total, err := s.repo.ListStock(ctx, id, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PaginatedResponse{
+		Data: levels,
+		Pagination: Pagination{
+			Page:       page,
+			PageSize:   pageSize,
+			Total:      total,
+			TotalPages: (total + pageSize - 1) / pageSize,
+		},
+	}, nil
+}
+
+func normalizePagination(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	if pageSize > 100 {
+		pageSize = 100
+	}
+	return page, pageSize
+}
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_movements_warehouse_id;
+ALTER TABLE movements DROP COLUMN IF EXISTS warehouse_id;
+DROP TABLE IF EXISTS stock_levels;
+DROP TRIGGER IF EXISTS update_warehouses_updated_at ON warehouses;
+DROP TABLE IF EXISTS warehouses;
+COMMENT ON COLUMN products.stock_quantity IS NULL;
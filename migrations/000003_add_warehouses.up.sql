@@ -0,0 +1,45 @@
+-- Migration: Multi-warehouse support
+-- Date: 2026-10-16
+-- Description: Warehouses catalog and per-warehouse stock levels
+
+CREATE TABLE warehouses (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(20) UNIQUE NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    address TEXT,
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP DEFAULT NULL
+);
+-- solo puede existir un almacen por defecto
+CREATE UNIQUE INDEX idx_warehouses_default ON warehouses(is_default) WHERE is_default;
+
+CREATE TRIGGER update_warehouses_updated_at
+    BEFORE UPDATE ON warehouses
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE stock_levels (
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (product_id, warehouse_id)
+);
+CREATE INDEX idx_stock_levels_warehouse_id ON stock_levels(warehouse_id);
+
+-- el stock existente pasa al almacen principal
+INSERT INTO warehouses (code, name, is_default) VALUES ('MAIN', 'Almacén principal', TRUE);
+
+INSERT INTO stock_levels (product_id, warehouse_id, quantity)
+SELECT p.id, w.id, p.stock_quantity
+FROM products p
+CROSS JOIN warehouses w
+WHERE w.code = 'MAIN' AND p.stock_quantity > 0;
+
+ALTER TABLE movements ADD COLUMN warehouse_id INTEGER REFERENCES warehouses(id) ON DELETE RESTRICT;
+UPDATE movements SET warehouse_id = (SELECT id FROM warehouses WHERE code = 'MAIN');
+ALTER TABLE movements ALTER COLUMN warehouse_id SET NOT NULL;
+CREATE INDEX idx_movements_warehouse_id ON movements(warehouse_id);
+
+COMMENT ON COLUMN products.stock_quantity IS 'Total stock across all warehouses (sum of stock_levels)';
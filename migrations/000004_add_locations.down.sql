@@ -0,0 +1,7 @@
+ALTER TABLE movements
+    DROP COLUMN IF EXISTS from_location_id,
+    DROP COLUMN IF EXISTS to_location_id;
+DROP TABLE IF EXISTS location_stock;
+DROP TRIGGER IF EXISTS update_locations_updated_at ON locations;
+DROP TABLE IF EXISTS locations;
+DROP TYPE IF EXISTS location_type;
@@ -0,0 +1,41 @@
+-- Migration: Location hierarchy inside warehouses
+-- Date: 2026-10-16
+-- Description: zone -> aisle -> rack -> bin locations and stock per (product, location)
+
+CREATE TYPE location_type AS ENUM ('ZONE', 'AISLE', 'RACK', 'BIN');
+
+CREATE TABLE locations (
+    id SERIAL PRIMARY KEY,
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
+    parent_id INTEGER REFERENCES locations(id) ON DELETE RESTRICT,
+    location_type location_type NOT NULL,
+    code VARCHAR(30) NOT NULL,
+    path VARCHAR(255) NOT NULL,
+    name VARCHAR(255),
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (warehouse_id, path)
+);
+CREATE INDEX idx_locations_warehouse_id ON locations(warehouse_id);
+CREATE INDEX idx_locations_parent_id ON locations(parent_id);
+
+CREATE TRIGGER update_locations_updated_at
+    BEFORE UPDATE ON locations
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON COLUMN locations.path IS 'Full location code (e.g. A/01/03/B), used for sorting and prefix queries';
+
+-- stock por ubicacion, siempre contenido en stock_levels del almacen
+CREATE TABLE location_stock (
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    location_id INTEGER NOT NULL REFERENCES locations(id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (product_id, location_id)
+);
+CREATE INDEX idx_location_stock_location_id ON location_stock(location_id);
+
+ALTER TABLE movements
+    ADD COLUMN from_location_id INTEGER REFERENCES locations(id) ON DELETE RESTRICT,
+    ADD COLUMN to_location_id INTEGER REFERENCES locations(id) ON DELETE RESTRICT;
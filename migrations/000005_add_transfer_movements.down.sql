@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_movements_to_warehouse_id;
+ALTER TABLE movements DROP COLUMN IF EXISTS to_warehouse_id;
+-- PostgreSQL no permite eliminar valores de un ENUM: 'TRANSFER' se conserva en movement_type
@@ -0,0 +1,9 @@
+-- Migration: TRANSFER movement type
+-- Date: 2026-10-16
+-- Description: Transfers between locations or warehouses stored as a single ledger entry
+
+ALTER TYPE movement_type ADD VALUE IF NOT EXISTS 'TRANSFER';
+
+-- almacen destino de la transferencia (warehouse_id es el origen)
+ALTER TABLE movements ADD COLUMN to_warehouse_id INTEGER REFERENCES warehouses(id) ON DELETE RESTRICT;
+CREATE INDEX idx_movements_to_warehouse_id ON movements(to_warehouse_id) WHERE to_warehouse_id IS NOT NULL;
@@ -0,0 +1,9 @@
+ALTER TABLE movements DROP CONSTRAINT IF EXISTS movements_adjust_reason_code_check;
+ALTER TABLE movements DROP CONSTRAINT IF EXISTS movements_quantity_check;
+ALTER TABLE movements ADD CONSTRAINT movements_quantity_check CHECK (quantity > 0);
+DROP INDEX IF EXISTS idx_movements_reason_code;
+ALTER TABLE movements DROP COLUMN IF EXISTS reason_code;
+DROP TRIGGER IF EXISTS update_reason_codes_updated_at ON reason_codes;
+DROP TABLE IF EXISTS reason_codes;
+DROP TYPE IF EXISTS adjustment_direction;
+-- PostgreSQL no permite eliminar valores de un ENUM: 'ADJUST' se conserva en movement_type
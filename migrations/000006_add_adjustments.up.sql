@@ -0,0 +1,37 @@
+-- Migration: ADJUST movement type and reason codes catalog
+-- Date: 2026-10-16
+-- Description: Inventory corrections (positive or negative) classified by a managed reason code
+
+ALTER TYPE movement_type ADD VALUE IF NOT EXISTS 'ADJUST';
+
+CREATE TYPE adjustment_direction AS ENUM ('INCREASE', 'DECREASE', 'BOTH');
+
+CREATE TABLE reason_codes (
+    code VARCHAR(30) PRIMARY KEY,
+    description VARCHAR(255) NOT NULL,
+    direction adjustment_direction NOT NULL DEFAULT 'BOTH',
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_reason_codes_updated_at
+    BEFORE UPDATE ON reason_codes
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+INSERT INTO reason_codes (code, description, direction) VALUES
+    ('DAMAGE', 'Mercancía dañada', 'DECREASE'),
+    ('SHRINKAGE', 'Merma / faltante', 'DECREASE'),
+    ('COUNT_CORRECTION', 'Corrección por conteo físico', 'BOTH'),
+    ('FOUND', 'Mercancía encontrada', 'INCREASE');
+
+ALTER TABLE movements ADD COLUMN reason_code VARCHAR(30) REFERENCES reason_codes(code) ON DELETE RESTRICT;
+CREATE INDEX idx_movements_reason_code ON movements(reason_code) WHERE reason_code IS NOT NULL;
+
+-- ADJUST guarda la cantidad con signo (positiva entra, negativa sale)
+-- se compara como texto porque el valor nuevo del ENUM no puede usarse en la misma transaccion
+ALTER TABLE movements DROP CONSTRAINT movements_quantity_check;
+ALTER TABLE movements ADD CONSTRAINT movements_quantity_check
+    CHECK (quantity <> 0 AND (quantity > 0 OR movement_type::text = 'ADJUST'));
+ALTER TABLE movements ADD CONSTRAINT movements_adjust_reason_code_check
+    CHECK (movement_type::text <> 'ADJUST' OR reason_code IS NOT NULL);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS count_lines;
+DROP TRIGGER IF EXISTS update_count_sessions_updated_at ON count_sessions;
+DROP TABLE IF EXISTS count_sessions;
+DROP TYPE IF EXISTS count_status;
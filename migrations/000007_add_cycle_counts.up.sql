@@ -0,0 +1,41 @@
+-- Migration: Cycle counting sessions
+-- Date: 2026-10-16
+-- Description: Physical count sessions per warehouse with snapshot, variance review and posting as ADJUST movements
+
+CREATE TYPE count_status AS ENUM ('OPEN', 'COUNTING', 'REVIEW', 'POSTED');
+
+CREATE TABLE count_sessions (
+    id SERIAL PRIMARY KEY,
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
+    status count_status NOT NULL DEFAULT 'OPEN',
+    notes VARCHAR(255),
+    created_by VARCHAR(100),
+    approved_by VARCHAR(100),
+    posted_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_count_sessions_warehouse_id ON count_sessions(warehouse_id);
+CREATE INDEX idx_count_sessions_status ON count_sessions(status);
+
+CREATE TRIGGER update_count_sessions_updated_at
+    BEFORE UPDATE ON count_sessions
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- expected_quantity es la foto del stock al crear la sesion
+-- location_id NULL es el stock del almacen que no esta en ningun bin
+CREATE TABLE count_lines (
+    id SERIAL PRIMARY KEY,
+    session_id INTEGER NOT NULL REFERENCES count_sessions(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    location_id INTEGER REFERENCES locations(id) ON DELETE RESTRICT,
+    expected_quantity INTEGER NOT NULL CHECK (expected_quantity >= 0),
+    counted_quantity INTEGER CHECK (counted_quantity >= 0),
+    counted_by VARCHAR(100),
+    counted_at TIMESTAMP,
+    movement_id INTEGER REFERENCES movements(id) ON DELETE RESTRICT,
+    UNIQUE NULLS NOT DISTINCT (session_id, product_id, location_id)
+);
+
+CREATE INDEX idx_count_lines_session_id ON count_lines(session_id);
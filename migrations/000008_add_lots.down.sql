@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS movement_lots;
+DROP TABLE IF EXISTS lot_stock;
+DROP TRIGGER IF EXISTS update_lots_updated_at ON lots;
+DROP TABLE IF EXISTS lots;
+ALTER TABLE products DROP COLUMN IF EXISTS is_lot_tracked;
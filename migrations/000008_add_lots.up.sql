@@ -0,0 +1,45 @@
+-- Migration: Lot/batch tracking
+-- Date: 2026-10-16
+-- Description: Lots with manufacture/expiry dates, stock per lot and warehouse, and per-lot breakdown of movements
+
+ALTER TABLE products ADD COLUMN is_lot_tracked BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE lots (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    lot_number VARCHAR(50) NOT NULL,
+    manufactured_at DATE,
+    expires_at DATE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (product_id, lot_number),
+    CHECK (expires_at IS NULL OR manufactured_at IS NULL OR expires_at >= manufactured_at)
+);
+
+-- FEFO: los lotes de un producto se recorren por fecha de caducidad
+CREATE INDEX idx_lots_product_expires_at ON lots(product_id, expires_at);
+
+CREATE TRIGGER update_lots_updated_at
+    BEFORE UPDATE ON lots
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- para productos con lotes la suma por almacen coincide con stock_levels
+CREATE TABLE lot_stock (
+    lot_id INTEGER NOT NULL REFERENCES lots(id) ON DELETE RESTRICT,
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (lot_id, warehouse_id)
+);
+
+CREATE INDEX idx_lot_stock_warehouse_id ON lot_stock(warehouse_id);
+
+-- desglose por lote de cada movimiento (cantidades siempre positivas, el sentido lo da el movimiento)
+CREATE TABLE movement_lots (
+    movement_id INTEGER NOT NULL REFERENCES movements(id) ON DELETE CASCADE,
+    lot_id INTEGER NOT NULL REFERENCES lots(id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    PRIMARY KEY (movement_id, lot_id)
+);
+
+CREATE INDEX idx_movement_lots_lot_id ON movement_lots(lot_id);
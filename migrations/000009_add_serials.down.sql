@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS movement_serials;
+DROP TRIGGER IF EXISTS update_serials_updated_at ON serials;
+DROP TABLE IF EXISTS serials;
+DROP TYPE IF EXISTS serial_status;
+ALTER TABLE products DROP COLUMN IF EXISTS is_serialized;
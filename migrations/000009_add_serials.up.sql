@@ -0,0 +1,34 @@
+-- Migration: Serial number tracking
+-- Date: 2026-10-16
+-- Description: One row per serialized unit with its current status and warehouse, and the serials of each movement
+
+ALTER TABLE products ADD COLUMN is_serialized BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TYPE serial_status AS ENUM ('IN_STOCK', 'SHIPPED', 'WRITTEN_OFF');
+
+-- el numero de serie es unico en todo el sistema (la busqueda es solo por serie)
+CREATE TABLE serials (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    serial_number VARCHAR(100) NOT NULL UNIQUE,
+    status serial_status NOT NULL DEFAULT 'IN_STOCK',
+    warehouse_id INTEGER REFERENCES warehouses(id) ON DELETE RESTRICT, -- nulo si la unidad ya salio
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((status = 'IN_STOCK') = (warehouse_id IS NOT NULL))
+);
+
+CREATE INDEX idx_serials_product_id ON serials(product_id);
+CREATE INDEX idx_serials_warehouse_id ON serials(warehouse_id) WHERE warehouse_id IS NOT NULL;
+
+CREATE TRIGGER update_serials_updated_at
+    BEFORE UPDATE ON serials
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE movement_serials (
+    movement_id INTEGER NOT NULL REFERENCES movements(id) ON DELETE CASCADE,
+    serial_id INTEGER NOT NULL REFERENCES serials(id) ON DELETE RESTRICT,
+    PRIMARY KEY (movement_id, serial_id)
+);
+
+CREATE INDEX idx_movement_serials_serial_id ON movement_serials(serial_id);
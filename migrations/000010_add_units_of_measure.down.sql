@@ -0,0 +1,6 @@
+ALTER TABLE movements DROP COLUMN IF EXISTS uom_quantity;
+ALTER TABLE movements DROP COLUMN IF EXISTS uom;
+DROP TRIGGER IF EXISTS update_product_uoms_updated_at ON product_uoms;
+DROP TABLE IF EXISTS product_uoms;
+DROP TRIGGER IF EXISTS update_units_of_measure_updated_at ON units_of_measure;
+DROP TABLE IF EXISTS units_of_measure;
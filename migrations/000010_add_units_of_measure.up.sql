@@ -0,0 +1,43 @@
+-- Migration: Units of measure and per-product conversions
+-- Date: 2026-10-16
+-- Description: UoM catalog, conversion factor of each unit to the base unit (EA) per product, and the entered unit on movements
+
+CREATE TABLE units_of_measure (
+    code VARCHAR(10) PRIMARY KEY,
+    name VARCHAR(50) NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_units_of_measure_updated_at
+    BEFORE UPDATE ON units_of_measure
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- EA es la unidad base: el stock siempre se guarda en piezas
+INSERT INTO units_of_measure (code, name) VALUES
+    ('EA', 'Pieza'),
+    ('INNER', 'Paquete interno'),
+    ('CASE', 'Caja'),
+    ('PALLET', 'Tarima');
+
+-- cuantas piezas trae una unidad del producto (la unidad base no se configura, siempre es 1)
+CREATE TABLE product_uoms (
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    uom_code VARCHAR(10) NOT NULL REFERENCES units_of_measure(code) ON DELETE RESTRICT,
+    factor INTEGER NOT NULL CHECK (factor > 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (product_id, uom_code),
+    CHECK (uom_code <> 'EA')
+);
+
+CREATE TRIGGER update_product_uoms_updated_at
+    BEFORE UPDATE ON product_uoms
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- quantity sigue en la unidad base, uom_quantity es la cantidad capturada en uom
+ALTER TABLE movements ADD COLUMN uom VARCHAR(10) NOT NULL DEFAULT 'EA' REFERENCES units_of_measure(code) ON DELETE RESTRICT;
+ALTER TABLE movements ADD COLUMN uom_quantity INTEGER;
+UPDATE movements SET uom_quantity = quantity;
+ALTER TABLE movements ALTER COLUMN uom_quantity SET NOT NULL;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_products_category_id;
+ALTER TABLE products DROP COLUMN IF EXISTS category_id;
+DROP TRIGGER IF EXISTS update_categories_updated_at ON categories;
+DROP TABLE IF EXISTS categories;
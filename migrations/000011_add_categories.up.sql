@@ -0,0 +1,24 @@
+-- Migration: Product categories
+-- Date: 2026-10-16
+-- Description: Hierarchical category tree (parent_id + materialized path) and the category of each product
+
+CREATE TABLE categories (
+    id SERIAL PRIMARY KEY,
+    parent_id INTEGER REFERENCES categories(id) ON DELETE RESTRICT,
+    code VARCHAR(30) NOT NULL,
+    path VARCHAR(255) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_categories_parent_id ON categories(parent_id);
+
+CREATE TRIGGER update_categories_updated_at
+    BEFORE UPDATE ON categories
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON COLUMN categories.path IS 'Full category code (e.g. ELECTRONICS/LAPTOPS), used for sorting and descendant queries';
+
+ALTER TABLE products ADD COLUMN category_id INTEGER REFERENCES categories(id) ON DELETE RESTRICT;
+CREATE INDEX idx_products_category_id ON products(category_id) WHERE category_id IS NOT NULL;
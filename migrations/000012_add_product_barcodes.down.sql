@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS product_barcodes;
+DROP TYPE IF EXISTS barcode_type;
@@ -0,0 +1,17 @@
+-- Migration: Product barcodes
+-- Date: 2026-10-16
+-- Description: Several GS1 barcodes (EAN-13, UPC-A, GTIN-14) per product for scan lookup
+
+CREATE TYPE barcode_type AS ENUM ('EAN13', 'UPCA', 'GTIN14');
+
+-- un codigo escaneado identifica a un solo producto
+CREATE TABLE product_barcodes (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    code VARCHAR(14) NOT NULL UNIQUE,
+    barcode_type barcode_type NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (code ~ '^[0-9]+$')
+);
+
+CREATE INDEX idx_product_barcodes_product_id ON product_barcodes(product_id);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS low_stock_alerts;
+DROP TYPE IF EXISTS alert_status;
+ALTER TABLE products
+DROP COLUMN IF EXISTS reorder_point,
+DROP COLUMN IF EXISTS min_level,
+DROP COLUMN IF EXISTS max_level;
@@ -0,0 +1,26 @@
+-- Migration: Reorder levels and low stock alerts
+-- Date: 2026-10-16
+-- Description: Per-product reorder point and min/max levels, and low stock alerts opened/resolved after each movement
+
+ALTER TABLE products
+ADD COLUMN reorder_point INTEGER CHECK (reorder_point >= 0),
+ADD COLUMN min_level INTEGER CHECK (min_level >= 0),
+ADD COLUMN max_level INTEGER CHECK (max_level >= 0);
+
+CREATE TYPE alert_status AS ENUM ('OPEN', 'RESOLVED');
+
+-- threshold y stock_at_open guardan la foto al abrir la alerta
+CREATE TABLE low_stock_alerts (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    status alert_status NOT NULL DEFAULT 'OPEN',
+    threshold INTEGER NOT NULL,
+    stock_at_open INTEGER NOT NULL,
+    stock_at_resolve INTEGER,
+    opened_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMP
+);
+
+-- a lo mas una alerta abierta por producto
+CREATE UNIQUE INDEX idx_low_stock_alerts_open ON low_stock_alerts(product_id) WHERE status = 'OPEN';
+CREATE INDEX idx_low_stock_alerts_opened_at ON low_stock_alerts(opened_at DESC);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS sales_order_lines;
+DROP TRIGGER IF EXISTS update_sales_orders_updated_at ON sales_orders;
+DROP TABLE IF EXISTS sales_orders;
+DROP TYPE IF EXISTS sales_order_status;
@@ -0,0 +1,43 @@
+-- Migration: Sales orders
+-- Date: 2026-10-16
+-- Description: Customer orders with lines, stock allocation and NEW -> ALLOCATED -> PICKED -> PACKED -> SHIPPED lifecycle
+
+CREATE TYPE sales_order_status AS ENUM ('NEW', 'ALLOCATED', 'PICKED', 'PACKED', 'SHIPPED', 'CANCELLED');
+
+CREATE TABLE sales_orders (
+    id SERIAL PRIMARY KEY,
+    customer_reference VARCHAR(50) NOT NULL,
+    customer_name VARCHAR(255),
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
+    status sales_order_status NOT NULL DEFAULT 'NEW',
+    notes VARCHAR(255),
+    created_by VARCHAR(100),
+    allocated_at TIMESTAMP,
+    picked_at TIMESTAMP,
+    packed_at TIMESTAMP,
+    shipped_at TIMESTAMP,
+    cancelled_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_sales_orders_customer_reference ON sales_orders(customer_reference);
+CREATE INDEX idx_sales_orders_status ON sales_orders(status);
+
+CREATE TRIGGER update_sales_orders_updated_at
+    BEFORE UPDATE ON sales_orders
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- cantidades en la unidad base; allocated_quantity es el stock apartado mientras la orden esta abierta
+CREATE TABLE sales_order_lines (
+    id SERIAL PRIMARY KEY,
+    sales_order_id INTEGER NOT NULL REFERENCES sales_orders(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    allocated_quantity INTEGER NOT NULL DEFAULT 0 CHECK (allocated_quantity >= 0),
+    shipped_quantity INTEGER NOT NULL DEFAULT 0 CHECK (shipped_quantity >= 0),
+    movement_id INTEGER REFERENCES movements(id) ON DELETE RESTRICT,
+    UNIQUE (sales_order_id, product_id)
+);
+
+CREATE INDEX idx_sales_order_lines_product_id ON sales_order_lines(product_id) WHERE allocated_quantity > 0;
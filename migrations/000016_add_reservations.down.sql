@@ -0,0 +1,6 @@
+ALTER TABLE sales_order_lines DROP COLUMN IF EXISTS reservation_id;
+DROP INDEX IF EXISTS idx_movements_reservation_id;
+ALTER TABLE movements DROP COLUMN IF EXISTS reservation_id;
+DROP TRIGGER IF EXISTS update_reservations_updated_at ON reservations;
+DROP TABLE IF EXISTS reservations;
+DROP TYPE IF EXISTS reservation_status;
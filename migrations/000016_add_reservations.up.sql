@@ -0,0 +1,51 @@
+-- Migration: Stock reservations
+-- Date: 2026-10-16
+-- Description: Reservations that hold stock for an owner (available = on_hand - reserved), consumed by OUT movements and released on expiry
+
+CREATE TYPE reservation_status AS ENUM ('ACTIVE', 'CONSUMED', 'RELEASED', 'EXPIRED');
+
+-- cantidades en la unidad base; lo reservado es quantity - consumed_quantity mientras este ACTIVE y vigente
+CREATE TABLE reservations (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    consumed_quantity INTEGER NOT NULL DEFAULT 0 CHECK (consumed_quantity >= 0 AND consumed_quantity <= quantity),
+    owner_reference VARCHAR(100) NOT NULL,
+    status reservation_status NOT NULL DEFAULT 'ACTIVE',
+    expires_at TIMESTAMP,
+    created_by VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    released_at TIMESTAMP
+);
+
+CREATE INDEX idx_reservations_product_warehouse ON reservations(product_id, warehouse_id) WHERE status = 'ACTIVE';
+CREATE INDEX idx_reservations_owner_reference ON reservations(owner_reference);
+CREATE INDEX idx_reservations_expires_at ON reservations(expires_at) WHERE status = 'ACTIVE' AND expires_at IS NOT NULL;
+
+CREATE TRIGGER update_reservations_updated_at
+    BEFORE UPDATE ON reservations
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- reserva consumida por una salida
+ALTER TABLE movements
+ADD COLUMN reservation_id INTEGER REFERENCES reservations(id) ON DELETE RESTRICT;
+CREATE INDEX idx_movements_reservation_id ON movements(reservation_id) WHERE reservation_id IS NOT NULL;
+
+-- las lineas de venta apartan su stock con una reserva
+ALTER TABLE sales_order_lines
+ADD COLUMN reservation_id INTEGER REFERENCES reservations(id) ON DELETE RESTRICT;
+
+-- lo apartado por ordenes de venta abiertas pasa a reservas
+INSERT INTO reservations (product_id, warehouse_id, quantity, owner_reference, created_by)
+SELECT sl.product_id, so.warehouse_id, sl.allocated_quantity, 'sales_order:' || so.id, so.created_by
+FROM sales_order_lines sl
+JOIN sales_orders so ON so.id = sl.sales_order_id
+WHERE so.status IN ('ALLOCATED', 'PICKED', 'PACKED') AND sl.allocated_quantity > 0;
+
+UPDATE sales_order_lines sl
+SET reservation_id = r.id
+FROM sales_orders so, reservations r
+WHERE so.id = sl.sales_order_id AND r.owner_reference = 'sales_order:' || so.id
+    AND r.product_id = sl.product_id AND sl.allocated_quantity > 0;
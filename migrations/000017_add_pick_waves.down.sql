@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS pick_tasks;
+DROP TYPE IF EXISTS pick_task_status;
+DROP TABLE IF EXISTS wave_orders;
+DROP TRIGGER IF EXISTS update_waves_updated_at ON waves;
+DROP TABLE IF EXISTS waves;
+DROP TYPE IF EXISTS wave_status;
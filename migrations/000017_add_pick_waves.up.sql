@@ -0,0 +1,57 @@
+-- Migration: Pick waves
+-- Date: 2026-10-16
+-- Description: Waves that group allocated sales orders and pick tasks per location in walking order
+
+CREATE TYPE wave_status AS ENUM ('OPEN', 'COMPLETED', 'CANCELLED');
+
+-- lo surtido se lleva a la ubicacion de preparacion (staging) de la ola
+CREATE TABLE waves (
+    id SERIAL PRIMARY KEY,
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
+    staging_location_id INTEGER NOT NULL REFERENCES locations(id) ON DELETE RESTRICT,
+    status wave_status NOT NULL DEFAULT 'OPEN',
+    notes VARCHAR(255),
+    created_by VARCHAR(100),
+    completed_at TIMESTAMP,
+    cancelled_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_waves_status ON waves(status);
+
+CREATE TRIGGER update_waves_updated_at
+    BEFORE UPDATE ON waves
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE wave_orders (
+    wave_id INTEGER NOT NULL REFERENCES waves(id) ON DELETE CASCADE,
+    sales_order_id INTEGER NOT NULL REFERENCES sales_orders(id) ON DELETE RESTRICT,
+    PRIMARY KEY (wave_id, sales_order_id)
+);
+
+CREATE INDEX idx_wave_orders_sales_order_id ON wave_orders(sales_order_id);
+
+CREATE TYPE pick_task_status AS ENUM ('PENDING', 'PICKED', 'SHORT', 'CANCELLED');
+
+-- location_id nulo: se surte del stock sin ubicar; sequence es el orden de recorrido dentro de la ola
+CREATE TABLE pick_tasks (
+    id SERIAL PRIMARY KEY,
+    wave_id INTEGER NOT NULL REFERENCES waves(id) ON DELETE CASCADE,
+    sequence INTEGER NOT NULL,
+    sales_order_id INTEGER NOT NULL REFERENCES sales_orders(id) ON DELETE RESTRICT,
+    sales_order_line_id INTEGER NOT NULL REFERENCES sales_order_lines(id) ON DELETE RESTRICT,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    location_id INTEGER REFERENCES locations(id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    picked_quantity INTEGER NOT NULL DEFAULT 0 CHECK (picked_quantity >= 0 AND picked_quantity <= quantity),
+    status pick_task_status NOT NULL DEFAULT 'PENDING',
+    movement_id INTEGER REFERENCES movements(id) ON DELETE RESTRICT,
+    picked_by VARCHAR(100),
+    picked_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (wave_id, sequence)
+);
+
+CREATE INDEX idx_pick_tasks_sales_order_line_id ON pick_tasks(sales_order_line_id);
+CREATE INDEX idx_pick_tasks_pending ON pick_tasks(product_id, location_id) WHERE status = 'PENDING';
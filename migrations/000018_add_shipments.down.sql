@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS shipment_package_lines;
+DROP TABLE IF EXISTS shipment_packages;
+DROP TRIGGER IF EXISTS update_shipments_updated_at ON shipments;
+DROP TABLE IF EXISTS shipments;
+DROP TYPE IF EXISTS shipment_status;
@@ -0,0 +1,52 @@
+-- Migration: Shipments and packages
+-- Date: 2026-10-16
+-- Description: Shipments per sales order with carrier, tracking number and packed cartons (dimensions, weight and lines)
+
+CREATE TYPE shipment_status AS ENUM ('OPEN', 'CONFIRMED', 'CANCELLED');
+
+CREATE TABLE shipments (
+    id SERIAL PRIMARY KEY,
+    sales_order_id INTEGER NOT NULL REFERENCES sales_orders(id) ON DELETE RESTRICT,
+    carrier VARCHAR(50),
+    tracking_number VARCHAR(100),
+    from_location_id INTEGER REFERENCES locations(id) ON DELETE RESTRICT,
+    status shipment_status NOT NULL DEFAULT 'OPEN',
+    notes VARCHAR(255),
+    created_by VARCHAR(100),
+    confirmed_by VARCHAR(100),
+    confirmed_at TIMESTAMP,
+    cancelled_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- un solo embarque vigente por orden
+CREATE UNIQUE INDEX idx_shipments_sales_order_active ON shipments(sales_order_id) WHERE status <> 'CANCELLED';
+CREATE INDEX idx_shipments_tracking_number ON shipments(tracking_number) WHERE tracking_number IS NOT NULL;
+
+CREATE TRIGGER update_shipments_updated_at
+    BEFORE UPDATE ON shipments
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE shipment_packages (
+    id SERIAL PRIMARY KEY,
+    shipment_id INTEGER NOT NULL REFERENCES shipments(id) ON DELETE CASCADE,
+    package_number INTEGER NOT NULL,
+    tracking_number VARCHAR(100),
+    length_cm NUMERIC(8, 2) NOT NULL CHECK (length_cm > 0),
+    width_cm NUMERIC(8, 2) NOT NULL CHECK (width_cm > 0),
+    height_cm NUMERIC(8, 2) NOT NULL CHECK (height_cm > 0),
+    weight_kg NUMERIC(10, 3) NOT NULL CHECK (weight_kg > 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (shipment_id, package_number)
+);
+
+CREATE INDEX idx_shipment_packages_tracking_number ON shipment_packages(tracking_number) WHERE tracking_number IS NOT NULL;
+
+-- cantidades en la unidad base
+CREATE TABLE shipment_package_lines (
+    package_id INTEGER NOT NULL REFERENCES shipment_packages(id) ON DELETE CASCADE,
+    sales_order_line_id INTEGER NOT NULL REFERENCES sales_order_lines(id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    PRIMARY KEY (package_id, sales_order_line_id)
+);
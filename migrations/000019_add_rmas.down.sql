@@ -0,0 +1,6 @@
+DELETE FROM reason_codes WHERE code = 'CUSTOMER_RETURN' AND NOT EXISTS (SELECT 1 FROM movements WHERE reason_code = 'CUSTOMER_RETURN');
+DROP TABLE IF EXISTS rma_lines;
+DROP TRIGGER IF EXISTS update_rmas_updated_at ON rmas;
+DROP TABLE IF EXISTS rmas;
+DROP TYPE IF EXISTS rma_disposition;
+DROP TYPE IF EXISTS rma_status;
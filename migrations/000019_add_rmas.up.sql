@@ -0,0 +1,56 @@
+-- Migration: Customer returns (RMA)
+-- Date: 2026-10-16
+-- Description: Return authorizations against a shipped sales order or OUT movement, with per-line inspection and disposition
+
+CREATE TYPE rma_status AS ENUM ('OPEN', 'CLOSED', 'CANCELLED');
+CREATE TYPE rma_disposition AS ENUM ('RESTOCK', 'QUARANTINE', 'SCRAP', 'RETURN_TO_VENDOR');
+
+CREATE TABLE rmas (
+    id SERIAL PRIMARY KEY,
+    sales_order_id INTEGER REFERENCES sales_orders(id) ON DELETE RESTRICT,
+    movement_id INTEGER REFERENCES movements(id) ON DELETE RESTRICT,
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
+    customer_reference VARCHAR(50),
+    reason VARCHAR(255),
+    status rma_status NOT NULL DEFAULT 'OPEN',
+    created_by VARCHAR(100),
+    closed_at TIMESTAMP,
+    cancelled_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    -- la devolucion siempre apunta a la salida original
+    CHECK (sales_order_id IS NOT NULL OR movement_id IS NOT NULL)
+);
+
+CREATE INDEX idx_rmas_sales_order_id ON rmas(sales_order_id) WHERE sales_order_id IS NOT NULL;
+CREATE INDEX idx_rmas_movement_id ON rmas(movement_id) WHERE movement_id IS NOT NULL;
+CREATE INDEX idx_rmas_status ON rmas(status);
+
+CREATE TRIGGER update_rmas_updated_at
+    BEFORE UPDATE ON rmas
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- cantidades en la unidad base
+-- disposition nula = linea pendiente de inspeccion
+CREATE TABLE rma_lines (
+    id SERIAL PRIMARY KEY,
+    rma_id INTEGER NOT NULL REFERENCES rmas(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    sales_order_line_id INTEGER REFERENCES sales_order_lines(id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    reason VARCHAR(255),
+    disposition rma_disposition,
+    location_id INTEGER REFERENCES locations(id) ON DELETE RESTRICT,
+    supplier_id INTEGER REFERENCES suppliers(id) ON DELETE RESTRICT,
+    movement_id INTEGER REFERENCES movements(id) ON DELETE RESTRICT,
+    inspection_notes VARCHAR(255),
+    inspected_by VARCHAR(100),
+    inspected_at TIMESTAMP,
+    UNIQUE (rma_id, product_id)
+);
+
+CREATE INDEX idx_rma_lines_sales_order_line_id ON rma_lines(sales_order_line_id) WHERE sales_order_line_id IS NOT NULL;
+
+INSERT INTO reason_codes (code, description, direction) VALUES
+    ('CUSTOMER_RETURN', 'Devolución de cliente', 'INCREASE')
+ON CONFLICT (code) DO NOTHING;
@@ -0,0 +1,5 @@
+ALTER TABLE movements DROP COLUMN IF EXISTS to_status;
+ALTER TABLE movements DROP COLUMN IF EXISTS from_status;
+DROP TABLE IF EXISTS stock_status_levels;
+DROP TYPE IF EXISTS stock_status;
+-- PostgreSQL no permite eliminar valores de un ENUM: 'STATUS_CHANGE' se conserva en movement_type
@@ -0,0 +1,23 @@
+-- Migration: Inventory status buckets
+-- Date: 2026-10-16
+-- Description: Stock held per status (quarantine, damaged, on hold) by product and warehouse, and STATUS_CHANGE movements between statuses
+
+ALTER TYPE movement_type ADD VALUE IF NOT EXISTS 'STATUS_CHANGE';
+
+CREATE TYPE stock_status AS ENUM ('AVAILABLE', 'QUARANTINE', 'DAMAGED', 'ON_HOLD');
+
+-- solo se guarda el stock retenido: lo disponible es stock_levels.quantity menos la suma de estos estados
+CREATE TABLE stock_status_levels (
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
+    status stock_status NOT NULL CHECK (status <> 'AVAILABLE'),
+    quantity INTEGER NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (product_id, warehouse_id, status)
+);
+
+CREATE INDEX idx_stock_status_levels_warehouse_id ON stock_status_levels(warehouse_id);
+
+-- estado de origen y destino del movimiento (nulo = AVAILABLE)
+ALTER TABLE movements ADD COLUMN from_status stock_status;
+ALTER TABLE movements ADD COLUMN to_status stock_status;
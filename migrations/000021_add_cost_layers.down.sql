@@ -0,0 +1,6 @@
+ALTER TABLE purchase_order_lines DROP COLUMN IF EXISTS unit_cost;
+
+ALTER TABLE movements DROP COLUMN IF EXISTS total_cost;
+ALTER TABLE movements DROP COLUMN IF EXISTS unit_cost;
+
+DROP TABLE IF EXISTS cost_layers;
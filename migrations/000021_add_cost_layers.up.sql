@@ -0,0 +1,30 @@
+-- Migration: Inventory valuation
+-- Date: 2026-10-16
+-- Description: Cost layers per product and warehouse (FIFO / moving average), unit and total cost on movements and unit cost on purchase order lines
+
+-- cada entrada con costo abre una capa; las salidas consumen las capas mas antiguas primero
+CREATE TABLE cost_layers (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
+    movement_id INTEGER REFERENCES movements(id) ON DELETE RESTRICT, -- nulo = saldo inicial
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    remaining_quantity INTEGER NOT NULL CHECK (remaining_quantity >= 0 AND remaining_quantity <= quantity),
+    unit_cost NUMERIC(14,4) NOT NULL CHECK (unit_cost >= 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_cost_layers_open ON cost_layers(product_id, warehouse_id, id) WHERE remaining_quantity > 0;
+CREATE INDEX idx_cost_layers_movement_id ON cost_layers(movement_id);
+
+-- costo del movimiento: unitario de la entrada o promedio de lo consumido en la salida
+ALTER TABLE movements ADD COLUMN unit_cost NUMERIC(14,4);
+ALTER TABLE movements ADD COLUMN total_cost NUMERIC(16,4);
+
+ALTER TABLE purchase_order_lines ADD COLUMN unit_cost NUMERIC(14,4) CHECK (unit_cost >= 0);
+
+-- el stock existente entra como saldo inicial sin costo
+INSERT INTO cost_layers (product_id, warehouse_id, quantity, remaining_quantity, unit_cost)
+SELECT product_id, warehouse_id, quantity, quantity, 0
+FROM stock_levels
+WHERE quantity > 0;
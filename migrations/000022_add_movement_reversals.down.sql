@@ -0,0 +1,4 @@
+DELETE FROM reason_codes WHERE code = 'REVERSAL' AND NOT EXISTS (SELECT 1 FROM movements WHERE reason_code = 'REVERSAL');
+
+DROP INDEX IF EXISTS idx_movements_reversal_of_id;
+ALTER TABLE movements DROP COLUMN IF EXISTS reversal_of_id;
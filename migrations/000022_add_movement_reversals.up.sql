@@ -0,0 +1,13 @@
+-- Migration: Movement reversals
+-- Date: 2026-10-16
+-- Description: Link from a compensating movement to the movement it reverses; each movement can be reversed only once
+
+ALTER TABLE movements ADD COLUMN reversal_of_id INTEGER REFERENCES movements(id) ON DELETE RESTRICT
+    CHECK (reversal_of_id <> id);
+
+-- un movimiento solo tiene una reversion
+CREATE UNIQUE INDEX idx_movements_reversal_of_id ON movements(reversal_of_id) WHERE reversal_of_id IS NOT NULL;
+
+INSERT INTO reason_codes (code, description, direction) VALUES
+    ('REVERSAL', 'Reversión de movimiento', 'BOTH')
+ON CONFLICT (code) DO NOTHING;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_movements_batch_id;
+ALTER TABLE movements DROP COLUMN IF EXISTS batch_id;
+DROP TABLE IF EXISTS movement_batches;
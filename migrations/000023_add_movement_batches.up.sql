@@ -0,0 +1,14 @@
+-- Migration: Movement batches
+-- Date: 2026-10-16
+-- Description: Header shared by the movements posted atomically in one POST /movements/batch
+
+CREATE TABLE movement_batches (
+    id SERIAL PRIMARY KEY,
+    reference VARCHAR(50), -- documento de origen (ej. remision del proveedor)
+    created_by VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE movements ADD COLUMN batch_id INTEGER REFERENCES movement_batches(id) ON DELETE RESTRICT;
+
+CREATE INDEX idx_movements_batch_id ON movements(batch_id) WHERE batch_id IS NOT NULL;
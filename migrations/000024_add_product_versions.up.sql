@@ -0,0 +1,6 @@
+-- Migration: Product row versions
+-- Date: 2026-10-16
+-- Description: Row version for optimistic concurrency (ETag / If-Match) on product edits
+
+-- sube en cada edicion, borrado o restauracion del producto; los movimientos de stock no la tocan
+ALTER TABLE products ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
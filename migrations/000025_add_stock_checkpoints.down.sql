@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS stock_checkpoint_runs;
+DROP INDEX IF EXISTS idx_stock_checkpoints_at;
+DROP TABLE IF EXISTS stock_checkpoints;
+DROP VIEW IF EXISTS stock_ledger;
@@ -0,0 +1,52 @@
+-- Migration: Stock checkpoints
+-- Date: 2026-10-16
+-- Description: Movement ledger view and stock checkpoints for point-in-time stock queries
+
+-- stock_ledger es el efecto de cada movimiento en el stock de cada almacen (con signo)
+-- las transferencias restan en el origen y suman en el destino, el cambio de estado no mueve stock
+CREATE VIEW stock_ledger AS
+SELECT id AS movement_id, product_id, warehouse_id,
+    CASE WHEN movement_type IN ('OUT', 'TRANSFER') THEN -quantity ELSE quantity END AS quantity,
+    created_at
+FROM movements
+WHERE movement_type <> 'STATUS_CHANGE'
+    AND (movement_type <> 'TRANSFER' OR to_warehouse_id IS DISTINCT FROM warehouse_id)
+UNION ALL
+SELECT id, product_id, to_warehouse_id, quantity, created_at
+FROM movements
+WHERE movement_type = 'TRANSFER' AND to_warehouse_id IS DISTINCT FROM warehouse_id;
+
+-- stock de un producto en un almacen con todo lo registrado antes de checkpoint_at
+-- el stock a una fecha es el ultimo checkpoint anterior mas el ledger desde ese checkpoint
+CREATE TABLE stock_checkpoints (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
+    checkpoint_at TIMESTAMP NOT NULL,
+    quantity INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (product_id, warehouse_id, checkpoint_at)
+);
+
+CREATE INDEX idx_stock_checkpoints_at ON stock_checkpoints(checkpoint_at);
+
+-- dias ya cerrados por el job de checkpoints: cada par producto/almacen con movimientos
+-- en el dia tiene un checkpoint al final de ese dia
+CREATE TABLE stock_checkpoint_runs (
+    checkpoint_at TIMESTAMP PRIMARY KEY,
+    checkpoints INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- saldo inicial: el stock que no viene de movimientos (stock inicial de productos y datos previos)
+-- queda como checkpoint a la fecha de alta del producto
+INSERT INTO stock_checkpoints (product_id, warehouse_id, checkpoint_at, quantity)
+SELECT sl.product_id, sl.warehouse_id, p.created_at, sl.quantity - COALESCE(l.quantity, 0)
+FROM stock_levels sl
+JOIN products p ON p.id = sl.product_id
+LEFT JOIN (
+    SELECT product_id, warehouse_id, SUM(quantity) AS quantity
+    FROM stock_ledger
+    GROUP BY product_id, warehouse_id
+) l ON l.product_id = sl.product_id AND l.warehouse_id = sl.warehouse_id
+WHERE sl.quantity - COALESCE(l.quantity, 0) <> 0;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_stock_reconciliations_product_id;
+DROP TABLE IF EXISTS stock_reconciliations;
+DELETE FROM reason_codes WHERE code IN ('OPENING_BALANCE', 'RECONCILIATION') AND NOT EXISTS (SELECT 1 FROM movements WHERE reason_code = reason_codes.code);
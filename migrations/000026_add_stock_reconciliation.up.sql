@@ -0,0 +1,24 @@
+-- Migration: Stock reconciliation
+-- Date: 2026-10-16
+-- Description: System reason codes for opening-balance movements and reconciliation adjustments, and the reconciliation log
+
+-- solo los registra el sistema, inactivos para que no se capturen a mano
+-- OPENING_BALANCE: entrada del stock inicial al dar de alta un producto
+-- RECONCILIATION: ajuste que completa el ledger con la diferencia contra stock_levels (no mueve stock)
+INSERT INTO reason_codes (code, description, direction, is_active) VALUES
+    ('OPENING_BALANCE', 'Saldo inicial del producto', 'INCREASE', FALSE),
+    ('RECONCILIATION', 'Conciliación de stock contra movimientos', 'BOTH', FALSE)
+ON CONFLICT (code) DO NOTHING;
+
+-- cada reparacion de un producto: el stock_quantity que tenia y el que quedo (la suma de sus almacenes)
+-- los ajustes RECONCILIATION de sus almacenes llevan el id en la razon ("stock reconciliation #<id>")
+CREATE TABLE stock_reconciliations (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    previous_stock_quantity INTEGER NOT NULL,
+    stock_quantity INTEGER NOT NULL,
+    created_by VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_stock_reconciliations_product_id ON stock_reconciliations(product_id);
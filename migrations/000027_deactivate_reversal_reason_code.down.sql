@@ -0,0 +1 @@
+UPDATE reason_codes SET is_active = TRUE WHERE code = 'REVERSAL';
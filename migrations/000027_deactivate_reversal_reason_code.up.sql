@@ -0,0 +1,5 @@
+-- Migration: Deactivate reversal reason code
+-- Date: 2026-10-16
+-- Description: REVERSAL becomes a system reason code: only POST /movements/:id/reverse can use it
+
+UPDATE reason_codes SET is_active = FALSE WHERE code = 'REVERSAL';
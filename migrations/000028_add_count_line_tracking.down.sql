@@ -0,0 +1,3 @@
+ALTER TABLE count_lines
+    DROP COLUMN IF EXISTS serials,
+    DROP COLUMN IF EXISTS lot_number;
@@ -0,0 +1,9 @@
+-- Migration: Lot and serial capture on count lines
+-- Date: 2026-10-17
+-- Description: Count lines record the lot of the found units and the serials of the difference so approval can post the ADJUST
+
+-- lot_number: lote al que entran las unidades encontradas (en faltantes es opcional, sin el se toma FEFO)
+-- serials: series de la diferencia, las faltantes si es negativa o las encontradas si es positiva
+ALTER TABLE count_lines
+    ADD COLUMN lot_number VARCHAR(50),
+    ADD COLUMN serials TEXT[] NOT NULL DEFAULT '{}';
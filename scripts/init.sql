@@ -114,6 +114,854 @@ BEGIN
     RAISE NOTICE '✅ Soft delete support added to products';
 END $$;    
 
+-- ==============================================
+-- MULTI-WAREHOUSE SUPPORT
+-- ==============================================
+
+CREATE TABLE IF NOT EXISTS warehouses (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(20) UNIQUE NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    address TEXT,
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP DEFAULT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_warehouses_default ON warehouses(is_default) WHERE is_default;
+
+CREATE TRIGGER update_warehouses_updated_at
+    BEFORE UPDATE ON warehouses
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- stock por almacen (products.stock_quantity es el total)
+CREATE TABLE IF NOT EXISTS stock_levels (
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (product_id, warehouse_id)
+);
+CREATE INDEX IF NOT EXISTS idx_stock_levels_warehouse_id ON stock_levels(warehouse_id);
+
+ALTER TABLE movements
+ADD COLUMN IF NOT EXISTS warehouse_id INTEGER NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT;
+CREATE INDEX IF NOT EXISTS idx_movements_warehouse_id ON movements(warehouse_id);
+
+INSERT INTO warehouses (code, name, is_default) VALUES
+    ('MAIN', 'Almacén principal', TRUE)
+ON CONFLICT (code) DO NOTHING;
+
+-- ==============================================
+-- LOCATIONS (zone -> aisle -> rack -> bin)
+-- ==============================================
+
+CREATE TYPE location_type AS ENUM ('ZONE', 'AISLE', 'RACK', 'BIN');
+
+CREATE TABLE IF NOT EXISTS locations (
+    id SERIAL PRIMARY KEY,
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
+    parent_id INTEGER REFERENCES locations(id) ON DELETE RESTRICT,
+    location_type location_type NOT NULL,
+    code VARCHAR(30) NOT NULL,
+    path VARCHAR(255) NOT NULL,
+    name VARCHAR(255),
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (warehouse_id, path)
+);
+CREATE INDEX IF NOT EXISTS idx_locations_warehouse_id ON locations(warehouse_id);
+CREATE INDEX IF NOT EXISTS idx_locations_parent_id ON locations(parent_id);
+
+CREATE TRIGGER update_locations_updated_at
+    BEFORE UPDATE ON locations
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- stock por ubicacion, siempre contenido en stock_levels del almacen
+CREATE TABLE IF NOT EXISTS location_stock (
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    location_id INTEGER NOT NULL REFERENCES locations(id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (product_id, location_id)
+);
+CREATE INDEX IF NOT EXISTS idx_location_stock_location_id ON location_stock(location_id);
+
+ALTER TABLE movements
+    ADD COLUMN IF NOT EXISTS from_location_id INTEGER REFERENCES locations(id) ON DELETE RESTRICT,
+    ADD COLUMN IF NOT EXISTS to_location_id INTEGER REFERENCES locations(id) ON DELETE RESTRICT;
+
+-- ==============================================
+-- TRANSFER MOVEMENTS
+-- ==============================================
+
+ALTER TYPE movement_type ADD VALUE IF NOT EXISTS 'TRANSFER';
+
+-- almacen destino de la transferencia (warehouse_id es el origen)
+ALTER TABLE movements
+ADD COLUMN IF NOT EXISTS to_warehouse_id INTEGER REFERENCES warehouses(id) ON DELETE RESTRICT;
+CREATE INDEX IF NOT EXISTS idx_movements_to_warehouse_id ON movements(to_warehouse_id) WHERE to_warehouse_id IS NOT NULL;
+
+-- ==============================================
+-- ADJUSTMENTS AND REASON CODES
+-- ==============================================
+
+ALTER TYPE movement_type ADD VALUE IF NOT EXISTS 'ADJUST';
+
+CREATE TYPE adjustment_direction AS ENUM ('INCREASE', 'DECREASE', 'BOTH');
+
+CREATE TABLE IF NOT EXISTS reason_codes (
+    code VARCHAR(30) PRIMARY KEY,
+    description VARCHAR(255) NOT NULL,
+    direction adjustment_direction NOT NULL DEFAULT 'BOTH',
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_reason_codes_updated_at
+    BEFORE UPDATE ON reason_codes
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+INSERT INTO reason_codes (code, description, direction) VALUES
+    ('DAMAGE', 'Mercancía dañada', 'DECREASE'),
+    ('SHRINKAGE', 'Merma / faltante', 'DECREASE'),
+    ('COUNT_CORRECTION', 'Corrección por conteo físico', 'BOTH'),
+    ('FOUND', 'Mercancía encontrada', 'INCREASE')
+ON CONFLICT (code) DO NOTHING;
+
+ALTER TABLE movements
+ADD COLUMN IF NOT EXISTS reason_code VARCHAR(30) REFERENCES reason_codes(code) ON DELETE RESTRICT;
+CREATE INDEX IF NOT EXISTS idx_movements_reason_code ON movements(reason_code) WHERE reason_code IS NOT NULL;
+
+-- ADJUST guarda la cantidad con signo (positiva entra, negativa sale)
+ALTER TABLE movements DROP CONSTRAINT IF EXISTS movements_quantity_check;
+ALTER TABLE movements ADD CONSTRAINT movements_quantity_check
+    CHECK (quantity <> 0 AND (quantity > 0 OR movement_type::text = 'ADJUST'));
+ALTER TABLE movements ADD CONSTRAINT movements_adjust_reason_code_check
+    CHECK (movement_type::text <> 'ADJUST' OR reason_code IS NOT NULL);
+
+-- ==============================================
+-- CYCLE COUNTS
+-- ==============================================
+
+CREATE TYPE count_status AS ENUM ('OPEN', 'COUNTING', 'REVIEW', 'POSTED');
+
+CREATE TABLE IF NOT EXISTS count_sessions (
+    id SERIAL PRIMARY KEY,
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
+    status count_status NOT NULL DEFAULT 'OPEN',
+    notes VARCHAR(255),
+    created_by VARCHAR(100),
+    approved_by VARCHAR(100),
+    posted_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_count_sessions_warehouse_id ON count_sessions(warehouse_id);
+CREATE INDEX IF NOT EXISTS idx_count_sessions_status ON count_sessions(status);
+
+CREATE TRIGGER update_count_sessions_updated_at
+    BEFORE UPDATE ON count_sessions
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS count_lines (
+    id SERIAL PRIMARY KEY,
+    session_id INTEGER NOT NULL REFERENCES count_sessions(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    location_id INTEGER REFERENCES locations(id) ON DELETE RESTRICT,
+    expected_quantity INTEGER NOT NULL CHECK (expected_quantity >= 0),
+    counted_quantity INTEGER CHECK (counted_quantity >= 0),
+    counted_by VARCHAR(100),
+    counted_at TIMESTAMP,
+    movement_id INTEGER REFERENCES movements(id) ON DELETE RESTRICT,
+    UNIQUE NULLS NOT DISTINCT (session_id, product_id, location_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_count_lines_session_id ON count_lines(session_id);
+
+-- ==============================================
+-- LOTS
+-- ==============================================
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS is_lot_tracked BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS lots (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    lot_number VARCHAR(50) NOT NULL,
+    manufactured_at DATE,
+    expires_at DATE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (product_id, lot_number),
+    CHECK (expires_at IS NULL OR manufactured_at IS NULL OR expires_at >= manufactured_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_lots_product_expires_at ON lots(product_id, expires_at);
+
+CREATE TRIGGER update_lots_updated_at
+    BEFORE UPDATE ON lots
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS lot_stock (
+    lot_id INTEGER NOT NULL REFERENCES lots(id) ON DELETE RESTRICT,
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (lot_id, warehouse_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_lot_stock_warehouse_id ON lot_stock(warehouse_id);
+
+CREATE TABLE IF NOT EXISTS movement_lots (
+    movement_id INTEGER NOT NULL REFERENCES movements(id) ON DELETE CASCADE,
+    lot_id INTEGER NOT NULL REFERENCES lots(id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    PRIMARY KEY (movement_id, lot_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_movement_lots_lot_id ON movement_lots(lot_id);
+
+-- ==============================================
+-- SERIALS
+-- ==============================================
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS is_serialized BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TYPE serial_status AS ENUM ('IN_STOCK', 'SHIPPED', 'WRITTEN_OFF');
+
+CREATE TABLE IF NOT EXISTS serials (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    serial_number VARCHAR(100) NOT NULL UNIQUE,
+    status serial_status NOT NULL DEFAULT 'IN_STOCK',
+    warehouse_id INTEGER REFERENCES warehouses(id) ON DELETE RESTRICT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((status = 'IN_STOCK') = (warehouse_id IS NOT NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_serials_product_id ON serials(product_id);
+CREATE INDEX IF NOT EXISTS idx_serials_warehouse_id ON serials(warehouse_id) WHERE warehouse_id IS NOT NULL;
+
+CREATE TRIGGER update_serials_updated_at
+    BEFORE UPDATE ON serials
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS movement_serials (
+    movement_id INTEGER NOT NULL REFERENCES movements(id) ON DELETE CASCADE,
+    serial_id INTEGER NOT NULL REFERENCES serials(id) ON DELETE RESTRICT,
+    PRIMARY KEY (movement_id, serial_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_movement_serials_serial_id ON movement_serials(serial_id);
+
+-- ==============================================
+-- UNITS OF MEASURE
+-- ==============================================
+
+CREATE TABLE IF NOT EXISTS units_of_measure (
+    code VARCHAR(10) PRIMARY KEY,
+    name VARCHAR(50) NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_units_of_measure_updated_at
+    BEFORE UPDATE ON units_of_measure
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+INSERT INTO units_of_measure (code, name) VALUES
+    ('EA', 'Pieza'),
+    ('INNER', 'Paquete interno'),
+    ('CASE', 'Caja'),
+    ('PALLET', 'Tarima')
+ON CONFLICT (code) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS product_uoms (
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    uom_code VARCHAR(10) NOT NULL REFERENCES units_of_measure(code) ON DELETE RESTRICT,
+    factor INTEGER NOT NULL CHECK (factor > 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (product_id, uom_code),
+    CHECK (uom_code <> 'EA')
+);
+
+CREATE TRIGGER update_product_uoms_updated_at
+    BEFORE UPDATE ON product_uoms
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+ALTER TABLE movements
+ADD COLUMN IF NOT EXISTS uom VARCHAR(10) NOT NULL DEFAULT 'EA' REFERENCES units_of_measure(code) ON DELETE RESTRICT;
+ALTER TABLE movements ADD COLUMN IF NOT EXISTS uom_quantity INTEGER;
+UPDATE movements SET uom_quantity = quantity WHERE uom_quantity IS NULL;
+ALTER TABLE movements ALTER COLUMN uom_quantity SET NOT NULL;
+
+-- ==============================================
+-- CATEGORIES
+-- ==============================================
+
+CREATE TABLE IF NOT EXISTS categories (
+    id SERIAL PRIMARY KEY,
+    parent_id INTEGER REFERENCES categories(id) ON DELETE RESTRICT,
+    code VARCHAR(30) NOT NULL,
+    path VARCHAR(255) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_categories_parent_id ON categories(parent_id);
+
+CREATE TRIGGER update_categories_updated_at
+    BEFORE UPDATE ON categories
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+ALTER TABLE products
+ADD COLUMN IF NOT EXISTS category_id INTEGER REFERENCES categories(id) ON DELETE RESTRICT;
+CREATE INDEX IF NOT EXISTS idx_products_category_id ON products(category_id) WHERE category_id IS NOT NULL;
+
+-- ==============================================
+-- PRODUCT BARCODES
+-- ==============================================
+
+CREATE TYPE barcode_type AS ENUM ('EAN13', 'UPCA', 'GTIN14');
+
+CREATE TABLE IF NOT EXISTS product_barcodes (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    code VARCHAR(14) NOT NULL UNIQUE,
+    barcode_type barcode_type NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (code ~ '^[0-9]+$')
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_barcodes_product_id ON product_barcodes(product_id);
+
+-- ==============================================
+-- REORDER LEVELS & LOW STOCK ALERTS
+-- ==============================================
+
+ALTER TABLE products
+ADD COLUMN IF NOT EXISTS reorder_point INTEGER CHECK (reorder_point >= 0),
+ADD COLUMN IF NOT EXISTS min_level INTEGER CHECK (min_level >= 0),
+ADD COLUMN IF NOT EXISTS max_level INTEGER CHECK (max_level >= 0);
+
+CREATE TYPE alert_status AS ENUM ('OPEN', 'RESOLVED');
+
+CREATE TABLE IF NOT EXISTS low_stock_alerts (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    status alert_status NOT NULL DEFAULT 'OPEN',
+    threshold INTEGER NOT NULL,
+    stock_at_open INTEGER NOT NULL,
+    stock_at_resolve INTEGER,
+    opened_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_low_stock_alerts_open ON low_stock_alerts(product_id) WHERE status = 'OPEN';
+CREATE INDEX IF NOT EXISTS idx_low_stock_alerts_opened_at ON low_stock_alerts(opened_at DESC);
+
+-- ==============================================
+-- PURCHASING
+-- ==============================================
+
+CREATE TABLE IF NOT EXISTS suppliers (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(20) NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL,
+    contact_name VARCHAR(100),
+    email VARCHAR(255),
+    phone VARCHAR(30),
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_suppliers_updated_at
+    BEFORE UPDATE ON suppliers
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TYPE purchase_order_status AS ENUM ('DRAFT', 'SENT', 'PARTIALLY_RECEIVED', 'RECEIVED', 'CLOSED');
+
+CREATE TABLE IF NOT EXISTS purchase_orders (
+    id SERIAL PRIMARY KEY,
+    supplier_id INTEGER NOT NULL REFERENCES suppliers(id) ON DELETE RESTRICT,
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
+    status purchase_order_status NOT NULL DEFAULT 'DRAFT',
+    supplier_reference VARCHAR(50),
+    expected_at DATE,
+    over_receipt_tolerance INTEGER NOT NULL DEFAULT 0 CHECK (over_receipt_tolerance BETWEEN 0 AND 100),
+    notes VARCHAR(255),
+    created_by VARCHAR(100),
+    sent_at TIMESTAMP,
+    closed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_purchase_orders_supplier_id ON purchase_orders(supplier_id);
+CREATE INDEX IF NOT EXISTS idx_purchase_orders_status ON purchase_orders(status);
+
+CREATE TRIGGER update_purchase_orders_updated_at
+    BEFORE UPDATE ON purchase_orders
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS purchase_order_lines (
+    id SERIAL PRIMARY KEY,
+    purchase_order_id INTEGER NOT NULL REFERENCES purchase_orders(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    ordered_quantity INTEGER NOT NULL CHECK (ordered_quantity > 0),
+    received_quantity INTEGER NOT NULL DEFAULT 0 CHECK (received_quantity >= 0),
+    UNIQUE (purchase_order_id, product_id)
+);
+
+CREATE TABLE IF NOT EXISTS purchase_receipts (
+    id SERIAL PRIMARY KEY,
+    purchase_order_id INTEGER NOT NULL REFERENCES purchase_orders(id) ON DELETE RESTRICT,
+    line_id INTEGER NOT NULL REFERENCES purchase_order_lines(id) ON DELETE RESTRICT,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    movement_id INTEGER NOT NULL REFERENCES movements(id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    received_by VARCHAR(100),
+    received_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_purchase_receipts_purchase_order_id ON purchase_receipts(purchase_order_id);
+
+-- ==============================================
+-- SALES ORDERS
+-- ==============================================
+
+CREATE TYPE sales_order_status AS ENUM ('NEW', 'ALLOCATED', 'PICKED', 'PACKED', 'SHIPPED', 'CANCELLED');
+
+CREATE TABLE IF NOT EXISTS sales_orders (
+    id SERIAL PRIMARY KEY,
+    customer_reference VARCHAR(50) NOT NULL,
+    customer_name VARCHAR(255),
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
+    status sales_order_status NOT NULL DEFAULT 'NEW',
+    notes VARCHAR(255),
+    created_by VARCHAR(100),
+    allocated_at TIMESTAMP,
+    picked_at TIMESTAMP,
+    packed_at TIMESTAMP,
+    shipped_at TIMESTAMP,
+    cancelled_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_sales_orders_customer_reference ON sales_orders(customer_reference);
+CREATE INDEX IF NOT EXISTS idx_sales_orders_status ON sales_orders(status);
+
+CREATE TRIGGER update_sales_orders_updated_at
+    BEFORE UPDATE ON sales_orders
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS sales_order_lines (
+    id SERIAL PRIMARY KEY,
+    sales_order_id INTEGER NOT NULL REFERENCES sales_orders(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    allocated_quantity INTEGER NOT NULL DEFAULT 0 CHECK (allocated_quantity >= 0),
+    shipped_quantity INTEGER NOT NULL DEFAULT 0 CHECK (shipped_quantity >= 0),
+    movement_id INTEGER REFERENCES movements(id) ON DELETE RESTRICT,
+    UNIQUE (sales_order_id, product_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sales_order_lines_product_id ON sales_order_lines(product_id) WHERE allocated_quantity > 0;
+
+-- ==============================================
+-- RESERVATIONS
+-- ==============================================
+
+CREATE TYPE reservation_status AS ENUM ('ACTIVE', 'CONSUMED', 'RELEASED', 'EXPIRED');
+
+-- cantidades en la unidad base; lo reservado es quantity - consumed_quantity mientras este ACTIVE y vigente
+CREATE TABLE IF NOT EXISTS reservations (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    consumed_quantity INTEGER NOT NULL DEFAULT 0 CHECK (consumed_quantity >= 0 AND consumed_quantity <= quantity),
+    owner_reference VARCHAR(100) NOT NULL,
+    status reservation_status NOT NULL DEFAULT 'ACTIVE',
+    expires_at TIMESTAMP,
+    created_by VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    released_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_reservations_product_warehouse ON reservations(product_id, warehouse_id) WHERE status = 'ACTIVE';
+CREATE INDEX IF NOT EXISTS idx_reservations_owner_reference ON reservations(owner_reference);
+CREATE INDEX IF NOT EXISTS idx_reservations_expires_at ON reservations(expires_at) WHERE status = 'ACTIVE' AND expires_at IS NOT NULL;
+
+CREATE TRIGGER update_reservations_updated_at
+    BEFORE UPDATE ON reservations
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- reserva consumida por una salida
+ALTER TABLE movements
+ADD COLUMN IF NOT EXISTS reservation_id INTEGER REFERENCES reservations(id) ON DELETE RESTRICT;
+CREATE INDEX IF NOT EXISTS idx_movements_reservation_id ON movements(reservation_id) WHERE reservation_id IS NOT NULL;
+
+-- las lineas de venta apartan su stock con una reserva
+ALTER TABLE sales_order_lines
+ADD COLUMN IF NOT EXISTS reservation_id INTEGER REFERENCES reservations(id) ON DELETE RESTRICT;
+
+-- ==============================================
+-- PICK WAVES
+-- ==============================================
+
+CREATE TYPE wave_status AS ENUM ('OPEN', 'COMPLETED', 'CANCELLED');
+
+-- lo surtido se lleva a la ubicacion de preparacion (staging) de la ola
+CREATE TABLE IF NOT EXISTS waves (
+    id SERIAL PRIMARY KEY,
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
+    staging_location_id INTEGER NOT NULL REFERENCES locations(id) ON DELETE RESTRICT,
+    status wave_status NOT NULL DEFAULT 'OPEN',
+    notes VARCHAR(255),
+    created_by VARCHAR(100),
+    completed_at TIMESTAMP,
+    cancelled_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_waves_status ON waves(status);
+
+CREATE TRIGGER update_waves_updated_at
+    BEFORE UPDATE ON waves
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS wave_orders (
+    wave_id INTEGER NOT NULL REFERENCES waves(id) ON DELETE CASCADE,
+    sales_order_id INTEGER NOT NULL REFERENCES sales_orders(id) ON DELETE RESTRICT,
+    PRIMARY KEY (wave_id, sales_order_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_wave_orders_sales_order_id ON wave_orders(sales_order_id);
+
+CREATE TYPE pick_task_status AS ENUM ('PENDING', 'PICKED', 'SHORT', 'CANCELLED');
+
+-- location_id nulo: se surte del stock sin ubicar; sequence es el orden de recorrido dentro de la ola
+CREATE TABLE IF NOT EXISTS pick_tasks (
+    id SERIAL PRIMARY KEY,
+    wave_id INTEGER NOT NULL REFERENCES waves(id) ON DELETE CASCADE,
+    sequence INTEGER NOT NULL,
+    sales_order_id INTEGER NOT NULL REFERENCES sales_orders(id) ON DELETE RESTRICT,
+    sales_order_line_id INTEGER NOT NULL REFERENCES sales_order_lines(id) ON DELETE RESTRICT,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    location_id INTEGER REFERENCES locations(id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    picked_quantity INTEGER NOT NULL DEFAULT 0 CHECK (picked_quantity >= 0 AND picked_quantity <= quantity),
+    status pick_task_status NOT NULL DEFAULT 'PENDING',
+    movement_id INTEGER REFERENCES movements(id) ON DELETE RESTRICT,
+    picked_by VARCHAR(100),
+    picked_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (wave_id, sequence)
+);
+
+CREATE INDEX IF NOT EXISTS idx_pick_tasks_sales_order_line_id ON pick_tasks(sales_order_line_id);
+CREATE INDEX IF NOT EXISTS idx_pick_tasks_pending ON pick_tasks(product_id, location_id) WHERE status = 'PENDING';
+
+-- ==============================================
+-- SHIPMENTS
+-- ==============================================
+
+CREATE TYPE shipment_status AS ENUM ('OPEN', 'CONFIRMED', 'CANCELLED');
+
+CREATE TABLE IF NOT EXISTS shipments (
+    id SERIAL PRIMARY KEY,
+    sales_order_id INTEGER NOT NULL REFERENCES sales_orders(id) ON DELETE RESTRICT,
+    carrier VARCHAR(50),
+    tracking_number VARCHAR(100),
+    from_location_id INTEGER REFERENCES locations(id) ON DELETE RESTRICT,
+    status shipment_status NOT NULL DEFAULT 'OPEN',
+    notes VARCHAR(255),
+    created_by VARCHAR(100),
+    confirmed_by VARCHAR(100),
+    confirmed_at TIMESTAMP,
+    cancelled_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- un solo embarque vigente por orden
+CREATE UNIQUE INDEX IF NOT EXISTS idx_shipments_sales_order_active ON shipments(sales_order_id) WHERE status <> 'CANCELLED';
+CREATE INDEX IF NOT EXISTS idx_shipments_tracking_number ON shipments(tracking_number) WHERE tracking_number IS NOT NULL;
+
+CREATE TRIGGER update_shipments_updated_at
+    BEFORE UPDATE ON shipments
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS shipment_packages (
+    id SERIAL PRIMARY KEY,
+    shipment_id INTEGER NOT NULL REFERENCES shipments(id) ON DELETE CASCADE,
+    package_number INTEGER NOT NULL,
+    tracking_number VARCHAR(100),
+    length_cm NUMERIC(8, 2) NOT NULL CHECK (length_cm > 0),
+    width_cm NUMERIC(8, 2) NOT NULL CHECK (width_cm > 0),
+    height_cm NUMERIC(8, 2) NOT NULL CHECK (height_cm > 0),
+    weight_kg NUMERIC(10, 3) NOT NULL CHECK (weight_kg > 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (shipment_id, package_number)
+);
+
+CREATE INDEX IF NOT EXISTS idx_shipment_packages_tracking_number ON shipment_packages(tracking_number) WHERE tracking_number IS NOT NULL;
+
+-- cantidades en la unidad base
+CREATE TABLE IF NOT EXISTS shipment_package_lines (
+    package_id INTEGER NOT NULL REFERENCES shipment_packages(id) ON DELETE CASCADE,
+    sales_order_line_id INTEGER NOT NULL REFERENCES sales_order_lines(id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    PRIMARY KEY (package_id, sales_order_line_id)
+);
+
+-- ==============================================
+-- RMAS
+-- ==============================================
+
+CREATE TYPE rma_status AS ENUM ('OPEN', 'CLOSED', 'CANCELLED');
+CREATE TYPE rma_disposition AS ENUM ('RESTOCK', 'QUARANTINE', 'SCRAP', 'RETURN_TO_VENDOR');
+
+CREATE TABLE IF NOT EXISTS rmas (
+    id SERIAL PRIMARY KEY,
+    sales_order_id INTEGER REFERENCES sales_orders(id) ON DELETE RESTRICT,
+    movement_id INTEGER REFERENCES movements(id) ON DELETE RESTRICT,
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
+    customer_reference VARCHAR(50),
+    reason VARCHAR(255),
+    status rma_status NOT NULL DEFAULT 'OPEN',
+    created_by VARCHAR(100),
+    closed_at TIMESTAMP,
+    cancelled_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    -- la devolucion siempre apunta a la salida original
+    CHECK (sales_order_id IS NOT NULL OR movement_id IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS idx_rmas_sales_order_id ON rmas(sales_order_id) WHERE sales_order_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_rmas_movement_id ON rmas(movement_id) WHERE movement_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_rmas_status ON rmas(status);
+
+CREATE TRIGGER update_rmas_updated_at
+    BEFORE UPDATE ON rmas
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- cantidades en la unidad base
+-- disposition nula = linea pendiente de inspeccion
+CREATE TABLE IF NOT EXISTS rma_lines (
+    id SERIAL PRIMARY KEY,
+    rma_id INTEGER NOT NULL REFERENCES rmas(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    sales_order_line_id INTEGER REFERENCES sales_order_lines(id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    reason VARCHAR(255),
+    disposition rma_disposition,
+    location_id INTEGER REFERENCES locations(id) ON DELETE RESTRICT,
+    supplier_id INTEGER REFERENCES suppliers(id) ON DELETE RESTRICT,
+    movement_id INTEGER REFERENCES movements(id) ON DELETE RESTRICT,
+    inspection_notes VARCHAR(255),
+    inspected_by VARCHAR(100),
+    inspected_at TIMESTAMP,
+    UNIQUE (rma_id, product_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_rma_lines_sales_order_line_id ON rma_lines(sales_order_line_id) WHERE sales_order_line_id IS NOT NULL;
+
+INSERT INTO reason_codes (code, description, direction) VALUES
+    ('CUSTOMER_RETURN', 'Devolución de cliente', 'INCREASE')
+ON CONFLICT (code) DO NOTHING;
+
+-- ==============================================
+-- INVENTORY STATUS
+-- ==============================================
+
+ALTER TYPE movement_type ADD VALUE IF NOT EXISTS 'STATUS_CHANGE';
+
+CREATE TYPE stock_status AS ENUM ('AVAILABLE', 'QUARANTINE', 'DAMAGED', 'ON_HOLD');
+
+-- solo se guarda el stock retenido: lo disponible es stock_levels.quantity menos la suma de estos estados
+CREATE TABLE IF NOT EXISTS stock_status_levels (
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
+    status stock_status NOT NULL CHECK (status <> 'AVAILABLE'),
+    quantity INTEGER NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (product_id, warehouse_id, status)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_status_levels_warehouse_id ON stock_status_levels(warehouse_id);
+
+-- estado de origen y destino del movimiento (nulo = AVAILABLE)
+ALTER TABLE movements ADD COLUMN IF NOT EXISTS from_status stock_status;
+ALTER TABLE movements ADD COLUMN IF NOT EXISTS to_status stock_status;
+
+-- ==============================================
+-- INVENTORY VALUATION (COST LAYERS)
+-- ==============================================
+
+-- cada entrada con costo abre una capa; las salidas consumen las capas mas antiguas primero
+CREATE TABLE IF NOT EXISTS cost_layers (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
+    movement_id INTEGER REFERENCES movements(id) ON DELETE RESTRICT, -- nulo = saldo inicial
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    remaining_quantity INTEGER NOT NULL CHECK (remaining_quantity >= 0 AND remaining_quantity <= quantity),
+    unit_cost NUMERIC(14,4) NOT NULL CHECK (unit_cost >= 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_cost_layers_open ON cost_layers(product_id, warehouse_id, id) WHERE remaining_quantity > 0;
+CREATE INDEX IF NOT EXISTS idx_cost_layers_movement_id ON cost_layers(movement_id);
+
+-- costo del movimiento: unitario de la entrada o promedio de lo consumido en la salida
+ALTER TABLE movements ADD COLUMN IF NOT EXISTS unit_cost NUMERIC(14,4);
+ALTER TABLE movements ADD COLUMN IF NOT EXISTS total_cost NUMERIC(16,4);
+
+ALTER TABLE purchase_order_lines ADD COLUMN IF NOT EXISTS unit_cost NUMERIC(14,4) CHECK (unit_cost >= 0);
+
+-- ==============================================
+-- MOVEMENT REVERSALS
+-- ==============================================
+
+ALTER TABLE movements ADD COLUMN IF NOT EXISTS reversal_of_id INTEGER REFERENCES movements(id) ON DELETE RESTRICT
+    CHECK (reversal_of_id <> id);
+
+-- un movimiento solo tiene una reversion
+CREATE UNIQUE INDEX IF NOT EXISTS idx_movements_reversal_of_id ON movements(reversal_of_id) WHERE reversal_of_id IS NOT NULL;
+
+-- codigo de sistema: inactivo para que no se capture a mano, solo lo usan las reversiones
+INSERT INTO reason_codes (code, description, direction, is_active) VALUES
+    ('REVERSAL', 'Reversión de movimiento', 'BOTH', FALSE)
+ON CONFLICT (code) DO NOTHING;
+
+-- ==============================================
+-- MOVEMENT BATCHES
+-- ==============================================
+
+CREATE TABLE IF NOT EXISTS movement_batches (
+    id SERIAL PRIMARY KEY,
+    reference VARCHAR(50), -- documento de origen (ej. remision del proveedor)
+    created_by VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE movements ADD COLUMN IF NOT EXISTS batch_id INTEGER REFERENCES movement_batches(id) ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS idx_movements_batch_id ON movements(batch_id) WHERE batch_id IS NOT NULL;
+
+-- ==============================================
+-- PRODUCT VERSIONS (ETag / If-Match)
+-- ==============================================
+
+-- sube en cada edicion, borrado o restauracion del producto; los movimientos de stock no la tocan
+ALTER TABLE products ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+-- ==============================================
+-- STOCK CHECKPOINTS (stock a una fecha)
+-- ==============================================
+
+-- stock_ledger es el efecto de cada movimiento en el stock de cada almacen (con signo)
+-- las transferencias restan en el origen y suman en el destino, el cambio de estado no mueve stock
+CREATE OR REPLACE VIEW stock_ledger AS
+SELECT id AS movement_id, product_id, warehouse_id,
+    CASE WHEN movement_type IN ('OUT', 'TRANSFER') THEN -quantity ELSE quantity END AS quantity,
+    created_at
+FROM movements
+WHERE movement_type <> 'STATUS_CHANGE'
+    AND (movement_type <> 'TRANSFER' OR to_warehouse_id IS DISTINCT FROM warehouse_id)
+UNION ALL
+SELECT id, product_id, to_warehouse_id, quantity, created_at
+FROM movements
+WHERE movement_type = 'TRANSFER' AND to_warehouse_id IS DISTINCT FROM warehouse_id;
+
+-- stock de un producto en un almacen con todo lo registrado antes de checkpoint_at
+-- el stock a una fecha es el ultimo checkpoint anterior mas el ledger desde ese checkpoint
+CREATE TABLE IF NOT EXISTS stock_checkpoints (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
+    checkpoint_at TIMESTAMP NOT NULL,
+    quantity INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (product_id, warehouse_id, checkpoint_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_checkpoints_at ON stock_checkpoints(checkpoint_at);
+
+-- dias ya cerrados por el job de checkpoints: cada par producto/almacen con movimientos
+-- en el dia tiene un checkpoint al final de ese dia
+CREATE TABLE IF NOT EXISTS stock_checkpoint_runs (
+    checkpoint_at TIMESTAMP PRIMARY KEY,
+    checkpoints INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- ==============================================
+-- STOCK RECONCILIATION
+-- ==============================================
+
+-- solo los registra el sistema, inactivos para que no se capturen a mano
+-- OPENING_BALANCE: entrada del stock inicial al dar de alta un producto
+-- RECONCILIATION: ajuste que completa el ledger con la diferencia contra stock_levels (no mueve stock)
+INSERT INTO reason_codes (code, description, direction, is_active) VALUES
+    ('OPENING_BALANCE', 'Saldo inicial del producto', 'INCREASE', FALSE),
+    ('RECONCILIATION', 'Conciliación de stock contra movimientos', 'BOTH', FALSE)
+ON CONFLICT (code) DO NOTHING;
+
+-- cada reparacion de un producto: el stock_quantity que tenia y el que quedo (la suma de sus almacenes)
+-- los ajustes RECONCILIATION de sus almacenes llevan el id en la razon ("stock reconciliation #<id>")
+CREATE TABLE IF NOT EXISTS stock_reconciliations (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    previous_stock_quantity INTEGER NOT NULL,
+    stock_quantity INTEGER NOT NULL,
+    created_by VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_reconciliations_product_id ON stock_reconciliations(product_id);
+
+-- ==============================================
+-- COUNT LINE TRACKING
+-- ==============================================
+
+-- lot_number: lote al que entran las unidades encontradas (en faltantes es opcional, sin el se toma FEFO)
+-- serials: series de la diferencia, las faltantes si es negativa o las encontradas si es positiva
+ALTER TABLE count_lines
+    ADD COLUMN IF NOT EXISTS lot_number VARCHAR(50),
+    ADD COLUMN IF NOT EXISTS serials TEXT[] NOT NULL DEFAULT '{}';
+
 -- Datos de prueba (opcional - comentar si no se necesita)
 INSERT INTO products (sku, name, description, stock_quantity) VALUES
     ('LAPTOP-001', 'Laptop Dell XPS 15', 'Laptop profesional 15 pulgadas', 10),
@@ -122,6 +970,33 @@ INSERT INTO products (sku, name, description, stock_quantity) VALUES
     ('MONITOR-001', 'Monitor Dell 27" 4K', 'Monitor UHD 27 pulgadas', 8)
 ON CONFLICT (sku) DO NOTHING;
 
+INSERT INTO stock_levels (product_id, warehouse_id, quantity)
+SELECT p.id, w.id, p.stock_quantity
+FROM products p
+CROSS JOIN warehouses w
+WHERE w.code = 'MAIN' AND p.stock_quantity > 0
+ON CONFLICT (product_id, warehouse_id) DO NOTHING;
+
+-- saldo inicial sin costo del stock de prueba
+INSERT INTO cost_layers (product_id, warehouse_id, quantity, remaining_quantity, unit_cost)
+SELECT sl.product_id, sl.warehouse_id, sl.quantity, sl.quantity, 0
+FROM stock_levels sl
+WHERE sl.quantity > 0
+    AND NOT EXISTS (SELECT 1 FROM cost_layers cl WHERE cl.product_id = sl.product_id AND cl.warehouse_id = sl.warehouse_id);
+
+-- saldo inicial del stock de prueba para las consultas de stock a una fecha
+INSERT INTO stock_checkpoints (product_id, warehouse_id, checkpoint_at, quantity)
+SELECT sl.product_id, sl.warehouse_id, p.created_at, sl.quantity - COALESCE(l.quantity, 0)
+FROM stock_levels sl
+JOIN products p ON p.id = sl.product_id
+LEFT JOIN (
+    SELECT product_id, warehouse_id, SUM(quantity) AS quantity
+    FROM stock_ledger
+    GROUP BY product_id, warehouse_id
+) l ON l.product_id = sl.product_id AND l.warehouse_id = sl.warehouse_id
+WHERE sl.quantity - COALESCE(l.quantity, 0) <> 0
+    AND NOT EXISTS (SELECT 1 FROM stock_checkpoints sc WHERE sc.product_id = sl.product_id AND sc.warehouse_id = sl.warehouse_id);
+
 INSERT INTO users (email, password_hash, full_name, role) VALUES
 ('admin@wms.com', '$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy', 'Admin User', 'admin')
 ON CONFLICT (email) DO NOTHING;